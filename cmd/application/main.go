@@ -53,6 +53,8 @@ func main() {
 	}
 	log.SetLevel(logLevel)
 
+	cj.SetProxyConfig(conf.ProxyConfig)
+
 	regManager := cj.NewRegistrationManager(conf.RegConfig)
 	sharedLogger = regManager.Logger
 	logger := sharedLogger
@@ -147,10 +149,15 @@ func main() {
 			log.Errorf("failed to parse app config: %v", err)
 		} else {
 			regManager.OnReload(newConf.RegConfig)
+			cj.SetProxyConfig(newConf.ProxyConfig)
 		}
 	}
 
 	cancel()
 	wg.Wait()
+
+	if err := regManager.SaveLivenessCache(); err != nil {
+		logger.Errorf("failed to save liveness cache: %v", err)
+	}
 	logger.Infof("shutdown complete")
 }
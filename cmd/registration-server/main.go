@@ -35,6 +35,12 @@ type config struct {
 	DNSListenAddr      string   `toml:"dns_listen_addr"`
 	Domain             string   `toml:"domain"`
 	DNSPrivkeyPath     string   `toml:"dns_private_key_path"`
+	DNSTrustedECS      []string `toml:"dns_ecs_trusted_resolvers"`
+	DNSLimitWindow     uint16   `toml:"dns_limit_window"`
+	DNSLimitResolver   int      `toml:"dns_limit_per_resolver"`
+	DNSLimitClient     int      `toml:"dns_limit_per_client"`
+	DNSLimitTotal      int      `toml:"dns_limit_per_window"`
+	DNSLimitRefuse     bool     `toml:"dns_limit_refuse"`
 	APIPort            uint16   `toml:"api_port"`
 	ZMQAuthVerbose     bool     `toml:"zmq_auth_verbose"`
 	ZMQAuthType        string   `toml:"zmq_auth_type"`
@@ -46,6 +52,10 @@ type config struct {
 	latestClientConf   *pb.ClientConf
 	LogLevel           string `toml:"log_level"`
 	LogMetricsInterval uint16 `toml:"log_metrics_interval"`
+
+	PhantomSubnetsPath    string `toml:"phantom_subnets_path"`
+	PhantomExclusionsPath string `toml:"phantom_exclusions_path"`
+	phantomExclusions     *lib.PhantomExclusions
 }
 
 var defaultTransports = map[pb.TransportType]lib.Transport{
@@ -134,6 +144,13 @@ func loadConfig(configPath string) (*config, error) {
 		return nil, err
 	}
 
+	if conf.PhantomExclusionsPath != "" {
+		conf.phantomExclusions, err = lib.PhantomExclusionsFromFile(conf.PhantomExclusionsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load phantom exclusions: %w", err)
+		}
+	}
+
 	return conf, nil
 }
 
@@ -141,6 +158,8 @@ func main() {
 	var configPath string
 	var apiOnly, dnsOnly bool
 
+	runSubcommand()
+
 	flag.StringVar(&configPath, "config", "", "configuration file path, alternative to CJ_REGISTRAR_CONFIG env var")
 	flag.BoolVar(&apiOnly, "api-only", false, "run only the API registrar")
 	flag.BoolVar(&dnsOnly, "dns-only", false, "run only the DNS registrar")
@@ -190,9 +209,9 @@ func main() {
 
 	switch conf.ZMQAuthType {
 	case "CURVE":
-		processor, err = regprocessor.NewRegProcessor(conf.ZMQBindAddr, conf.ZMQPort, zmqPrivkey, conf.ZMQAuthVerbose, conf.StationPublicKeys, metrics)
+		processor, err = regprocessor.NewRegProcessor(conf.ZMQBindAddr, conf.ZMQPort, zmqPrivkey, conf.ZMQAuthVerbose, conf.StationPublicKeys, conf.PhantomSubnetsPath, metrics)
 	case "NULL":
-		processor, err = regprocessor.NewRegProcessorNoAuth(conf.ZMQBindAddr, conf.ZMQPort, metrics)
+		processor, err = regprocessor.NewRegProcessorNoAuth(conf.ZMQBindAddr, conf.ZMQPort, conf.PhantomSubnetsPath, metrics)
 	default:
 		log.Fatalf("Unknown ZMQ auth type: %s", conf.ZMQAuthType)
 	}
@@ -201,6 +220,8 @@ func main() {
 		log.Fatal(err)
 	}
 
+	processor.SetPhantomExclusions(conf.phantomExclusions)
+
 	for transportType, t := range defaultTransports {
 		err := processor.AddTransport(transportType, t)
 		if err != nil {
@@ -223,6 +244,22 @@ func main() {
 			log.Fatal(err)
 		}
 
+		err = dnsRegServer.SetTrustedECSResolvers(conf.DNSTrustedECS)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		err = dnsRegServer.SetLimits(dnsregserver.Limits{
+			Window:      time.Duration(conf.DNSLimitWindow) * time.Second,
+			PerResolver: conf.DNSLimitResolver,
+			PerClient:   conf.DNSLimitClient,
+			PerWindow:   conf.DNSLimitTotal,
+			Refuse:      conf.DNSLimitRefuse,
+		})
+		if err != nil {
+			log.Fatal(err)
+		}
+
 		regServers = append(regServers, dnsRegServer)
 	}
 
@@ -256,6 +293,7 @@ func main() {
 					if err != nil {
 						log.Errorf("failed to reload phantom subnets - aborting reload: %v", err)
 					}
+					processor.SetPhantomExclusions(conf.phantomExclusions)
 					if !dnsOnly && apiRegServer != nil {
 						apiRegServer.NewClientConf(conf.latestClientConf)
 					}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/refraction-networking/conjure/pkg/station/lib"
+	pb "github.com/refraction-networking/conjure/proto"
+)
+
+// validatePhantoms implements the validate-phantoms subcommand, which checks a phantom subnets
+// file against the ClientConf given to clients and the phantom blocklist of the station, and
+// prints the selection probability of every subnet. It returns the exit code.
+func validatePhantoms(args []string, out io.Writer) int {
+	var phantomsPath, clientConfPath, stationConfPath string
+
+	fs := flag.NewFlagSet("validate-phantoms", flag.ContinueOnError)
+	fs.SetOutput(out)
+	fs.StringVar(&phantomsPath, "phantoms", "", "phantom subnets file, defaults to phantom_subnets_path of the station config or the PHANTOM_SUBNET_LOCATION env var")
+	fs.StringVar(&clientConfPath, "clientconf", "", "ClientConf file whose phantom subnets must match the station")
+	fs.StringVar(&stationConfPath, "station-config", "", "station config file providing the phantom blocklist")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	stationConf := &lib.RegConfig{}
+	if stationConfPath != "" {
+		if _, err := toml.DecodeFile(stationConfPath, stationConf); err != nil {
+			fmt.Fprintf(out, "failed to load station config: %v\n", err)
+			return 2
+		}
+	}
+	if phantomsPath == "" {
+		phantomsPath = stationConf.PhantomSubnetsLocation()
+	}
+
+	var clientConf *pb.ClientConf
+	if clientConfPath != "" {
+		var err error
+		clientConf, err = parseClientConf(clientConfPath)
+		if err != nil {
+			fmt.Fprintf(out, "failed to load ClientConf: %v\n", err)
+			return 2
+		}
+	}
+
+	report, err := lib.ValidatePhantomFile(phantomsPath, clientConf, stationConf.PhantomBlocklist)
+	if err != nil {
+		fmt.Fprintf(out, "failed to load phantom subnets: %v\n", err)
+		return 2
+	}
+
+	gens := make([]uint, 0, len(report.Generations))
+	for gen := range report.Generations {
+		gens = append(gens, gen)
+	}
+	sort.Slice(gens, func(i, j int) bool { return gens[i] < gens[j] })
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "GENERATION\tSUBNET\tWEIGHT\tV6 CLIENTS\tV4 CLIENTS")
+	for _, gen := range gens {
+		for _, p := range report.Generations[gen] {
+			fmt.Fprintf(w, "%d\t%s\t%d\t%.4f\t%.4f\n", gen, p.Subnet, p.Weight, p.V6Client, p.V4Client)
+		}
+	}
+	w.Flush()
+
+	for _, e := range report.Errors {
+		fmt.Fprintln(out, "error:", e)
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintln(out, "warning:", warning)
+	}
+
+	if !report.OK() {
+		return 1
+	}
+	return 0
+}
+
+// runSubcommand runs the subcommand named by the first argument, if any, and exits.
+func runSubcommand() {
+	if len(os.Args) < 2 {
+		return
+	}
+	switch os.Args[1] {
+	case "validate-phantoms":
+		os.Exit(validatePhantoms(os.Args[2:], os.Stdout))
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/refraction-networking/conjure/proto"
+)
+
+func TestValidatePhantoms(t *testing.T) {
+	dir := t.TempDir()
+	phantomsPath, err := filepath.Abs("../../pkg/station/lib/test/phantom_subnets.toml")
+	require.Nil(t, err)
+
+	stationConfPath := filepath.Join(dir, "app_config.toml")
+	stationConf := fmt.Sprintf("phantom_subnets_path = %q\nphantom_blocklist = [\"35.8.0.0/16\"]\n", phantomsPath)
+	require.Nil(t, os.WriteFile(stationConfPath, []byte(stationConf), 0644))
+
+	clientConf, err := proto.Marshal(&pb.ClientConf{
+		Generation: proto.Uint32(2),
+		PhantomSubnetsList: &pb.PhantomSubnetsList{
+			WeightedSubnets: []*pb.PhantomSubnets{
+				{Weight: proto.Uint32(1), Subnets: []string{"192.122.190.0/28", "2001:48a8:687f:1::/96"}},
+			},
+		},
+	})
+	require.Nil(t, err)
+	clientConfPath := filepath.Join(dir, "ClientConf")
+	require.Nil(t, os.WriteFile(clientConfPath, clientConf, 0644))
+
+	// Without a phantom subnets file there is nothing to validate.
+	t.Setenv("PHANTOM_SUBNET_LOCATION", "")
+	var out bytes.Buffer
+	code := validatePhantoms([]string{"-clientconf", clientConfPath}, &out)
+	require.Equal(t, 2, code, out.String())
+
+	out.Reset()
+	code = validatePhantoms([]string{"-phantoms", phantomsPath, "-clientconf", clientConfPath}, &out)
+	require.Equal(t, 0, code, out.String())
+	require.Contains(t, out.String(), "2           192.122.190.0/28       1       0.0000      1.0000")
+
+	// The station config provides the phantom subnets file and blocklist.
+	out.Reset()
+	code = validatePhantoms([]string{"-station-config", stationConfPath, "-clientconf", clientConfPath}, &out)
+	require.Equal(t, 1, code, out.String())
+	require.Contains(t, out.String(), "error: generation 957: subnet 35.8.0.0/16 is in blocklisted subnet 35.8.0.0/16")
+}
@@ -0,0 +1,317 @@
+// Package mux multiplexes many reliable, ordered streams over a single connection, so that a
+// client can carry several covert streams over one phantom connection.
+//
+// Every frame starts with a 7 byte header followed by the frame payload:
+//
+//	type (1) | stream ID (4, big endian) | payload length (2, big endian)
+//
+// Streams are opened by the client, which numbers them with odd IDs, and are closed by either
+// side. Each side may send at most a window of data on a stream before the other side
+// acknowledges that it consumed it with a window update, so that one slow stream can not use
+// unbounded memory or hold up the others.
+package mux
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+)
+
+const (
+	frameOpen   = 0 // opens a stream, no payload
+	frameData   = 1 // stream data
+	frameWindow = 2 // grants the peer 4 bytes (big endian) more of send window
+	frameClose  = 3 // the sender closed the stream, no payload
+)
+
+const (
+	headerLen = 7
+
+	// maxFrameData is the largest data payload put in a single frame.
+	maxFrameData = 16 * 1024
+
+	// initialWindow is the amount of data that may be sent on a stream before the first window
+	// update.
+	initialWindow = 256 * 1024
+)
+
+var (
+	// ErrSessionClosed is returned when using a closed session or one of its streams.
+	ErrSessionClosed = errors.New("mux: session closed")
+
+	// ErrStreamClosed is returned when writing to a closed stream.
+	ErrStreamClosed = errors.New("mux: stream closed")
+
+	// ErrProtocol is returned when the peer violates the protocol. The session is closed.
+	ErrProtocol = errors.New("mux: protocol error")
+)
+
+// Config holds the settings of a Session.
+type Config struct {
+	// MaxStreams is the maximum number of streams open at once in a server session. Streams the
+	// peer opens beyond it are closed right away. Zero means no limit.
+	MaxStreams int
+}
+
+// Session is a connection carrying multiplexed streams.
+type Session struct {
+	conn   net.Conn
+	config Config
+	client bool
+
+	writeMu sync.Mutex
+
+	mu       sync.Mutex
+	streams  map[uint32]*Stream
+	nextID   uint32
+	accepted uint64
+	err      error
+
+	acceptCh chan *Stream
+	done     chan struct{}
+}
+
+// Server starts a session over conn that accepts streams opened by the peer.
+func Server(conn net.Conn, config Config) *Session {
+	return newSession(conn, config, false)
+}
+
+// Client starts a session over conn that opens streams to the peer.
+func Client(conn net.Conn, config Config) *Session {
+	return newSession(conn, config, true)
+}
+
+func newSession(conn net.Conn, config Config, client bool) *Session {
+	s := &Session{
+		conn:     conn,
+		config:   config,
+		client:   client,
+		streams:  make(map[uint32]*Stream),
+		nextID:   1,
+		acceptCh: make(chan *Stream, 64),
+		done:     make(chan struct{}),
+	}
+	go s.recvLoop()
+	return s
+}
+
+// Open opens a new stream to the peer. Only client sessions can open streams.
+func (s *Session) Open() (*Stream, error) {
+	if !s.client {
+		return nil, fmt.Errorf("mux: server sessions can not open streams")
+	}
+
+	// Streams are opened in the order of their IDs so that the peer can reject reused IDs.
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return nil, s.err
+	}
+	id := s.nextID
+	s.nextID += 2
+	st := newStream(s, id)
+	s.streams[id] = st
+	s.mu.Unlock()
+
+	if err := s.writeFrameLocked(frameOpen, id, nil); err != nil {
+		s.removeStream(id)
+		return nil, err
+	}
+	return st, nil
+}
+
+// Accept waits for the peer to open a stream and returns it.
+func (s *Session) Accept() (*Stream, error) {
+	select {
+	case st := <-s.acceptCh:
+		return st, nil
+	case <-s.done:
+		// Streams opened before the session closed are still handed out.
+		select {
+		case st := <-s.acceptCh:
+			return st, nil
+		default:
+		}
+		return nil, s.Err()
+	}
+}
+
+// NumStreams returns the number of streams currently open in the session.
+func (s *Session) NumStreams() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.streams)
+}
+
+// Accepted returns the number of streams the peer opened over the lifetime of the session.
+func (s *Session) Accepted() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.accepted
+}
+
+// Err returns the error that closed the session, or nil if it is open. A session closed by
+// calling Close or by the peer closing the connection reports ErrSessionClosed.
+func (s *Session) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Close closes the session, its streams and the underlying connection.
+func (s *Session) Close() error {
+	s.closeWithError(ErrSessionClosed)
+	return nil
+}
+
+// Done returns a channel that is closed once the session is closed.
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+func (s *Session) closeWithError(err error) {
+	s.mu.Lock()
+	if s.err != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.err = err
+	streams := s.streams
+	s.streams = make(map[uint32]*Stream)
+	s.mu.Unlock()
+
+	close(s.done)
+	s.conn.Close()
+	for _, st := range streams {
+		st.notifyAll()
+	}
+}
+
+func (s *Session) writeFrame(t byte, id uint32, payload []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.writeFrameLocked(t, id, payload)
+}
+
+// writeFrameLocked writes a frame to the connection. The caller must hold s.writeMu.
+func (s *Session) writeFrameLocked(t byte, id uint32, payload []byte) error {
+	var hdr [headerLen]byte
+	hdr[0] = t
+	binary.BigEndian.PutUint32(hdr[1:], id)
+	binary.BigEndian.PutUint16(hdr[5:], uint16(len(payload)))
+
+	if err := s.Err(); err != nil {
+		return err
+	}
+	_, err := s.conn.Write(append(hdr[:], payload...))
+	if err != nil {
+		s.closeWithError(ErrSessionClosed)
+	}
+	return err
+}
+
+func (s *Session) recvLoop() {
+	var hdr [headerLen]byte
+	payload := make([]byte, 0xffff)
+	for {
+		if _, err := io.ReadFull(s.conn, hdr[:]); err != nil {
+			s.closeWithError(ErrSessionClosed)
+			return
+		}
+		t := hdr[0]
+		id := binary.BigEndian.Uint32(hdr[1:])
+		n := int(binary.BigEndian.Uint16(hdr[5:]))
+		if _, err := io.ReadFull(s.conn, payload[:n]); err != nil {
+			s.closeWithError(ErrSessionClosed)
+			return
+		}
+		if err := s.handleFrame(t, id, payload[:n]); err != nil {
+			s.closeWithError(err)
+			return
+		}
+	}
+}
+
+func (s *Session) handleFrame(t byte, id uint32, payload []byte) error {
+	switch t {
+	case frameOpen:
+		return s.handleOpen(id)
+	case frameData, frameWindow, frameClose:
+	default:
+		return fmt.Errorf("%w: unknown frame type %d", ErrProtocol, t)
+	}
+
+	s.mu.Lock()
+	st := s.streams[id]
+	s.mu.Unlock()
+	if st == nil {
+		// Frames may still arrive for a stream this side already closed.
+		return nil
+	}
+
+	switch t {
+	case frameData:
+		return st.receive(payload)
+	case frameWindow:
+		if len(payload) != 4 {
+			return fmt.Errorf("%w: bad window update", ErrProtocol)
+		}
+		st.grant(binary.BigEndian.Uint32(payload))
+	case frameClose:
+		st.remoteClose()
+	}
+	return nil
+}
+
+func (s *Session) handleOpen(id uint32) error {
+	if s.client || id%2 != 1 {
+		return fmt.Errorf("%w: unexpected stream %d", ErrProtocol, id)
+	}
+
+	s.mu.Lock()
+	if _, ok := s.streams[id]; ok || id < s.nextID {
+		s.mu.Unlock()
+		return fmt.Errorf("%w: stream %d reused", ErrProtocol, id)
+	}
+	if s.config.MaxStreams > 0 && len(s.streams) >= s.config.MaxStreams {
+		s.nextID = id + 2
+		s.mu.Unlock()
+		return s.refuse(id)
+	}
+	s.nextID = id + 2
+	st := newStream(s, id)
+	s.streams[id] = st
+	s.mu.Unlock()
+
+	select {
+	case s.acceptCh <- st:
+		s.mu.Lock()
+		s.accepted++
+		s.mu.Unlock()
+		return nil
+	default:
+		s.removeStream(id)
+		return s.refuse(id)
+	}
+}
+
+// refuse closes a stream the peer opened without handing it out, leaving the other streams of
+// the session open. The refusal is written from the receive loop so that a peer opening
+// streams faster than it reads the refusals is slowed down.
+func (s *Session) refuse(id uint32) error {
+	// A failed write closes the session, and the receive loop stops on its next read.
+	_ = s.writeFrame(frameClose, id, nil)
+	return nil
+}
+
+func (s *Session) removeStream(id uint32) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.streams, id)
+}
@@ -0,0 +1,195 @@
+package mux
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func sessionPair(config Config) (*Session, *Session) {
+	c, s := net.Pipe()
+	return Client(c, Config{}), Server(s, config)
+}
+
+// echoStreams echoes everything read on each accepted stream back to it.
+func echoStreams(server *Session) {
+	for {
+		st, err := server.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer st.Close()
+			_, _ = io.Copy(st, st)
+		}()
+	}
+}
+
+func TestStreamsEcho(t *testing.T) {
+	client, server := sessionPair(Config{})
+	defer client.Close()
+	go echoStreams(server)
+
+	// Several streams, each sending more than the window, at once.
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			st, err := client.Open()
+			require.Nil(t, err)
+			defer st.Close()
+
+			data := make([]byte, 3*initialWindow+123)
+			_, _ = rand.Read(data)
+			go func() {
+				_, err := st.Write(data)
+				require.Nil(t, err)
+			}()
+
+			got := make([]byte, len(data))
+			_, err = io.ReadFull(st, got)
+			require.Nil(t, err)
+			require.True(t, bytes.Equal(data, got))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, uint64(8), server.Accepted())
+}
+
+func TestStreamClose(t *testing.T) {
+	client, server := sessionPair(Config{})
+	defer client.Close()
+
+	st, err := client.Open()
+	require.Nil(t, err)
+	_, err = st.Write([]byte("hello"))
+	require.Nil(t, err)
+	require.Nil(t, st.Close())
+
+	// The peer reads the data sent before the close, then EOF.
+	remote, err := server.Accept()
+	require.Nil(t, err)
+	require.Equal(t, uint32(1), remote.ID())
+	got, err := io.ReadAll(remote)
+	require.Nil(t, err)
+	require.Equal(t, "hello", string(got))
+
+	_, err = remote.Write([]byte("late"))
+	require.ErrorIs(t, err, ErrStreamClosed)
+	_, err = st.Read(make([]byte, 1))
+	require.ErrorIs(t, err, ErrStreamClosed)
+
+	require.Nil(t, remote.Close())
+	require.Eventually(t, func() bool { return server.NumStreams() == 0 && client.NumStreams() == 0 },
+		time.Second, 10*time.Millisecond)
+}
+
+func TestStreamDeadline(t *testing.T) {
+	client, server := sessionPair(Config{})
+	defer client.Close()
+
+	st, err := client.Open()
+	require.Nil(t, err)
+	_, err = server.Accept()
+	require.Nil(t, err)
+
+	require.Nil(t, st.SetReadDeadline(time.Now().Add(50*time.Millisecond)))
+	_, err = st.Read(make([]byte, 1))
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	var netErr net.Error
+	require.True(t, errors.As(err, &netErr) && netErr.Timeout())
+
+	// Nothing reads on the server side so the send window runs out.
+	require.Nil(t, st.SetWriteDeadline(time.Now().Add(50*time.Millisecond)))
+	n, err := st.Write(make([]byte, 2*initialWindow))
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	require.Equal(t, initialWindow, n)
+}
+
+func TestSessionLimits(t *testing.T) {
+	client, server := sessionPair(Config{MaxStreams: 2})
+	defer client.Close()
+
+	var streams []*Stream
+	for i := 0; i < 3; i++ {
+		st, err := client.Open()
+		require.Nil(t, err)
+		streams = append(streams, st)
+	}
+
+	// The stream over the limit is closed, the others and the session stay open.
+	_, err := streams[2].Read(make([]byte, 1))
+	require.ErrorIs(t, err, io.EOF)
+	require.Nil(t, server.Err())
+	remote, err := server.Accept()
+	require.Nil(t, err)
+	_, err = server.Accept()
+	require.Nil(t, err)
+	require.Equal(t, uint64(2), server.Accepted())
+
+	// Closing a stream makes room for another.
+	require.Nil(t, streams[0].Close())
+	require.Nil(t, remote.Close())
+	require.Eventually(t, func() bool { return server.NumStreams() == 1 }, time.Second, 10*time.Millisecond)
+	_, err = client.Open()
+	require.Nil(t, err)
+	_, err = server.Accept()
+	require.Nil(t, err)
+
+	_, err = server.Open()
+	require.NotNil(t, err)
+}
+
+func TestSessionAcceptBacklog(t *testing.T) {
+	client, server := sessionPair(Config{})
+	defer client.Close()
+
+	// Nothing accepts, so the streams past the backlog are closed.
+	var last *Stream
+	for i := 0; i <= cap(server.acceptCh); i++ {
+		st, err := client.Open()
+		require.Nil(t, err)
+		last = st
+	}
+	_, err := last.Read(make([]byte, 1))
+	require.ErrorIs(t, err, io.EOF)
+	require.Nil(t, server.Err())
+	require.Equal(t, cap(server.acceptCh), server.NumStreams())
+
+	_, err = server.Accept()
+	require.Nil(t, err)
+	_, err = client.Open()
+	require.Nil(t, err)
+	require.Eventually(t, func() bool { return server.Accepted() == uint64(cap(server.acceptCh)+1) },
+		time.Second, 10*time.Millisecond)
+}
+
+func TestSessionClose(t *testing.T) {
+	client, server := sessionPair(Config{})
+
+	st, err := client.Open()
+	require.Nil(t, err)
+	_, err = server.Accept()
+	require.Nil(t, err)
+
+	readErr := make(chan error, 1)
+	go func() {
+		_, err := st.Read(make([]byte, 1))
+		readErr <- err
+	}()
+
+	require.Nil(t, server.Close())
+	require.ErrorIs(t, <-readErr, ErrSessionClosed)
+	<-client.Done()
+	_, err = client.Open()
+	require.ErrorIs(t, err, ErrSessionClosed)
+}
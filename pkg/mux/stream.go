@@ -0,0 +1,251 @@
+package mux
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// Stream is a single stream of a Session. It implements net.Conn.
+type Stream struct {
+	id   uint32
+	sess *Session
+
+	mu            sync.Mutex
+	recvBuf       bytes.Buffer
+	recvWindow    int // data the peer may still send before the next window update
+	consumed      int // data read since the last window update
+	sendWindow    int
+	localClosed   bool
+	remoteClosed  bool
+	readDeadline  time.Time
+	writeDeadline time.Time
+
+	// readable and writable are signalled whenever the state of the stream changes in a way
+	// that may let a blocked Read or Write proceed.
+	readable chan struct{}
+	writable chan struct{}
+}
+
+func newStream(sess *Session, id uint32) *Stream {
+	return &Stream{
+		id:         id,
+		sess:       sess,
+		recvWindow: initialWindow,
+		sendWindow: initialWindow,
+		readable:   make(chan struct{}, 1),
+		writable:   make(chan struct{}, 1),
+	}
+}
+
+// ID returns the identifier of the stream within its session.
+func (st *Stream) ID() uint32 {
+	return st.id
+}
+
+func notify(c chan struct{}) {
+	select {
+	case c <- struct{}{}:
+	default:
+	}
+}
+
+func (st *Stream) notifyAll() {
+	notify(st.readable)
+	notify(st.writable)
+}
+
+// wait blocks until c is signalled, the deadline passes or the session closes.
+func (st *Stream) wait(c chan struct{}, deadline time.Time) error {
+	var timeout <-chan time.Time
+	if !deadline.IsZero() {
+		d := time.Until(deadline)
+		if d <= 0 {
+			return os.ErrDeadlineExceeded
+		}
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-c:
+		return nil
+	case <-timeout:
+		return os.ErrDeadlineExceeded
+	case <-st.sess.done:
+		return nil
+	}
+}
+
+// Read reads data from the stream. It returns io.EOF once the peer closed the stream and all
+// data it sent was read.
+func (st *Stream) Read(b []byte) (int, error) {
+	for {
+		st.mu.Lock()
+		if st.recvBuf.Len() > 0 {
+			n, _ := st.recvBuf.Read(b)
+			st.consumed += n
+			var update uint32
+			if st.consumed >= initialWindow/2 {
+				update = uint32(st.consumed)
+				st.recvWindow += st.consumed
+				st.consumed = 0
+			}
+			st.mu.Unlock()
+
+			if update > 0 {
+				_ = st.sess.writeFrame(frameWindow, st.id, binary.BigEndian.AppendUint32(nil, update))
+			}
+			return n, nil
+		}
+		if st.localClosed {
+			st.mu.Unlock()
+			return 0, ErrStreamClosed
+		}
+		if st.remoteClosed {
+			st.mu.Unlock()
+			return 0, io.EOF
+		}
+		if err := st.sess.Err(); err != nil {
+			st.mu.Unlock()
+			return 0, err
+		}
+		deadline := st.readDeadline
+		st.mu.Unlock()
+
+		if err := st.wait(st.readable, deadline); err != nil {
+			return 0, err
+		}
+	}
+}
+
+// Write writes data to the stream, blocking while the send window is exhausted.
+func (st *Stream) Write(b []byte) (int, error) {
+	written := 0
+	for written < len(b) {
+		st.mu.Lock()
+		if st.localClosed || st.remoteClosed {
+			st.mu.Unlock()
+			return written, ErrStreamClosed
+		}
+		if err := st.sess.Err(); err != nil {
+			st.mu.Unlock()
+			return written, err
+		}
+		if st.sendWindow == 0 {
+			deadline := st.writeDeadline
+			st.mu.Unlock()
+			if err := st.wait(st.writable, deadline); err != nil {
+				return written, err
+			}
+			continue
+		}
+
+		n := len(b) - written
+		if n > maxFrameData {
+			n = maxFrameData
+		}
+		if n > st.sendWindow {
+			n = st.sendWindow
+		}
+		st.sendWindow -= n
+		st.mu.Unlock()
+
+		if err := st.sess.writeFrame(frameData, st.id, b[written:written+n]); err != nil {
+			return written, err
+		}
+		written += n
+	}
+	return written, nil
+}
+
+// Close closes the stream in both directions and tells the peer. Closing a stream of a closed
+// session does nothing.
+func (st *Stream) Close() error {
+	st.mu.Lock()
+	if st.localClosed {
+		st.mu.Unlock()
+		return nil
+	}
+	st.localClosed = true
+	st.mu.Unlock()
+	st.notifyAll()
+
+	st.sess.removeStream(st.id)
+	// A failed write closes the session, which closes the stream on the peer too.
+	_ = st.sess.writeFrame(frameClose, st.id, nil)
+	return nil
+}
+
+func (st *Stream) receive(p []byte) error {
+	st.mu.Lock()
+	if len(p) > st.recvWindow {
+		st.mu.Unlock()
+		return fmt.Errorf("%w: stream %d exceeded its window", ErrProtocol, st.id)
+	}
+	st.recvWindow -= len(p)
+	if !st.localClosed {
+		st.recvBuf.Write(p)
+	}
+	st.mu.Unlock()
+	notify(st.readable)
+	return nil
+}
+
+func (st *Stream) grant(n uint32) {
+	st.mu.Lock()
+	st.sendWindow += int(n)
+	st.mu.Unlock()
+	notify(st.writable)
+}
+
+func (st *Stream) remoteClose() {
+	st.mu.Lock()
+	st.remoteClosed = true
+	st.mu.Unlock()
+	st.notifyAll()
+}
+
+// LocalAddr returns the local address of the session connection.
+func (st *Stream) LocalAddr() net.Addr {
+	return st.sess.conn.LocalAddr()
+}
+
+// RemoteAddr returns the remote address of the session connection.
+func (st *Stream) RemoteAddr() net.Addr {
+	return st.sess.conn.RemoteAddr()
+}
+
+// SetDeadline sets the read and write deadlines of the stream.
+func (st *Stream) SetDeadline(t time.Time) error {
+	st.mu.Lock()
+	st.readDeadline = t
+	st.writeDeadline = t
+	st.mu.Unlock()
+	st.notifyAll()
+	return nil
+}
+
+// SetReadDeadline sets the read deadline of the stream.
+func (st *Stream) SetReadDeadline(t time.Time) error {
+	st.mu.Lock()
+	st.readDeadline = t
+	st.mu.Unlock()
+	notify(st.readable)
+	return nil
+}
+
+// SetWriteDeadline sets the write deadline of the stream.
+func (st *Stream) SetWriteDeadline(t time.Time) error {
+	st.mu.Lock()
+	st.writeDeadline = t
+	st.mu.Unlock()
+	notify(st.writable)
+	return nil
+}
@@ -0,0 +1,87 @@
+package phantoms
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/refraction-networking/conjure/proto"
+)
+
+// The golden vectors are generated from the station implementation, see TestPhantomGoldenVectors
+// in pkg/station/lib.
+const phantomVectorsPath = "./testdata/phantom_vectors.json"
+
+const phantomVectorsVersion = 1
+
+// hkdfMinLibVer is the first client library version selecting phantoms with hkdf, which is the
+// only selection this package implements.
+const hkdfMinLibVer = 2
+
+type phantomVectorCorpus struct {
+	Version     int `json:"version"`
+	Generations map[string][]struct {
+		Weight  uint32
+		Subnets []string
+	} `json:"generations"`
+	Vectors []struct {
+		Seed       string `json:"seed"`
+		Generation uint   `json:"generation"`
+		LibVer     uint   `json:"libver"`
+		V6         bool   `json:"v6"`
+		Phantom    string `json:"phantom,omitempty"`
+		Err        string `json:"error,omitempty"`
+	} `json:"vectors"`
+}
+
+func TestPhantomGoldenVectors(t *testing.T) {
+	data, err := os.ReadFile(phantomVectorsPath)
+	require.Nil(t, err)
+	var corpus phantomVectorCorpus
+	require.Nil(t, json.Unmarshal(data, &corpus))
+	require.Equal(t, phantomVectorsVersion, corpus.Version)
+
+	subnetsLists := make(map[string]*pb.PhantomSubnetsList)
+	for gen, groups := range corpus.Generations {
+		list := &pb.PhantomSubnetsList{}
+		for _, group := range groups {
+			list.WeightedSubnets = append(list.WeightedSubnets, &pb.PhantomSubnets{
+				Weight:  proto.Uint32(group.Weight),
+				Subnets: group.Subnets,
+			})
+		}
+		subnetsLists[gen] = list
+	}
+
+	checked := 0
+	for _, v := range corpus.Vectors {
+		if v.LibVer < hkdfMinLibVer {
+			continue
+		}
+		checked++
+
+		list, ok := subnetsLists[strconv.FormatUint(uint64(v.Generation), 10)]
+		require.True(t, ok, "vector %+v has no generation", v)
+		seed, err := hex.DecodeString(v.Seed)
+		require.Nil(t, err)
+
+		var transform SubnetFilter
+		if !v.V6 {
+			transform = V4Only
+		}
+
+		addr, err := SelectPhantomWeighted(seed, list, transform)
+		if v.Err != "" {
+			require.NotNil(t, err, "vector %+v", v)
+			continue
+		}
+		require.Nil(t, err, "vector %+v", v)
+		require.Equal(t, v.Phantom, addr.String(), "vector %+v", v)
+	}
+	require.NotZero(t, checked)
+}
@@ -46,7 +46,14 @@ var (
 
 const (
 	// https://tools.ietf.org/html/rfc1035#section-3.2.2
-	RRTypeTXT = 16
+	RRTypeA     = 1
+	RRTypeCNAME = 5
+	RRTypeTXT   = 16
+	// https://tools.ietf.org/html/rfc3596#section-2.1
+	RRTypeAAAA = 28
+	// https://tools.ietf.org/html/rfc9460#section-14.1
+	RRTypeSVCB  = 64
+	RRTypeHTTPS = 65
 	// https://tools.ietf.org/html/rfc6891#section-6.1.1
 	RRTypeOPT = 41
 
@@ -58,6 +65,7 @@ const (
 	RcodeFormatError    = 1 // a.k.a. FORMERR
 	RcodeNameError      = 3 // a.k.a. NXDOMAIN
 	RcodeNotImplemented = 4 // a.k.a. NOTIMPL
+	RcodeRefused        = 5 // a.k.a. REFUSED
 	// https://tools.ietf.org/html/rfc6891#section-9
 	ExtendedRcodeBadVers = 16 // a.k.a. BADVERS
 )
@@ -153,6 +161,17 @@ func (name Name) TrimSuffix(suffix Name) (Name, bool) {
 	return fore, true
 }
 
+// wireFormat returns the uncompressed wire format encoding of name.
+func (name Name) wireFormat() []byte {
+	// A fresh builder has no earlier names to point to.
+	builder := newMessageBuilder()
+	err := builder.WriteName(name)
+	if err != nil {
+		panic(err)
+	}
+	return builder.Bytes()
+}
+
 // Message represents a DNS message.
 //
 // https://tools.ietf.org/html/rfc1035#section-4.1
@@ -325,6 +344,13 @@ func readRR(r io.ReadSeeker) (RR, error) {
 	if err != nil {
 		return rr, err
 	}
+	if rr.Type == RRTypeCNAME {
+		// The RDATA of a CNAME may contain compression pointers into the
+		// rest of the message, so store it decompressed.
+		// https://tools.ietf.org/html/rfc3597#section-4
+		rr.Data, err = readRDataName(r, rdLength)
+		return rr, err
+	}
 	rr.Data = make([]byte, rdLength)
 	_, err = io.ReadFull(r, rr.Data)
 	if err != nil {
@@ -334,6 +360,27 @@ func readRR(r io.ReadSeeker) (RR, error) {
 	return rr, nil
 }
 
+// readRDataName parses RDATA of length rdLength that consists of a single,
+// possibly compressed, name and returns the name in uncompressed wire format.
+func readRDataName(r io.ReadSeeker, rdLength uint16) ([]byte, error) {
+	start, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	name, err := readName(r)
+	if err != nil {
+		return nil, err
+	}
+	end, err := r.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if end-start != int64(rdLength) {
+		return nil, fmt.Errorf("RDATA length %d does not match name length %d", rdLength, end-start)
+	}
+	return name.wireFormat(), nil
+}
+
 // readMessage parses a complete DNS message. It leaves r positioned just after
 // the parsed message.
 func readMessage(r io.ReadSeeker) (Message, error) {
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"strconv"
 	"strings"
 	"testing"
@@ -595,3 +596,310 @@ func TestRDataTXTRoundTrip(t *testing.T) {
 		}
 	}
 }
+
+// payloadTestInputs are payloads exercised by the record type encoding tests.
+var payloadTestInputs = [][]byte{
+	{},
+	[]byte("\x00"),
+	[]byte("abc"),
+	[]byte("supercalifragilisticexpialidocious"),
+	bytes.Repeat([]byte{0xff}, 100),
+}
+
+func TestRDataARoundTrip(t *testing.T) {
+	for _, p := range payloadTestInputs {
+		rdatas, err := EncodeRDataA(p)
+		if err != nil {
+			t.Errorf("%+q cannot encode: %v", p, err)
+			continue
+		}
+		for _, rdata := range rdatas {
+			if len(rdata) != 4 {
+				t.Errorf("%+q encoded to A RDATA of length %d", p, len(rdata))
+			}
+		}
+		// Resolvers may reorder the records of an RRset.
+		reversed := make([][]byte, len(rdatas))
+		for i, rdata := range rdatas {
+			reversed[len(rdatas)-1-i] = rdata
+		}
+		decoded, err := DecodeRDataA(reversed)
+		if err != nil || !bytes.Equal(decoded, p) {
+			t.Errorf("%+q returned (%+q, %v)", p, decoded, err)
+			continue
+		}
+	}
+
+	// A missing record is an error, not a truncated payload.
+	rdatas, err := EncodeRDataA(bytes.Repeat([]byte("x"), 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecodeRDataA(append(rdatas[:1:1], rdatas[2:]...))
+	if err != ErrMissingRecord {
+		t.Errorf("decoding with a missing record returned %v", err)
+	}
+
+	// Too many records to number.
+	_, err = EncodeRDataA(make([]byte, 3*maxAddrRecords))
+	if err != ErrIntegerOverflow {
+		t.Errorf("encoding an oversized payload returned %v", err)
+	}
+}
+
+func TestRDataAAAARoundTrip(t *testing.T) {
+	for _, p := range payloadTestInputs {
+		rdatas, err := EncodeRDataAAAA(p)
+		if err != nil {
+			t.Errorf("%+q cannot encode: %v", p, err)
+			continue
+		}
+		for _, rdata := range rdatas {
+			if len(rdata) != 16 {
+				t.Errorf("%+q encoded to AAAA RDATA of length %d", p, len(rdata))
+			}
+		}
+		reversed := make([][]byte, len(rdatas))
+		for i, rdata := range rdatas {
+			reversed[len(rdatas)-1-i] = rdata
+		}
+		decoded, err := DecodeRDataAAAA(reversed)
+		if err != nil || !bytes.Equal(decoded, p) {
+			t.Errorf("%+q returned (%+q, %v)", p, decoded, err)
+			continue
+		}
+	}
+
+	// Duplicated records are rejected.
+	rdatas, err := EncodeRDataAAAA([]byte("abc"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecodeRDataAAAA(append(rdatas, rdatas[0]))
+	if err != ErrMissingRecord {
+		t.Errorf("decoding with a duplicated record returned %v", err)
+	}
+}
+
+func TestRDataCNAMERoundTrip(t *testing.T) {
+	suffix := mustParseName("t.example.com")
+	for _, p := range payloadTestInputs {
+		rdata, err := EncodeRDataCNAME(p, suffix)
+		if err != nil {
+			t.Errorf("%+q cannot encode: %v", p, err)
+			continue
+		}
+		decoded, err := DecodeRDataCNAME(rdata, suffix)
+		if err != nil || !bytes.Equal(decoded, p) {
+			t.Errorf("%+q returned (%+q, %v)", p, decoded, err)
+			continue
+		}
+	}
+
+	// The name must be under the suffix.
+	rdata, err := EncodeRDataCNAME([]byte("abc"), mustParseName("other.example"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecodeRDataCNAME(rdata, suffix)
+	if err == nil {
+		t.Errorf("decoding CNAME under the wrong suffix succeeded")
+	}
+
+	// Payloads that do not fit in a name are rejected.
+	_, err = EncodeRDataCNAME(make([]byte, 200), suffix)
+	if !errors.Is(err, ErrNameTooLong) {
+		t.Errorf("encoding an oversized payload returned %v", err)
+	}
+}
+
+func TestRDataCNAMECompressed(t *testing.T) {
+	// A CNAME answer whose RDATA "abc.example.com" points into the name in
+	// the Question section is stored decompressed.
+	buf := "\x12\x34\x81\x80\x00\x01\x00\x01\x00\x00\x00\x00\x03www\x07example\x03com\x00\x00\x05\x00\x01" +
+		"\xc0\x0c\x00\x05\x00\x01\x00\x00\x00\x80\x00\x06\x03abc\xc0\x10"
+	message, err := MessageFromWireFormat([]byte(buf))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "\x03abc\x07example\x03com\x00"
+	if len(message.Answer) != 1 || string(message.Answer[0].Data) != expected {
+		t.Errorf("%+q\nreturned %+v\nexpected RDATA %+q", buf, message.Answer, expected)
+	}
+
+	// The RDATA length must cover exactly the name.
+	bad := "\x12\x34\x81\x80\x00\x01\x00\x01\x00\x00\x00\x00\x03www\x07example\x03com\x00\x00\x05\x00\x01" +
+		"\xc0\x0c\x00\x05\x00\x01\x00\x00\x00\x80\x00\x07\x03abc\xc0\x10\x00"
+	_, err = MessageFromWireFormat([]byte(bad))
+	if err == nil {
+		t.Errorf("%+q parsed with mismatched RDATA length", bad)
+	}
+}
+
+func TestRDataSVCBRoundTrip(t *testing.T) {
+	for _, p := range payloadTestInputs {
+		rdata, err := EncodeRDataSVCB(p)
+		if err != nil {
+			t.Errorf("%+q cannot encode: %v", p, err)
+			continue
+		}
+		decoded, err := DecodeRDataSVCB(rdata)
+		if err != nil || !bytes.Equal(decoded, p) {
+			t.Errorf("%+q returned (%+q, %v)", p, decoded, err)
+			continue
+		}
+	}
+
+	for _, test := range []struct {
+		p   []byte
+		err error
+	}{
+		{[]byte{}, io.ErrUnexpectedEOF},
+		// No TargetName.
+		{[]byte("\x00\x01"), io.ErrUnexpectedEOF},
+		// No parameters.
+		{[]byte("\x00\x01\x00"), ErrNoPayloadParam},
+		// Only alpn.
+		{[]byte("\x00\x01\x00\x00\x01\x00\x03\x02h2"), ErrNoPayloadParam},
+		// Truncated ech value.
+		{[]byte("\x00\x01\x00\x00\x05\x00\x03ab"), io.ErrUnexpectedEOF},
+		// Compressed TargetName.
+		{[]byte("\x00\x01\xc0\x0c"), ErrReservedLabelType},
+	} {
+		_, err := DecodeRDataSVCB(test.p)
+		if err != test.err {
+			t.Errorf("%+q returned %v, expected %v", test.p, err, test.err)
+		}
+	}
+}
+
+func TestPayloadMessageRoundTrip(t *testing.T) {
+	suffix := mustParseName("t.example.com")
+	name := mustParseName("query.t.example.com")
+	p := []byte("supercalifragilisticexpialidocious")
+	for _, rrType := range []uint16{RRTypeTXT, RRTypeA, RRTypeAAAA, RRTypeCNAME, RRTypeSVCB, RRTypeHTTPS} {
+		rdatas, err := EncodePayload(rrType, p, suffix)
+		if err != nil {
+			t.Errorf("type %d cannot encode: %v", rrType, err)
+			continue
+		}
+		message := Message{
+			ID:       0x1234,
+			Flags:    0x8400,
+			Question: []Question{{Name: name, Type: rrType, Class: ClassIN}},
+		}
+		for _, rdata := range rdatas {
+			message.Answer = append(message.Answer, RR{Name: name, Type: rrType, Class: ClassIN, TTL: 60, Data: rdata})
+		}
+		buf, err := message.WireFormat()
+		if err != nil {
+			t.Errorf("type %d cannot serialize: %v", rrType, err)
+			continue
+		}
+		parsed, err := MessageFromWireFormat(buf)
+		if err != nil {
+			t.Errorf("type %d cannot parse: %v", rrType, err)
+			continue
+		}
+		var parsedRDatas [][]byte
+		for _, rr := range parsed.Answer {
+			parsedRDatas = append(parsedRDatas, rr.Data)
+		}
+		decoded, err := DecodePayload(rrType, parsedRDatas, suffix)
+		if err != nil || !bytes.Equal(decoded, p) {
+			t.Errorf("type %d returned (%+q, %v)", rrType, decoded, err)
+		}
+	}
+
+	if IsPayloadRRType(RRTypeOPT) {
+		t.Errorf("OPT reported as a payload type")
+	}
+	_, err := EncodePayload(RRTypeOPT, p, suffix)
+	if err != ErrUnsupportedRRType {
+		t.Errorf("encoding in OPT returned %v", err)
+	}
+}
+
+func TestRDataOPTRoundTrip(t *testing.T) {
+	for _, options := range [][]EDNSOption{
+		nil,
+		{{Code: EDNSOptionClientSubnet, Data: []byte("\x00\x01\x18\x00\xcb\x00\x71")}},
+		{{Code: 10, Data: []byte("cookie12")}, {Code: 12, Data: []byte{}}},
+	} {
+		rdata, err := EncodeRDataOPT(options)
+		if err != nil {
+			t.Errorf("%+v cannot encode: %v", options, err)
+			continue
+		}
+		decoded, err := DecodeRDataOPT(rdata)
+		if err != nil || len(decoded) != len(options) {
+			t.Errorf("%+v returned (%+v, %v)", options, decoded, err)
+			continue
+		}
+		for i := range options {
+			if decoded[i].Code != options[i].Code || !bytes.Equal(decoded[i].Data, options[i].Data) {
+				t.Errorf("%+v returned %+v", options, decoded)
+			}
+		}
+	}
+
+	for _, p := range []string{"\x00", "\x00\x08\x00\x04\x00\x01"} {
+		_, err := DecodeRDataOPT([]byte(p))
+		if err != io.ErrUnexpectedEOF {
+			t.Errorf("%+q returned %v", p, err)
+		}
+	}
+}
+
+func TestClientSubnet(t *testing.T) {
+	for _, test := range []struct {
+		p        string
+		expected *ClientSubnet
+		err      error
+	}{
+		{"\x00\x01\x18\x00\xcb\x00\x71", &ClientSubnet{24, 0, net.ParseIP("203.0.113.0").To4()}, nil},
+		{"\x00\x01\x00\x00", &ClientSubnet{0, 0, net.IPv4zero.To4()}, nil},
+		{"\x00\x02\x30\x00\x20\x01\x0d\xb8\x00\x01", &ClientSubnet{48, 0, net.ParseIP("2001:db8:1::")}, nil},
+		{"\x00\x01\x18", nil, io.ErrUnexpectedEOF},
+		// Unknown family.
+		{"\x00\x03\x00\x00", nil, ErrBadClientSubnet},
+		// Prefix longer than the address.
+		{"\x00\x01\x21\x00\x01\x02\x03\x04\x05", nil, ErrBadClientSubnet},
+		// Address longer than the prefix requires.
+		{"\x00\x01\x10\x00\xcb\x00\x71", nil, ErrBadClientSubnet},
+		// Bits set beyond the prefix.
+		{"\x00\x01\x14\x00\xcb\x00\x71", nil, ErrBadClientSubnet},
+	} {
+		cs, err := DecodeClientSubnet([]byte(test.p))
+		if !errors.Is(err, test.err) {
+			t.Errorf("%+q returned error %v, expected %v", test.p, err, test.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if cs.SourcePrefix != test.expected.SourcePrefix || cs.ScopePrefix != test.expected.ScopePrefix || !cs.Address.Equal(test.expected.Address) {
+			t.Errorf("%+q returned %+v, expected %+v", test.p, cs, test.expected)
+			continue
+		}
+		encoded, err := cs.Encode()
+		if err != nil || string(encoded) != test.p {
+			t.Errorf("%+v encoded to (%+q, %v), expected %+q", cs, encoded, err, test.p)
+		}
+	}
+
+	// Encoding clears bits beyond the source prefix.
+	cs := &ClientSubnet{SourcePrefix: 20, Address: net.ParseIP("203.0.113.7")}
+	encoded, err := cs.Encode()
+	if err != nil || string(encoded) != "\x00\x01\x14\x00\xcb\x00\x70" {
+		t.Errorf("%+v encoded to (%+q, %v)", cs, encoded, err)
+	}
+
+	network := (&ClientSubnet{SourcePrefix: 32, Address: net.ParseIP("203.0.113.7").To4()}).Network(24, 48)
+	if network == nil || network.String() != "203.0.113.0/24" {
+		t.Errorf("Network returned %v", network)
+	}
+	if network := (&ClientSubnet{SourcePrefix: 0, Address: net.IPv4zero.To4()}).Network(24, 48); network != nil {
+		t.Errorf("Network of an empty prefix returned %v", network)
+	}
+}
@@ -0,0 +1,158 @@
+package dns
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+)
+
+const (
+	// https://tools.ietf.org/html/rfc7871#section-6
+	EDNSOptionClientSubnet = 8
+
+	// Address families used in the Client Subnet option.
+	// https://www.iana.org/assignments/address-family-numbers
+	clientSubnetFamilyIPv4 = 1
+	clientSubnetFamilyIPv6 = 2
+)
+
+// ErrBadClientSubnet is the error returned when decoding a malformed Client
+// Subnet option.
+var ErrBadClientSubnet = errors.New("malformed client subnet option")
+
+// EDNSOption represents an option in the RDATA of an OPT RR.
+//
+// https://tools.ietf.org/html/rfc6891#section-6.1.2
+type EDNSOption struct {
+	Code uint16
+	Data []byte
+}
+
+// DecodeRDataOPT decodes the RDATA of an OPT RR as a list of options.
+//
+// https://tools.ietf.org/html/rfc6891#section-6.1.2
+func DecodeRDataOPT(p []byte) ([]EDNSOption, error) {
+	var options []EDNSOption
+	for len(p) > 0 {
+		if len(p) < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		code := binary.BigEndian.Uint16(p)
+		length := int(binary.BigEndian.Uint16(p[2:]))
+		p = p[4:]
+		if len(p) < length {
+			return nil, io.ErrUnexpectedEOF
+		}
+		options = append(options, EDNSOption{Code: code, Data: p[:length]})
+		p = p[length:]
+	}
+	return options, nil
+}
+
+// EncodeRDataOPT encodes a list of options as the RDATA of an OPT RR. It
+// returns ErrIntegerOverflow if the data of an option is longer than 65535
+// octets.
+func EncodeRDataOPT(options []EDNSOption) ([]byte, error) {
+	p := []byte{}
+	for _, option := range options {
+		if len(option.Data) > 0xffff {
+			return nil, ErrIntegerOverflow
+		}
+		p = binary.BigEndian.AppendUint16(p, option.Code)
+		p = binary.BigEndian.AppendUint16(p, uint16(len(option.Data)))
+		p = append(p, option.Data...)
+	}
+	return p, nil
+}
+
+// ClientSubnet represents an EDNS(0) Client Subnet option, which a recursive
+// resolver may attach to queries to indicate the network of the client it is
+// resolving for. Address is a 4-byte IPv4 or 16-byte IPv6 address whose bits
+// beyond SourcePrefix are zero.
+//
+// https://tools.ietf.org/html/rfc7871#section-6
+type ClientSubnet struct {
+	SourcePrefix uint8
+	ScopePrefix  uint8
+	Address      net.IP
+}
+
+// DecodeClientSubnet decodes the data of a Client Subnet option. Following
+// RFC 7871, it rejects options whose address is longer than the source prefix
+// requires or has bits set beyond the source prefix.
+//
+// https://tools.ietf.org/html/rfc7871#section-7.1.1
+func DecodeClientSubnet(p []byte) (*ClientSubnet, error) {
+	if len(p) < 4 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	family := binary.BigEndian.Uint16(p)
+	cs := &ClientSubnet{SourcePrefix: p[2], ScopePrefix: p[3]}
+	addr := p[4:]
+
+	var size int
+	switch family {
+	case clientSubnetFamilyIPv4:
+		size = net.IPv4len
+	case clientSubnetFamilyIPv6:
+		size = net.IPv6len
+	default:
+		return nil, fmt.Errorf("%w: unknown family %d", ErrBadClientSubnet, family)
+	}
+	if int(cs.SourcePrefix) > size*8 || int(cs.ScopePrefix) > size*8 {
+		return nil, fmt.Errorf("%w: prefix length too long", ErrBadClientSubnet)
+	}
+	if len(addr) != (int(cs.SourcePrefix)+7)/8 {
+		return nil, fmt.Errorf("%w: address length %d for source prefix %d", ErrBadClientSubnet, len(addr), cs.SourcePrefix)
+	}
+
+	cs.Address = make(net.IP, size)
+	copy(cs.Address, addr)
+	if !cs.Address.Mask(net.CIDRMask(int(cs.SourcePrefix), size*8)).Equal(cs.Address) {
+		return nil, fmt.Errorf("%w: address bits set beyond source prefix", ErrBadClientSubnet)
+	}
+	return cs, nil
+}
+
+// Encode encodes cs as the data of a Client Subnet option. Bits of the address
+// beyond SourcePrefix are cleared.
+func (cs *ClientSubnet) Encode() ([]byte, error) {
+	family := uint16(clientSubnetFamilyIPv6)
+	addr := cs.Address.To16()
+	if ip4 := cs.Address.To4(); ip4 != nil {
+		family = clientSubnetFamilyIPv4
+		addr = ip4
+	}
+	if addr == nil {
+		return nil, fmt.Errorf("%w: invalid address", ErrBadClientSubnet)
+	}
+	if int(cs.SourcePrefix) > len(addr)*8 || int(cs.ScopePrefix) > len(addr)*8 {
+		return nil, fmt.Errorf("%w: prefix length too long", ErrBadClientSubnet)
+	}
+	addr = addr.Mask(net.CIDRMask(int(cs.SourcePrefix), len(addr)*8))
+
+	p := binary.BigEndian.AppendUint16(nil, family)
+	p = append(p, cs.SourcePrefix, cs.ScopePrefix)
+	return append(p, addr[:(int(cs.SourcePrefix)+7)/8]...), nil
+}
+
+// Network returns the network of cs truncated to at most maxBits4 bits for
+// IPv4 or maxBits6 bits for IPv6, or nil if the option gives no address bits.
+func (cs *ClientSubnet) Network(maxBits4, maxBits6 int) *net.IPNet {
+	bits, max := 8*net.IPv6len, maxBits6
+	addr := cs.Address.To16()
+	if ip4 := cs.Address.To4(); ip4 != nil {
+		bits, max, addr = 8*net.IPv4len, maxBits4, ip4
+	}
+	ones := int(cs.SourcePrefix)
+	if ones > max {
+		ones = max
+	}
+	if addr == nil || ones <= 0 {
+		return nil
+	}
+	mask := net.CIDRMask(ones, bits)
+	return &net.IPNet{IP: addr.Mask(mask), Mask: mask}
+}
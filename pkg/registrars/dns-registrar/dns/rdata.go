@@ -0,0 +1,329 @@
+package dns
+
+import (
+	"bytes"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Besides TXT, downstream payloads can be carried in A, AAAA, CNAME, SVCB and
+// HTTPS records. The record type of the answer is the QTYPE of the query, so
+// the requester picks the encoding by the type of query it sends.
+
+var (
+	// ErrUnsupportedRRType is the error returned when encoding or decoding a
+	// payload in a record type that cannot carry one.
+	ErrUnsupportedRRType = errors.New("unsupported record type for payload")
+
+	// ErrMissingRecord is the error returned when a set of address records
+	// does not contain every record that makes up a payload.
+	ErrMissingRecord = errors.New("missing or duplicate address record")
+
+	// ErrNoPayloadParam is the error returned when SVCB or HTTPS RDATA does
+	// not contain the parameter carrying the payload.
+	ErrNoPayloadParam = errors.New("no payload parameter in service binding")
+)
+
+// base32Encoding is a base32 encoding without padding.
+var base32Encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+const (
+	// Address records begin with a one-octet sequence number, since
+	// resolvers are free to reorder the records of an RRset.
+	addrSeqLen = 1
+	// The payload in address records is preceded by a two-octet length, as
+	// the final record is padded with zeros.
+	addrLengthLen = 2
+	// An RRset of address records can hold at most this many records, the
+	// number of distinct sequence numbers.
+	maxAddrRecords = 256
+
+	// https://tools.ietf.org/html/rfc9460#section-14.3.2
+	svcParamKeyALPN = 1
+	svcParamKeyECH  = 5
+	// Service bindings are sent in ServiceMode.
+	// https://tools.ietf.org/html/rfc9460#section-2.4.3
+	svcPriority = 1
+)
+
+// svcALPN is the value of the alpn SvcParam included alongside the payload,
+// advertising HTTP/2.
+var svcALPN = []byte("\x02h2")
+
+// IsPayloadRRType reports whether payloads can be carried in records of type
+// rrType.
+func IsPayloadRRType(rrType uint16) bool {
+	switch rrType {
+	case RRTypeTXT, RRTypeA, RRTypeAAAA, RRTypeCNAME, RRTypeSVCB, RRTypeHTTPS:
+		return true
+	}
+	return false
+}
+
+// EncodePayload encodes p as the RDATA of one or more records of type rrType.
+// suffix is the domain that names encoded in CNAME records are placed under.
+func EncodePayload(rrType uint16, p []byte, suffix Name) ([][]byte, error) {
+	switch rrType {
+	case RRTypeTXT:
+		return [][]byte{EncodeRDataTXT(p)}, nil
+	case RRTypeA:
+		return EncodeRDataA(p)
+	case RRTypeAAAA:
+		return EncodeRDataAAAA(p)
+	case RRTypeCNAME:
+		rdata, err := EncodeRDataCNAME(p, suffix)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{rdata}, nil
+	case RRTypeSVCB, RRTypeHTTPS:
+		rdata, err := EncodeRDataSVCB(p)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{rdata}, nil
+	}
+	return nil, ErrUnsupportedRRType
+}
+
+// DecodePayload decodes a payload from the RDATA of the records of type rrType
+// that carry it. It is the inverse of EncodePayload.
+func DecodePayload(rrType uint16, rdatas [][]byte, suffix Name) ([]byte, error) {
+	switch rrType {
+	case RRTypeA:
+		return DecodeRDataA(rdatas)
+	case RRTypeAAAA:
+		return DecodeRDataAAAA(rdatas)
+	}
+	if !IsPayloadRRType(rrType) {
+		return nil, ErrUnsupportedRRType
+	}
+	if len(rdatas) != 1 {
+		return nil, fmt.Errorf("payload in %d records of type %d", len(rdatas), rrType)
+	}
+	switch rrType {
+	case RRTypeTXT:
+		return DecodeRDataTXT(rdatas[0])
+	case RRTypeCNAME:
+		return DecodeRDataCNAME(rdatas[0], suffix)
+	default:
+		return DecodeRDataSVCB(rdatas[0])
+	}
+}
+
+// EncodeRDataA encodes a slice of bytes as the RDATA of a set of A records.
+// Each record carries a sequence number octet followed by three octets of the
+// length-prefixed, zero-padded payload.
+//
+// https://tools.ietf.org/html/rfc1035#section-3.4.1
+func EncodeRDataA(p []byte) ([][]byte, error) {
+	return encodeAddrRecords(p, 4)
+}
+
+// DecodeRDataA decodes a payload encoded by EncodeRDataA. The records may be
+// in any order.
+func DecodeRDataA(rdatas [][]byte) ([]byte, error) {
+	return decodeAddrRecords(rdatas, 4)
+}
+
+// EncodeRDataAAAA encodes a slice of bytes as the RDATA of a set of AAAA
+// records. Each record carries a sequence number octet followed by fifteen
+// octets of the length-prefixed, zero-padded payload.
+//
+// https://tools.ietf.org/html/rfc3596#section-2.2
+func EncodeRDataAAAA(p []byte) ([][]byte, error) {
+	return encodeAddrRecords(p, 16)
+}
+
+// DecodeRDataAAAA decodes a payload encoded by EncodeRDataAAAA. The records
+// may be in any order.
+func DecodeRDataAAAA(rdatas [][]byte) ([]byte, error) {
+	return decodeAddrRecords(rdatas, 16)
+}
+
+func encodeAddrRecords(p []byte, size int) ([][]byte, error) {
+	chunk := size - addrSeqLen
+	if len(p) > 0xffff {
+		return nil, ErrIntegerOverflow
+	}
+	buf := make([]byte, addrLengthLen+len(p))
+	binary.BigEndian.PutUint16(buf, uint16(len(p)))
+	copy(buf[addrLengthLen:], p)
+
+	n := (len(buf) + chunk - 1) / chunk
+	if n > maxAddrRecords {
+		return nil, ErrIntegerOverflow
+	}
+	rdatas := make([][]byte, n)
+	for i := range rdatas {
+		rdata := make([]byte, size)
+		rdata[0] = byte(i)
+		copy(rdata[addrSeqLen:], buf[i*chunk:])
+		rdatas[i] = rdata
+	}
+	return rdatas, nil
+}
+
+func decodeAddrRecords(rdatas [][]byte, size int) ([]byte, error) {
+	for _, rdata := range rdatas {
+		if len(rdata) != size {
+			return nil, fmt.Errorf("address RDATA of length %d, expected %d", len(rdata), size)
+		}
+	}
+	sorted := append([][]byte{}, rdatas...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i][0] < sorted[j][0] })
+
+	var buf bytes.Buffer
+	for i, rdata := range sorted {
+		if int(rdata[0]) != i {
+			return nil, ErrMissingRecord
+		}
+		buf.Write(rdata[addrSeqLen:])
+	}
+	if buf.Len() < addrLengthLen {
+		return nil, io.ErrUnexpectedEOF
+	}
+	b := buf.Bytes()
+	length := int(binary.BigEndian.Uint16(b))
+	b = b[addrLengthLen:]
+	if len(b) < length {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if len(b)-length >= size-addrSeqLen {
+		// More records than the length calls for.
+		return nil, ErrTrailingBytes
+	}
+	return b[:length], nil
+}
+
+// EncodeRDataCNAME encodes a slice of bytes as the RDATA of a CNAME record,
+// a name made of the base32-encoded payload in labels of at most 63 octets,
+// followed by suffix. It returns ErrNameTooLong if the payload does not fit.
+//
+// https://tools.ietf.org/html/rfc1035#section-3.3.1
+func EncodeRDataCNAME(p []byte, suffix Name) ([]byte, error) {
+	encoded := make([]byte, base32Encoding.EncodedLen(len(p)))
+	base32Encoding.Encode(encoded, p)
+	encoded = bytes.ToLower(encoded)
+
+	var labels [][]byte
+	for len(encoded) > 0 {
+		n := len(encoded)
+		if n > 63 {
+			n = 63
+		}
+		labels = append(labels, encoded[:n])
+		encoded = encoded[n:]
+	}
+	name, err := NewName(append(labels, suffix...))
+	if err != nil {
+		return nil, err
+	}
+	return name.wireFormat(), nil
+}
+
+// DecodeRDataCNAME decodes a payload encoded by EncodeRDataCNAME. p must be
+// the uncompressed name, as stored by MessageFromWireFormat.
+func DecodeRDataCNAME(p []byte, suffix Name) ([]byte, error) {
+	r := bytes.NewReader(p)
+	name, err := readName(r)
+	if err == io.EOF {
+		return nil, io.ErrUnexpectedEOF
+	} else if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, ErrTrailingBytes
+	}
+	prefix, ok := name.TrimSuffix(suffix)
+	if !ok {
+		return nil, fmt.Errorf("CNAME %s is not under %s", name, suffix)
+	}
+	encoded := bytes.ToUpper(bytes.Join(prefix, nil))
+	payload := make([]byte, base32Encoding.DecodedLen(len(encoded)))
+	n, err := base32Encoding.Decode(payload, encoded)
+	if err != nil {
+		return nil, err
+	}
+	return payload[:n], nil
+}
+
+// EncodeRDataSVCB encodes a slice of bytes as the RDATA of a SVCB or HTTPS
+// record. The record is in ServiceMode with the root as TargetName, and
+// carries an alpn parameter along with the payload as the value of the ech
+// parameter, where an opaque blob of this size is expected.
+//
+// https://tools.ietf.org/html/rfc9460#section-2.2
+func EncodeRDataSVCB(p []byte) ([]byte, error) {
+	if len(p) > 0xffff {
+		return nil, ErrIntegerOverflow
+	}
+	var buf bytes.Buffer
+	binary.Write(&buf, binary.BigEndian, uint16(svcPriority))
+	// TargetName "." (the root).
+	buf.WriteByte(0)
+	// SvcParams must be in strictly increasing order of key.
+	for _, param := range []struct {
+		key   uint16
+		value []byte
+	}{
+		{svcParamKeyALPN, svcALPN},
+		{svcParamKeyECH, p},
+	} {
+		binary.Write(&buf, binary.BigEndian, param.key)
+		binary.Write(&buf, binary.BigEndian, uint16(len(param.value)))
+		buf.Write(param.value)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeRDataSVCB decodes a payload encoded by EncodeRDataSVCB, returning the
+// value of the ech parameter.
+func DecodeRDataSVCB(p []byte) ([]byte, error) {
+	if len(p) < 2 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	p = p[2:]
+	// TargetName is never compressed.
+	// https://tools.ietf.org/html/rfc9460#section-2.2
+	for {
+		if len(p) == 0 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		n := int(p[0])
+		if n&0xc0 != 0 {
+			return nil, ErrReservedLabelType
+		}
+		if len(p) < 1+n {
+			return nil, io.ErrUnexpectedEOF
+		}
+		p = p[1+n:]
+		if n == 0 {
+			break
+		}
+	}
+	var payload []byte
+	for len(p) > 0 {
+		if len(p) < 4 {
+			return nil, io.ErrUnexpectedEOF
+		}
+		key := binary.BigEndian.Uint16(p)
+		length := int(binary.BigEndian.Uint16(p[2:]))
+		p = p[4:]
+		if len(p) < length {
+			return nil, io.ErrUnexpectedEOF
+		}
+		if key == svcParamKeyECH {
+			payload = p[:length]
+		}
+		p = p[length:]
+	}
+	if payload == nil {
+		return nil, ErrNoPayloadParam
+	}
+	return payload, nil
+}
@@ -44,3 +44,110 @@ func RemoveResponseFormat(p []byte) ([]byte, error) {
 	}
 	return p[2 : 2+length], nil
 }
+
+// Messages too large for a single query are split into sequence-numbered
+// fragments. A fragmented request is distinguished from a single-query request
+// by its first byte: single-query requests start with the length of a noise
+// handshake message, which is never less than fragmentKindMax.
+const (
+	// FragmentUpload carries one piece of a request.
+	FragmentUpload byte = 0x00
+	// FragmentPoll asks for one piece of the response to a reassembled request.
+	FragmentPoll byte = 0x01
+
+	fragmentKindMax byte = 0x02
+)
+
+const (
+	// FragmentIDLen is the length of the identifier binding fragments of one
+	// exchange together.
+	FragmentIDLen = 8
+
+	// UploadHeaderLen is the overhead of an upload fragment.
+	UploadHeaderLen = 1 + FragmentIDLen + 1 + 1
+
+	// PollHeaderLen is the length of a poll.
+	PollHeaderLen = 1 + FragmentIDLen + 1
+
+	// DownloadHeaderLen is the overhead of a response fragment.
+	DownloadHeaderLen = 1 + 1
+)
+
+// Fragment is a parsed upload fragment or poll.
+type Fragment struct {
+	Kind  byte
+	ID    [FragmentIDLen]byte
+	Seq   uint8
+	Total uint8
+	Data  []byte
+}
+
+// IsFragment reports whether a request payload is a fragment or poll rather
+// than a single-query request.
+func IsFragment(p []byte) bool {
+	return len(p) > 0 && p[0] < fragmentKindMax
+}
+
+// AddUploadFormat prefixes one piece of a request with the fragment header.
+func AddUploadFormat(id [FragmentIDLen]byte, seq, total uint8, p []byte) []byte {
+	buf := make([]byte, 0, UploadHeaderLen+len(p))
+	buf = append(buf, FragmentUpload)
+	buf = append(buf, id[:]...)
+	buf = append(buf, seq, total)
+	return append(buf, p...)
+}
+
+// AddPollFormat builds a poll for response fragment seq of exchange id.
+func AddPollFormat(id [FragmentIDLen]byte, seq uint8) []byte {
+	buf := make([]byte, 0, PollHeaderLen)
+	buf = append(buf, FragmentPoll)
+	buf = append(buf, id[:]...)
+	return append(buf, seq)
+}
+
+// RemoveFragmentFormat parses an upload fragment or poll.
+func RemoveFragmentFormat(p []byte) (*Fragment, error) {
+	if !IsFragment(p) {
+		return nil, errors.New("not a fragment")
+	}
+
+	f := &Fragment{Kind: p[0]}
+	switch f.Kind {
+	case FragmentUpload:
+		if len(p) < UploadHeaderLen {
+			return nil, errors.New("invalid fragment length")
+		}
+		copy(f.ID[:], p[1:1+FragmentIDLen])
+		f.Seq = p[1+FragmentIDLen]
+		f.Total = p[2+FragmentIDLen]
+		f.Data = p[UploadHeaderLen:]
+		if f.Total == 0 || f.Seq >= f.Total {
+			return nil, errors.New("invalid fragment sequence number")
+		}
+	case FragmentPoll:
+		if len(p) < PollHeaderLen {
+			return nil, errors.New("invalid poll length")
+		}
+		copy(f.ID[:], p[1:1+FragmentIDLen])
+		f.Seq = p[1+FragmentIDLen]
+	}
+	return f, nil
+}
+
+// AddDownloadFormat prefixes one piece of a response with its sequence number
+// and the total number of pieces.
+func AddDownloadFormat(seq, total uint8, p []byte) []byte {
+	return append([]byte{seq, total}, p...)
+}
+
+// RemoveDownloadFormat parses one piece of a response.
+func RemoveDownloadFormat(p []byte) (seq, total uint8, data []byte, err error) {
+	if len(p) < DownloadHeaderLen {
+		return 0, 0, nil, errors.New("invalid message length")
+	}
+	seq, total = p[0], p[1]
+	if total == 0 || seq >= total {
+		return 0, 0, nil, errors.New("invalid fragment sequence number")
+	}
+	return seq, total, p[DownloadHeaderLen:], nil
+}
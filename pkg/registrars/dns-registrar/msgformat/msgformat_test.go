@@ -40,3 +40,47 @@ func TestResponseFormat(t *testing.T) {
 		}
 	}
 }
+
+func TestFragmentFormat(t *testing.T) {
+	id := [FragmentIDLen]byte{1, 2, 3, 4, 5, 6, 7, 8}
+	data := []byte("blablabla")
+
+	upload := AddUploadFormat(id, 2, 3, data)
+	if !IsFragment(upload) {
+		t.Fatalf("upload not recognized as fragment")
+	}
+	f, err := RemoveFragmentFormat(upload)
+	if err != nil {
+		t.Fatalf("err: [%v]", err)
+	}
+	if f.Kind != FragmentUpload || f.ID != id || f.Seq != 2 || f.Total != 3 || !reflect.DeepEqual(f.Data, data) {
+		t.Errorf("bad upload fragment %+v", f)
+	}
+
+	_, err = RemoveFragmentFormat(AddUploadFormat(id, 3, 3, data))
+	if err == nil {
+		t.Errorf("expected error for out of range sequence number")
+	}
+
+	f, err = RemoveFragmentFormat(AddPollFormat(id, 7))
+	if err != nil {
+		t.Fatalf("err: [%v]", err)
+	}
+	if f.Kind != FragmentPoll || f.ID != id || f.Seq != 7 {
+		t.Errorf("bad poll %+v", f)
+	}
+
+	// single-query requests carry a noise message length in the first byte
+	request, _ := AddRequestFormat(make([]byte, 48))
+	if IsFragment(request) {
+		t.Errorf("single-query request recognized as fragment")
+	}
+
+	seq, total, got, err := RemoveDownloadFormat(AddDownloadFormat(1, 2, data))
+	if err != nil {
+		t.Fatalf("err: [%v]", err)
+	}
+	if seq != 1 || total != 2 || !reflect.DeepEqual(got, data) {
+		t.Errorf("bad download fragment %d/%d %v", seq, total, got)
+	}
+}
@@ -1,8 +1,11 @@
 package requester
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net"
+
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/dns"
 )
 
 type Config struct {
@@ -23,6 +26,25 @@ type Config struct {
 
 	// DialTransport allows for a custom dialer to be used for the underlying TCP/UDP transport
 	DialTransport DialFunc
+
+	// MultiQuery forces every request to use the sequence-numbered multi-query exchange,
+	// which allows responses larger than a single DNS answer. Requests that do not fit in a
+	// single query use the multi-query exchange regardless.
+	MultiQuery bool
+
+	// TLSConfig is the TLS configuration used for DoT when no uTLS distribution is sampled,
+	// and for DoQ. If nil, the system roots are used and the server name is taken from Target.
+	TLSConfig *tls.Config
+
+	// RecordType is the DNS record type, one of dns.RRTypeTXT, dns.RRTypeA, dns.RRTypeAAAA,
+	// dns.RRTypeCNAME, dns.RRTypeSVCB or dns.RRTypeHTTPS, that responses are requested in.
+	// If zero, TXT is used. Types other than TXT always use the multi-query exchange.
+	RecordType uint16
+
+	// ClientSubnet, if not nil, is sent as the EDNS(0) Client Subnet option of every query.
+	// A SourcePrefix of 0 asks recursive resolvers not to reveal the client's network to
+	// the responder.
+	ClientSubnet *dns.ClientSubnet
 }
 
 // TransportMethodType declares the transport method to be used
@@ -32,6 +54,8 @@ const (
 	DoH TransportMethodType = iota
 	DoT
 	UDP
+	DoQ
+	TCP
 )
 
 func defaultDialTransport() DialFunc {
@@ -46,6 +70,26 @@ func (c *Config) dialTransport() DialFunc {
 	return c.DialTransport
 }
 
+func (c *Config) recordType() uint16 {
+	if c.RecordType == 0 {
+		return dns.RRTypeTXT
+	}
+	return c.RecordType
+}
+
+// optData returns the RDATA of the OPT RR to send with queries.
+func (c *Config) optData() ([]byte, error) {
+	var options []dns.EDNSOption
+	if c.ClientSubnet != nil {
+		data, err := c.ClientSubnet.Encode()
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, dns.EDNSOption{Code: dns.EDNSOptionClientSubnet, Data: data})
+	}
+	return dns.EncodeRDataOPT(options)
+}
+
 func validateConfig(config *Config) error {
 	if config == nil {
 		return fmt.Errorf("no config provided")
@@ -59,5 +103,9 @@ func validateConfig(config *Config) error {
 		return fmt.Errorf("no base domain configured")
 	}
 
+	if !dns.IsPayloadRRType(config.recordType()) {
+		return fmt.Errorf("unsupported record type %d", config.RecordType)
+	}
+
 	return nil
 }
@@ -17,8 +17,9 @@ var base32Encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
 
 // DNSPacketConn provides a packet-sending and -receiving interface over various
 // forms of DNS. It handles the details of how packets and padding are encoded
-// as a DNS name in the Question section of an upstream query, and as a TXT RR
-// in downstream responses.
+// as a DNS name in the Question section of an upstream query, and as the RDATA
+// of the answers in downstream responses. The QTYPE of the query selects the
+// type of records the responder answers with.
 //
 // DNSPacketConn does not handle the mechanics of actually sending and receiving
 // encoded DNS messages. That is rather the responsibility of some other
@@ -26,6 +27,10 @@ var base32Encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
 // which must be provided to NewDNSPacketConn.
 type DNSPacketConn struct {
 	domain dns.Name
+	// rrType is the QTYPE of queries, and the type of answers expected.
+	rrType uint16
+	// optData is the RDATA of the OPT RR sent with queries.
+	optData []byte
 	// QueuePacketConn is the direct receiver of ReadFrom and WriteTo calls.
 	// recvLoop and sendLoop take the messages out of the receive and send
 	// queues and actually put them on the network.
@@ -35,11 +40,15 @@ type DNSPacketConn struct {
 // NewDNSPacketConn creates a new DNSPacketConn. transport, through its WriteTo
 // and ReadFrom methods, handles the actual sending and receiving the DNS
 // messages encoded by DNSPacketConn. addr is the address to be passed to
-// transport.WriteTo whenever a message needs to be sent.
-func NewDNSPacketConn(transport net.Conn, addr net.Addr, domain dns.Name) *DNSPacketConn {
+// transport.WriteTo whenever a message needs to be sent. rrType is the type of
+// records that responses are requested in, and optData is the RDATA of the
+// OPT RR sent with every query (see dns.EncodeRDataOPT).
+func NewDNSPacketConn(transport net.Conn, addr net.Addr, domain dns.Name, rrType uint16, optData []byte) *DNSPacketConn {
 	// Generate a new random ClientID.
 	c := &DNSPacketConn{
 		domain:          domain,
+		rrType:          rrType,
+		optData:         optData,
 		QueuePacketConn: queuepacketconn.NewQueuePacketConn(queuepacketconn.DummyAddr{}, 0),
 	}
 	go func() {
@@ -58,9 +67,10 @@ func NewDNSPacketConn(transport net.Conn, addr net.Addr, domain dns.Name) *DNSPa
 }
 
 // dnsResponsePayload extracts the downstream payload of a DNS response, encoded
-// into the RDATA of a TXT RR. It returns nil if the message doesn't pass format
-// checks, or if the name in its Question entry is not a subdomain of domain.
-func dnsResponsePayload(resp *dns.Message, domain dns.Name) []byte {
+// into the RDATA of the answers of type rrType. It returns nil if the message
+// doesn't pass format checks, or if the name in its Answer entries is not a
+// subdomain of domain.
+func dnsResponsePayload(resp *dns.Message, domain dns.Name, rrType uint16) []byte {
 	if resp.Flags&0x8000 != 0x8000 {
 		// QR != 1, this is not a response.
 		return nil
@@ -69,22 +79,25 @@ func dnsResponsePayload(resp *dns.Message, domain dns.Name) []byte {
 		return nil
 	}
 
-	if len(resp.Answer) != 1 {
+	if len(resp.Answer) == 0 {
 		return nil
 	}
-	answer := resp.Answer[0]
+	var rdatas [][]byte
+	for _, answer := range resp.Answer {
+		_, ok := answer.Name.TrimSuffix(domain)
+		if !ok {
+			// Not the name we are expecting.
+			return nil
+		}
 
-	_, ok := answer.Name.TrimSuffix(domain)
-	if !ok {
-		// Not the name we are expecting.
-		return nil
+		if answer.Type != rrType {
+			// Only answers of the type we asked for carry data.
+			return nil
+		}
+		rdatas = append(rdatas, answer.Data)
 	}
 
-	if answer.Type != dns.RRTypeTXT {
-		// We only support TYPE == TXT.
-		return nil
-	}
-	payload, err := dns.DecodeRDataTXT(answer.Data)
+	payload, err := dns.DecodePayload(rrType, rdatas, domain)
 	if err != nil {
 		return nil
 	}
@@ -114,7 +127,7 @@ func (c *DNSPacketConn) recvLoop(transport net.Conn) error {
 			continue
 		}
 
-		payload := dnsResponsePayload(&resp, c.domain)
+		payload := dnsResponsePayload(&resp, c.domain, c.rrType)
 
 		c.QueuePacketConn.QueueIncoming(payload, transport.RemoteAddr())
 	}
@@ -135,6 +148,19 @@ func chunks(p []byte, n int) [][]byte {
 	return result
 }
 
+// maxQueryPayload returns the largest packet that send can encode into a
+// single query name under domain.
+func maxQueryPayload(domain dns.Name) int {
+	for n := 255; n > 0; n-- {
+		encoded := bytes.Repeat([]byte{'a'}, base32Encoding.EncodedLen(n))
+		labels := append(chunks(encoded, 63), domain...)
+		if _, err := dns.NewName(labels); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
 // send sends p as a single packet encoded into a DNS query, using
 // transport.WriteTo(query, addr). The length of p must be less than 224 bytes.
 //
@@ -169,7 +195,7 @@ func (c *DNSPacketConn) send(transport net.Conn, p []byte) error {
 		Question: []dns.Question{
 			{
 				Name:  name,
-				Type:  dns.RRTypeTXT,
+				Type:  c.rrType,
 				Class: dns.ClassIN,
 			},
 		},
@@ -180,7 +206,7 @@ func (c *DNSPacketConn) send(transport net.Conn, p []byte) error {
 				Type:  dns.RRTypeOPT,
 				Class: 4096, // requester's UDP payload size
 				TTL:   0,    // extended RCODE and flags
-				Data:  []byte{},
+				Data:  c.optData,
 			},
 		},
 	}
@@ -0,0 +1,219 @@
+package requester
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/queuepacketconn"
+)
+
+// doqALPN is the ALPN token for DNS over QUIC.
+const doqALPN = "doq"
+
+// doqExchangeTimeout bounds the time spent on a single query stream.
+const doqExchangeTimeout = 30 * time.Second
+
+// QUICPacketConn is a QUIC-based transport for DNS messages, used for DNS over
+// QUIC (DoQ). Each message passed to WriteTo is sent on a new bidirectional
+// stream, prefixed with a two-octet length field, and the response read from
+// the same stream is queued to be returned from ReadFrom.
+//
+// QUICPacketConn deals only with already formatted DNS messages. It does not
+// handle encoding information into the messages. That is rather the
+// responsibility of DNSPacketConn.
+//
+// https://tools.ietf.org/html/rfc9250
+type QUICPacketConn struct {
+	// QueuePacketConn is the direct receiver of ReadFrom and WriteTo calls.
+	// sendLoop takes messages out of the send queue and exchanges them on
+	// new streams.
+	*queuepacketconn.QueuePacketConn
+
+	dial func() (quic.Connection, error)
+
+	connLock sync.Mutex
+	conn     quic.Connection
+}
+
+// NewQUICPacketConn creates a new QUICPacketConn configured to use the QUIC
+// server at addr as a DNS over QUIC resolver. The UDP socket is obtained from
+// dialContext so that custom dialers can be used. It maintains one QUIC
+// connection to the resolver, reconnecting as necessary.
+func NewQUICPacketConn(addr string, tlsConfig *tls.Config, dialContext func(ctx context.Context, network, addr string) (net.Conn, error)) (*QUICPacketConn, error) {
+	if tlsConfig == nil {
+		tlsConfig = &tls.Config{}
+	}
+	tlsConfig = tlsConfig.Clone()
+	tlsConfig.NextProtos = []string{doqALPN}
+	if tlsConfig.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.ServerName = host
+	}
+
+	dial := func() (quic.Connection, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), dialTimeout)
+		defer cancel()
+		udpConn, err := dialContext(ctx, "udp", addr)
+		if err != nil {
+			return nil, err
+		}
+		conn, err := quic.Dial(ctx, &connectedPacketConn{udpConn}, udpConn.RemoteAddr(), tlsConfig, nil)
+		if err != nil {
+			udpConn.Close()
+			return nil, err
+		}
+		// quic-go does not close sockets it did not create.
+		go func() {
+			<-conn.Context().Done()
+			udpConn.Close()
+		}()
+		return conn, nil
+	}
+
+	// Do the first dial here so that any immediate and permanent connection
+	// errors are reported directly to the caller of NewQUICPacketConn.
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+
+	c := &QUICPacketConn{
+		QueuePacketConn: queuepacketconn.NewQueuePacketConn(queuepacketconn.DummyAddr{}, 0),
+		dial:            dial,
+		conn:            conn,
+	}
+	go c.sendLoop()
+	return c, nil
+}
+
+// sendLoop reads messages from the outgoing queue and exchanges each of them
+// on its own stream.
+func (c *QUICPacketConn) sendLoop() {
+	defer c.Close()
+	for p := range c.QueuePacketConn.OutgoingQueue(queuepacketconn.DummyAddr{}) {
+		conn, err := c.connection()
+		if err != nil {
+			log.Printf("dial quic: %v", err)
+			return
+		}
+		go func(p []byte) {
+			err := c.exchange(conn, p)
+			if err != nil {
+				log.Printf("exchange: %v", err)
+			}
+		}(p)
+	}
+}
+
+// connection returns the current QUIC connection, redialing if it has been
+// closed.
+func (c *QUICPacketConn) connection() (quic.Connection, error) {
+	c.connLock.Lock()
+	defer c.connLock.Unlock()
+
+	select {
+	case <-c.conn.Context().Done():
+		conn, err := c.dial()
+		if err != nil {
+			return nil, err
+		}
+		c.conn = conn
+	default:
+	}
+	return c.conn, nil
+}
+
+// exchange sends p on a new stream of conn and queues the response.
+func (c *QUICPacketConn) exchange(conn quic.Connection, p []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), doqExchangeTimeout)
+	defer cancel()
+
+	stream, err := conn.OpenStreamSync(ctx)
+	if err != nil {
+		return err
+	}
+	defer stream.CancelRead(0)
+
+	err = stream.SetDeadline(time.Now().Add(doqExchangeTimeout))
+	if err != nil {
+		return err
+	}
+
+	// https://tools.ietf.org/html/rfc9250#section-4.2.1
+	// "When sending queries over a QUIC connection, the DNS Message ID MUST
+	// be set to 0."
+	msg := make([]byte, 2+len(p))
+	binary.BigEndian.PutUint16(msg, uint16(len(p)))
+	copy(msg[2:], p)
+	if len(p) >= 2 {
+		msg[2], msg[3] = 0, 0
+	}
+
+	_, err = stream.Write(msg)
+	if err != nil {
+		return err
+	}
+	// Indicate through the STREAM FIN mechanism that no further data will be
+	// sent on the stream.
+	err = stream.Close()
+	if err != nil {
+		return err
+	}
+
+	var length uint16
+	err = binary.Read(stream, binary.BigEndian, &length)
+	if err != nil {
+		return err
+	}
+	resp := make([]byte, int(length))
+	_, err = io.ReadFull(stream, resp)
+	if err != nil {
+		return err
+	}
+
+	c.QueuePacketConn.QueueIncoming(resp, queuepacketconn.DummyAddr{})
+	return nil
+}
+
+// Close closes the QUIC connection and the underlying queues.
+func (c *QUICPacketConn) Close() error {
+	c.connLock.Lock()
+	conn := c.conn
+	c.connLock.Unlock()
+	if conn != nil {
+		_ = conn.CloseWithError(0, "")
+	}
+	return c.QueuePacketConn.Close()
+}
+
+// connectedPacketConn adapts a connected net.Conn (for example a dialed UDP
+// socket) to the net.PacketConn interface expected by quic-go. All writes go
+// to the connected peer and all reads are reported as coming from it.
+type connectedPacketConn struct {
+	net.Conn
+}
+
+func (c *connectedPacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	n, err := c.Conn.Read(p)
+	return n, c.Conn.RemoteAddr(), err
+}
+
+func (c *connectedPacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	if addr.String() != c.Conn.RemoteAddr().String() {
+		return 0, fmt.Errorf("write to %v on connection to %v", addr, c.Conn.RemoteAddr())
+	}
+	return c.Conn.Write(p)
+}
+
+var _ net.PacketConn = (*connectedPacketConn)(nil)
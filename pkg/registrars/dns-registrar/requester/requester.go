@@ -33,11 +33,17 @@ type Requester struct {
 
 	// server public key
 	pubkey []byte
+
+	// largest payload that fits in a single query
+	maxQueryPayload int
+
+	// use the multi-query exchange even when a request fits in one query
+	multiQuery bool
 }
 
 // New Requester using DoT as transport
-func dialDoT(dotaddr string, utlsDistribution string, dialTransport DialFunc) (net.Conn, error) {
-	utlsClientHelloID, err := sampleUTLSDistribution(utlsDistribution)
+func dialDoT(dotaddr string, utlsDistribution string, tlsConfig *tls.Config, dialTransport DialFunc) (net.Conn, error) {
+	utlsClientHelloID, err := SampleUTLSDistribution(utlsDistribution)
 	if err != nil {
 		return nil, err
 	}
@@ -45,11 +51,15 @@ func dialDoT(dotaddr string, utlsDistribution string, dialTransport DialFunc) (n
 	var dialTLSContext func(ctx context.Context, network, addr string) (net.Conn, error)
 	if utlsClientHelloID == nil {
 		dialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+			config, err := clientTLSConfig(tlsConfig, addr)
+			if err != nil {
+				return nil, err
+			}
 			conn, err := dialTransport(ctx, network, addr)
 			if err != nil {
 				return nil, err
 			}
-			return tls.Client(conn, &tls.Config{}), nil
+			return tls.Client(conn, config), nil
 		}
 	} else {
 		dialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
@@ -66,7 +76,7 @@ func dialDoT(dotaddr string, utlsDistribution string, dialTransport DialFunc) (n
 
 // New Requester using DoH as transport
 func dialDoH(dohurl string, utlsDistribution string, dialTransport DialFunc) (net.Conn, error) {
-	utlsClientHelloID, err := sampleUTLSDistribution(utlsDistribution)
+	utlsClientHelloID, err := SampleUTLSDistribution(utlsDistribution)
 	if err != nil {
 		return nil, err
 	}
@@ -94,6 +104,43 @@ func dialDoH(dohurl string, utlsDistribution string, dialTransport DialFunc) (ne
 	return dohconn, nil
 }
 
+// New Requester using DoQ as transport
+func dialDoQ(doqaddr string, tlsConfig *tls.Config, dialTransport DialFunc) (net.Conn, error) {
+	doqconn, err := NewQUICPacketConn(doqaddr, tlsConfig, dialTransport)
+	if err != nil {
+		return nil, err
+	}
+
+	return doqconn, nil
+}
+
+// New Requester using plain TCP as transport
+func dialTCP(tcpaddr string, dialTransport DialFunc) (net.Conn, error) {
+	tcpconn, err := NewTCPPacketConn(tcpaddr, dialTransport)
+	if err != nil {
+		return nil, err
+	}
+
+	return tcpconn, nil
+}
+
+// clientTLSConfig returns a copy of config with ServerName set from addr if
+// it was not already set.
+func clientTLSConfig(config *tls.Config, addr string) (*tls.Config, error) {
+	if config == nil {
+		config = &tls.Config{}
+	}
+	config = config.Clone()
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		config.ServerName = host
+	}
+	return config, nil
+}
+
 // New Requester using UDP as transport
 func dialUDP(remoteAddr string, dialContext DialFunc) (net.Conn, error) {
 	udpConn, err := dialContext(context.Background(), "udp", remoteAddr)
@@ -106,7 +153,7 @@ func dialUDP(remoteAddr string, dialContext DialFunc) (net.Conn, error) {
 
 func resolveAddr(config *Config) (net.Addr, error) {
 	switch config.TransportMethod {
-	case DoH, DoT:
+	case DoH, DoT, DoQ, TCP:
 		return queuepacketconn.DummyAddr{}, nil
 	case UDP:
 		addr, err := net.ResolveUDPAddr("udp", config.Target)
@@ -135,44 +182,75 @@ func NewRequester(config *Config) (*Requester, error) {
 		return nil, fmt.Errorf("error resolving addr from config: %v", err)
 	}
 
+	rrType := config.recordType()
+
+	optData, err := config.optData()
+	if err != nil {
+		return nil, fmt.Errorf("error encoding EDNS(0) options: %v", err)
+	}
+
 	dialTransport := func(dialer DialFunc) (net.PacketConn, error) {
 		switch config.TransportMethod {
 		case DoT:
-			conn, err := dialDoT(config.Target, config.UtlsDistribution, dialer)
+			conn, err := dialDoT(config.Target, config.UtlsDistribution, config.TLSConfig, dialer)
 			if err != nil {
 				return nil, fmt.Errorf("error dialing DoT connection: %v", err)
 			}
 
-			return NewDNSPacketConn(conn, addr, baseDomain), nil
+			return NewDNSPacketConn(conn, addr, baseDomain, rrType, optData), nil
+		case DoQ:
+			conn, err := dialDoQ(config.Target, config.TLSConfig, dialer)
+			if err != nil {
+				return nil, fmt.Errorf("error dialing DoQ connection: %v", err)
+			}
+
+			return NewDNSPacketConn(conn, addr, baseDomain, rrType, optData), nil
+		case TCP:
+			conn, err := dialTCP(config.Target, dialer)
+			if err != nil {
+				return nil, fmt.Errorf("error dialing TCP connection: %v", err)
+			}
+
+			return NewDNSPacketConn(conn, addr, baseDomain, rrType, optData), nil
 		case DoH:
 			conn, err := dialDoH(config.Target, config.UtlsDistribution, dialer)
 			if err != nil {
 				return nil, fmt.Errorf("error dialing DoH connection: %v", err)
 			}
 
-			return NewDNSPacketConn(conn, addr, baseDomain), nil
+			return NewDNSPacketConn(conn, addr, baseDomain, rrType, optData), nil
 		case UDP:
 			conn, err := dialUDP(config.Target, dialer)
 			if err != nil {
 				return nil, fmt.Errorf("error dialing UDP connection: %v", err)
 			}
 
-			return NewDNSPacketConn(conn, addr, baseDomain), nil
+			return NewDNSPacketConn(conn, addr, baseDomain, rrType, optData), nil
 		}
 
 		return nil, fmt.Errorf("invalid transport type configured")
 	}
 
+	maxPayload := maxQueryPayload(baseDomain)
+	if maxPayload <= msgformat.UploadHeaderLen {
+		return nil, fmt.Errorf("base domain %s leaves no room for queries", config.BaseDomain)
+	}
+
 	return &Requester{
-		dialTransport: dialTransport,
-		dialer:        config.dialTransport(),
-		remoteAddr:    addr,
-		pubkey:        config.Pubkey,
+		dialTransport:   dialTransport,
+		dialer:          config.dialTransport(),
+		remoteAddr:      addr,
+		pubkey:          config.Pubkey,
+		maxQueryPayload: maxPayload,
+		// Other record types carry less than TXT, and are only answered
+		// by responders that support the multi-query exchange.
+		multiQuery: config.MultiQuery || rrType != dns.RRTypeTXT,
 	}, nil
 }
 
-// Send the payload together with noise handshake, returns noise recvCipher for decrypting response
-func (r *Requester) sendHandshake(payload []byte) (*noise.CipherState, *noise.CipherState, error) {
+// handshake builds the noise handshake message carrying payload, returns the
+// message and the noise recvCipher for decrypting the response
+func (r *Requester) handshake(payload []byte) ([]byte, *noise.CipherState, error) {
 	config := encryption.NewConfig()
 	config.Initiator = true
 	config.PeerStatic = r.pubkey
@@ -180,19 +258,101 @@ func (r *Requester) sendHandshake(payload []byte) (*noise.CipherState, *noise.Ci
 	if err != nil {
 		return nil, nil, err
 	}
-	msgToSend, recvCipher, sendCipher, err := handshakeState.WriteMessage(nil, payload)
+	msgToSend, recvCipher, _, err := handshakeState.WriteMessage(nil, payload)
 	if err != nil {
 		return nil, nil, err
 	}
-	msgToSend, err = msgformat.AddRequestFormat([]byte(msgToSend))
+	return msgToSend, recvCipher, nil
+}
+
+// recv returns the payload of the next response from the remote address.
+func (r *Requester) recv() ([]byte, error) {
+	var recvBuf [4096]byte
+	for {
+		n, recvAddr, err := r.transport.ReadFrom(recvBuf[:])
+		if err != nil {
+			return nil, err
+		}
+		if recvAddr.String() == r.remoteAddr.String() {
+			return recvBuf[:n], nil
+		}
+	}
+}
+
+// exchangeSingle sends msg in a single query and returns the formatted
+// response payload.
+func (r *Requester) exchangeSingle(msg []byte) ([]byte, error) {
+	msgToSend, err := msgformat.AddRequestFormat(msg)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
 	_, err = r.transport.WriteTo(msgToSend, r.remoteAddr)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
+	}
+
+	recvBuf, err := r.recv()
+	if err != nil {
+		return nil, err
 	}
-	return recvCipher, sendCipher, nil
+
+	return msgformat.RemoveResponseFormat(recvBuf)
+}
+
+// exchangeFragmented uploads msg split across sequence-numbered queries, then
+// polls for every fragment of the response and returns the reassembled
+// response payload. Each query waits for its answer before the next is sent.
+func (r *Requester) exchangeFragmented(msg []byte) ([]byte, error) {
+	id := queuepacketconn.NewClientID()
+	pieces := chunks(msg, r.maxQueryPayload-msgformat.UploadHeaderLen)
+	if len(pieces) == 0 || len(pieces) > 255 {
+		return nil, fmt.Errorf("cannot fragment %d byte request", len(msg))
+	}
+	total := uint8(len(pieces))
+
+	var first []byte
+	for seq, piece := range pieces {
+		_, err := r.transport.WriteTo(msgformat.AddUploadFormat(id, uint8(seq), total, piece), r.remoteAddr)
+		if err != nil {
+			return nil, err
+		}
+		// Answers to all but the final fragment are empty acknowledgements.
+		resp, err := r.recv()
+		if err != nil {
+			return nil, err
+		}
+		first = resp
+	}
+
+	seq, respTotal, data, err := msgformat.RemoveDownloadFormat(first)
+	if err != nil {
+		return nil, err
+	}
+	if seq != 0 {
+		return nil, fmt.Errorf("unexpected response fragment %d", seq)
+	}
+	response := append([]byte{}, data...)
+
+	for k := uint8(1); k < respTotal; k++ {
+		_, err := r.transport.WriteTo(msgformat.AddPollFormat(id, k), r.remoteAddr)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := r.recv()
+		if err != nil {
+			return nil, err
+		}
+		seq, t, data, err := msgformat.RemoveDownloadFormat(resp)
+		if err != nil {
+			return nil, err
+		}
+		if seq != k || t != respTotal {
+			return nil, fmt.Errorf("unexpected response fragment %d/%d, expected %d/%d", seq, t, k, respTotal)
+		}
+		response = append(response, data...)
+	}
+
+	return msgformat.RemoveResponseFormat(response)
 }
 
 // SetDialer sets a custom dialer for the underlying TCP/UDP transport
@@ -215,23 +375,17 @@ func (r *Requester) RequestAndRecv(sendBytes []byte) ([]byte, error) {
 		r.transport = transport
 	}
 
-	recvCipher, _, err := r.sendHandshake(sendBytes)
+	msg, recvCipher, err := r.handshake(sendBytes)
 	if err != nil {
 		return nil, err
 	}
 
-	var recvBuf [4096]byte
-	for {
-		_, recvAddr, err := r.transport.ReadFrom(recvBuf[:])
-		if err != nil {
-			return nil, err
-		}
-		if recvAddr.String() == r.remoteAddr.String() {
-			break
-		}
+	var encryptedBuf []byte
+	if !r.multiQuery && len(msg) <= 255 && len(msg)+1 <= r.maxQueryPayload {
+		encryptedBuf, err = r.exchangeSingle(msg)
+	} else {
+		encryptedBuf, err = r.exchangeFragmented(msg)
 	}
-
-	encryptedBuf, err := msgformat.RemoveResponseFormat(recvBuf[:])
 	if err != nil {
 		return nil, err
 	}
@@ -248,11 +402,11 @@ func (r *Requester) Close() error {
 	return r.transport.Close()
 }
 
-// sampleUTLSDistribution parses a weighted uTLS Client Hello ID distribution
+// SampleUTLSDistribution parses a weighted uTLS Client Hello ID distribution
 // string of the form "3*Firefox,2*Chrome,1*iOS", matches each label to a
 // utls.ClientHelloID from utlsClientHelloIDMap, and randomly samples one
 // utls.ClientHelloID from the distribution.
-func sampleUTLSDistribution(spec string) (*utls.ClientHelloID, error) {
+func SampleUTLSDistribution(spec string) (*utls.ClientHelloID, error) {
 	weights, labels, err := parseWeightedList(spec)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,31 @@
+package requester
+
+import (
+	"context"
+	"net"
+)
+
+// TCPPacketConn is a TCP-based transport for DNS messages. Its WriteTo and
+// ReadFrom methods exchange DNS messages over a plain TCP connection, prefixing
+// each message with a two-octet length field.
+//
+// The framing is identical to DNS over TLS, so TCPPacketConn reuses the
+// connection management of TLSPacketConn with a dialer that does not wrap the
+// connection in TLS.
+//
+// https://tools.ietf.org/html/rfc7766
+type TCPPacketConn struct {
+	*TLSPacketConn
+}
+
+// NewTCPPacketConn creates a new TCPPacketConn configured to use the DNS
+// server at addr over TCP. It maintains a TCP connection to the server,
+// reconnecting as necessary. It closes the connection if any reconnection
+// attempt fails.
+func NewTCPPacketConn(addr string, dialContext func(ctx context.Context, network, addr string) (net.Conn, error)) (*TCPPacketConn, error) {
+	c, err := NewTLSPacketConn(addr, dialContext)
+	if err != nil {
+		return nil, err
+	}
+	return &TCPPacketConn{c}, nil
+}
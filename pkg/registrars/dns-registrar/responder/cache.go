@@ -0,0 +1,140 @@
+package responder
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/dns"
+)
+
+// answerCacheTimeout is how long a crafted answer is replayed to
+// retransmissions of its query. Resolvers give up on a query well within this.
+const answerCacheTimeout = 10 * time.Second
+
+// maxAnswerEntries bounds the number of queries held in the answerCache. Once
+// it is full, answers to new queries are not cached until entries expire.
+const maxAnswerEntries = 1 << 16
+
+// answerKey identifies a query and its retransmissions. Resolvers may
+// randomize the case of the name between retransmissions, so the name is
+// compared case-insensitively. The ID is left out as resolvers retrying over
+// another upstream path send a new one; the cached answer holds only the
+// payload, the response header is built from each query.
+//
+// https://tools.ietf.org/html/draft-vixie-dnsext-dns0x20-00
+type answerKey struct {
+	name  string
+	qtype uint16
+}
+
+func answerKeyFor(query *dns.Message) answerKey {
+	question := query.Question[0]
+	return answerKey{
+		name:  strings.ToLower(question.Name.String()),
+		qtype: question.Type,
+	}
+}
+
+// cacheState is the state of a query in the answerCache.
+type cacheState int
+
+const (
+	// cacheMiss means the query was not seen before and the caller must
+	// craft the answer and then call finish or abort.
+	cacheMiss cacheState = iota
+	// cacheInFlight means the answer to an earlier copy of the query is
+	// still being crafted.
+	cacheInFlight
+	// cacheHit means the answer to an earlier copy of the query is
+	// available to be replayed.
+	cacheHit
+)
+
+type answerEntry struct {
+	done     bool
+	response []byte
+	expires  time.Time
+}
+
+// answerCache holds the answers crafted for recent queries so that a
+// retransmitted query is answered without processing its payload again.
+type answerCache struct {
+	lock       sync.Mutex
+	entries    map[answerKey]*answerEntry
+	timeout    time.Duration
+	maxEntries int
+	lastSweep  time.Time
+}
+
+func newAnswerCache(timeout time.Duration, maxEntries int) *answerCache {
+	return &answerCache{
+		entries:    make(map[answerKey]*answerEntry),
+		timeout:    timeout,
+		maxEntries: maxEntries,
+	}
+}
+
+// begin looks up key. On a cacheMiss it records the query as in flight, unless
+// the cache is full; on a cacheHit it also returns the cached response.
+func (c *answerCache) begin(key answerKey) (cacheState, []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := time.Now()
+	c.sweep(now, false)
+
+	entry, ok := c.entries[key]
+	if ok && !entry.done {
+		return cacheInFlight, nil
+	}
+	if ok && now.Before(entry.expires) {
+		return cacheHit, entry.response
+	}
+	if !ok && len(c.entries) >= c.maxEntries {
+		c.sweep(now, true)
+		if len(c.entries) >= c.maxEntries {
+			return cacheMiss, nil
+		}
+	}
+	c.entries[key] = &answerEntry{}
+	return cacheMiss, nil
+}
+
+// finish stores the response crafted for key after a cacheMiss. Queries that
+// were not recorded as in flight because the cache was full are not stored.
+func (c *answerCache) finish(key answerKey, response []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if _, ok := c.entries[key]; !ok {
+		return
+	}
+	c.entries[key] = &answerEntry{
+		done:     true,
+		response: response,
+		expires:  time.Now().Add(c.timeout),
+	}
+}
+
+// abort forgets key after a cacheMiss whose answer could not be crafted, so
+// that a retransmission is processed afresh.
+func (c *answerCache) abort(key answerKey) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, key)
+}
+
+// sweep drops expired entries, at most once per timeout unless forced. Entries
+// still in flight are kept until finished or aborted. The caller must hold
+// c.lock.
+func (c *answerCache) sweep(now time.Time, force bool) {
+	if !force && now.Sub(c.lastSweep) < c.timeout {
+		return
+	}
+	c.lastSweep = now
+	for key, entry := range c.entries {
+		if entry.done && !now.Before(entry.expires) {
+			delete(c.entries, key)
+		}
+	}
+}
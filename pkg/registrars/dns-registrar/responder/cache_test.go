@@ -0,0 +1,58 @@
+package responder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/dns"
+	"github.com/stretchr/testify/require"
+)
+
+func testQuery(t *testing.T, id uint16, name string, qtype uint16) *dns.Message {
+	n, err := dns.ParseName(name)
+	require.Nil(t, err)
+	return &dns.Message{ID: id, Question: []dns.Question{{Name: n, Type: qtype, Class: dns.ClassIN}}}
+}
+
+func TestAnswerKey(t *testing.T) {
+	key := answerKeyFor(testQuery(t, 1, "abc.example.com", dns.RRTypeTXT))
+
+	// Retransmissions may change the ID and the case of the name.
+	require.Equal(t, key, answerKeyFor(testQuery(t, 2, "aBc.EXAMPLE.com", dns.RRTypeTXT)))
+	require.NotEqual(t, key, answerKeyFor(testQuery(t, 1, "abd.example.com", dns.RRTypeTXT)))
+	require.NotEqual(t, key, answerKeyFor(testQuery(t, 1, "abc.example.com", dns.RRTypeA)))
+}
+
+func TestAnswerCacheLimit(t *testing.T) {
+	c := newAnswerCache(time.Minute, 2)
+	a := answerKey{name: "a", qtype: dns.RRTypeTXT}
+	b := answerKey{name: "b", qtype: dns.RRTypeTXT}
+	full := answerKey{name: "c", qtype: dns.RRTypeTXT}
+
+	state, _ := c.begin(a)
+	require.Equal(t, cacheMiss, state)
+	c.finish(a, []byte("a"))
+	state, _ = c.begin(b)
+	require.Equal(t, cacheMiss, state)
+
+	// A full cache still lets new queries be answered, without keeping them.
+	state, _ = c.begin(full)
+	require.Equal(t, cacheMiss, state)
+	c.finish(full, []byte("c"))
+	require.Len(t, c.entries, 2)
+	state, _ = c.begin(full)
+	require.Equal(t, cacheMiss, state)
+
+	state, response := c.begin(a)
+	require.Equal(t, cacheHit, state)
+	require.Equal(t, []byte("a"), response)
+
+	// Expired entries make room once the cache is full.
+	c.lock.Lock()
+	c.entries[a].expires = time.Now()
+	c.lock.Unlock()
+	state, _ = c.begin(full)
+	require.Equal(t, cacheMiss, state)
+	state, _ = c.begin(full)
+	require.Equal(t, cacheInFlight, state)
+}
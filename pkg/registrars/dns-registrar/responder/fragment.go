@@ -0,0 +1,363 @@
+package responder
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/msgformat"
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/queuepacketconn"
+)
+
+// fragmentTimeout is how long a fragmented exchange is kept after its last
+// query, both to finish reassembling the request and to serve polls for the
+// response.
+const fragmentTimeout = 30 * time.Second
+
+// Default limits on the fragmented exchanges held at once, in total and from a
+// single source. Sessions are created by unauthenticated queries, so without
+// them any peer could hold unbounded memory for fragmentTimeout.
+const (
+	maxFragmentSessions       = 4096
+	maxSourceFragmentSessions = 256
+	maxFragmentBytes          = 64 << 20
+	maxSourceFragmentBytes    = 4 << 20
+)
+
+var errFragmentLimit = errors.New("fragmented exchange limit reached")
+
+// fragmentLimits bounds the sessions, and the bytes of request and response
+// fragments they hold, in total and per source.
+type fragmentLimits struct {
+	sessions, sourceSessions int
+	bytes, sourceBytes       int
+}
+
+// fragmentUsage counts the sessions, and the bytes they hold, of a source or
+// of the whole store.
+type fragmentUsage struct {
+	sessions int
+	bytes    int
+}
+
+// fragmentSession holds the state of one fragmented exchange.
+type fragmentSession struct {
+	source   string
+	size     int       // bytes of request and response fragments held
+	removed  bool      // set once the session is dropped from the store
+	lastSeen time.Time // time of the last query of the exchange
+
+	total    int
+	pieces   [][]byte
+	received int
+
+	// processOnce guards processing of the reassembled request so that
+	// retransmitted final fragments do not process it again; done is closed
+	// once response or err is set.
+	processOnce sync.Once
+	done        chan struct{}
+	response    [][]byte
+	err         error
+}
+
+// fragmentStore reassembles fragmented requests and holds fragmented
+// responses until they are polled. Each query of an exchange refreshes its
+// session, and sessions left without a query for the timeout are dropped,
+// either by the query that finds them expired or by a sweep run every half
+// timeout until the store is closed.
+type fragmentStore struct {
+	limits  fragmentLimits
+	timeout time.Duration
+
+	lock     sync.Mutex
+	sessions map[string]*fragmentSession
+	usage    fragmentUsage
+	sources  map[string]*fragmentUsage
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newFragmentStore(timeout time.Duration) *fragmentStore {
+	s := &fragmentStore{
+		limits: fragmentLimits{
+			sessions:       maxFragmentSessions,
+			sourceSessions: maxSourceFragmentSessions,
+			bytes:          maxFragmentBytes,
+			sourceBytes:    maxSourceFragmentBytes,
+		},
+		timeout:  timeout,
+		sessions: make(map[string]*fragmentSession),
+		sources:  make(map[string]*fragmentUsage),
+		closed:   make(chan struct{}),
+	}
+	go s.sweep()
+	return s
+}
+
+// close stops the sweep of expired sessions.
+func (s *fragmentStore) close() {
+	s.closeOnce.Do(func() { close(s.closed) })
+}
+
+// sweep drops the expired sessions every half timeout until the store is
+// closed.
+func (s *fragmentStore) sweep() {
+	ticker := time.NewTicker(s.timeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closed:
+			return
+		case now := <-ticker.C:
+			s.lock.Lock()
+			for key, session := range s.sessions {
+				if now.Sub(session.lastSeen) >= s.timeout {
+					s.remove(key)
+				}
+			}
+			s.lock.Unlock()
+		}
+	}
+}
+
+// touch refreshes the expiry of exchange id and returns its session, creating
+// it for source if create is set. Nothing is kept for an exchange without a
+// session. errFragmentLimit is returned if the session can not be created
+// within the limits of the store.
+func (s *fragmentStore) touch(id queuepacketconn.ClientID, source string, create bool) (*fragmentSession, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	key := id.String()
+	now := time.Now()
+	if session, ok := s.sessions[key]; ok {
+		if now.Sub(session.lastSeen) < s.timeout {
+			session.lastSeen = now
+			return session, nil
+		}
+		// Expired, but not swept yet.
+		s.remove(key)
+	}
+	if !create {
+		return nil, nil
+	}
+
+	usage := s.sources[source]
+	if usage == nil {
+		usage = &fragmentUsage{}
+	}
+	if s.usage.sessions >= s.limits.sessions || usage.sessions >= s.limits.sourceSessions {
+		return nil, errFragmentLimit
+	}
+	s.sources[source] = usage
+	s.usage.sessions++
+	usage.sessions++
+
+	session := &fragmentSession{source: source, lastSeen: now, done: make(chan struct{})}
+	s.sessions[key] = session
+	return session, nil
+}
+
+// hold accounts for n more bytes held by session, or returns errFragmentLimit
+// if they do not fit within the limits of the store. A negative n releases
+// bytes. The store lock must be held.
+func (s *fragmentStore) hold(session *fragmentSession, n int) error {
+	if session.removed {
+		// Released with the session already, and freed once its last
+		// query is answered.
+		return nil
+	}
+	usage := s.sources[session.source]
+	if n > 0 && (s.usage.bytes+n > s.limits.bytes || usage.bytes+n > s.limits.sourceBytes) {
+		return errFragmentLimit
+	}
+	s.usage.bytes += n
+	usage.bytes += n
+	session.size += n
+	return nil
+}
+
+// remove drops the session of exchange key, if any, and releases what it
+// held. The store lock must be held.
+func (s *fragmentStore) remove(key string) {
+	session, ok := s.sessions[key]
+	if !ok {
+		return
+	}
+	delete(s.sessions, key)
+	session.removed = true
+
+	usage := s.sources[session.source]
+	s.usage.sessions--
+	s.usage.bytes -= session.size
+	usage.sessions--
+	usage.bytes -= session.size
+	if usage.sessions == 0 {
+		delete(s.sources, session.source)
+	}
+}
+
+// handleFragment processes one fragment or poll and returns the TXT payload to
+// answer it with. Non-final upload fragments are answered with an empty
+// payload. The final upload fragment triggers processing of the reassembled
+// request and is answered with the first response fragment; the remaining
+// response fragments are answered to polls. maxChunk is the largest response
+// fragment that fits in the answer. admit, if not nil, is called before a
+// session is created for an exchange, and the fragment is declined with its
+// error.
+func (r *Responder) handleFragment(f *msgformat.Fragment, source string, maxChunk int, admit func() error, process func([]byte) ([]byte, error)) ([]byte, error) {
+	id := queuepacketconn.ClientID(f.ID)
+
+	switch f.Kind {
+	case msgformat.FragmentUpload:
+		session, err := r.fragments.touch(id, source, false)
+		if session == nil && err == nil {
+			if admit != nil {
+				if err := admit(); err != nil {
+					return nil, err
+				}
+			}
+			session, err = r.fragments.touch(id, source, true)
+		}
+		if err != nil {
+			r.count("dns_responder_fragment_limited", 1)
+			return nil, err
+		}
+
+		r.fragments.lock.Lock()
+		if session.total == 0 {
+			session.total = int(f.Total)
+			session.pieces = make([][]byte, f.Total)
+		}
+		if int(f.Total) != session.total {
+			r.fragments.lock.Unlock()
+			return nil, fmt.Errorf("fragment total %d != %d", f.Total, session.total)
+		}
+		// The pieces are dropped once the request is processed.
+		if session.pieces != nil && session.pieces[f.Seq] == nil {
+			if err := r.fragments.hold(session, len(f.Data)); err != nil {
+				r.fragments.lock.Unlock()
+				r.count("dns_responder_fragment_limited", 1)
+				return nil, err
+			}
+			session.pieces[f.Seq] = append([]byte{}, f.Data...)
+			session.received++
+		}
+		complete := session.received == session.total
+		r.fragments.lock.Unlock()
+
+		if int(f.Seq) != session.total-1 {
+			return []byte{}, nil
+		}
+		if !complete {
+			return nil, errors.New("final fragment received before all others")
+		}
+
+		session.processOnce.Do(func() {
+			defer close(session.done)
+			var request []byte
+			for _, piece := range session.pieces {
+				request = append(request, piece...)
+			}
+			response, err := process(request)
+
+			// The request pieces are no longer needed once processed, and
+			// the response is held in their place.
+			r.fragments.lock.Lock()
+			defer r.fragments.lock.Unlock()
+			_ = r.fragments.hold(session, -piecesLen(session.pieces))
+			session.pieces = nil
+			if err != nil {
+				session.err = err
+				return
+			}
+			pieces := splitResponse(response, maxChunk)
+			if err := r.fragments.hold(session, piecesLen(pieces)); err != nil {
+				r.count("dns_responder_fragment_limited", 1)
+				session.err = err
+				return
+			}
+			session.response = pieces
+		})
+		<-session.done
+		if session.err != nil {
+			return nil, session.err
+		}
+		return session.response[0], nil
+
+	case msgformat.FragmentPoll:
+		session, _ := r.fragments.touch(id, source, false)
+		if session == nil {
+			return nil, fmt.Errorf("poll for unknown exchange %s", id)
+		}
+		select {
+		case <-session.done:
+		default:
+			return nil, fmt.Errorf("poll for unprocessed exchange %s", id)
+		}
+		if session.err != nil {
+			return nil, session.err
+		}
+		if int(f.Seq) >= len(session.response) {
+			return nil, fmt.Errorf("poll for fragment %d of %d", f.Seq, len(session.response))
+		}
+		return session.response[f.Seq], nil
+	}
+
+	return nil, fmt.Errorf("unknown fragment kind %d", f.Kind)
+}
+
+// sourceKey returns the source that the fragmented exchanges of the query
+// described by info are accounted to, the address of its resolver without the
+// port.
+func sourceKey(info *QueryInfo) string {
+	if info == nil || info.Resolver == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(info.Resolver.String())
+	if err != nil {
+		return info.Resolver.String()
+	}
+	return host
+}
+
+// piecesLen returns the number of bytes in pieces.
+func piecesLen(pieces [][]byte) int {
+	n := 0
+	for _, piece := range pieces {
+		n += len(piece)
+	}
+	return n
+}
+
+// splitResponse breaks a formatted response into download fragments carrying
+// at most maxChunk bytes each, including the fragment header.
+func splitResponse(p []byte, maxChunk int) [][]byte {
+	size := maxChunk - msgformat.DownloadHeaderLen
+	if size < 1 {
+		size = 1
+	}
+	pieces := [][]byte{}
+	for len(p) > 0 || len(pieces) == 0 {
+		n := len(p)
+		if n > size {
+			n = size
+		}
+		pieces = append(pieces, p[:n])
+		p = p[n:]
+	}
+	total := uint8(len(pieces))
+	if int(total) != len(pieces) {
+		// Too many fragments to number; the first fragment signals an
+		// empty response instead.
+		return [][]byte{msgformat.AddDownloadFormat(0, 1, nil)}
+	}
+	out := make([][]byte, len(pieces))
+	for i, piece := range pieces {
+		out[i] = msgformat.AddDownloadFormat(uint8(i), total, piece)
+	}
+	return out
+}
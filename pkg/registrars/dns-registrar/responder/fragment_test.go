@@ -0,0 +1,173 @@
+package responder
+
+import (
+	"testing"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/msgformat"
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/queuepacketconn"
+	"github.com/stretchr/testify/require"
+)
+
+func fragmentID(i byte) queuepacketconn.ClientID {
+	return queuepacketconn.ClientID{i}
+}
+
+func TestFragmentStoreSessionLimits(t *testing.T) {
+	s := newFragmentStore(time.Minute)
+	defer s.close()
+	s.limits.sessions = 3
+	s.limits.sourceSessions = 2
+
+	_, err := s.touch(fragmentID(1), "a", true)
+	require.Nil(t, err)
+	_, err = s.touch(fragmentID(2), "a", true)
+	require.Nil(t, err)
+	_, err = s.touch(fragmentID(3), "a", true)
+	require.ErrorIs(t, err, errFragmentLimit)
+
+	// Existing sessions are still returned, and other sources can create
+	// sessions up to the total limit.
+	session, err := s.touch(fragmentID(1), "a", true)
+	require.Nil(t, err)
+	require.NotNil(t, session)
+	_, err = s.touch(fragmentID(3), "b", true)
+	require.Nil(t, err)
+	_, err = s.touch(fragmentID(4), "c", true)
+	require.ErrorIs(t, err, errFragmentLimit)
+
+	s.lock.Lock()
+	s.remove(fragmentID(1).String())
+	s.lock.Unlock()
+	_, err = s.touch(fragmentID(4), "c", true)
+	require.Nil(t, err)
+}
+
+func TestFragmentStoreUnknownExchanges(t *testing.T) {
+	s := newFragmentStore(time.Minute)
+	defer s.close()
+	s.limits.sessions = 1
+
+	// Polls for unknown exchanges and refused uploads keep nothing.
+	for i := byte(0); i < 100; i++ {
+		session, err := s.touch(fragmentID(i), "a", false)
+		require.Nil(t, err)
+		require.Nil(t, session)
+	}
+	_, err := s.touch(fragmentID(0), "a", true)
+	require.Nil(t, err)
+	for i := byte(1); i < 100; i++ {
+		_, err := s.touch(fragmentID(i), "b", true)
+		require.ErrorIs(t, err, errFragmentLimit)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	require.Len(t, s.sessions, 1)
+	require.Len(t, s.sources, 1)
+}
+
+func TestFragmentStoreByteLimits(t *testing.T) {
+	r := &Responder{fragments: newFragmentStore(time.Minute)}
+	defer r.fragments.close()
+	r.fragments.limits.bytes = 30
+	r.fragments.limits.sourceBytes = 20
+
+	upload := func(id byte, seq uint8) error {
+		f := &msgformat.Fragment{ID: fragmentID(id), Kind: msgformat.FragmentUpload, Seq: seq, Total: 4, Data: make([]byte, 10)}
+		_, err := r.handleFragment(f, string('a'+rune(id%2)), 100, nil, nil)
+		return err
+	}
+
+	require.Nil(t, upload(0, 0))
+	require.Nil(t, upload(0, 1))
+	require.ErrorIs(t, upload(0, 2), errFragmentLimit)
+	// Retransmitted fragments are not held again.
+	require.Nil(t, upload(0, 1))
+
+	require.Nil(t, upload(1, 0))
+	require.ErrorIs(t, upload(1, 1), errFragmentLimit)
+	require.Equal(t, 30, r.fragments.usage.bytes)
+
+	r.fragments.lock.Lock()
+	r.fragments.remove(fragmentID(0).String())
+	r.fragments.lock.Unlock()
+	require.Nil(t, upload(1, 1))
+	require.Equal(t, 20, r.fragments.usage.bytes)
+}
+
+func TestFragmentAdmission(t *testing.T) {
+	r := &Responder{fragments: newFragmentStore(time.Minute)}
+	defer r.fragments.close()
+
+	admitted := 0
+	refuse := true
+	admit := func() error {
+		admitted++
+		if refuse {
+			return ErrRefused
+		}
+		return nil
+	}
+	upload := func(seq uint8) error {
+		f := &msgformat.Fragment{ID: fragmentID(1), Kind: msgformat.FragmentUpload, Seq: seq, Total: 3, Data: make([]byte, 10)}
+		_, err := r.handleFragment(f, "a", 100, admit, nil)
+		return err
+	}
+
+	// A declined exchange keeps no state.
+	require.ErrorIs(t, upload(0), ErrRefused)
+	require.Equal(t, 1, admitted)
+	require.Len(t, r.fragments.sessions, 0)
+	require.Equal(t, 0, r.fragments.usage.bytes)
+
+	// Admission is checked once per exchange.
+	refuse = false
+	require.Nil(t, upload(0))
+	require.Nil(t, upload(1))
+	require.Equal(t, 2, admitted)
+	require.Len(t, r.fragments.sessions, 1)
+}
+
+func TestFragmentStoreExpiry(t *testing.T) {
+	s := newFragmentStore(20 * time.Millisecond)
+	id := fragmentID(1)
+
+	old, err := s.touch(id, "a", true)
+	require.Nil(t, err)
+
+	// Hold the store while the exchange expires, so that the query
+	// recreating it races the sweep.
+	s.lock.Lock()
+	time.Sleep(100 * time.Millisecond)
+	s.lock.Unlock()
+
+	session, err := s.touch(id, "a", true)
+	require.Nil(t, err)
+	require.NotSame(t, old, session)
+
+	// Whichever ran first, the sweep must not drop the new session.
+	time.Sleep(5 * time.Millisecond)
+	current, err := s.touch(id, "a", false)
+	require.Nil(t, err)
+	require.Same(t, session, current)
+
+	s.lock.Lock()
+	require.Equal(t, fragmentUsage{sessions: 1}, s.usage)
+	s.lock.Unlock()
+
+	// An idle exchange is swept, until the store is closed.
+	require.Eventually(t, func() bool {
+		s.lock.Lock()
+		defer s.lock.Unlock()
+		return len(s.sessions) == 0
+	}, time.Second, 5*time.Millisecond)
+
+	s.close()
+	_, err = s.touch(id, "a", true)
+	require.Nil(t, err)
+	time.Sleep(100 * time.Millisecond)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	require.Len(t, s.sessions, 1)
+}
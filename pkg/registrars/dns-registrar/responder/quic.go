@@ -0,0 +1,161 @@
+package responder
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go"
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/queuepacketconn"
+)
+
+// doqALPN is the ALPN token for DNS over QUIC.
+const doqALPN = "doq"
+
+// doqStreamTimeout bounds how long a query stream is kept open waiting for
+// the query and its response.
+const doqStreamTimeout = 30 * time.Second
+
+// streamAddr identifies a single query stream on a QUIC connection.
+type streamAddr struct {
+	remote net.Addr
+	id     quic.StreamID
+}
+
+func (a streamAddr) Network() string { return "doq" }
+func (a streamAddr) String() string  { return fmt.Sprintf("%s/%d", a.remote, a.id) }
+
+// QUICPacketConn is a net.PacketConn that serves DNS over QUIC. Each query
+// arrives on its own bidirectional stream; ReadFrom returns the query tagged
+// with an address identifying the stream, and WriteTo sends the response on
+// that stream and closes it.
+//
+// https://tools.ietf.org/html/rfc9250
+type QUICPacketConn struct {
+	// QueuePacketConn holds the queue of incoming messages returned by
+	// ReadFrom.
+	*queuepacketconn.QueuePacketConn
+
+	ln *quic.Listener
+
+	streamsLock sync.Mutex
+	streams     map[string]quic.Stream
+}
+
+// ListenQUIC listens for DNS over QUIC connections on addr, using config for
+// the TLS server configuration. The "doq" ALPN is added to config.
+func ListenQUIC(addr string, config *tls.Config) (*QUICPacketConn, error) {
+	config = config.Clone()
+	config.NextProtos = []string{doqALPN}
+
+	ln, err := quic.ListenAddr(addr, config, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &QUICPacketConn{
+		QueuePacketConn: queuepacketconn.NewQueuePacketConn(ln.Addr(), 0),
+		ln:              ln,
+		streams:         make(map[string]quic.Stream),
+	}
+	go c.acceptLoop()
+	return c, nil
+}
+
+func (c *QUICPacketConn) acceptLoop() {
+	for {
+		conn, err := c.ln.Accept(context.Background())
+		if err != nil {
+			if errors.Is(err, quic.ErrServerClosed) {
+				return
+			}
+			log.Printf("Accept error: %v", err)
+			continue
+		}
+		go c.handleConn(conn)
+	}
+}
+
+func (c *QUICPacketConn) handleConn(conn quic.Connection) {
+	for {
+		stream, err := conn.AcceptStream(context.Background())
+		if err != nil {
+			return
+		}
+		go c.handleStream(conn.RemoteAddr(), stream)
+	}
+}
+
+// handleStream reads the single query sent on stream and queues it. The
+// stream is registered so that WriteTo can answer on it, and is cancelled if
+// no answer is written before doqStreamTimeout.
+func (c *QUICPacketConn) handleStream(remote net.Addr, stream quic.Stream) {
+	addr := streamAddr{remote: remote, id: stream.StreamID()}
+
+	err := stream.SetDeadline(time.Now().Add(doqStreamTimeout))
+	if err != nil {
+		stream.CancelRead(0)
+		stream.CancelWrite(0)
+		return
+	}
+
+	p, err := readLengthPrefixed(stream)
+	if err != nil {
+		log.Printf("stream read error from %v: %v", addr, err)
+		stream.CancelRead(0)
+		stream.CancelWrite(0)
+		return
+	}
+
+	c.streamsLock.Lock()
+	c.streams[addr.String()] = stream
+	c.streamsLock.Unlock()
+
+	c.QueuePacketConn.QueueIncoming(p, addr)
+
+	time.AfterFunc(doqStreamTimeout, func() {
+		if s := c.takeStream(addr); s != nil {
+			s.CancelWrite(0)
+		}
+	})
+}
+
+func (c *QUICPacketConn) takeStream(addr net.Addr) quic.Stream {
+	c.streamsLock.Lock()
+	defer c.streamsLock.Unlock()
+	stream, ok := c.streams[addr.String()]
+	if !ok {
+		return nil
+	}
+	delete(c.streams, addr.String())
+	return stream
+}
+
+// WriteTo sends p, length-prefixed, as the response on the stream identified
+// by addr and closes the stream.
+func (c *QUICPacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	stream := c.takeStream(addr)
+	if stream == nil {
+		return 0, fmt.Errorf("no open stream for %v", addr)
+	}
+
+	err := writeLengthPrefixed(stream, p)
+	if err != nil {
+		stream.CancelWrite(0)
+		return 0, err
+	}
+	return len(p), stream.Close()
+}
+
+// Close closes the listener and all of its connections, and unblocks pending
+// ReadFrom calls.
+func (c *QUICPacketConn) Close() error {
+	err := c.ln.Close()
+	c.QueuePacketConn.Close()
+	return err
+}
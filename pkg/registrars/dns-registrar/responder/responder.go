@@ -3,8 +3,11 @@ package responder
 import (
 	"bytes"
 	"encoding/base32"
+	"errors"
+	"fmt"
 	"log"
 	"net"
+	"sync/atomic"
 
 	"github.com/flynn/noise"
 	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/dns"
@@ -15,6 +18,13 @@ import (
 const (
 	// How to set the TTL field in Answer resource records.
 	responseTTL = 60
+
+	// Number of queries answered concurrently.
+	respondWorkers = 64
+
+	// Number of queries waiting for a worker before further queries are
+	// dropped.
+	maxQueuedQueries = 1024
 )
 
 // base32Encoding is a base32 encoding without padding.
@@ -26,10 +36,48 @@ type Responder struct {
 	transport     net.PacketConn
 	noiseConfig   noise.Config
 	maxUDPPayload int
+	fragments     *fragmentStore
+	answers       *answerCache
+	metrics       func(name string, val int)
+	admit         func(info *QueryInfo) error
+	closed        atomic.Bool
 }
 
+// pendingQuery is a DNS message waiting to be answered, and the address it came from.
+type pendingQuery struct {
+	buf  []byte
+	addr net.Addr
+}
+
+// QueryInfo describes the DNS query that carried a request.
+type QueryInfo struct {
+	// Resolver is the address the query was received from, usually that of a
+	// recursive resolver rather than of the client.
+	Resolver net.Addr
+
+	// ClientSubnet is the EDNS(0) Client Subnet option of the query, or nil if
+	// it had none. It is set by the resolver and is only as trustworthy as
+	// the resolver is.
+	ClientSubnet *dns.ClientSubnet
+}
+
+// ResponseFunc processes the decrypted payload of a request carried by the
+// query described by info, and returns the payload of the response. Returning
+// ErrRefused or ErrNoAnswer (or an error wrapping them) declines the request.
+type ResponseFunc func(payload []byte, info *QueryInfo) ([]byte, error)
+
+var (
+	// ErrRefused declines a request by answering its query with a REFUSED
+	// RCODE.
+	ErrRefused = errors.New("request refused")
+
+	// ErrNoAnswer declines a request by answering its query with an empty
+	// Answer section.
+	ErrNoAnswer = errors.New("request not answered")
+)
+
 // Decrypt the message and pass it to processMsg, then encrypt and return the response.
-func (r *Responder) craftResponse(msg []byte, processMsg func([]byte) ([]byte, error)) ([]byte, error) {
+func (r *Responder) craftResponse(msg []byte, info *QueryInfo, processMsg ResponseFunc) ([]byte, error) {
 	handshakeState, err := noise.NewHandshakeState(r.noiseConfig)
 	if err != nil {
 		return nil, err
@@ -40,7 +88,7 @@ func (r *Responder) craftResponse(msg []byte, processMsg func([]byte) ([]byte, e
 		return nil, err
 	}
 
-	responseBytes, err := processMsg(payload)
+	responseBytes, err := processMsg(payload, info)
 	if err != nil {
 		return nil, err
 	}
@@ -52,11 +100,13 @@ func (r *Responder) craftResponse(msg []byte, processMsg func([]byte) ([]byte, e
 }
 
 // responseFor constructs a response dns.Message that is appropriate for query.
-// Along with the dns.Message, it returns the query's decoded data payload. If
+// Along with the dns.Message, it returns the query's decoded data payload and
+// its EDNS(0) Client Subnet option, if it has one. If
 // the returned dns.Message is nil, it means that there should be no response to
 // this query. If the returned dns.Message has an Rcode() of dns.RcodeNoError,
-// the message is a candidate for for carrying downstream data in a TXT record.
-func (r *Responder) responseFor(query *dns.Message, domain dns.Name) (*dns.Message, []byte) {
+// the message is a candidate for for carrying downstream data in records of
+// the type of its question.
+func (r *Responder) responseFor(query *dns.Message, domain dns.Name) (*dns.Message, []byte, *dns.ClientSubnet) {
 	resp := &dns.Message{
 		ID:       query.ID,
 		Flags:    0x8000, // QR = 1, RCODE = no error
@@ -65,7 +115,7 @@ func (r *Responder) responseFor(query *dns.Message, domain dns.Name) (*dns.Messa
 
 	if query.Flags&0x8000 != 0 {
 		// QR != 0, this is not a query. Don't even send a response.
-		return nil, nil
+		return nil, nil, nil
 	}
 
 	// Check for EDNS(0) support. Include our own OPT RR only if we receive
@@ -76,6 +126,7 @@ func (r *Responder) responseFor(query *dns.Message, domain dns.Name) (*dns.Messa
 	// specification and that the responder MUST NOT include an OPT record
 	// in its response."
 	payloadSize := 0
+	var clientSubnet *dns.ClientSubnet
 	for _, rr := range query.Additional {
 		if rr.Type != dns.RRTypeOPT {
 			continue
@@ -86,7 +137,7 @@ func (r *Responder) responseFor(query *dns.Message, domain dns.Name) (*dns.Messa
 			// received, a FORMERR (RCODE=1) MUST be returned."
 			resp.Flags |= dns.RcodeFormatError
 			log.Printf("FORMERR: more than one OPT RR")
-			return resp, nil
+			return resp, nil, nil
 		}
 		resp.Additional = append(resp.Additional, dns.RR{
 			Name:  dns.Name{},
@@ -106,7 +157,38 @@ func (r *Responder) responseFor(query *dns.Message, domain dns.Name) (*dns.Messa
 			resp.Flags |= dns.ExtendedRcodeBadVers & 0xf
 			additional.TTL = (dns.ExtendedRcodeBadVers >> 4) << 24
 			log.Printf("BADVERS: EDNS version %d != 0", version)
-			return resp, nil
+			return resp, nil, nil
+		}
+
+		options, err := dns.DecodeRDataOPT(rr.Data)
+		if err != nil {
+			resp.Flags |= dns.RcodeFormatError
+			log.Printf("FORMERR: OPT RDATA: %v", err)
+			return resp, nil, nil
+		}
+		for _, option := range options {
+			if option.Code != dns.EDNSOptionClientSubnet {
+				continue
+			}
+			// A malformed option calls for a FORMERR.
+			// https://tools.ietf.org/html/rfc7871#section-7.1.1
+			clientSubnet, err = dns.DecodeClientSubnet(option.Data)
+			if err != nil {
+				resp.Flags |= dns.RcodeFormatError
+				log.Printf("FORMERR: %v", err)
+				return resp, nil, nil
+			}
+			// Answers are the same for every client, so echo the
+			// option with a SCOPE PREFIX-LENGTH of 0.
+			// https://tools.ietf.org/html/rfc7871#section-7.2.1
+			echo := *clientSubnet
+			echo.ScopePrefix = 0
+			additional.Data, err = encodeClientSubnetOPT(&echo)
+			if err != nil {
+				resp.Flags |= dns.RcodeFormatError
+				log.Printf("FORMERR: %v", err)
+				return resp, nil, nil
+			}
 		}
 
 		payloadSize = int(rr.Class)
@@ -123,7 +205,7 @@ func (r *Responder) responseFor(query *dns.Message, domain dns.Name) (*dns.Messa
 	if len(query.Question) != 1 {
 		resp.Flags |= dns.RcodeFormatError
 		log.Printf("FORMERR: too few or too many questions (%d)", len(query.Question))
-		return resp, nil
+		return resp, nil, nil
 	}
 	question := query.Question[0]
 	// Check the name to see if it ends in our chosen domain, and extract
@@ -135,7 +217,7 @@ func (r *Responder) responseFor(query *dns.Message, domain dns.Name) (*dns.Messa
 		// Not a name we are authoritative for.
 		resp.Flags |= dns.RcodeNameError
 		log.Printf("NXDOMAIN: not authoritative for %s", question.Name)
-		return resp, nil
+		return resp, nil, nil
 	}
 	resp.Flags |= 0x0400 // AA = 1
 
@@ -143,18 +225,20 @@ func (r *Responder) responseFor(query *dns.Message, domain dns.Name) (*dns.Messa
 		// We don't support OPCODE != QUERY.
 		resp.Flags |= dns.RcodeNotImplemented
 		log.Printf("NOTIMPL: unrecognized OPCODE %d", query.Opcode())
-		return resp, nil
+		return resp, nil, nil
 	}
 
-	if question.Type != dns.RRTypeTXT {
-		// We only support QTYPE == TXT.
+	if !dns.IsPayloadRRType(question.Type) {
+		// We only support QTYPEs that can carry a payload. The answer
+		// uses the same type as the question, which is how the
+		// requester chooses the encoding.
 		resp.Flags |= dns.RcodeNameError
 		// No log message here; it's common for recursive resolvers to
-		// send NS or A queries when the client only asked for a TXT. I
+		// send NS queries when the client only asked for a TXT. I
 		// suspect this is related to QNAME minimization, but I'm not
 		// sure. https://tools.ietf.org/html/rfc7816
-		// log.Printf("NXDOMAIN: QTYPE %d != TXT", question.Type)
-		return resp, nil
+		// log.Printf("NXDOMAIN: unsupported QTYPE %d", question.Type)
+		return resp, nil, nil
 	}
 
 	encoded := bytes.ToUpper(bytes.Join(prefix, nil))
@@ -164,7 +248,7 @@ func (r *Responder) responseFor(query *dns.Message, domain dns.Name) (*dns.Messa
 		// Base32 error, make like the name doesn't exist.
 		resp.Flags |= dns.RcodeNameError
 		log.Printf("NXDOMAIN: base32 decoding: %v", err)
-		return resp, nil
+		return resp, nil, nil
 	}
 	payload = payload[:n]
 
@@ -177,21 +261,45 @@ func (r *Responder) responseFor(query *dns.Message, domain dns.Name) (*dns.Messa
 	if payloadSize < r.maxUDPPayload {
 		resp.Flags |= dns.RcodeFormatError
 		log.Printf("FORMERR: requester payload size %d is too small (minimum %d)", payloadSize, r.maxUDPPayload)
-		return resp, nil
+		return resp, nil, nil
 	}
 
-	return resp, payload
+	return resp, payload, clientSubnet
 }
 
-// recvLoop repeatedly calls dnsConn.ReadFrom, extracts the packets contained in
-// the incoming DNS queries, and puts them on ttConn's incoming queue. Whenever
-// a query calls for a response, constructs a partial response and passes it to
-// sendLoop over ch.
-func (r *Responder) RecvAndRespond(getResponse func([]byte) ([]byte, error)) error {
+// encodeClientSubnetOPT encodes the RDATA of an OPT RR holding only the Client
+// Subnet option cs.
+func encodeClientSubnetOPT(cs *dns.ClientSubnet) ([]byte, error) {
+	data, err := cs.Encode()
+	if err != nil {
+		return nil, err
+	}
+	return dns.EncodeRDataOPT([]dns.EDNSOption{{Code: dns.EDNSOptionClientSubnet, Data: data}})
+}
+
+// RecvAndRespond repeatedly reads DNS queries from the transport and hands them
+// to a pool of respondWorkers workers, which answer them using getResponse to
+// process the decrypted payloads. When every worker is busy and
+// maxQueuedQueries queries are already waiting, further queries are dropped
+// until the backlog clears.
+func (r *Responder) RecvAndRespond(getResponse ResponseFunc) error {
+	queries := make(chan pendingQuery, maxQueuedQueries)
+	defer close(queries)
+	for i := 0; i < respondWorkers; i++ {
+		go func() {
+			for q := range queries {
+				r.respond(q.buf, q.addr, getResponse)
+			}
+		}()
+	}
+
 	for {
 		var buf [4096]byte
 		n, addr, err := r.transport.ReadFrom(buf[:])
 		if err != nil {
+			if r.closed.Load() {
+				return nil
+			}
 			if err, ok := err.(net.Error); ok {
 				log.Printf("ReadFrom error: %v", err)
 				continue
@@ -199,84 +307,227 @@ func (r *Responder) RecvAndRespond(getResponse func([]byte) ([]byte, error)) err
 			return err
 		}
 
-		// Parse message and respond
-		go func() {
-			// Got a UDP packet. Try to parse it as a DNS message.
-			query, err := dns.MessageFromWireFormat(buf[:n])
-			if err != nil {
-				log.Printf("cannot parse DNS query: %v", err)
-			}
+		select {
+		case queries <- pendingQuery{buf: append([]byte{}, buf[:n]...), addr: addr}:
+		default:
+			r.count("dns_responder_dropped_queries", 1)
+		}
+	}
+}
 
-			resp, payload := r.responseFor(&query, r.domain)
-			if resp == nil {
-				return
-			}
+// respond answers a single query. Retransmissions of a query whose answer was
+// recently crafted are answered from the cache, and those arriving while the
+// original is still being processed are dropped, as the original's answer
+// will satisfy them.
+func (r *Responder) respond(buf []byte, addr net.Addr, getResponse ResponseFunc) {
+	// Got a UDP packet. Try to parse it as a DNS message.
+	query, err := dns.MessageFromWireFormat(buf)
+	if err != nil {
+		log.Printf("cannot parse DNS query: %v", err)
+	}
 
-			var responseBuf []byte
-
-			// invalid msg if returned payload is empty, do not process
-			if payload != nil {
-				payload, err = msgformat.RemoveRequestFormat(payload)
-				if err != nil {
-					log.Printf("RemoveFormat err: %v", err)
-					return
-				}
-
-				responseBuf, err = r.craftResponse(payload, getResponse)
-				if err != nil {
-					log.Printf("craftResponse err: %v", err)
-					return
-				}
-
-				responseBuf, err = msgformat.AddResponseFormat(responseBuf)
-				if err != nil {
-					log.Printf("AddFormat err: %v", err)
-					return
-				}
-			}
+	resp, payload, clientSubnet := r.responseFor(&query, r.domain)
+	if resp == nil {
+		return
+	}
 
-			responsePayload, err := r.dnsRespToUDPResp(resp, responseBuf)
-			if err != nil {
-				log.Printf("dnsRespToUDPResp err: %v", err)
+	var responseBuf []byte
+
+	// invalid msg if returned payload is empty, do not process
+	if payload != nil {
+		key := answerKeyFor(&query)
+		state, cached := r.answers.begin(key)
+		switch state {
+		case cacheHit:
+			r.count("dns_responder_cache_hits", 1)
+			responseBuf = cached
+		case cacheInFlight:
+			r.count("dns_responder_inflight_duplicates", 1)
+			return
+		case cacheMiss:
+			info := &QueryInfo{Resolver: addr, ClientSubnet: clientSubnet}
+			if stream, ok := addr.(streamAddr); ok {
+				info.Resolver = stream.remote
+			}
+			responseBuf, err = r.processPayload(resp, payload, info, getResponse)
+			if errors.Is(err, ErrRefused) || errors.Is(err, ErrNoAnswer) {
+				r.answers.abort(key)
+				r.decline(resp, addr, err)
+				return
+			} else if err != nil {
+				r.answers.abort(key)
+				log.Printf("%v", err)
 				return
 			}
+			r.answers.finish(key, responseBuf)
+		}
+	}
 
-			if len(responsePayload) > r.maxUDPPayload {
-				log.Printf("ERR: Response UDP payload length [%d] exceed maxUDPPayload size [%d], responding with empty response.", len(responsePayload), r.maxUDPPayload)
-				responsePayload, err = r.dnsRespToUDPResp(resp, []byte{})
-				if err != nil {
-					log.Printf("dnsRespToUDPResp err: %v", err)
-					return
-				}
-			}
+	responsePayload, err := r.dnsRespToUDPResp(resp, responseBuf)
+	if err != nil || len(responsePayload) > r.maxUDPPayload {
+		log.Printf("ERR: Response of length [%d] does not fit in maxUDPPayload size [%d], responding with empty response.", len(responseBuf), r.maxUDPPayload)
+		responsePayload, err = r.dnsRespToUDPResp(resp, []byte{})
+		if err != nil {
+			log.Printf("dnsRespToUDPResp err: %v", err)
+			return
+		}
+	}
 
-			_, err = r.transport.WriteTo(responsePayload, addr)
-			if err != nil {
-				log.Printf("WriteTo err: %v", err)
-			}
-		}()
+	_, err = r.transport.WriteTo(responsePayload, addr)
+	if err != nil {
+		log.Printf("WriteTo err: %v", err)
+	}
+}
+
+// decline answers a declined query with a REFUSED RCODE if err is ErrRefused,
+// or otherwise with an empty Answer section.
+func (r *Responder) decline(resp *dns.Message, addr net.Addr, err error) {
+	if errors.Is(err, ErrRefused) {
+		resp.Flags |= dns.RcodeRefused
+	}
+	resp.Answer = nil
+	buf, err := resp.WireFormat()
+	if err != nil {
+		log.Printf("resp WireFormat: %v", err)
+		return
+	}
+	_, err = r.transport.WriteTo(buf, addr)
+	if err != nil {
+		log.Printf("WriteTo err: %v", err)
 	}
 }
 
-// Put response payload into DNS answer ready to send
+// processPayload decrypts and processes the payload of a query, and returns
+// the formatted payload to answer it with.
+func (r *Responder) processPayload(resp *dns.Message, payload []byte, info *QueryInfo, getResponse ResponseFunc) ([]byte, error) {
+	if msgformat.IsFragment(payload) {
+		responseBuf, err := r.fragmentResponse(resp, payload, info, getResponse)
+		if err != nil {
+			return nil, fmt.Errorf("fragmentResponse err: %w", err)
+		}
+		return responseBuf, nil
+	}
+
+	payload, err := msgformat.RemoveRequestFormat(payload)
+	if err != nil {
+		return nil, fmt.Errorf("RemoveFormat err: %w", err)
+	}
+
+	if r.admit != nil {
+		// Decline before paying for the handshake.
+		if err := r.admit(info); err != nil {
+			return nil, err
+		}
+	}
+
+	responseBuf, err := r.craftResponse(payload, info, getResponse)
+	if err != nil {
+		return nil, fmt.Errorf("craftResponse err: %w", err)
+	}
+
+	responseBuf, err = msgformat.AddResponseFormat(responseBuf)
+	if err != nil {
+		return nil, fmt.Errorf("AddFormat err: %w", err)
+	}
+	return responseBuf, nil
+}
+
+// SetMetrics sets the function used to count cache hits, duplicate queries
+// and dropped queries, such as (*metrics.Metrics).Add. It must be called
+// before RecvAndRespond.
+func (r *Responder) SetMetrics(add func(name string, val int)) {
+	r.metrics = add
+}
+
+// SetAdmission sets a function called with the description of each query
+// carrying a complete request before the request is decrypted, and of the
+// first fragment of a request split over several queries before any state is
+// kept for it. If it returns ErrRefused or ErrNoAnswer, the request is
+// declined without being decrypted or passed to the ResponseFunc. It must be
+// called before RecvAndRespond.
+func (r *Responder) SetAdmission(admit func(info *QueryInfo) error) {
+	r.admit = admit
+}
+
+func (r *Responder) count(name string, val int) {
+	if r.metrics != nil {
+		r.metrics(name, val)
+	}
+}
+
+// fragmentResponse handles a fragment or poll of a multi-query exchange and
+// returns the payload to answer it with.
+func (r *Responder) fragmentResponse(resp *dns.Message, payload []byte, info *QueryInfo, getResponse ResponseFunc) ([]byte, error) {
+	f, err := msgformat.RemoveFragmentFormat(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	maxChunk, err := r.maxResponsePayload(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	process := func(request []byte) ([]byte, error) {
+		responseBuf, err := r.craftResponse(request, info, getResponse)
+		if err != nil {
+			return nil, err
+		}
+		return msgformat.AddResponseFormat(responseBuf)
+	}
+
+	var admit func() error
+	if r.admit != nil {
+		admit = func() error { return r.admit(info) }
+	}
+
+	return r.handleFragment(f, sourceKey(info), maxChunk, admit, process)
+}
+
+// maxResponsePayload returns the largest payload that can be carried in the
+// answer to resp without exceeding maxUDPPayload.
+func (r *Responder) maxResponsePayload(resp *dns.Message) (int, error) {
+	fits := func(n int) bool {
+		buf, err := r.dnsRespToUDPResp(resp, make([]byte, n))
+		return err == nil && len(buf) <= r.maxUDPPayload
+	}
+	// The capacity depends on the record type, so search for it.
+	lo, hi := 0, r.maxUDPPayload
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if fits(mid) {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	if lo < msgformat.DownloadHeaderLen+1 {
+		return 0, fmt.Errorf("no room for response in %d byte payload", r.maxUDPPayload)
+	}
+	return lo, nil
+}
+
+// Put response payload into DNS answer ready to send, encoded in records of
+// the type of the question.
 func (r *Responder) dnsRespToUDPResp(resp *dns.Message, response []byte) ([]byte, error) {
+	resp.Answer = nil
 	if resp.Rcode() == dns.RcodeNoError && len(resp.Question) == 1 {
 		// If it's a non-error response, we can fill the Answer
 		// section with downstream packets.
-
-		// Any changes to how responses are built need to happen
-		// also in computeMaxEncodedPayload.
-		resp.Answer = []dns.RR{
-			{
-				Name:  resp.Question[0].Name,
-				Type:  resp.Question[0].Type,
-				Class: resp.Question[0].Class,
+		question := resp.Question[0]
+		rdatas, err := dns.EncodePayload(question.Type, response, r.domain)
+		if err != nil {
+			return nil, err
+		}
+		for _, rdata := range rdatas {
+			resp.Answer = append(resp.Answer, dns.RR{
+				Name:  question.Name,
+				Type:  question.Type,
+				Class: question.Class,
 				TTL:   responseTTL,
-				Data:  nil, // will be filled in below
-			},
+				Data:  rdata,
+			})
 		}
-
-		resp.Answer[0].Data = dns.EncodeRDataTXT(response)
 	}
 
 	buf, err := resp.WireFormat()
@@ -288,7 +539,25 @@ func (r *Responder) dnsRespToUDPResp(resp *dns.Message, response []byte) ([]byte
 	return buf, nil
 }
 
+// NewDnsResponder creates a Responder listening for DNS over UDP on listenAddr.
 func NewDnsResponder(domain string, listenAddr string, privkey []byte) (*Responder, error) {
+	dnsConn, err := net.ListenPacket("udp", listenAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := NewDnsResponderFromConn(domain, dnsConn, privkey)
+	if err != nil {
+		dnsConn.Close()
+		return nil, err
+	}
+	return r, nil
+}
+
+// NewDnsResponderFromConn creates a Responder that answers the DNS messages
+// read from transport, which may be a UDP socket or one of the stream or QUIC
+// listeners in this package.
+func NewDnsResponderFromConn(domain string, transport net.PacketConn, privkey []byte) (*Responder, error) {
 	noiseConfig := encryption.NewConfig()
 	noiseConfig.Initiator = false
 	noiseConfig.StaticKeypair = noise.DHKey{
@@ -301,11 +570,6 @@ func NewDnsResponder(domain string, listenAddr string, privkey []byte) (*Respond
 		return nil, err
 	}
 
-	dnsConn, err := net.ListenPacket("udp", listenAddr)
-	if err != nil {
-		return nil, err
-	}
-
 	// We don't send UDP payloads larger than this, in an attempt to avoid
 	// network-layer fragmentation. 1280 is the minimum IPv6 MTU, 40 bytes
 	// is the size of an IPv6 header (though without any extension headers),
@@ -321,13 +585,22 @@ func NewDnsResponder(domain string, listenAddr string, privkey []byte) (*Respond
 
 	return &Responder{
 		domain:        basename,
-		transport:     dnsConn,
+		transport:     transport,
 		privkey:       privkey,
 		noiseConfig:   noiseConfig,
 		maxUDPPayload: maxUDPPayload,
+		fragments:     newFragmentStore(fragmentTimeout),
+		answers:       newAnswerCache(answerCacheTimeout, maxAnswerEntries),
 	}, nil
 }
 
+// LocalAddr returns the address the responder is serving on.
+func (r *Responder) LocalAddr() net.Addr {
+	return r.transport.LocalAddr()
+}
+
 func (r *Responder) Close() error {
+	r.closed.Store(true)
+	r.fragments.close()
 	return r.transport.Close()
 }
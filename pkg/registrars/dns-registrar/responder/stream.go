@@ -0,0 +1,185 @@
+package responder
+
+import (
+	"bufio"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/queuepacketconn"
+)
+
+// streamIdleTimeout is how long a stream connection may go without sending a
+// query before it is closed.
+const streamIdleTimeout = 2 * time.Minute
+
+// StreamPacketConn is a net.PacketConn that serves DNS messages received over
+// stream connections (DNS over TCP or DNS over TLS) accepted from a listener.
+// Each message is prefixed with a two-octet length field. Messages read from
+// ReadFrom are tagged with the remote address of the connection they arrived
+// on, and messages passed to WriteTo are sent back on that connection.
+//
+// https://tools.ietf.org/html/rfc7766
+// https://tools.ietf.org/html/rfc7858
+type StreamPacketConn struct {
+	// QueuePacketConn holds the queue of incoming messages returned by
+	// ReadFrom.
+	*queuepacketconn.QueuePacketConn
+
+	ln net.Listener
+
+	connsLock sync.Mutex
+	conns     map[string]*streamConn
+}
+
+// streamConn is an accepted connection with a lock serializing writes.
+type streamConn struct {
+	net.Conn
+	writeLock sync.Mutex
+}
+
+// ListenTCP listens for DNS over TCP connections on addr.
+func ListenTCP(addr string) (*StreamPacketConn, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return NewStreamPacketConn(ln), nil
+}
+
+// ListenTLS listens for DNS over TLS connections on addr, using config for the
+// TLS server configuration.
+func ListenTLS(addr string, config *tls.Config) (*StreamPacketConn, error) {
+	ln, err := tls.Listen("tcp", addr, config)
+	if err != nil {
+		return nil, err
+	}
+	return NewStreamPacketConn(ln), nil
+}
+
+// NewStreamPacketConn creates a StreamPacketConn serving connections accepted
+// from ln.
+func NewStreamPacketConn(ln net.Listener) *StreamPacketConn {
+	c := &StreamPacketConn{
+		QueuePacketConn: queuepacketconn.NewQueuePacketConn(ln.Addr(), 0),
+		ln:              ln,
+		conns:           make(map[string]*streamConn),
+	}
+	go c.acceptLoop()
+	return c
+}
+
+func (c *StreamPacketConn) acceptLoop() {
+	for {
+		conn, err := c.ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			log.Printf("Accept error: %v", err)
+			continue
+		}
+		go c.handleConn(&streamConn{Conn: conn})
+	}
+}
+
+// handleConn reads length-prefixed messages from conn and passes them to the
+// incoming queue until conn is closed or idles out.
+func (c *StreamPacketConn) handleConn(conn *streamConn) {
+	addr := conn.RemoteAddr()
+
+	c.connsLock.Lock()
+	c.conns[addr.String()] = conn
+	c.connsLock.Unlock()
+
+	defer func() {
+		c.connsLock.Lock()
+		delete(c.conns, addr.String())
+		c.connsLock.Unlock()
+		conn.Close()
+	}()
+
+	br := bufio.NewReader(conn)
+	for {
+		err := conn.SetReadDeadline(time.Now().Add(streamIdleTimeout))
+		if err != nil {
+			return
+		}
+		p, err := readLengthPrefixed(br)
+		if err != nil {
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				log.Printf("stream read error from %v: %v", addr, err)
+			}
+			return
+		}
+		c.QueuePacketConn.QueueIncoming(p, addr)
+	}
+}
+
+// WriteTo sends p, length-prefixed, on the connection whose remote address is
+// addr.
+func (c *StreamPacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	c.connsLock.Lock()
+	conn, ok := c.conns[addr.String()]
+	c.connsLock.Unlock()
+	if !ok {
+		return 0, fmt.Errorf("no connection from %v", addr)
+	}
+
+	conn.writeLock.Lock()
+	defer conn.writeLock.Unlock()
+	err := writeLengthPrefixed(conn, p)
+	if err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close stops accepting connections, closes the open ones, and unblocks
+// pending ReadFrom calls.
+func (c *StreamPacketConn) Close() error {
+	err := c.ln.Close()
+
+	c.connsLock.Lock()
+	for _, conn := range c.conns {
+		conn.Close()
+	}
+	c.connsLock.Unlock()
+
+	c.QueuePacketConn.Close()
+	return err
+}
+
+// readLengthPrefixed reads one message prefixed with a two-octet length.
+func readLengthPrefixed(r io.Reader) ([]byte, error) {
+	var length uint16
+	err := binary.Read(r, binary.BigEndian, &length)
+	if err != nil {
+		return nil, err
+	}
+	p := make([]byte, int(length))
+	_, err = io.ReadFull(r, p)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// writeLengthPrefixed writes p prefixed with a two-octet length in a single
+// write.
+func writeLengthPrefixed(w io.Writer, p []byte) error {
+	if len(p) > 0xffff {
+		return fmt.Errorf("message too long: %d", len(p))
+	}
+	buf := make([]byte, 2+len(p))
+	binary.BigEndian.PutUint16(buf, uint16(len(p)))
+	copy(buf[2:], p)
+	_, err := w.Write(buf)
+	return err
+}
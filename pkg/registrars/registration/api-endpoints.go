@@ -0,0 +1,183 @@
+package registration
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/requester"
+)
+
+// defaultEndpointBackoff is the length of time an API endpoint is deprioritized
+// after a failed registration attempt when no backoff is configured.
+const defaultEndpointBackoff = 2 * time.Minute
+
+// APIEndpoint describes a single registration API endpoint and the optional
+// domain fronting and TLS fingerprinting parameters used to reach it.
+type APIEndpoint struct {
+	// URL is the registration API URL. The host of this URL is sent in the
+	// HTTP Host header.
+	URL string
+
+	// FrontDomain, if set, is the domain that is dialed and sent as TLS SNI in
+	// place of the host in URL. An optional port may be included.
+	FrontDomain string
+
+	// UTLSDistribution is a weighted uTLS Client Hello ID distribution of the
+	// form "3*Firefox,2*Chrome,1*iOS" sampled for each registration. If empty,
+	// crypto/tls is used.
+	UTLSDistribution string
+}
+
+// request builds a registration POST request for this endpoint, rewriting the
+// destination to the front domain if one is configured.
+func (e *APIEndpoint) request(ctx context.Context, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", e.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	if e.FrontDomain != "" {
+		req.Host = req.URL.Host
+		req.URL.Host = frontHost(e.FrontDomain, req.URL)
+	}
+
+	return req, nil
+}
+
+// roundTripper builds the HTTP transport used to reach this endpoint, dialing
+// the underlying TCP connection using dialer.
+func (e *APIEndpoint) roundTripper(dialer requester.DialFunc) (http.RoundTripper, error) {
+	if e.UTLSDistribution != "" {
+		id, err := requester.SampleUTLSDistribution(e.UTLSDistribution)
+		if err != nil {
+			return nil, fmt.Errorf("bad uTLS distribution for %s: %w", e.URL, err)
+		}
+		if id != nil {
+			return requester.NewUTLSRoundTripper(nil, id, dialer), nil
+		}
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if dialer != nil {
+		t.DialContext = dialer
+	}
+	return t, nil
+}
+
+// frontHost returns the host[:port] to dial for a fronted request, carrying
+// over the port from the original URL when the front domain has none.
+func frontHost(front string, u *url.URL) string {
+	if _, _, err := net.SplitHostPort(front); err == nil {
+		return front
+	}
+	if u.Port() == "" {
+		return front
+	}
+	return net.JoinHostPort(front, u.Port())
+}
+
+// endpointState tracks the health of a single configured APIEndpoint.
+type endpointState struct {
+	APIEndpoint
+
+	// consecutive failed attempts since the last success
+	failures int
+
+	// time of the most recent failed attempt
+	lastFailure time.Time
+}
+
+// endpointRotation cycles registration attempts through a set of API
+// endpoints, moving endpoints that failed within the backoff window to the back
+// of the order.
+type endpointRotation struct {
+	mu        sync.Mutex
+	endpoints []*endpointState
+	next      int
+	backoff   time.Duration
+	now       func() time.Time
+}
+
+func newEndpointRotation(endpoints []APIEndpoint, backoff time.Duration) *endpointRotation {
+	if backoff <= 0 {
+		backoff = defaultEndpointBackoff
+	}
+
+	states := make([]*endpointState, 0, len(endpoints))
+	for _, e := range endpoints {
+		states = append(states, &endpointState{APIEndpoint: e})
+	}
+
+	return &endpointRotation{
+		endpoints: states,
+		backoff:   backoff,
+		now:       time.Now,
+	}
+}
+
+// order returns the endpoints in the order they should be tried for the next
+// registration. Healthy endpoints come first, rotating the starting point on
+// each call, followed by recently failed endpoints with the least recent
+// failure first.
+func (r *endpointRotation) order() []*endpointState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(r.endpoints)
+	if n == 0 {
+		return nil
+	}
+
+	now := r.now()
+	healthy := make([]*endpointState, 0, n)
+	failed := []*endpointState{}
+	for i := 0; i < n; i++ {
+		e := r.endpoints[(r.next+i)%n]
+		if e.failures > 0 && now.Sub(e.lastFailure) < r.backoff {
+			failed = append(failed, e)
+			continue
+		}
+		healthy = append(healthy, e)
+	}
+	r.next = (r.next + 1) % n
+
+	sort.SliceStable(failed, func(i, j int) bool {
+		return failed[i].lastFailure.Before(failed[j].lastFailure)
+	})
+
+	return append(healthy, failed...)
+}
+
+// markFailure records a failed attempt against an endpoint.
+func (r *endpointRotation) markFailure(e *endpointState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e.failures++
+	e.lastFailure = r.now()
+}
+
+// markSuccess resets the failure count of an endpoint.
+func (r *endpointRotation) markSuccess(e *endpointState) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e.failures = 0
+}
+
+// endpointsFromConfig returns the API endpoints configured in config, falling
+// back to a single un-fronted endpoint built from Target.
+func endpointsFromConfig(config *Config) []APIEndpoint {
+	if len(config.APIEndpoints) > 0 {
+		return config.APIEndpoints
+	}
+	if config.Target == "" {
+		return nil
+	}
+	return []APIEndpoint{{URL: config.Target}}
+}
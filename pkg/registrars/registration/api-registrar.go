@@ -1,7 +1,6 @@
 package registration
 
 import (
-	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -9,6 +8,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/requester"
 	pb "github.com/refraction-networking/conjure/proto"
 	"github.com/refraction-networking/gotapdance/tapdance"
 	"github.com/sirupsen/logrus"
@@ -16,14 +16,15 @@ import (
 )
 
 // Registration strategy using a centralized REST API to
-// create registrations. Only the Endpoint need be specified;
+// create registrations. Only the Target or APIEndpoints need be specified;
 // the remaining fields are valid with their zero values and
 // provide the opportunity for additional control over the process.
 type APIRegistrar struct {
-	// endpoint to use in registration request
-	endpoint string
+	// endpoints to rotate through in registration requests
+	endpoints *endpointRotation
 
-	// HTTP client to use in request
+	// HTTP client to use in request. If nil, a client is built for each
+	// endpoint using the registration dialer.
 	client *http.Client
 
 	// Wether registrations should be bidirectional
@@ -54,8 +55,22 @@ type APIRegistrar struct {
 }
 
 func NewAPIRegistrar(config *Config) (*APIRegistrar, error) {
+	endpoints := endpointsFromConfig(config)
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("no API endpoints configured")
+	}
+	if config.HTTPClient != nil {
+		// a fixed client carries its own transport, so the per-endpoint uTLS
+		// fingerprint could not be applied.
+		for _, e := range endpoints {
+			if e.UTLSDistribution != "" {
+				return nil, fmt.Errorf("API endpoint %s sets a uTLS distribution, which can not be used with a fixed HTTPClient", e.URL)
+			}
+		}
+	}
+
 	return &APIRegistrar{
-		endpoint:           config.Target,
+		endpoints:          newEndpointRotation(endpoints, config.APIEndpointBackoff),
 		bidirectional:      config.Bidirectional,
 		connectionDelay:    config.Delay,
 		maxRetries:         config.MaxRetries,
@@ -81,15 +96,27 @@ func (r *APIRegistrar) registerUnidirectional(cjSession *tapdance.ConjureSession
 		return nil, ErrRegFailed
 	}
 
-	r.setHTTPClient(reg)
+	clients := r.newClientSet(reg)
+	endpoints := r.endpoints.order()
 
 	for tries := 0; tries < r.maxRetries+1; tries++ {
+		endpoint := endpoints[tries%len(endpoints)]
 		logger := logger.WithField("attempt", strconv.Itoa(tries+1)+"/"+strconv.Itoa(r.maxRetries+1))
-		err = r.executeHTTPRequest(ctx, payload, logger)
+
+		client, err := clients.get(endpoint)
+		if err != nil {
+			logger.Warnf("error building client for %s: %v", endpoint.URL, err)
+			r.endpoints.markFailure(endpoint)
+			continue
+		}
+
+		err = r.executeHTTPRequest(ctx, client, &endpoint.APIEndpoint, payload, logger)
 		if err != nil {
 			logger.Warnf("error in registration attempt: %v", err)
+			r.endpoints.markFailure(endpoint)
 			continue
 		}
+		r.endpoints.markSuccess(endpoint)
 		logger.Debugf("registration succeeded")
 		return reg, nil
 	}
@@ -121,16 +148,27 @@ func (r *APIRegistrar) registerBidirectional(cjSession *tapdance.ConjureSession,
 		return nil, ErrRegFailed
 	}
 
-	r.setHTTPClient(reg)
+	clients := r.newClientSet(reg)
+	endpoints := r.endpoints.order()
 
 	for tries := 0; tries < r.maxRetries+1; tries++ {
+		endpoint := endpoints[tries%len(endpoints)]
 		logger := logger.WithField("attempt", strconv.Itoa(tries+1)+"/"+strconv.Itoa(r.maxRetries+1))
 
-		regResp, err := r.executeHTTPRequestBidirectional(ctx, payload, logger)
+		client, err := clients.get(endpoint)
+		if err != nil {
+			logger.Warnf("error building client for %s: %v", endpoint.URL, err)
+			r.endpoints.markFailure(endpoint)
+			continue
+		}
+
+		regResp, err := r.executeHTTPRequestBidirectional(ctx, client, &endpoint.APIEndpoint, payload, logger)
 		if err != nil {
 			logger.Warnf("error in registration attempt: %v", err)
+			r.endpoints.markFailure(endpoint)
 			continue
 		}
+		r.endpoints.markSuccess(endpoint)
 
 		err = reg.UnpackRegResp(regResp)
 		if err != nil {
@@ -151,16 +189,41 @@ func (r *APIRegistrar) registerBidirectional(cjSession *tapdance.ConjureSession,
 	return nil, ErrRegFailed
 }
 
-func (r *APIRegistrar) setHTTPClient(reg *tapdance.ConjureReg) {
-	if r.client == nil {
-		// Transports should ideally be re-used for TCP connection pooling,
-		// but each registration is most likely making precisely one request,
-		// or if it's making more than one, is most likely due to an underlying
-		// connection issue rather than an application-level error anyways.
-		t := http.DefaultTransport.(*http.Transport).Clone()
-		t.DialContext = reg.Dialer
-		r.client = &http.Client{Transport: t}
+// clientSet lazily builds one HTTP client per endpoint for a single
+// registration.
+type clientSet struct {
+	fixed   *http.Client
+	dialer  requester.DialFunc
+	clients map[*endpointState]*http.Client
+}
+
+func (r *APIRegistrar) newClientSet(reg *tapdance.ConjureReg) *clientSet {
+	return &clientSet{
+		fixed:   r.client,
+		dialer:  reg.Dialer,
+		clients: make(map[*endpointState]*http.Client),
+	}
+}
+
+func (c *clientSet) get(e *endpointState) (*http.Client, error) {
+	if c.fixed != nil {
+		return c.fixed, nil
+	}
+	if client, ok := c.clients[e]; ok {
+		return client, nil
+	}
+
+	// Transports should ideally be re-used for TCP connection pooling,
+	// but each registration is most likely making precisely one request,
+	// or if it's making more than one, is most likely due to an underlying
+	// connection issue rather than an application-level error anyways.
+	rt, err := e.roundTripper(c.dialer)
+	if err != nil {
+		return nil, err
 	}
+	client := &http.Client{Transport: rt}
+	c.clients[e] = client
+	return client, nil
 }
 
 func (r APIRegistrar) Register(cjSession *tapdance.ConjureSession, ctx context.Context) (*tapdance.ConjureReg, error) {
@@ -174,49 +237,49 @@ func (r APIRegistrar) Register(cjSession *tapdance.ConjureSession, ctx context.C
 
 }
 
-func (r APIRegistrar) executeHTTPRequest(ctx context.Context, payload []byte, logger logrus.FieldLogger) error {
-	req, err := http.NewRequestWithContext(ctx, "POST", r.endpoint, bytes.NewReader(payload))
+func (r APIRegistrar) executeHTTPRequest(ctx context.Context, client *http.Client, endpoint *APIEndpoint, payload []byte, logger logrus.FieldLogger) error {
+	req, err := endpoint.request(ctx, payload)
 	if err != nil {
-		logger.Warnf("failed to create HTTP request to registration endpoint %s: %v", r.endpoint, err)
+		logger.Warnf("failed to create HTTP request to registration endpoint %s: %v", endpoint.URL, err)
 		return err
 	}
 
-	resp, err := r.client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		logger.Warnf("failed to do HTTP request to registration endpoint %s: %v", r.endpoint, err)
+		logger.Warnf("failed to do HTTP request to registration endpoint %s: %v", endpoint.URL, err)
 		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		// logger.Warnf("got non-success response code %d from registration endpoint %v", resp.StatusCode, r.endpoint)
-		return fmt.Errorf("non-success response code %d on %s", resp.StatusCode, r.endpoint)
+		// logger.Warnf("got non-success response code %d from registration endpoint %v", resp.StatusCode, endpoint.URL)
+		return fmt.Errorf("non-success response code %d on %s", resp.StatusCode, endpoint.URL)
 	}
 
 	return nil
 }
 
-func (r APIRegistrar) executeHTTPRequestBidirectional(ctx context.Context, payload []byte, logger logrus.FieldLogger) (*pb.RegistrationResponse, error) {
+func (r APIRegistrar) executeHTTPRequestBidirectional(ctx context.Context, client *http.Client, endpoint *APIEndpoint, payload []byte, logger logrus.FieldLogger) (*pb.RegistrationResponse, error) {
 	// Create an instance of the ConjureReg struct to return; this will hold the updated phantom4 and phantom6 addresses received from registrar response
 	regResp := &pb.RegistrationResponse{}
 	// Make new HTTP request with given context, registrar, and paylaod
-	req, err := http.NewRequestWithContext(ctx, "POST", r.endpoint, bytes.NewReader(payload))
+	req, err := endpoint.request(ctx, payload)
 	if err != nil {
-		logger.Warnf("%v failed to create HTTP request to registration endpoint %s: %v", r.endpoint, err)
+		logger.Warnf("failed to create HTTP request to registration endpoint %s: %v", endpoint.URL, err)
 		return regResp, err
 	}
 
-	resp, err := r.client.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
-		logger.Warnf("%v failed to do HTTP request to registration endpoint %s: %v", r.endpoint, err)
+		logger.Warnf("failed to do HTTP request to registration endpoint %s: %v", endpoint.URL, err)
 		return regResp, err
 	}
 	defer resp.Body.Close()
 
 	// Check that the HTTP request returned a success code
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		// logger.Warnf("got non-success response code %d from registration endpoint %v", resp.StatusCode, r.endpoint)
-		return regResp, fmt.Errorf("non-success response code %d on %s", resp.StatusCode, r.endpoint)
+		// logger.Warnf("got non-success response code %d from registration endpoint %v", resp.StatusCode, endpoint.URL)
+		return regResp, fmt.Errorf("non-success response code %d on %s", resp.StatusCode, endpoint.URL)
 	}
 
 	// Read the HTTP response body into []bytes
@@ -3,12 +3,17 @@ package registration
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/binary"
 	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	transports "github.com/refraction-networking/conjure/pkg/transports/client"
 	pb "github.com/refraction-networking/conjure/proto"
@@ -52,7 +57,7 @@ func TestAPIRegistrar(t *testing.T) {
 	}))
 
 	registrar := APIRegistrar{
-		endpoint:      server.URL,
+		endpoints:     newEndpointRotation([]APIEndpoint{{URL: server.URL}}, 0),
 		client:        server.Client(),
 		bidirectional: false,
 		logger:        logrus.New(),
@@ -117,7 +122,7 @@ func TestAPIRegistrarBidirectional(t *testing.T) {
 	}))
 
 	registrar := APIRegistrar{
-		endpoint:      server.URL,
+		endpoints:     newEndpointRotation([]APIEndpoint{{URL: server.URL}}, 0),
 		client:        server.Client(),
 		bidirectional: true,
 		logger:        logrus.New(),
@@ -144,3 +149,151 @@ func TestAPIRegistrarBidirectional(t *testing.T) {
 
 	server.Close()
 }
+
+// testSeed is a ConjureSeed that selects a phantom from the default ClientConf.
+// Sessions otherwise use a random seed, and some seeds select a weighted subnet
+// group without both IPv4 and IPv6 subnets, failing registration before any
+// request is made.
+var testSeed = sha256.Sum256([]byte("api-registrar-test-0"))
+
+func newTestSession(t *testing.T) *tapdance.ConjureSession {
+	transport, err := transports.New("min")
+	require.Nil(t, err)
+
+	session := tapdance.MakeConjureSession("1.2.3.4:1234", transport)
+	session.Keys.ConjureSeed = testSeed[:]
+	return session
+}
+
+func TestAPIRegistrarRotation(t *testing.T) {
+	_ = transports.EnableDefaultTransports()
+
+	var badHits, goodHits int64
+	bad := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&badHits, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer bad.Close()
+
+	// the handler runs outside the test goroutine, so the fronted hosts it sees
+	// are checked once registration returns.
+	var hostsMu sync.Mutex
+	var hosts []string
+	good := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&goodHits, 1)
+		hostsMu.Lock()
+		hosts = append(hosts, r.Host)
+		hostsMu.Unlock()
+	}))
+	defer good.Close()
+
+	goodURL, err := url.Parse(good.URL)
+	require.Nil(t, err)
+
+	registrar := APIRegistrar{
+		endpoints: newEndpointRotation([]APIEndpoint{
+			{URL: bad.URL},
+			{URL: "https://registrar.example.com/", FrontDomain: goodURL.Host},
+		}, time.Hour),
+		client:     good.Client(),
+		maxRetries: 1,
+		logger:     logrus.New(),
+	}
+	// both test servers share the same certificate, so either client works.
+	_, err = registrar.Register(newTestSession(t), context.TODO())
+	require.Nil(t, err)
+	require.Equal(t, int64(1), atomic.LoadInt64(&badHits))
+	require.Equal(t, int64(1), atomic.LoadInt64(&goodHits))
+
+	// the failed endpoint is now deprioritized regardless of rotation.
+	for i := 0; i < 3; i++ {
+		_, err = registrar.Register(newTestSession(t), context.TODO())
+		require.Nil(t, err)
+	}
+	require.Equal(t, int64(1), atomic.LoadInt64(&badHits))
+	require.Equal(t, int64(4), atomic.LoadInt64(&goodHits))
+
+	hostsMu.Lock()
+	defer hostsMu.Unlock()
+	for _, host := range hosts {
+		require.Equal(t, "registrar.example.com", host, "incorrect fronted host")
+	}
+}
+
+func TestAPIRegistrarEndpointsFail(t *testing.T) {
+	_ = transports.EnableDefaultTransports()
+
+	var hits [2]int64
+	servers := make([]*httptest.Server, len(hits))
+	for i := range servers {
+		i := i
+		servers[i] = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&hits[i], 1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer servers[i].Close()
+	}
+
+	registrar := APIRegistrar{
+		endpoints: newEndpointRotation([]APIEndpoint{
+			{URL: servers[0].URL},
+			{URL: servers[1].URL},
+		}, time.Hour),
+		client:     servers[0].Client(),
+		maxRetries: 3,
+		logger:     logrus.New(),
+	}
+
+	// every endpoint is tried in turn before registration fails.
+	_, err := registrar.Register(newTestSession(t), context.TODO())
+	require.ErrorIs(t, err, ErrRegFailed)
+	require.Equal(t, int64(2), atomic.LoadInt64(&hits[0]))
+	require.Equal(t, int64(2), atomic.LoadInt64(&hits[1]))
+}
+
+func TestNewAPIRegistrarFixedClient(t *testing.T) {
+	_, err := NewAPIRegistrar(&Config{
+		HTTPClient:   http.DefaultClient,
+		APIEndpoints: []APIEndpoint{{URL: "https://registrar.example.com/", UTLSDistribution: "1*Firefox"}},
+	})
+	require.NotNil(t, err)
+
+	_, err = NewAPIRegistrar(&Config{
+		HTTPClient:   http.DefaultClient,
+		APIEndpoints: []APIEndpoint{{URL: "https://registrar.example.com/"}},
+	})
+	require.Nil(t, err)
+}
+
+func TestEndpointRotationOrder(t *testing.T) {
+	now := time.Unix(1000, 0)
+	r := newEndpointRotation([]APIEndpoint{{URL: "a"}, {URL: "b"}, {URL: "c"}}, time.Minute)
+	r.now = func() time.Time { return now }
+
+	urls := func(es []*endpointState) []string {
+		out := []string{}
+		for _, e := range es {
+			out = append(out, e.URL)
+		}
+		return out
+	}
+
+	require.Equal(t, []string{"a", "b", "c"}, urls(r.order()))
+	require.Equal(t, []string{"b", "c", "a"}, urls(r.order()))
+
+	order := r.order()
+	require.Equal(t, []string{"c", "a", "b"}, urls(order))
+
+	r.markFailure(order[1])
+	now = now.Add(time.Second)
+	r.markFailure(order[0])
+	require.Equal(t, []string{"b", "a", "c"}, urls(r.order()))
+
+	// failures expire after the backoff
+	now = now.Add(2 * time.Minute)
+	require.Equal(t, []string{"b", "c", "a"}, urls(r.order()))
+
+	r.markFailure(r.endpoints[1])
+	r.markSuccess(r.endpoints[1])
+	require.Equal(t, []string{"c", "a", "b"}, urls(r.order()))
+}
@@ -1,10 +1,12 @@
 package registration
 
 import (
+	"crypto/tls"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/requester"
 	"github.com/refraction-networking/gotapdance/tapdance"
 )
 
@@ -21,9 +23,27 @@ type Config struct {
 	// Pubkey is the public key for the listening DNS registration server
 	Pubkey []byte
 
+	// DNSMultiQuery makes the DNS registrar always use the multi-query exchange, allowing
+	// responses larger than a single DNS answer. The registration server must support it.
+	DNSMultiQuery bool
+
+	// DNSRecordType is the DNS record type the DNS registrar requests responses in, one of
+	// dns.RRTypeTXT, dns.RRTypeA, dns.RRTypeAAAA, dns.RRTypeCNAME, dns.RRTypeSVCB or
+	// dns.RRTypeHTTPS. If zero, TXT is used. Types other than TXT imply DNSMultiQuery.
+	DNSRecordType uint16
+
 	// UTLSDistribution allows utls distribution to be specified for the utls connection used during DoH and DoT in the DNS registrar
 	UTLSDistribution string
 
+	// DNSTLSConfig is the TLS configuration used by the DNS registrar for DoT when no uTLS
+	// distribution is sampled, and for DoQ. If nil, the system roots are used and the server
+	// name is taken from Target.
+	DNSTLSConfig *tls.Config
+
+	// DNSDialTransport is the dialer used for the underlying UDP or TCP connection of every DNS
+	// registrar transport, unless a registration provides its own dialer.
+	DNSDialTransport requester.DialFunc
+
 	// MaxRetries is the max number of retries a registrar will attempt
 	MaxRetries int
 
@@ -33,14 +53,32 @@ type Config struct {
 	// STUNAddr is the address of STUN server used to determine the client's IPv4 address for the DNS registrar
 	STUNAddr string
 
+	// STUNAddrs are additional STUN servers queried in parallel with STUNAddr
+	// to determine and cross-check the client's IPv4 and IPv6 addresses
+	STUNAddrs []string
+
+	// STUNTimeout bounds each STUN request made during address discovery
+	STUNTimeout time.Duration
+
 	// Bidirectional sets wether the registrar should be bidirectional or unidirectional
 	Bidirectional bool
 
 	// SecondaryRegistrar is the secondary registrar to use when the main one fails
 	SecondaryRegistrar tapdance.Registrar
 
-	// HTTPClient is the HTTP client to use for the API registrar
+	// HTTPClient is the HTTP client to use for the API registrar. It is used
+	// for every endpoint as is, so it can not be combined with endpoints that
+	// set a UTLSDistribution.
 	HTTPClient *http.Client
+
+	// APIEndpoints is the list of endpoints, with optional domain fronting
+	// parameters, that the API registrar rotates through. If empty, Target is
+	// used as the only endpoint.
+	APIEndpoints []APIEndpoint
+
+	// APIEndpointBackoff is how long an API endpoint is deprioritized after a
+	// failed registration attempt
+	APIEndpointBackoff time.Duration
 }
 
 // DNSTransportMethodType declares the DNS transport method to be used
@@ -50,8 +88,24 @@ const (
 	DoH DNSTransportMethodType = iota
 	DoT
 	UDP
+	DoQ
+	TCP
 )
 
+// stunServers returns all configured STUN servers without duplicates.
+func (c *Config) stunServers() []string {
+	servers := []string{}
+	seen := map[string]bool{}
+	for _, s := range append([]string{c.STUNAddr}, c.STUNAddrs...) {
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		servers = append(servers, s)
+	}
+	return servers
+}
+
 //nolint:unused
 func validateConfig(config *Config) error {
 	if config == nil {
@@ -4,11 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net"
 	"strconv"
 	"time"
 
-	"github.com/pion/stun"
 	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/requester"
 	pb "github.com/refraction-networking/conjure/proto"
 	"github.com/refraction-networking/gotapdance/tapdance"
@@ -30,33 +28,35 @@ type DNSRegistrar struct {
 }
 
 func createRequester(config *Config) (*requester.Requester, error) {
+	reqConfig := &requester.Config{
+		Target:     config.Target,
+		BaseDomain: config.BaseDomain,
+		Pubkey:     config.Pubkey,
+		MultiQuery: config.DNSMultiQuery,
+		RecordType: config.DNSRecordType,
+
+		TLSConfig:     config.DNSTLSConfig,
+		DialTransport: config.DNSDialTransport,
+	}
+
 	switch config.DNSTransportMethod {
 	case UDP:
-		return requester.NewRequester(&requester.Config{
-			TransportMethod: requester.UDP,
-			Target:          config.Target,
-			BaseDomain:      config.BaseDomain,
-			Pubkey:          config.Pubkey,
-		})
+		reqConfig.TransportMethod = requester.UDP
 	case DoT:
-		return requester.NewRequester(&requester.Config{
-			TransportMethod:  requester.DoT,
-			UtlsDistribution: config.UTLSDistribution,
-			Target:           config.Target,
-			BaseDomain:       config.BaseDomain,
-			Pubkey:           config.Pubkey,
-		})
+		reqConfig.TransportMethod = requester.DoT
+		reqConfig.UtlsDistribution = config.UTLSDistribution
 	case DoH:
-		return requester.NewRequester(&requester.Config{
-			TransportMethod:  requester.DoH,
-			UtlsDistribution: config.UTLSDistribution,
-			Target:           config.Target,
-			BaseDomain:       config.BaseDomain,
-			Pubkey:           config.Pubkey,
-		})
+		reqConfig.TransportMethod = requester.DoH
+		reqConfig.UtlsDistribution = config.UTLSDistribution
+	case DoQ:
+		reqConfig.TransportMethod = requester.DoQ
+	case TCP:
+		reqConfig.TransportMethod = requester.TCP
+	default:
+		return nil, fmt.Errorf("invalid DNS transport method")
 	}
 
-	return nil, fmt.Errorf("invalid DNS transport method")
+	return requester.NewRequester(reqConfig)
 }
 
 // NewDNSRegistrar creates a DNSRegistrar from config
@@ -66,9 +66,16 @@ func NewDNSRegistrar(config *Config) (*DNSRegistrar, error) {
 		return nil, fmt.Errorf("error creating requester: %v", err)
 	}
 
-	ip, err := getPublicIp(config.STUNAddr)
+	logger := tapdance.Logger().WithField("registrar", "DNS")
+
+	// Failing to discover our public address is not fatal, the registration is
+	// sent without a RegistrationAddress instead.
+	var ip []byte
+	addrs, err := discoverPublicAddrs(config.stunServers(), config.STUNTimeout, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get public IP: %v", err)
+		logger.Warnf("failed to discover public address, registering without it: %v", err)
+	} else {
+		ip = addrs.registrationAddress()
 	}
 
 	return &DNSRegistrar{
@@ -77,7 +84,7 @@ func NewDNSRegistrar(config *Config) (*DNSRegistrar, error) {
 		maxRetries:      config.MaxRetries,
 		bidirectional:   config.Bidirectional,
 		connectionDelay: config.Delay,
-		logger:          tapdance.Logger().WithField("registrar", "DNS"),
+		logger:          logger,
 	}, nil
 }
 
@@ -201,39 +208,6 @@ func (r *DNSRegistrar) Register(cjSession *tapdance.ConjureSession, ctx context.
 	return r.registerUnidirectional(cjSession)
 }
 
-func getPublicIp(server string) ([]byte, error) {
-
-	c, err := stun.Dial("udp4", server)
-	if err != nil {
-		return nil, errors.New("Failed to connect to STUN server: " + err.Error())
-	}
-
-	message := stun.MustBuild(stun.TransactionID, stun.BindingRequest)
-
-	ip := net.IP{}
-
-	err = c.Do(message, func(res stun.Event) {
-		if res.Error != nil {
-			err = res.Error
-			return
-		}
-
-		var xorAddr stun.XORMappedAddress
-		err = xorAddr.GetFrom(res.Message)
-		if err != nil {
-			return
-		}
-
-		ip = xorAddr.IP
-	})
-
-	if err != nil {
-		err = errors.New("Failed to get IP address from STUN: " + err.Error())
-	}
-
-	return ip.To4(), nil
-}
-
 func sleepWithContext(ctx context.Context, duration time.Duration) {
 	timer := time.NewTimer(duration)
 	defer timer.Stop()
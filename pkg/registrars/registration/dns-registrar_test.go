@@ -0,0 +1,84 @@
+package registration
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/responder"
+	"github.com/stretchr/testify/require"
+)
+
+var errTestDial = errors.New("test dial")
+
+func TestCreateRequesterDialTransport(t *testing.T) {
+	for method, network := range map[DNSTransportMethodType]string{TCP: "tcp", DoQ: "udp"} {
+		var dialed string
+		req, err := createRequester(&Config{
+			DNSTransportMethod: method,
+			Target:             "127.0.0.1:853",
+			BaseDomain:         "registrar.example.com",
+			Pubkey:             make([]byte, 32),
+			DNSDialTransport: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				dialed = network
+				return nil, errTestDial
+			},
+		})
+		require.Nil(t, err)
+
+		_, err = req.RequestAndRecv([]byte("registration"))
+		require.NotNil(t, err)
+		require.Equal(t, network, dialed, "transport %d did not use the configured dialer", method)
+	}
+}
+
+func TestCreateRequesterTLSConfig(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "registration test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+
+	server, err := responder.ListenQUIC("127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	})
+	require.Nil(t, err)
+	defer server.Close()
+
+	// The handshake reaching the configured verification shows the TLS config
+	// is used for DoQ, it is then aborted as the test server never answers.
+	verified := false
+	req, err := createRequester(&Config{
+		DNSTransportMethod: DoQ,
+		Target:             server.LocalAddr().String(),
+		BaseDomain:         "registrar.example.com",
+		Pubkey:             make([]byte, 32),
+		DNSTLSConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			VerifyConnection: func(tls.ConnectionState) error {
+				verified = true
+				return errTestDial
+			},
+		},
+	})
+	require.Nil(t, err)
+
+	_, err = req.RequestAndRecv([]byte("registration"))
+	require.NotNil(t, err)
+	require.True(t, verified)
+}
@@ -0,0 +1,185 @@
+package registration
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pion/stun"
+)
+
+// defaultSTUNTimeout bounds how long a single STUN binding request may take.
+const defaultSTUNTimeout = 3 * time.Second
+
+var (
+	// ErrNoSTUNServers is returned when address discovery is attempted with no
+	// STUN servers configured.
+	ErrNoSTUNServers = errors.New("no STUN servers configured")
+
+	// ErrSTUNDisagree is returned when the configured STUN servers report
+	// different public addresses and no address is reported by a majority.
+	ErrSTUNDisagree = errors.New("STUN servers disagree on public address")
+)
+
+// stunResult is the outcome of a single STUN binding request.
+type stunResult struct {
+	server string
+	ip     net.IP
+	err    error
+}
+
+// stunLookupFunc performs one STUN binding request to server over network
+// ("udp4" or "udp6") and returns the reflexive address.
+type stunLookupFunc func(network, server string, timeout time.Duration) (net.IP, error)
+
+// publicAddrs holds the public addresses discovered for the client.
+type publicAddrs struct {
+	v4 net.IP
+	v6 net.IP
+}
+
+// registrationAddress returns the address to report in the
+// RegistrationAddress field, preferring IPv4. Returns nil if no address is
+// known.
+func (p *publicAddrs) registrationAddress() []byte {
+	if p == nil {
+		return nil
+	}
+	if p.v4 != nil {
+		return p.v4.To4()
+	}
+	if p.v6 != nil {
+		return p.v6.To16()
+	}
+	return nil
+}
+
+// discoverPublicAddrs queries every server over both IPv4 and IPv6 in parallel
+// and cross-checks the answers per address family. An address family is only
+// reported when a strict majority of servers that answered for it agree. An
+// error is returned only if neither family produced an address.
+func discoverPublicAddrs(servers []string, timeout time.Duration, lookup stunLookupFunc) (*publicAddrs, error) {
+	if len(servers) == 0 {
+		return nil, ErrNoSTUNServers
+	}
+	if timeout <= 0 {
+		timeout = defaultSTUNTimeout
+	}
+	if lookup == nil {
+		lookup = stunLookup
+	}
+
+	var wg sync.WaitGroup
+	v4Results := make([]stunResult, len(servers))
+	v6Results := make([]stunResult, len(servers))
+	for i, server := range servers {
+		wg.Add(2)
+		go func(i int, server string) {
+			defer wg.Done()
+			ip, err := lookup("udp4", server, timeout)
+			v4Results[i] = stunResult{server: server, ip: ip, err: err}
+		}(i, server)
+		go func(i int, server string) {
+			defer wg.Done()
+			ip, err := lookup("udp6", server, timeout)
+			v6Results[i] = stunResult{server: server, ip: ip, err: err}
+		}(i, server)
+	}
+	wg.Wait()
+
+	v4, err4 := crossCheckSTUN(v4Results, func(ip net.IP) bool { return ip.To4() != nil })
+	v6, err6 := crossCheckSTUN(v6Results, func(ip net.IP) bool { return ip.To4() == nil && ip.To16() != nil })
+	if v4 == nil && v6 == nil {
+		return nil, fmt.Errorf("public address discovery failed: v4: %v, v6: %v", err4, err6)
+	}
+
+	return &publicAddrs{v4: v4, v6: v6}, nil
+}
+
+// crossCheckSTUN returns the address reported by a strict majority of the
+// successful results that satisfy valid.
+func crossCheckSTUN(results []stunResult, valid func(net.IP) bool) (net.IP, error) {
+	counts := map[string]int{}
+	ips := map[string]net.IP{}
+	answered := 0
+	var lastErr error
+	for _, res := range results {
+		if res.err != nil {
+			lastErr = fmt.Errorf("%s: %w", res.server, res.err)
+			continue
+		}
+		if res.ip == nil || !valid(res.ip) {
+			lastErr = fmt.Errorf("%s: unexpected address %v", res.server, res.ip)
+			continue
+		}
+		answered++
+		counts[res.ip.String()]++
+		ips[res.ip.String()] = res.ip
+	}
+
+	if answered == 0 {
+		if lastErr == nil {
+			lastErr = ErrNoSTUNServers
+		}
+		return nil, lastErr
+	}
+
+	for addr, n := range counts {
+		if 2*n > answered {
+			return ips[addr], nil
+		}
+	}
+
+	return nil, ErrSTUNDisagree
+}
+
+// stunLookup sends a STUN binding request to server and returns the
+// XOR-mapped address from the response.
+func stunLookup(network, server string, timeout time.Duration) (net.IP, error) {
+	c, err := stun.Dial(network, server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to STUN server: %w", err)
+	}
+	defer c.Close()
+
+	message := stun.MustBuild(stun.TransactionID, stun.BindingRequest)
+
+	type reply struct {
+		ip  net.IP
+		err error
+	}
+	done := make(chan reply, 1)
+	go func() {
+		var ip net.IP
+		var cbErr error
+		err := c.Do(message, func(res stun.Event) {
+			if res.Error != nil {
+				cbErr = res.Error
+				return
+			}
+
+			var xorAddr stun.XORMappedAddress
+			if err := xorAddr.GetFrom(res.Message); err != nil {
+				cbErr = err
+				return
+			}
+			ip = xorAddr.IP
+		})
+		if err == nil {
+			err = cbErr
+		}
+		done <- reply{ip, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			return nil, fmt.Errorf("failed to get IP address from STUN: %w", r.err)
+		}
+		return r.ip, nil
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("STUN request to %s timed out", server)
+	}
+}
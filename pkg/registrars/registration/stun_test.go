@@ -0,0 +1,65 @@
+package registration
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func fakeSTUN(answers map[string]string) stunLookupFunc {
+	return func(network, server string, timeout time.Duration) (net.IP, error) {
+		addr, ok := answers[network+"/"+server]
+		if !ok {
+			return nil, errors.New("unreachable")
+		}
+		return net.ParseIP(addr), nil
+	}
+}
+
+func TestDiscoverPublicAddrs(t *testing.T) {
+	servers := []string{"a:3478", "b:3478", "c:3478"}
+
+	addrs, err := discoverPublicAddrs(servers, time.Second, fakeSTUN(map[string]string{
+		"udp4/a:3478": "192.0.2.1",
+		"udp4/b:3478": "192.0.2.1",
+		"udp4/c:3478": "192.0.2.99",
+		"udp6/b:3478": "2001:db8::1",
+	}))
+	require.Nil(t, err)
+	require.Equal(t, "192.0.2.1", addrs.v4.String())
+	require.Equal(t, "2001:db8::1", addrs.v6.String())
+	require.Equal(t, []byte(net.ParseIP("192.0.2.1").To4()), addrs.registrationAddress())
+
+	// v6 only
+	addrs, err = discoverPublicAddrs(servers, time.Second, fakeSTUN(map[string]string{
+		"udp6/a:3478": "2001:db8::1",
+	}))
+	require.Nil(t, err)
+	require.Nil(t, addrs.v4)
+	require.Equal(t, []byte(net.ParseIP("2001:db8::1").To16()), addrs.registrationAddress())
+
+	// no majority
+	_, err = discoverPublicAddrs(servers[:2], time.Second, fakeSTUN(map[string]string{
+		"udp4/a:3478": "192.0.2.1",
+		"udp4/b:3478": "192.0.2.2",
+	}))
+	require.ErrorContains(t, err, ErrSTUNDisagree.Error())
+
+	// nothing reachable
+	_, err = discoverPublicAddrs(servers, time.Second, fakeSTUN(nil))
+	require.NotNil(t, err)
+
+	_, err = discoverPublicAddrs(nil, time.Second, fakeSTUN(nil))
+	require.ErrorIs(t, err, ErrNoSTUNServers)
+}
+
+func TestCrossCheckSTUNWrongFamily(t *testing.T) {
+	ip, err := crossCheckSTUN([]stunResult{
+		{server: "a", ip: net.ParseIP("2001:db8::1")},
+	}, func(ip net.IP) bool { return ip.To4() != nil })
+	require.Nil(t, ip)
+	require.NotNil(t, err)
+}
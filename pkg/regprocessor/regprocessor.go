@@ -60,11 +60,19 @@ type ipSelector interface {
 	Select([]byte, uint, uint, bool) (net.IP, error)
 }
 
+// excludingIPSelector is implemented by selectors that can re-derive a phantom to avoid known live
+// hosts.
+type excludingIPSelector interface {
+	SelectExcluding([]byte, uint, uint, bool, func(net.IP) bool) (net.IP, error)
+}
+
 // RegProcessor provides an interface to publish registrations and helper functions to process registration requests
 type RegProcessor struct {
 	zmqMutex      sync.Mutex
 	selectorMutex sync.RWMutex
 	ipSelector    ipSelector
+	exclusions    *lib.PhantomExclusions // known live phantoms, guarded by selectorMutex
+	subnetsPath   string                 // phantom subnets file, see lib.PhantomSubnetsFile
 	sock          zmqSender
 	metrics       *metrics.Metrics
 	authenticated bool
@@ -75,15 +83,16 @@ type RegProcessor struct {
 	transports map[pb.TransportType]lib.Transport
 }
 
-// NewRegProcessor initialize a new RegProcessor
-func NewRegProcessor(zmqBindAddr string, zmqPort uint16, privkey []byte, authVerbose bool, stationPublicKeys []string, metrics *metrics.Metrics) (*RegProcessor, error) {
+// NewRegProcessor initialize a new RegProcessor selecting phantoms from the subnets file at
+// subnetsPath, or the PHANTOM_SUBNET_LOCATION env var if it is empty.
+func NewRegProcessor(zmqBindAddr string, zmqPort uint16, privkey []byte, authVerbose bool, stationPublicKeys []string, subnetsPath string, metrics *metrics.Metrics) (*RegProcessor, error) {
 
 	if len(privkey) != ed25519.PrivateKeySize {
 		// We require the 64 byte [private_key][public_key] format to Sign using crypto/ed25519
 		return nil, fmt.Errorf("incorrect private key size %d, expected %d", len(privkey), ed25519.PrivateKeySize)
 	}
 
-	phantomSelector, err := lib.GetPhantomSubnetSelector()
+	phantomSelector, err := lib.SubnetsFromTomlFile(lib.PhantomSubnetsFile(subnetsPath))
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +102,7 @@ func NewRegProcessor(zmqBindAddr string, zmqPort uint16, privkey []byte, authVer
 		return nil, err
 	}
 	regProcessor.ipSelector = phantomSelector
+	regProcessor.subnetsPath = subnetsPath
 	regProcessor.metrics = metrics
 
 	return regProcessor, nil
@@ -148,7 +158,7 @@ func newRegProcessor(zmqBindAddr string, zmqPort uint16, privkey []byte, authVer
 }
 
 // NewRegProcessorNoAuth creates a regprocessor without authentication to zmq address
-func NewRegProcessorNoAuth(zmqBindAddr string, zmqPort uint16, metrics *metrics.Metrics) (*RegProcessor, error) {
+func NewRegProcessorNoAuth(zmqBindAddr string, zmqPort uint16, subnetsPath string, metrics *metrics.Metrics) (*RegProcessor, error) {
 	sock, err := zmq.NewSocket(zmq.PUB)
 	if err != nil {
 		return nil, ErrZmqSocket
@@ -159,7 +169,7 @@ func NewRegProcessorNoAuth(zmqBindAddr string, zmqPort uint16, metrics *metrics.
 		return nil, ErrZmqSocket
 	}
 
-	phantomSelector, err := lib.GetPhantomSubnetSelector()
+	phantomSelector, err := lib.SubnetsFromTomlFile(lib.PhantomSubnetsFile(subnetsPath))
 	if err != nil {
 		return nil, err
 	}
@@ -168,6 +178,7 @@ func NewRegProcessorNoAuth(zmqBindAddr string, zmqPort uint16, metrics *metrics.
 		zmqMutex:      sync.Mutex{},
 		selectorMutex: sync.RWMutex{},
 		ipSelector:    phantomSelector,
+		subnetsPath:   subnetsPath,
 		sock:          sock,
 		metrics:       metrics,
 		transports:    make(map[pb.TransportType]lib.Transport),
@@ -212,9 +223,14 @@ func (p *RegProcessor) sendToZMQ(message []byte) error {
 func (p *RegProcessor) RegisterUnidirectional(c2sPayload *pb.C2SWrapper, regMethod pb.RegistrationSource, clientAddr []byte) error {
 	// While Registration response is a valid field in the client-to-station-wrapper (C2SWrapper) it
 	// is not a field that the client is allowed to set, and it is not meaningful in the context of
-	// a unidirectional registration.
-	if c2sPayload.GetRegistrationResponse() != nil {
-		c2sPayload.RegistrationResponse = nil
+	// a unidirectional registration. Registrations shared by a station keep the phantom it uses,
+	// which stations only accept if it is a re-derivation of the registration seed.
+	if rr := c2sPayload.GetRegistrationResponse(); rr != nil {
+		if c2sPayload.GetRegistrationSource() == pb.RegistrationSource_DetectorPrescan {
+			c2sPayload.RegistrationResponse = &pb.RegistrationResponse{Ipv4Addr: rr.Ipv4Addr, Ipv6Addr: rr.Ipv6Addr}
+		} else {
+			c2sPayload.RegistrationResponse = nil
+		}
 	}
 
 	zmqPayload, err := p.processC2SWrapper(c2sPayload, clientAddr, regMethod)
@@ -240,6 +256,9 @@ func (p *RegProcessor) RegisterBidirectional(c2sPayload *pb.C2SWrapper, regMetho
 
 	regResp, err := p.processBdReq(c2sPayload)
 	if err != nil {
+		if errors.Is(err, lib.ErrGenerationExpired) {
+			p.metrics.Add("reg_expired_generation_"+regMethod.String(), 1)
+		}
 		return nil, err
 	}
 
@@ -277,9 +296,7 @@ func (p *RegProcessor) processBdReq(c2sPayload *pb.C2SWrapper) (*pb.Registration
 	}
 
 	if c2s.GetV4Support() {
-		p.selectorMutex.RLock()
-		defer p.selectorMutex.RUnlock()
-		phantom4, err := p.ipSelector.Select(
+		phantom4, err := p.selectPhantom(
 			cjkeys.ConjureSeed,
 			uint(c2s.GetDecoyListGeneration()), //generation type uint
 			clientLibVer,
@@ -295,9 +312,7 @@ func (p *RegProcessor) processBdReq(c2sPayload *pb.C2SWrapper) (*pb.Registration
 	}
 
 	if c2s.GetV6Support() {
-		p.selectorMutex.RLock()
-		defer p.selectorMutex.RUnlock()
-		phantom6, err := p.ipSelector.Select(
+		phantom6, err := p.selectPhantom(
 			cjkeys.ConjureSeed,
 			uint(c2s.GetDecoyListGeneration()),
 			clientLibVer,
@@ -352,6 +367,19 @@ func (p *RegProcessor) processBdReq(c2sPayload *pb.C2SWrapper) (*pb.Registration
 	return regResp, nil
 }
 
+// selectPhantom selects the phantom address for a bidirectional registration. If known live
+// phantoms are set and the selector supports it, a live phantom is re-derived to another address
+// that the station accepts.
+func (p *RegProcessor) selectPhantom(seed []byte, generation uint, clientLibVer uint, v6Support bool) (net.IP, error) {
+	p.selectorMutex.RLock()
+	defer p.selectorMutex.RUnlock()
+
+	if sel, ok := p.ipSelector.(excludingIPSelector); ok && p.exclusions != nil {
+		return sel.SelectExcluding(seed, generation, clientLibVer, v6Support, p.exclusions.Contains)
+	}
+	return p.ipSelector.Select(seed, generation, clientLibVer, v6Support)
+}
+
 // processC2SWrapper adds missing variables to the input c2s and returns the payload in format ready to be published to zmq
 func (p *RegProcessor) processC2SWrapper(c2sPayload *pb.C2SWrapper, clientAddr []byte, regMethod pb.RegistrationSource) ([]byte, error) {
 	if c2sPayload == nil {
@@ -414,7 +442,7 @@ func (p *RegProcessor) processC2SWrapper(c2sPayload *pb.C2SWrapper, clientAddr [
 // subnets when the registrar receives a SIGHUP signal for example. If it fails it reports and error
 // and keeps the existing set of phantom subnets.
 func (p *RegProcessor) ReloadSubnets() error {
-	phantomSelector, err := lib.GetPhantomSubnetSelector()
+	phantomSelector, err := lib.SubnetsFromTomlFile(lib.PhantomSubnetsFile(p.subnetsPath))
 	if err != nil {
 		return err
 	}
@@ -426,6 +454,14 @@ func (p *RegProcessor) ReloadSubnets() error {
 	return nil
 }
 
+// SetPhantomExclusions sets the known live phantoms that bidirectional registrations avoid. A nil
+// set disables the exclusion.
+func (p *RegProcessor) SetPhantomExclusions(exclusions *lib.PhantomExclusions) {
+	p.selectorMutex.Lock()
+	defer p.selectorMutex.Unlock()
+	p.exclusions = exclusions
+}
+
 // ReloadOverrides allows the registrar to reload the configuration for the registration processing
 // overrides when the registrar receives a SIGHUP signal for example.
 // TODO: implement
@@ -16,6 +16,7 @@ import (
 	"github.com/refraction-networking/conjure/pkg/core/interfaces"
 	"github.com/refraction-networking/conjure/pkg/metrics"
 	"github.com/refraction-networking/conjure/pkg/regserver/overrides"
+	"github.com/refraction-networking/conjure/pkg/station/lib"
 	"github.com/refraction-networking/conjure/pkg/transports"
 	"github.com/refraction-networking/conjure/pkg/transports/wrapping/min"
 	"github.com/refraction-networking/conjure/pkg/transports/wrapping/prefix"
@@ -212,6 +213,32 @@ func TestRegisterUnidirectional(t *testing.T) {
 
 }
 
+func TestRegisterUnidirectionalResponse(t *testing.T) {
+	port := uint32(22)
+	phantom := uint32(0xc07abe07)
+	var sent *pb.C2SWrapper
+	s := mockRegProcessor()
+	s.sock = fakeZmqSender{fakeSend: func(m []byte, flag zmq.Flag) (int, error) {
+		sent = &pb.C2SWrapper{}
+		return len(m), proto.Unmarshal(m, sent)
+	}}
+
+	// Clients can not set a registration response.
+	c2sPayload, _ := generateC2SWrapperPayload()
+	c2sPayload.RegistrationResponse = &pb.RegistrationResponse{Ipv4Addr: &phantom, DstPort: &port}
+	require.Nil(t, s.RegisterUnidirectional(c2sPayload, pb.RegistrationSource_API, net.ParseIP("4.3.2.1")))
+	require.Nil(t, sent.GetRegistrationResponse())
+
+	// Registrations shared by a station keep only the phantom it uses.
+	regSrc := pb.RegistrationSource_DetectorPrescan
+	c2sPayload, _ = generateC2SWrapperPayload()
+	c2sPayload.RegistrationSource = &regSrc
+	c2sPayload.RegistrationResponse = &pb.RegistrationResponse{Ipv4Addr: &phantom, DstPort: &port}
+	require.Nil(t, s.RegisterUnidirectional(c2sPayload, pb.RegistrationSource_API, net.ParseIP("4.3.2.1")))
+	require.Equal(t, phantom, sent.GetRegistrationResponse().GetIpv4Addr())
+	require.Nil(t, sent.GetRegistrationResponse().DstPort)
+}
+
 func TestUnspecifiedReg(t *testing.T) {
 	originalIP := "1.2.3.4"
 	updatedIP := "4.3.2.1"
@@ -548,3 +575,64 @@ func (mp *mockPrefix) DstPort([]byte) uint16 {
 func (mp *mockPrefix) FlushAfterPrefix() bool {
 	return true
 }
+
+func TestRegProcessBdReqExclusions(t *testing.T) {
+	phantomSelector, err := lib.SubnetsFromTomlFile("../station/lib/test/phantom_subnets.toml")
+	require.Nil(t, err)
+
+	r := &RegProcessor{
+		zmqMutex:      sync.Mutex{},
+		selectorMutex: sync.RWMutex{},
+		authenticated: false,
+		ipSelector:    phantomSelector,
+	}
+	err = r.AddTransport(pb.TransportType_Min, min.Transport{})
+	require.Nil(t, err)
+
+	clv := uint32(4)
+	gen := uint32(957)
+	tspt := pb.TransportType_Min
+	trueptr := true
+	newC2SW := func() *pb.C2SWrapper {
+		return &pb.C2SWrapper{
+			RegistrationPayload: &pb.ClientToStation{
+				ClientLibVersion:    &clv,
+				DecoyListGeneration: &gen,
+				Transport:           &tspt,
+				V4Support:           &trueptr,
+			},
+			SharedSecret: make([]byte, 32),
+		}
+	}
+
+	resp, err := r.processBdReq(newC2SW())
+	require.Nil(t, err)
+	selected := make(net.IP, 4)
+	binary.BigEndian.PutUint32(selected, resp.GetIpv4Addr())
+
+	// A known live phantom is re-derived to another address.
+	live := lib.NewPhantomExclusions()
+	live.Add(selected)
+	r.SetPhantomExclusions(live)
+
+	resp, err = r.processBdReq(newC2SW())
+	require.Nil(t, err)
+	reselected := make(net.IP, 4)
+	binary.BigEndian.PutUint32(reselected, resp.GetIpv4Addr())
+	require.False(t, reselected.Equal(selected))
+
+	// The station accepts the re-derived phantom.
+	keys, err := lib.GenSharedKeys(uint(clv), make([]byte, 32), tspt)
+	require.Nil(t, err)
+	require.True(t, phantomSelector.IsReselection(keys.ConjureSeed, uint(gen), uint(clv), false, reselected))
+}
+
+func TestRegProcessReloadSubnetsPath(t *testing.T) {
+	t.Setenv("PHANTOM_SUBNET_LOCATION", "")
+	r := &RegProcessor{subnetsPath: "../station/lib/test/phantom_subnets.toml"}
+	require.Nil(t, r.ReloadSubnets())
+	require.NotNil(t, r.ipSelector)
+
+	r = &RegProcessor{}
+	require.NotNil(t, r.ReloadSubnets())
+}
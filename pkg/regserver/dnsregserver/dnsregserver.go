@@ -4,6 +4,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net"
 	"sync/atomic"
 
 	"github.com/refraction-networking/conjure/pkg/metrics"
@@ -27,8 +28,21 @@ type DNSRegServer struct {
 	latestCCGen  uint32
 	logger       log.FieldLogger
 	metrics      *metrics.Metrics
+
+	// resolvers whose EDNS(0) Client Subnet options are trusted as registration addresses
+	trustedECSResolvers []*net.IPNet
+
+	// limiter enforces the request limits, if any are set
+	limiter *limiter
 }
 
+const (
+	// Client Subnet networks are truncated to at most these many bits before being used as
+	// registration addresses, so that no full client address is recorded.
+	ecsMaxBitsIPv4 = 24
+	ecsMaxBitsIPv6 = 48
+)
+
 // NewDNSRegServer creates a new DNSRegServer object.
 func NewDNSRegServer(domain string, udpAddr string, privkey []byte, regprocessor *regprocessor.RegProcessor, latestClientConfGeneration uint32, logger log.FieldLogger, metrics *metrics.Metrics) (*DNSRegServer, error) {
 
@@ -54,7 +68,109 @@ func NewDNSRegServer(domain string, udpAddr string, privkey []byte, regprocessor
 	}, nil
 }
 
+// NewDNSRegServerFromConn creates a new DNSRegServer answering the DNS requests read from conn.
+// This allows serving over transports other than UDP, such as the listeners returned by
+// responder.ListenTCP, responder.ListenTLS and responder.ListenQUIC.
+func NewDNSRegServerFromConn(domain string, conn net.PacketConn, privkey []byte, regprocessor *regprocessor.RegProcessor, latestClientConfGeneration uint32, logger log.FieldLogger, metrics *metrics.Metrics) (*DNSRegServer, error) {
+
+	if domain == "" || conn == nil || privkey == nil || regprocessor == nil || logger == nil {
+		return nil, errors.New("all arguments must not be nil")
+	}
+
+	if len(privkey) < 32 {
+		return nil, fmt.Errorf("Expected 32 byte privkey: got %d", len(privkey))
+	}
+
+	respder, err := responder.NewDnsResponderFromConn(domain, conn, privkey[:32])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DNS responder: %v", err)
+	}
+
+	return &DNSRegServer{
+		dnsResponder: respder,
+		processor:    regprocessor,
+		latestCCGen:  latestClientConfGeneration,
+		logger:       logger,
+		metrics:      metrics,
+	}, nil
+}
+
+// SetTrustedECSResolvers sets the networks, in CIDR notation, of the recursive resolvers whose
+// EDNS(0) Client Subnet options are used as the coarse registration address of registrations
+// that do not report one. Client Subnet options from other resolvers are ignored.
+func (s *DNSRegServer) SetTrustedECSResolvers(cidrs []string) error {
+	nets := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid trusted ECS resolver network %q: %v", cidr, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	s.trustedECSResolvers = nets
+	return nil
+}
+
+// registrationAddress returns the coarse registration address given by the Client Subnet
+// option of the query described by info, or nil if it has none or its resolver is not trusted.
+func (s *DNSRegServer) registrationAddress(info *responder.QueryInfo) []byte {
+	if info == nil || info.ClientSubnet == nil || !s.trustedResolver(info.Resolver) {
+		return nil
+	}
+	network := info.ClientSubnet.Network(ecsMaxBitsIPv4, ecsMaxBitsIPv6)
+	if network == nil {
+		return nil
+	}
+	return network.IP
+}
+
+func (s *DNSRegServer) trustedResolver(addr net.Addr) bool {
+	ip := net.ParseIP(resolverKey(addr))
+	if ip == nil {
+		return false
+	}
+	for _, ipNet := range s.trustedECSResolvers {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// SetLimits sets the limits on the requests the server accepts. It must be called before
+// ListenAndServe.
+func (s *DNSRegServer) SetLimits(limits Limits) error {
+	if limits.PerResolver < 0 || limits.PerClient < 0 || limits.PerWindow < 0 {
+		return errors.New("limits must not be negative")
+	}
+	if limits.PerResolver == 0 && limits.PerClient == 0 && limits.PerWindow == 0 {
+		s.limiter = nil
+		return nil
+	}
+	if limits.Window <= 0 {
+		return errors.New("limits require a positive window")
+	}
+	s.limiter = newLimiter(limits)
+	return nil
+}
+
+// admit declines requests from resolvers over their limit before they are decrypted.
+func (s *DNSRegServer) admit(info *responder.QueryInfo) error {
+	ok, _ := s.limiter.resolvers.allow(resolverKey(info.Resolver), s.limiter.limits.PerResolver, 0)
+	if !ok {
+		s.metrics.Add("dns_limited_resolver_total", 1)
+		return s.limiter.declined()
+	}
+	return nil
+}
+
 func (s *DNSRegServer) ListenAndServe() error {
+	if s.metrics != nil {
+		s.dnsResponder.SetMetrics(s.metrics.Add)
+	}
+	if s.limiter != nil {
+		s.dnsResponder.SetAdmission(s.admit)
+	}
 	err := s.dnsResponder.RecvAndRespond(s.processRequest)
 	if err != nil {
 		return errors.New("dns responder error: " + err.Error())
@@ -62,7 +178,7 @@ func (s *DNSRegServer) ListenAndServe() error {
 	return nil
 }
 
-func (s *DNSRegServer) processRequest(reqIn []byte) ([]byte, error) {
+func (s *DNSRegServer) processRequest(reqIn []byte, info *responder.QueryInfo) ([]byte, error) {
 	s.metrics.Add("dns_requests_total", 1)
 
 	c2sPayload := &pb.C2SWrapper{}
@@ -75,6 +191,20 @@ func (s *DNSRegServer) processRequest(reqIn []byte) ([]byte, error) {
 	reqLogger := s.logger.WithField("regid", hex.EncodeToString(c2sPayload.GetSharedSecret()))
 	reqLogger.Tracef("Request received: [%+v]", c2sPayload)
 
+	if s.limiter != nil {
+		clientOK, windowOK := s.limiter.clients.allow(string(c2sPayload.GetSharedSecret()), s.limiter.limits.PerClient, s.limiter.limits.PerWindow)
+		if !clientOK {
+			s.metrics.Add("dns_limited_client_total", 1)
+			reqLogger.Debugf("registration declined: client over limit")
+			return nil, s.limiter.declined()
+		}
+		if !windowOK {
+			s.metrics.Add("dns_limited_window_total", 1)
+			reqLogger.Debugf("registration declined: window over limit")
+			return nil, s.limiter.declined()
+		}
+	}
+
 	clientconfOutdated := false
 	if c2sPayload.RegistrationPayload.GetDecoyListGeneration() < atomic.LoadUint32(&s.latestCCGen) {
 		clientconfOutdated = true
@@ -84,15 +214,26 @@ func (s *DNSRegServer) processRequest(reqIn []byte) ([]byte, error) {
 		ClientconfOutdated: &clientconfOutdated,
 	}
 
+	// The ECS network is only a fallback for clients that do not report their own address, and
+	// only its prefix length is logged, never the client's network.
+	var clientAddr []byte
+	if c2sPayload.GetRegistrationAddress() == nil {
+		clientAddr = s.registrationAddress(info)
+	}
+	if clientAddr != nil {
+		s.metrics.Add("dns_ecs_addresses_total", 1)
+		reqLogger = reqLogger.WithField("ecs-prefix", info.ClientSubnet.SourcePrefix)
+	}
+
 	reqIsBd := c2sPayload.GetRegistrationSource() == pb.RegistrationSource_BidirectionalDNS
 	if reqIsBd {
-		reqLogger = s.logger.WithField("registration-type", "bidirectional")
+		reqLogger = reqLogger.WithField("registration-type", "bidirectional")
 		var regResponse *pb.RegistrationResponse
-		regResponse, err = s.processor.RegisterBidirectional(c2sPayload, pb.RegistrationSource_BidirectionalDNS, nil)
+		regResponse, err = s.processor.RegisterBidirectional(c2sPayload, pb.RegistrationSource_BidirectionalDNS, clientAddr)
 		dnsResp.BidirectionalResponse = regResponse
 	} else {
-		reqLogger = s.logger.WithField("registration-type", "unidirectional")
-		err = s.processor.RegisterUnidirectional(c2sPayload, pb.RegistrationSource_DNS, nil)
+		reqLogger = reqLogger.WithField("registration-type", "unidirectional")
+		err = s.processor.RegisterUnidirectional(c2sPayload, pb.RegistrationSource_DNS, clientAddr)
 	}
 
 	// if registration publish failed, immediately return
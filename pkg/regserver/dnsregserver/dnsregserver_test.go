@@ -1,17 +1,30 @@
 package dnsregserver
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"net"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/refraction-networking/conjure/pkg/metrics"
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/dns"
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/encryption"
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/requester"
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/responder"
 	"github.com/refraction-networking/conjure/pkg/regprocessor"
 	pb "github.com/refraction-networking/conjure/proto"
 	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -100,7 +113,7 @@ func TestBadAccepter(t *testing.T) {
 
 	_, body := generateC2SWrapperPayload()
 
-	responsePayload, err := s.processRequest(body)
+	responsePayload, err := s.processRequest(body, nil)
 
 	if err != nil {
 		t.Fatalf("processRequest returned error")
@@ -138,7 +151,7 @@ func TestCorrectUnidirectionalDNS(t *testing.T) {
 	c2sPayload.RegistrationAddress = net.ParseIP(clientIP).To16()
 	body, _ := proto.Marshal(c2sPayload)
 
-	responsePayload, err := s.processRequest(body)
+	responsePayload, err := s.processRequest(body, nil)
 
 	if err != nil {
 		t.Fatalf("processRequest returned error: %v", err)
@@ -191,7 +204,7 @@ func TestCorrectBidirectionalDNS(t *testing.T) {
 
 	fmt.Println(c2sPayload.SharedSecret)
 
-	responsePayload, err := s.processRequest(body)
+	responsePayload, err := s.processRequest(body, nil)
 
 	if err != nil {
 		t.Fatalf("processRequest returned error: %v", err)
@@ -262,7 +275,7 @@ func TestCorrectClientConfGen(t *testing.T) {
 
 	fmt.Println(c2sPayload.SharedSecret)
 
-	responsePayload, err := s.processRequest(body)
+	responsePayload, err := s.processRequest(body, nil)
 
 	if err != nil {
 		t.Fatalf("processRequest returned error: %v", err)
@@ -279,3 +292,508 @@ func TestCorrectClientConfGen(t *testing.T) {
 	}
 
 }
+
+func selfSignedTLSConfig(t *testing.T) (*tls.Config, *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.Nil(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dnsregserver test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.Nil(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.Nil(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+	}, pool
+}
+
+func TestDNSRegServerTransports(t *testing.T) {
+	domain := "r.example.com"
+	privkey, err := encryption.GeneratePrivkey()
+	require.Nil(t, err)
+
+	serverTLS, roots := selfSignedTLSConfig(t)
+
+	listeners := map[requester.TransportMethodType]func() (net.PacketConn, error){
+		requester.UDP: func() (net.PacketConn, error) { return net.ListenPacket("udp", "127.0.0.1:0") },
+		requester.TCP: func() (net.PacketConn, error) { return responder.ListenTCP("127.0.0.1:0") },
+		requester.DoT: func() (net.PacketConn, error) { return responder.ListenTLS("127.0.0.1:0", serverTLS) },
+		requester.DoQ: func() (net.PacketConn, error) { return responder.ListenQUIC("127.0.0.1:0", serverTLS) },
+	}
+
+	for method, listen := range listeners {
+		conn, err := listen()
+		require.Nil(t, err)
+
+		dnsResponder, err := responder.NewDnsResponderFromConn(domain, conn, privkey)
+		require.Nil(t, err)
+
+		s := newDNSRegServer()
+		s.dnsResponder = dnsResponder
+		s.processor = &fakeRegistrar{}
+		go func() {
+			_ = s.ListenAndServe()
+		}()
+
+		req, err := requester.NewRequester(&requester.Config{
+			TransportMethod:  method,
+			Target:           dnsResponder.LocalAddr().String(),
+			BaseDomain:       domain,
+			Pubkey:           encryption.PubkeyFromPrivkey(privkey),
+			TLSConfig:        &tls.Config{RootCAs: roots},
+			UtlsDistribution: "none",
+		})
+		require.Nil(t, err)
+
+		_, body := generateC2SWrapperPayload()
+
+		type result struct {
+			resp []byte
+			err  error
+		}
+		done := make(chan result, 1)
+		go func() {
+			resp, err := req.RequestAndRecv(body)
+			done <- result{resp, err}
+		}()
+
+		select {
+		case res := <-done:
+			require.Nil(t, res.err, "transport %d", method)
+			response := &pb.DnsResponse{}
+			require.Nil(t, proto.Unmarshal(res.resp, response))
+			require.True(t, response.GetSuccess(), "transport %d", method)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for response on transport %d", method)
+		}
+
+		req.Close()
+		s.Close()
+	}
+}
+
+func TestDNSRegServerMultiQuery(t *testing.T) {
+	domain := "r.example.com"
+	privkey, err := encryption.GeneratePrivkey()
+	require.Nil(t, err)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	dnsResponder, err := responder.NewDnsResponderFromConn(domain, conn, privkey)
+	require.Nil(t, err)
+
+	// Large enough to need several response fragments.
+	bigError := string(make([]byte, 4000))
+
+	paddingLen := make(chan int, 1)
+	s := newDNSRegServer()
+	s.dnsResponder = dnsResponder
+	s.processor = &fakeRegistrar{
+		fakeRegisterBidirectionalFunc: func(c2s *pb.C2SWrapper, _ pb.RegistrationSource, _ []byte) (*pb.RegistrationResponse, error) {
+			select {
+			case paddingLen <- len(c2s.GetRegistrationPayload().GetPadding()):
+			default:
+			}
+			return &pb.RegistrationResponse{Error: &bigError}, nil
+		},
+	}
+	go func() {
+		_ = s.ListenAndServe()
+	}()
+	defer s.Close()
+
+	for _, multiQuery := range []bool{false, true} {
+		req, err := requester.NewRequester(&requester.Config{
+			TransportMethod: requester.UDP,
+			Target:          dnsResponder.LocalAddr().String(),
+			BaseDomain:      domain,
+			Pubkey:          encryption.PubkeyFromPrivkey(privkey),
+			MultiQuery:      multiQuery,
+		})
+		require.Nil(t, err)
+
+		c2s, _ := generateC2SWrapperPayload()
+		regSrc := pb.RegistrationSource_BidirectionalDNS
+		c2s.RegistrationSource = &regSrc
+		c2s.RegistrationPayload.Padding = make([]byte, 2000)
+		body, _ := proto.Marshal(c2s)
+
+		type result struct {
+			resp []byte
+			err  error
+		}
+		done := make(chan result, 1)
+		go func() {
+			resp, err := req.RequestAndRecv(body)
+			done <- result{resp, err}
+		}()
+
+		select {
+		case res := <-done:
+			require.Nil(t, res.err, "multiQuery %v", multiQuery)
+			require.Equal(t, 2000, <-paddingLen)
+			response := &pb.DnsResponse{}
+			require.Nil(t, proto.Unmarshal(res.resp, response))
+			require.True(t, response.GetSuccess())
+			require.Equal(t, bigError, response.GetBidirectionalResponse().GetError())
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for response")
+		}
+
+		req.Close()
+	}
+}
+
+func TestDNSRegServerRecordTypes(t *testing.T) {
+	domain := "r.example.com"
+	privkey, err := encryption.GeneratePrivkey()
+	require.Nil(t, err)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	dnsResponder, err := responder.NewDnsResponderFromConn(domain, conn, privkey)
+	require.Nil(t, err)
+
+	// Larger than a single answer of the smaller encodings.
+	respError := string(make([]byte, 500))
+
+	s := newDNSRegServer()
+	s.dnsResponder = dnsResponder
+	s.processor = &fakeRegistrar{
+		fakeRegisterBidirectionalFunc: func(c2s *pb.C2SWrapper, _ pb.RegistrationSource, _ []byte) (*pb.RegistrationResponse, error) {
+			return &pb.RegistrationResponse{Error: &respError}, nil
+		},
+	}
+	go func() {
+		_ = s.ListenAndServe()
+	}()
+	defer s.Close()
+
+	for _, rrType := range []uint16{dns.RRTypeTXT, dns.RRTypeA, dns.RRTypeAAAA, dns.RRTypeCNAME, dns.RRTypeSVCB, dns.RRTypeHTTPS} {
+		req, err := requester.NewRequester(&requester.Config{
+			TransportMethod: requester.UDP,
+			Target:          dnsResponder.LocalAddr().String(),
+			BaseDomain:      domain,
+			Pubkey:          encryption.PubkeyFromPrivkey(privkey),
+			RecordType:      rrType,
+		})
+		require.Nil(t, err)
+
+		c2s, _ := generateC2SWrapperPayload()
+		regSrc := pb.RegistrationSource_BidirectionalDNS
+		c2s.RegistrationSource = &regSrc
+		body, _ := proto.Marshal(c2s)
+
+		type result struct {
+			resp []byte
+			err  error
+		}
+		done := make(chan result, 1)
+		go func() {
+			resp, err := req.RequestAndRecv(body)
+			done <- result{resp, err}
+		}()
+
+		select {
+		case res := <-done:
+			require.Nil(t, res.err, "record type %d", rrType)
+			response := &pb.DnsResponse{}
+			require.Nil(t, proto.Unmarshal(res.resp, response))
+			require.True(t, response.GetSuccess())
+			require.Equal(t, respError, response.GetBidirectionalResponse().GetError())
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for response in record type %d", rrType)
+		}
+
+		req.Close()
+	}
+}
+
+// duplicatingProxy forwards every UDP datagram it receives to upstream twice,
+// as a resolver retransmitting a query would, and relays the answers back.
+func duplicatingProxy(t *testing.T, upstream net.Addr) net.Addr {
+	proxy, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	t.Cleanup(func() { proxy.Close() })
+	conn, err := net.Dial("udp", upstream.String())
+	require.Nil(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	var client atomic.Value
+	go func() {
+		var buf [4096]byte
+		for {
+			n, addr, err := proxy.ReadFrom(buf[:])
+			if err != nil {
+				return
+			}
+			client.Store(addr)
+			for i := 0; i < 2; i++ {
+				_, _ = conn.Write(buf[:n])
+			}
+		}
+	}()
+	go func() {
+		var buf [4096]byte
+		for {
+			n, err := conn.Read(buf[:])
+			if err != nil {
+				return
+			}
+			if addr, ok := client.Load().(net.Addr); ok {
+				_, _ = proxy.WriteTo(buf[:n], addr)
+			}
+		}
+	}()
+	return proxy.LocalAddr()
+}
+
+func TestDNSRegServerRetransmission(t *testing.T) {
+	domain := "r.example.com"
+	privkey, err := encryption.GeneratePrivkey()
+	require.Nil(t, err)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	dnsResponder, err := responder.NewDnsResponderFromConn(domain, conn, privkey)
+	require.Nil(t, err)
+
+	var registrations atomic.Int32
+	s := newDNSRegServer()
+	s.dnsResponder = dnsResponder
+	s.processor = &fakeRegistrar{
+		fakeRegisterBidirectionalFunc: func(c2s *pb.C2SWrapper, _ pb.RegistrationSource, _ []byte) (*pb.RegistrationResponse, error) {
+			registrations.Add(1)
+			// Slow enough that some retransmissions arrive while the
+			// original is still being processed.
+			time.Sleep(10 * time.Millisecond)
+			return &pb.RegistrationResponse{}, nil
+		},
+	}
+	go func() {
+		_ = s.ListenAndServe()
+	}()
+	defer s.Close()
+
+	proxyAddr := duplicatingProxy(t, dnsResponder.LocalAddr())
+
+	for _, multiQuery := range []bool{false, true} {
+		registrations.Store(0)
+		req, err := requester.NewRequester(&requester.Config{
+			TransportMethod: requester.UDP,
+			Target:          proxyAddr.String(),
+			BaseDomain:      domain,
+			Pubkey:          encryption.PubkeyFromPrivkey(privkey),
+			MultiQuery:      multiQuery,
+		})
+		require.Nil(t, err)
+
+		c2s, _ := generateC2SWrapperPayload()
+		regSrc := pb.RegistrationSource_BidirectionalDNS
+		c2s.RegistrationSource = &regSrc
+		body, _ := proto.Marshal(c2s)
+
+		type result struct {
+			resp []byte
+			err  error
+		}
+		done := make(chan result, 1)
+		go func() {
+			resp, err := req.RequestAndRecv(body)
+			done <- result{resp, err}
+		}()
+
+		select {
+		case res := <-done:
+			require.Nil(t, res.err, "multiQuery %v", multiQuery)
+			response := &pb.DnsResponse{}
+			require.Nil(t, proto.Unmarshal(res.resp, response))
+			require.True(t, response.GetSuccess())
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for response")
+		}
+		req.Close()
+
+		require.Equal(t, int32(1), registrations.Load(), "multiQuery %v", multiQuery)
+	}
+}
+
+func TestDNSRegServerClientSubnet(t *testing.T) {
+	var gotAddr []byte
+	s := newDNSRegServer()
+	s.processor = &fakeRegistrar{
+		fakeRegisterUnidirectionalFunc: func(_ *pb.C2SWrapper, _ pb.RegistrationSource, clientAddr []byte) error {
+			gotAddr = clientAddr
+			return nil
+		},
+	}
+	require.Nil(t, s.SetTrustedECSResolvers([]string{"192.0.2.0/24", "2001:db8::/32"}))
+	require.NotNil(t, s.SetTrustedECSResolvers([]string{"not a network"}))
+
+	_, body := generateC2SWrapperPayload()
+	trusted := &net.UDPAddr{IP: net.ParseIP("192.0.2.53"), Port: 53}
+	untrusted := &net.UDPAddr{IP: net.ParseIP("198.51.100.53"), Port: 53}
+
+	for _, test := range []struct {
+		info     *responder.QueryInfo
+		expected net.IP
+	}{
+		{nil, nil},
+		{&responder.QueryInfo{Resolver: trusted}, nil},
+		// Truncated to /24 even if the resolver sends more.
+		{&responder.QueryInfo{Resolver: trusted, ClientSubnet: &dns.ClientSubnet{SourcePrefix: 32, Address: net.ParseIP("203.0.113.7").To4()}}, net.ParseIP("203.0.113.0").To4()},
+		{&responder.QueryInfo{Resolver: trusted, ClientSubnet: &dns.ClientSubnet{SourcePrefix: 16, Address: net.ParseIP("203.0.0.0").To4()}}, net.ParseIP("203.0.0.0").To4()},
+		{&responder.QueryInfo{Resolver: &net.UDPAddr{IP: net.ParseIP("2001:db8::53"), Port: 53}, ClientSubnet: &dns.ClientSubnet{SourcePrefix: 56, Address: net.ParseIP("2001:db8:1:2:3::")}}, net.ParseIP("2001:db8:1::")},
+		// A source prefix of 0 means the client opted out.
+		{&responder.QueryInfo{Resolver: trusted, ClientSubnet: &dns.ClientSubnet{SourcePrefix: 0, Address: net.IPv4zero.To4()}}, nil},
+		{&responder.QueryInfo{Resolver: untrusted, ClientSubnet: &dns.ClientSubnet{SourcePrefix: 24, Address: net.ParseIP("203.0.113.0").To4()}}, nil},
+	} {
+		gotAddr = nil
+		_, err := s.processRequest(body, test.info)
+		require.Nil(t, err)
+		require.Equal(t, []byte(test.expected), gotAddr)
+	}
+}
+
+func TestDNSRegServerClientSubnetSelfReported(t *testing.T) {
+	var gotPayload *pb.C2SWrapper
+	var gotAddr []byte
+	s := newDNSRegServer()
+	s.processor = &fakeRegistrar{
+		fakeRegisterUnidirectionalFunc: func(c2s *pb.C2SWrapper, _ pb.RegistrationSource, clientAddr []byte) error {
+			gotPayload = c2s
+			gotAddr = clientAddr
+			return nil
+		},
+	}
+	require.Nil(t, s.SetTrustedECSResolvers([]string{"192.0.2.0/24"}))
+
+	selfReported := net.ParseIP("198.51.100.7").To4()
+	c2s, _ := generateC2SWrapperPayload()
+	c2s.RegistrationAddress = selfReported
+	body, err := proto.Marshal(c2s)
+	require.Nil(t, err)
+
+	info := &responder.QueryInfo{
+		Resolver:     &net.UDPAddr{IP: net.ParseIP("192.0.2.53"), Port: 53},
+		ClientSubnet: &dns.ClientSubnet{SourcePrefix: 24, Address: net.ParseIP("203.0.113.0").To4()},
+	}
+	_, err = s.processRequest(body, info)
+	require.Nil(t, err)
+
+	// The self-reported address is kept, so the ECS network must not be passed to override it.
+	require.Nil(t, gotAddr)
+	require.Equal(t, []byte(selfReported), gotPayload.GetRegistrationAddress())
+}
+
+func TestDNSRegServerClientSubnetQuery(t *testing.T) {
+	domain := "r.example.com"
+	privkey, err := encryption.GeneratePrivkey()
+	require.Nil(t, err)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	dnsResponder, err := responder.NewDnsResponderFromConn(domain, conn, privkey)
+	require.Nil(t, err)
+
+	gotAddr := make(chan []byte, 1)
+	s := newDNSRegServer()
+	s.dnsResponder = dnsResponder
+	s.processor = &fakeRegistrar{
+		fakeRegisterUnidirectionalFunc: func(_ *pb.C2SWrapper, _ pb.RegistrationSource, clientAddr []byte) error {
+			gotAddr <- clientAddr
+			return nil
+		},
+	}
+	require.Nil(t, s.SetTrustedECSResolvers([]string{"127.0.0.0/8"}))
+	go func() {
+		_ = s.ListenAndServe()
+	}()
+	defer s.Close()
+
+	req, err := requester.NewRequester(&requester.Config{
+		TransportMethod: requester.UDP,
+		Target:          dnsResponder.LocalAddr().String(),
+		BaseDomain:      domain,
+		Pubkey:          encryption.PubkeyFromPrivkey(privkey),
+		ClientSubnet:    &dns.ClientSubnet{SourcePrefix: 24, Address: net.ParseIP("203.0.113.0").To4()},
+	})
+	require.Nil(t, err)
+	defer req.Close()
+
+	_, body := generateC2SWrapperPayload()
+	done := make(chan error, 1)
+	go func() {
+		_, err := req.RequestAndRecv(body)
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		require.Nil(t, err)
+		require.Equal(t, []byte(net.ParseIP("203.0.113.0").To4()), <-gotAddr)
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for response")
+	}
+}
+
+func TestDNSRegServerDeclined(t *testing.T) {
+	domain := "r.example.com"
+	privkey, err := encryption.GeneratePrivkey()
+	require.Nil(t, err)
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	dnsResponder, err := responder.NewDnsResponderFromConn(domain, conn, privkey)
+	require.Nil(t, err)
+
+	s := newDNSRegServer()
+	s.dnsResponder = dnsResponder
+	s.processor = &fakeRegistrar{}
+	require.Nil(t, s.SetLimits(Limits{Window: time.Minute, PerResolver: 1, Refuse: true}))
+	go func() {
+		_ = s.ListenAndServe()
+	}()
+	defer s.Close()
+
+	for i, expectOK := range []bool{true, false} {
+		req, err := requester.NewRequester(&requester.Config{
+			TransportMethod: requester.UDP,
+			Target:          dnsResponder.LocalAddr().String(),
+			BaseDomain:      domain,
+			Pubkey:          encryption.PubkeyFromPrivkey(privkey),
+		})
+		require.Nil(t, err)
+
+		_, body := generateC2SWrapperPayload()
+		done := make(chan error, 1)
+		go func() {
+			_, err := req.RequestAndRecv(body)
+			done <- err
+		}()
+
+		select {
+		case err := <-done:
+			if expectOK {
+				require.Nil(t, err, "request %d", i)
+			} else {
+				require.NotNil(t, err, "request %d", i)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timed out waiting for response %d", i)
+		}
+		req.Close()
+	}
+}
@@ -0,0 +1,107 @@
+package dnsregserver
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/responder"
+)
+
+// Limits bounds how many requests the DNS registration server accepts. Each limit counts over
+// fixed windows of length Window, and a limit of zero is not enforced.
+type Limits struct {
+	// Window is the length of the windows the limits are counted over.
+	Window time.Duration
+
+	// PerResolver is the maximum number of requests accepted from a single resolver address in
+	// each window. Requests over the limit are declined before they are decrypted.
+	PerResolver int
+
+	// PerClient is the maximum number of registrations accepted for a single shared secret in
+	// each window.
+	PerClient int
+
+	// PerWindow is the maximum number of registrations accepted from all clients in each window.
+	PerWindow int
+
+	// Refuse makes declined requests be answered with a REFUSED RCODE. Otherwise they are
+	// answered with an empty answer.
+	Refuse bool
+}
+
+// windowCounter counts events by key over fixed windows of time.
+type windowCounter struct {
+	mu     sync.Mutex
+	window time.Duration
+	start  time.Time
+	counts map[string]int
+	total  int
+	now    func() time.Time
+}
+
+func newWindowCounter(window time.Duration) *windowCounter {
+	return &windowCounter{
+		window: window,
+		counts: map[string]int{},
+		now:    time.Now,
+	}
+}
+
+// allow counts an event for key unless that would exceed perKey events for key or total events
+// in the current window, and reports which limit, if any, was hit. Limits of zero are not
+// enforced.
+func (c *windowCounter) allow(key string, perKey, total int) (keyOK, totalOK bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if now.Sub(c.start) >= c.window {
+		c.start = now
+		c.counts = map[string]int{}
+		c.total = 0
+	}
+
+	keyOK = perKey <= 0 || c.counts[key] < perKey
+	totalOK = total <= 0 || c.total < total
+	if keyOK && totalOK {
+		c.counts[key]++
+		c.total++
+	}
+	return keyOK, totalOK
+}
+
+// limiter enforces Limits on the DNS registration server.
+type limiter struct {
+	limits    Limits
+	resolvers *windowCounter
+	clients   *windowCounter
+}
+
+func newLimiter(limits Limits) *limiter {
+	return &limiter{
+		limits:    limits,
+		resolvers: newWindowCounter(limits.Window),
+		clients:   newWindowCounter(limits.Window),
+	}
+}
+
+// declined returns the error that declines a request according to the policy.
+func (l *limiter) declined() error {
+	if l.limits.Refuse {
+		return responder.ErrRefused
+	}
+	return responder.ErrNoAnswer
+}
+
+// resolverKey returns the IP address of the resolver addr without its port.
+func resolverKey(addr net.Addr) string {
+	if addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return addr.String()
+	}
+	return host
+}
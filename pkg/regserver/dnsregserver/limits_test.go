@@ -0,0 +1,104 @@
+package dnsregserver
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/registrars/dns-registrar/responder"
+	pb "github.com/refraction-networking/conjure/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestWindowCounter(t *testing.T) {
+	now := time.Unix(1000, 0)
+	c := newWindowCounter(time.Minute)
+	c.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		keyOK, totalOK := c.allow("a", 2, 3)
+		require.True(t, keyOK && totalOK)
+	}
+	keyOK, totalOK := c.allow("a", 2, 3)
+	require.False(t, keyOK)
+	require.True(t, totalOK)
+
+	keyOK, totalOK = c.allow("b", 2, 3)
+	require.True(t, keyOK && totalOK)
+	keyOK, totalOK = c.allow("c", 2, 3)
+	require.True(t, keyOK)
+	require.False(t, totalOK)
+
+	// Zero limits are not enforced.
+	keyOK, totalOK = c.allow("a", 0, 0)
+	require.True(t, keyOK && totalOK)
+
+	// Counts start over in the next window.
+	now = now.Add(time.Minute)
+	keyOK, totalOK = c.allow("a", 1, 1)
+	require.True(t, keyOK && totalOK)
+}
+
+func TestDNSRegServerSetLimits(t *testing.T) {
+	s := newDNSRegServer()
+	require.Nil(t, s.SetLimits(Limits{}))
+	require.Nil(t, s.limiter)
+	require.NotNil(t, s.SetLimits(Limits{PerClient: 1}))
+	require.NotNil(t, s.SetLimits(Limits{Window: time.Minute, PerClient: -1}))
+	require.Nil(t, s.SetLimits(Limits{Window: time.Minute, PerClient: 1}))
+	require.NotNil(t, s.limiter)
+}
+
+func TestDNSRegServerClientLimits(t *testing.T) {
+	registrations := 0
+	s := newDNSRegServer()
+	s.processor = &fakeRegistrar{
+		fakeRegisterUnidirectionalFunc: func(*pb.C2SWrapper, pb.RegistrationSource, []byte) error {
+			registrations++
+			return nil
+		},
+	}
+
+	for _, refuse := range []bool{false, true} {
+		registrations = 0
+		require.Nil(t, s.SetLimits(Limits{Window: time.Minute, PerClient: 2, PerWindow: 3, Refuse: refuse}))
+		declined := responder.ErrNoAnswer
+		if refuse {
+			declined = responder.ErrRefused
+		}
+
+		c2s, body := generateC2SWrapperPayload()
+		for i := 0; i < 2; i++ {
+			_, err := s.processRequest(body, nil)
+			require.Nil(t, err)
+		}
+		_, err := s.processRequest(body, nil)
+		require.ErrorIs(t, err, declined)
+
+		// Another client may register until the window is full.
+		c2s.SharedSecret = append([]byte{}, secret...)
+		c2s.SharedSecret[0] ^= 0xff
+		other, _ := proto.Marshal(c2s)
+		_, err = s.processRequest(other, nil)
+		require.Nil(t, err)
+		c2s.SharedSecret[0] ^= 0x0f
+		third, _ := proto.Marshal(c2s)
+		_, err = s.processRequest(third, nil)
+		require.ErrorIs(t, err, declined)
+
+		require.Equal(t, 3, registrations)
+	}
+}
+
+func TestDNSRegServerResolverLimits(t *testing.T) {
+	s := newDNSRegServer()
+	require.Nil(t, s.SetLimits(Limits{Window: time.Minute, PerResolver: 1, Refuse: true}))
+
+	resolver := &net.UDPAddr{IP: net.ParseIP("192.0.2.53"), Port: 1111}
+	require.Nil(t, s.admit(&responder.QueryInfo{Resolver: resolver}))
+	// The port does not matter.
+	resolver.Port = 2222
+	require.ErrorIs(t, s.admit(&responder.QueryInfo{Resolver: resolver}), responder.ErrRefused)
+	require.Nil(t, s.admit(&responder.QueryInfo{Resolver: &net.UDPAddr{IP: net.ParseIP("192.0.2.54"), Port: 1111}}))
+}
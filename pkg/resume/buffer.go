@@ -0,0 +1,70 @@
+package resume
+
+// Buffer holds the last bytes written to a stream, up to a fixed size, so that they can be sent
+// again to a peer that missed them. It grows as bytes are written until it reaches its size, then
+// overwrites the oldest bytes in place. A Buffer is not safe for concurrent use.
+type Buffer struct {
+	size  int
+	buf   []byte
+	start int    // index in buf of the oldest byte once buf is full, zero until then
+	end   uint64 // stream position following the last byte written
+}
+
+// NewBuffer returns a Buffer keeping the last size bytes of a stream whose first byte written is
+// at position pos.
+func NewBuffer(size int, pos uint64) *Buffer {
+	return &Buffer{size: size, end: pos}
+}
+
+// Write appends p to the stream, dropping the oldest bytes held beyond the size of the buffer.
+// It always returns len(p) and a nil error.
+func (b *Buffer) Write(p []byte) (int, error) {
+	n := len(p)
+	b.end += uint64(n)
+	if b.size <= 0 {
+		return n, nil
+	}
+
+	if len(p) >= b.size {
+		b.buf = append(b.buf[:0], p[len(p)-b.size:]...)
+		b.start = 0
+		return n, nil
+	}
+	if len(b.buf) < b.size {
+		fill := b.size - len(b.buf)
+		if fill > len(p) {
+			fill = len(p)
+		}
+		b.buf = append(b.buf, p[:fill]...)
+		p = p[fill:]
+	}
+	for len(p) > 0 {
+		c := copy(b.buf[b.start:], p)
+		p = p[c:]
+		b.start = (b.start + c) % b.size
+	}
+	return n, nil
+}
+
+// Pos returns the stream position following the last byte written.
+func (b *Buffer) Pos() uint64 {
+	return b.end
+}
+
+// Since returns a copy of the bytes of the stream from position pos to the last byte written, or
+// ErrOffset if pos is past the last byte written or the bytes from pos are no longer held.
+func (b *Buffer) Since(pos uint64) ([]byte, error) {
+	if pos > b.end || b.end-pos > uint64(len(b.buf)) {
+		return nil, ErrOffset
+	}
+
+	n := int(b.end - pos)
+	out := make([]byte, 0, n)
+	// The bytes from pos are the last n of the buffer in stream order.
+	first := (b.start + len(b.buf) - n) % max(len(b.buf), 1)
+	if first+n <= len(b.buf) {
+		return append(out, b.buf[first:first+n]...), nil
+	}
+	out = append(out, b.buf[first:]...)
+	return append(out, b.buf[:n-len(out)]...), nil
+}
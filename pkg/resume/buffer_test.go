@@ -0,0 +1,48 @@
+package resume
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuffer(t *testing.T) {
+	b := NewBuffer(8, 100)
+	got, err := b.Since(100)
+	require.Nil(t, err)
+	require.Len(t, got, 0)
+	_, err = b.Since(101)
+	require.ErrorIs(t, err, ErrOffset)
+
+	_, _ = b.Write([]byte("abcde"))
+	got, err = b.Since(101)
+	require.Nil(t, err)
+	require.Equal(t, "bcde", string(got))
+
+	// Wraps around once full, dropping the oldest bytes.
+	_, _ = b.Write([]byte("fghij"))
+	require.Equal(t, uint64(110), b.Pos())
+	got, err = b.Since(102)
+	require.Nil(t, err)
+	require.Equal(t, "cdefghij", string(got))
+	got, err = b.Since(107)
+	require.Nil(t, err)
+	require.Equal(t, "hij", string(got))
+	_, err = b.Since(101)
+	require.ErrorIs(t, err, ErrOffset)
+
+	_, _ = b.Write([]byte("klm"))
+	got, err = b.Since(105)
+	require.Nil(t, err)
+	require.Equal(t, "fghijklm", string(got))
+
+	// Writes larger than the buffer keep their end.
+	_, _ = b.Write([]byte("0123456789"))
+	got, err = b.Since(115)
+	require.Nil(t, err)
+	require.Equal(t, "23456789", string(got))
+	_, _ = b.Write([]byte("x"))
+	got, err = b.Since(116)
+	require.Nil(t, err)
+	require.Equal(t, "3456789x", string(got))
+}
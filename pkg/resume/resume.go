@@ -0,0 +1,289 @@
+// Package resume implements resumable sessions, which let a client carry one covert stream over
+// a series of phantom connections, picking it up where it left off when a phantom connection
+// drops.
+//
+// A client opts into resumable sessions by setting upload_sync in its registration, to the
+// position of its first upload byte, normally zero. Every phantom connection of the session
+// starts with a handshake: the client sends the number of downstream bytes it received so far, as
+// 8 bytes big endian, and the station answers with the number of upstream bytes it received so far
+// in the same form. The station then sends again the downstream data the client is missing, and
+// the client the upstream data the station is missing. Each side only keeps a bounded number of
+// the last bytes it sent, and a session whose peer is missing more can not be resumed.
+package resume
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	// HandshakeLength is the length of the handshake sent by each side of a phantom connection.
+	HandshakeLength = 8
+
+	// HandshakeTimeout bounds the handshake of a phantom connection.
+	HandshakeTimeout = 10 * time.Second
+
+	// DefaultBufferSize is the number of sent bytes kept by default to be sent again.
+	DefaultBufferSize = 1024 * 1024
+)
+
+var (
+	// ErrOffset is returned when a peer asks to resume from data that is no longer held, or was
+	// never sent.
+	ErrOffset = errors.New("resume: offset not held")
+
+	// ErrClosed is returned when using a closed Conn.
+	ErrClosed = errors.New("resume: connection closed")
+)
+
+// Conn is the client side of a resumable session. It is a net.Conn carrying the covert stream of
+// the session over phantom connections obtained from a dial function, replacing the phantom
+// connection whenever reading from or writing to it fails.
+type Conn struct {
+	dial func() (net.Conn, error)
+
+	// wmu serializes writes to the phantom connection, including the data sent again after a
+	// handshake, and is held while replacing the phantom connection. rmu is held while reading
+	// from the phantom connection and accounting for what was read, so that the handshake of a
+	// replacement sees every byte received. Locks are taken in the order wmu, rmu, mu.
+	wmu sync.Mutex
+	rmu sync.Mutex
+
+	mu            sync.Mutex
+	conn          net.Conn
+	gen           uint64 // incremented every time conn is replaced
+	up            *Buffer
+	downRecv      uint64
+	closed        bool
+	err           error // set once the session can not be resumed
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+// Dial opens a resumable session over the phantom connections returned by dial. uploadSync is
+// the upload_sync of the registration, and bufSize the number of upstream bytes kept to be sent
+// again, DefaultBufferSize if zero.
+func Dial(dial func() (net.Conn, error), uploadSync uint64, bufSize int) (*Conn, error) {
+	if bufSize <= 0 {
+		bufSize = DefaultBufferSize
+	}
+	c := &Conn{dial: dial, up: NewBuffer(bufSize, uploadSync)}
+
+	conn, err := dial()
+	if err != nil {
+		return nil, err
+	}
+	if err := c.handshake(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	c.conn = conn
+	return c, nil
+}
+
+// handshake resumes the session over conn, sending again the upstream data the station is
+// missing. The caller must hold wmu and rmu.
+func (c *Conn) handshake(conn net.Conn) error {
+	if err := conn.SetDeadline(time.Now().Add(HandshakeTimeout)); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	downRecv := c.downRecv
+	c.mu.Unlock()
+	if _, err := conn.Write(binary.BigEndian.AppendUint64(nil, downRecv)); err != nil {
+		return err
+	}
+
+	var hdr [HandshakeLength]byte
+	if _, err := io.ReadFull(conn, hdr[:]); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	missing, err := c.up.Since(binary.BigEndian.Uint64(hdr[:]))
+	readDeadline, writeDeadline := c.readDeadline, c.writeDeadline
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if _, err := conn.Write(missing); err != nil {
+		return err
+	}
+
+	if err := conn.SetReadDeadline(readDeadline); err != nil {
+		return err
+	}
+	return conn.SetWriteDeadline(writeDeadline)
+}
+
+// reconnect replaces the phantom connection of generation gen, if it was not replaced already.
+// If that fails the session can not be resumed anymore. The caller must hold wmu.
+func (c *Conn) reconnect(gen uint64) error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return ErrClosed
+	}
+	if c.err != nil {
+		c.mu.Unlock()
+		return c.err
+	}
+	if c.gen != gen {
+		c.mu.Unlock()
+		return nil
+	}
+	old := c.conn
+	c.mu.Unlock()
+
+	// Closing the old connection ends a read in progress, which must be accounted for before
+	// the handshake.
+	old.Close()
+	c.rmu.Lock()
+	defer c.rmu.Unlock()
+
+	conn, err := c.dial()
+	if err == nil {
+		if err = c.handshake(conn); err != nil {
+			conn.Close()
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		c.err = err
+		return err
+	}
+	if c.closed {
+		conn.Close()
+		return ErrClosed
+	}
+	c.conn = conn
+	c.gen++
+	return nil
+}
+
+// current returns the phantom connection and its generation.
+func (c *Conn) current() (net.Conn, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn, c.gen
+}
+
+// Read reads downstream data of the session. If the phantom connection fails, the session is
+// resumed over a new one, and the error is only returned if that fails.
+func (c *Conn) Read(p []byte) (int, error) {
+	for {
+		c.rmu.Lock()
+		conn, gen := c.current()
+		n, err := conn.Read(p)
+		c.mu.Lock()
+		c.downRecv += uint64(n)
+		closed := c.closed
+		c.mu.Unlock()
+		c.rmu.Unlock()
+
+		if n > 0 {
+			return n, nil
+		}
+		if closed {
+			return 0, ErrClosed
+		}
+		if err == nil {
+			continue
+		}
+		if ne, ok := err.(net.Error); ok && ne.Timeout() {
+			return 0, err
+		}
+
+		c.wmu.Lock()
+		rerr := c.reconnect(gen)
+		c.wmu.Unlock()
+		if rerr != nil {
+			return 0, err
+		}
+	}
+}
+
+// Write writes upstream data of the session. If the phantom connection fails, the session is
+// resumed over a new one, which sends p again, and the error is only returned if that fails.
+func (c *Conn) Write(p []byte) (int, error) {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return 0, ErrClosed
+	}
+	_, _ = c.up.Write(p)
+	conn, gen := c.conn, c.gen
+	c.mu.Unlock()
+
+	_, err := conn.Write(p)
+	if err == nil {
+		return len(p), nil
+	}
+	if ne, ok := err.(net.Error); ok && ne.Timeout() {
+		return 0, err
+	}
+	if rerr := c.reconnect(gen); rerr != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close closes the session and its phantom connection. The station keeps the covert connection
+// until its resume timeout expires.
+func (c *Conn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return ErrClosed
+	}
+	c.closed = true
+	return c.conn.Close()
+}
+
+// LocalAddr returns the local address of the current phantom connection.
+func (c *Conn) LocalAddr() net.Addr {
+	conn, _ := c.current()
+	return conn.LocalAddr()
+}
+
+// RemoteAddr returns the remote address of the current phantom connection.
+func (c *Conn) RemoteAddr() net.Addr {
+	conn, _ := c.current()
+	return conn.RemoteAddr()
+}
+
+// SetDeadline sets the read and write deadlines of the session, which carry over to the phantom
+// connections that replace the current one.
+func (c *Conn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline, c.writeDeadline = t, t
+	return c.conn.SetDeadline(t)
+}
+
+// SetReadDeadline sets the read deadline of the session, which carries over to the phantom
+// connections that replace the current one.
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.readDeadline = t
+	return c.conn.SetReadDeadline(t)
+}
+
+// SetWriteDeadline sets the write deadline of the session, which carries over to the phantom
+// connections that replace the current one.
+func (c *Conn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeDeadline = t
+	return c.conn.SetWriteDeadline(t)
+}
@@ -0,0 +1,203 @@
+package lib
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// errQuotaExceeded is reported when a tunnel is closed because its registration used up its
+// byte quota.
+var errQuotaExceeded = errors.New("quota")
+
+// tokenBucket limits a flow of bytes to rate bytes per second with bursts of up to burst bytes.
+// Bytes are always taken from the bucket, running it into debt if needed, and the caller waits
+// until the debt is repaid.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func newTokenBucket(rate, burst int64) *tokenBucket {
+	if burst <= 0 {
+		burst = rate
+	}
+	return &tokenBucket{
+		rate:   float64(rate),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// take removes n bytes from the bucket and returns how long the caller must wait before sending
+// them.
+func (b *tokenBucket) take(n int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+
+	b.tokens -= float64(n)
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// setLimits changes the rate and burst of the bucket, keeping the bytes already taken.
+func (b *tokenBucket) setLimits(rate, burst int64) {
+	if burst <= 0 {
+		burst = rate
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rate == float64(rate) && b.burst == float64(burst) {
+		return
+	}
+
+	// Refill at the old rate up to now so that the new rate only applies from here on.
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		b.last = now
+	}
+	b.rate = float64(rate)
+	b.burst = float64(burst)
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+}
+
+// groupBuckets holds the buckets shared by all registrations from the same client ASN or
+// country code. They are dropped whenever the proxy config is set. Registrations whose ASN or
+// country code is unknown are not limited by that group, as they would all share one bucket.
+var groupBuckets = struct {
+	sync.Mutex
+	asn map[uint]*tokenBucket
+	cc  map[string]*tokenBucket
+}{
+	asn: map[uint]*tokenBucket{},
+	cc:  map[string]*tokenBucket{},
+}
+
+func resetGroupBuckets() {
+	groupBuckets.Lock()
+	defer groupBuckets.Unlock()
+	groupBuckets.asn = map[uint]*tokenBucket{}
+	groupBuckets.cc = map[string]*tokenBucket{}
+}
+
+func asnBucket(asn uint, conf *ProxyConfig) *tokenBucket {
+	groupBuckets.Lock()
+	defer groupBuckets.Unlock()
+	b, ok := groupBuckets.asn[asn]
+	if !ok {
+		b = newTokenBucket(conf.BandwidthPerASN, conf.BandwidthBurst)
+		groupBuckets.asn[asn] = b
+	}
+	return b
+}
+
+// regBucket returns the bucket shared by all tunnels of reg, updating its limits if the proxy
+// config changed since it was created.
+func regBucket(reg *DecoyRegistration, conf *ProxyConfig) *tokenBucket {
+	reg.bandwidthMu.Lock()
+	defer reg.bandwidthMu.Unlock()
+	if reg.bandwidth == nil {
+		reg.bandwidth = newTokenBucket(conf.BandwidthPerReg, conf.BandwidthBurst)
+	} else {
+		reg.bandwidth.setLimits(conf.BandwidthPerReg, conf.BandwidthBurst)
+	}
+	return reg.bandwidth
+}
+
+func ccBucket(cc string, conf *ProxyConfig) *tokenBucket {
+	groupBuckets.Lock()
+	defer groupBuckets.Unlock()
+	b, ok := groupBuckets.cc[cc]
+	if !ok {
+		b = newTokenBucket(conf.BandwidthPerCC, conf.BandwidthBurst)
+		groupBuckets.cc[cc] = b
+	}
+	return b
+}
+
+// tunnelThrottle applies the bandwidth limits and byte quota of a registration to one of its
+// tunnels.
+type tunnelThrottle struct {
+	reg     *DecoyRegistration
+	quota   int64
+	buckets []*tokenBucket
+}
+
+// newTunnelThrottle returns the throttle for a new tunnel of reg, or nil if no limits apply.
+func newTunnelThrottle(reg *DecoyRegistration) *tunnelThrottle {
+	conf := getProxyConfig()
+	t := &tunnelThrottle{reg: reg, quota: conf.ByteQuotaPerReg}
+
+	if conf.BandwidthPerReg > 0 {
+		t.buckets = append(t.buckets, regBucket(reg, conf))
+	}
+	if conf.BandwidthPerASN > 0 && reg.regASN != 0 {
+		t.buckets = append(t.buckets, asnBucket(reg.regASN, conf))
+	}
+	if conf.BandwidthPerCC > 0 && reg.regCC != "" {
+		t.buckets = append(t.buckets, ccBucket(reg.regCC, conf))
+	}
+
+	if t.quota <= 0 && len(t.buckets) == 0 {
+		return nil
+	}
+	return t
+}
+
+// exhausted reports whether the registration already used up its byte quota, in which case
+// there is no point dialing the covert destination for a new tunnel.
+func (t *tunnelThrottle) exhausted() bool {
+	return t != nil && t.quota > 0 && atomic.LoadInt64(&t.reg.bytesProxied) >= t.quota
+}
+
+// allow charges n bytes to the registration quota and returns how many of them may be sent,
+// along with errQuotaExceeded once the quota is used up.
+func (t *tunnelThrottle) allow(n int) (int, error) {
+	if t.quota <= 0 {
+		return n, nil
+	}
+	total := atomic.AddInt64(&t.reg.bytesProxied, int64(n))
+	if total <= t.quota {
+		return n, nil
+	}
+	allowed := int64(n) - (total - t.quota)
+	if allowed < 0 {
+		allowed = 0
+	}
+	return int(allowed), errQuotaExceeded
+}
+
+// wait blocks until n bytes may be sent under every bandwidth limit and returns how long it
+// waited.
+func (t *tunnelThrottle) wait(n int) time.Duration {
+	var delay time.Duration
+	for _, b := range t.buckets {
+		if d := b.take(n); d > delay {
+			delay = d
+		}
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+	return delay
+}
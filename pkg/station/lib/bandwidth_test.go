@@ -0,0 +1,190 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/refraction-networking/conjure/pkg/station/log"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := newTokenBucket(1000, 500)
+	b.now = func() time.Time { return now }
+
+	// The burst is available immediately.
+	require.Equal(t, time.Duration(0), b.take(500))
+
+	// Going into debt costs the time to repay it.
+	require.Equal(t, 100*time.Millisecond, b.take(100))
+
+	now = now.Add(100 * time.Millisecond)
+	require.Equal(t, time.Duration(0), b.take(0))
+
+	// Idle time refills the bucket up to the burst only.
+	now = now.Add(10 * time.Second)
+	require.Equal(t, time.Duration(0), b.take(500))
+	require.Equal(t, time.Second, b.take(1000))
+
+	// A zero burst defaults to one second of traffic.
+	require.Equal(t, float64(1000), newTokenBucket(1000, 0).burst)
+
+	// Changing the limits keeps the debt and repays it at the new rate.
+	b.setLimits(2000, 0)
+	require.Equal(t, 500*time.Millisecond, b.take(0))
+	now = now.Add(time.Second)
+	require.Equal(t, time.Duration(0), b.take(1000))
+}
+
+func TestTunnelThrottleQuota(t *testing.T) {
+	defer SetProxyConfig(nil)
+
+	SetProxyConfig(nil)
+	require.Nil(t, newTunnelThrottle(&DecoyRegistration{}))
+
+	SetProxyConfig(&ProxyConfig{ByteQuotaPerReg: 100})
+	reg := &DecoyRegistration{}
+	t1 := newTunnelThrottle(reg)
+	t2 := newTunnelThrottle(reg)
+
+	// The quota is shared by all tunnels of the registration.
+	n, err := t1.allow(60)
+	require.Nil(t, err)
+	require.Equal(t, 60, n)
+	n, err = t2.allow(60)
+	require.ErrorIs(t, err, errQuotaExceeded)
+	require.Equal(t, 40, n)
+	n, err = t1.allow(10)
+	require.ErrorIs(t, err, errQuotaExceeded)
+	require.Equal(t, 0, n)
+	require.True(t, newTunnelThrottle(reg).exhausted())
+	require.False(t, newTunnelThrottle(&DecoyRegistration{}).exhausted())
+}
+
+func TestTunnelThrottleBuckets(t *testing.T) {
+	defer SetProxyConfig(nil)
+	SetProxyConfig(&ProxyConfig{BandwidthPerReg: 1000, BandwidthPerASN: 2000, BandwidthPerCC: 3000})
+
+	regA := &DecoyRegistration{regASN: 1, regCC: "AA"}
+	regB := &DecoyRegistration{regASN: 1, regCC: "BB"}
+
+	tA1 := newTunnelThrottle(regA)
+	tA2 := newTunnelThrottle(regA)
+	tB := newTunnelThrottle(regB)
+	require.Len(t, tA1.buckets, 3)
+
+	// Tunnels of one registration share its bucket, registrations share ASN and CC buckets.
+	require.Same(t, tA1.buckets[0], tA2.buckets[0])
+	require.NotSame(t, tA1.buckets[0], tB.buckets[0])
+	require.Same(t, tA1.buckets[1], tB.buckets[1])
+	require.NotSame(t, tA1.buckets[2], tB.buckets[2])
+
+	// Setting the config again drops the group buckets, and updates the registration buckets.
+	SetProxyConfig(&ProxyConfig{BandwidthPerReg: 4000, BandwidthPerASN: 2000})
+	tB2 := newTunnelThrottle(regB)
+	require.Same(t, tB.buckets[0], tB2.buckets[0])
+	require.Equal(t, float64(4000), tB2.buckets[0].rate)
+	require.NotSame(t, tB.buckets[1], tB2.buckets[1])
+}
+
+func TestTunnelThrottleUnknownGroup(t *testing.T) {
+	defer SetProxyConfig(nil)
+	SetProxyConfig(&ProxyConfig{BandwidthPerASN: 2000, BandwidthPerCC: 3000})
+
+	// Registrations with an unknown ASN or country code do not share a group bucket.
+	require.Nil(t, newTunnelThrottle(&DecoyRegistration{}))
+
+	tASN := newTunnelThrottle(&DecoyRegistration{regASN: 1})
+	require.Len(t, tASN.buckets, 1)
+	require.Equal(t, float64(2000), tASN.buckets[0].rate)
+
+	tCC := newTunnelThrottle(&DecoyRegistration{regCC: "AA"})
+	require.Len(t, tCC.buckets, 1)
+	require.Equal(t, float64(3000), tCC.buckets[0].rate)
+	require.Len(t, groupBuckets.asn, 1)
+	require.Len(t, groupBuckets.cc, 1)
+}
+
+func TestProxyQuotaExhausted(t *testing.T) {
+	defer SetProxyConfig(nil)
+	SetProxyConfig(&ProxyConfig{ByteQuotaPerReg: 100})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+	accepted := make(chan struct{}, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		c.Close()
+		accepted <- struct{}{}
+	}()
+
+	reg := testProxyReg(ln.Addr().String())
+	reg.bytesProxied = 100
+	client, station := net.Pipe()
+	defer client.Close()
+
+	var logBuf bytes.Buffer
+	Proxy(reg, station, log.New(&logBuf, "", 0))
+
+	// The covert destination is not dialed for a registration that used up its quota.
+	select {
+	case <-accepted:
+		t.Fatal("covert destination dialed")
+	case <-time.After(50 * time.Millisecond):
+	}
+	var stats tunnelStats
+	require.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(logBuf.String(), "proxy closed ")), &stats))
+	require.True(t, stats.QuotaExceeded)
+	require.Equal(t, errConnRefused.Error(), stats.CovertDialErr)
+}
+
+func TestProxyThrottledAndQuota(t *testing.T) {
+	defer SetProxyConfig(nil)
+	SetProxyConfig(&ProxyConfig{BandwidthPerReg: 64 * 1024, BandwidthBurst: 16 * 1024, ByteQuotaPerReg: 48 * 1024})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+	received := make(chan int64, 1)
+	go func() {
+		c, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer c.Close()
+		n, _ := io.Copy(io.Discard, c)
+		received <- n
+	}()
+
+	client, station := net.Pipe()
+	go func() {
+		defer client.Close()
+		_, _ = client.Write(make([]byte, 64*1024))
+	}()
+
+	var logBuf bytes.Buffer
+	start := time.Now()
+	Proxy(testProxyReg(ln.Addr().String()), station, log.New(&logBuf, "", 0))
+	require.Greater(t, time.Since(start), 250*time.Millisecond)
+	require.Equal(t, int64(48*1024), <-received)
+
+	line := logBuf.String()
+	require.True(t, strings.HasPrefix(line, "proxy closed "))
+	var stats tunnelStats
+	require.Nil(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "proxy closed ")), &stats))
+	require.True(t, stats.QuotaExceeded)
+	require.Equal(t, int64(48*1024), stats.BytesUp)
+	require.Greater(t, stats.ThrottledBytesUp, int64(0))
+	require.Greater(t, stats.ThrottledMs, int64(0))
+}
@@ -11,6 +11,7 @@ import (
 type Config struct {
 	*ZMQConfig
 	*RegConfig
+	*ProxyConfig
 
 	// Log verbosity level
 	LogLevel string `toml:"log_level"`
@@ -37,6 +38,12 @@ func ParseConfig() (*Config, error) {
 
 	c.ParseBlocklists()
 
+	if c.ProxyConfig != nil {
+		if err := c.ProxyConfig.parse(); err != nil {
+			return nil, fmt.Errorf("failed to load config (%s): %w", envPath, err)
+		}
+	}
+
 	return &c, nil
 }
 
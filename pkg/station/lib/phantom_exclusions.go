@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+)
+
+// PhantomExclusions is a set of phantom addresses known to be live hosts, fed from liveness
+// results or an offline scan, that phantom selection should avoid. It is safe for concurrent use.
+type PhantomExclusions struct {
+	mu    sync.RWMutex
+	addrs map[string]struct{}
+}
+
+// NewPhantomExclusions returns an empty exclusion set.
+func NewPhantomExclusions() *PhantomExclusions {
+	return &PhantomExclusions{addrs: make(map[string]struct{})}
+}
+
+// PhantomExclusionsFromFile loads an exclusion set from a file with one address per line. The
+// file may also be a liveness cache file saved by a station (see liveness cache_path), with lines
+// of the form `<address> <live|nonlive> <time tested>`, in which case only the phantoms found live
+// are loaded. Blank lines and lines starting with # are ignored.
+func PhantomExclusionsFromFile(path string) (*PhantomExclusions, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	e := NewPhantomExclusions()
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 1:
+		case len(fields) == 3 && fields[1] == "live":
+		case len(fields) == 3 && fields[1] == "nonlive":
+			continue
+		default:
+			return nil, fmt.Errorf("%s:%d: bad line %q", path, n, line)
+		}
+		addr := net.ParseIP(fields[0])
+		if addr == nil {
+			return nil, fmt.Errorf("%s:%d: bad address %q", path, n, fields[0])
+		}
+		e.Add(addr)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Add adds addr to the set.
+func (e *PhantomExclusions) Add(addr net.IP) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.addrs[addr.String()] = struct{}{}
+}
+
+// Remove removes addr from the set, for example once it is found not to be live anymore.
+func (e *PhantomExclusions) Remove(addr net.IP) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.addrs, addr.String())
+}
+
+// Contains reports whether addr is in the set. It can be passed to
+// PhantomIPSelector.SelectExcluding.
+func (e *PhantomExclusions) Contains(addr net.IP) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	_, ok := e.addrs[addr.String()]
+	return ok
+}
+
+// Len returns the number of addresses in the set.
+func (e *PhantomExclusions) Len() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.addrs)
+}
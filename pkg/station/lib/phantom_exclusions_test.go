@@ -0,0 +1,45 @@
+package lib
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhantomExclusionsFromFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "live_phantoms")
+	contents := "# scan 2024-02-01\n192.122.190.7\n\n  2001:48a8:687f:1::7  \n"
+	require.Nil(t, os.WriteFile(path, []byte(contents), 0644))
+
+	e, err := PhantomExclusionsFromFile(path)
+	require.Nil(t, err)
+	require.Equal(t, 2, e.Len())
+	require.True(t, e.Contains(net.ParseIP("192.122.190.7")))
+	require.True(t, e.Contains(net.ParseIP("::ffff:192.122.190.7")))
+	require.True(t, e.Contains(net.ParseIP("2001:48a8:687f:1::7")))
+	require.False(t, e.Contains(net.ParseIP("192.122.190.8")))
+
+	e.Remove(net.ParseIP("192.122.190.7"))
+	require.False(t, e.Contains(net.ParseIP("192.122.190.7")))
+
+	require.Nil(t, os.WriteFile(path, []byte("192.122.190.7\nnot-an-address\n"), 0644))
+	_, err = PhantomExclusionsFromFile(path)
+	require.ErrorContains(t, err, ":2: bad address")
+
+	// The liveness cache file of a station feeds the live phantoms it found.
+	contents = "# conjure liveness cache: <address> <live|nonlive> <time tested>\n" +
+		"192.122.190.7 live 2024-03-01T12:00:00Z\n" +
+		"192.122.190.8 nonlive 2024-03-01T12:05:00Z\n"
+	require.Nil(t, os.WriteFile(path, []byte(contents), 0644))
+	e, err = PhantomExclusionsFromFile(path)
+	require.Nil(t, err)
+	require.Equal(t, 1, e.Len())
+	require.True(t, e.Contains(net.ParseIP("192.122.190.7")))
+
+	require.Nil(t, os.WriteFile(path, []byte("192.122.190.7 maybe 2024-03-01T12:00:00Z\n"), 0644))
+	_, err = PhantomExclusionsFromFile(path)
+	require.ErrorContains(t, err, ":1: bad line")
+}
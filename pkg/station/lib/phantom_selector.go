@@ -19,6 +19,10 @@ import (
 const (
 	phantomSelectionMinGeneration uint = 1
 	phantomHkdfMinVersion         uint = 2
+
+	// phantomReselectLimit bounds the number of times a phantom address is re-derived to avoid
+	// known live hosts.
+	phantomReselectLimit uint = 8
 )
 
 var (
@@ -28,6 +32,10 @@ var (
 	// ErrMissingAddrs indicates that no subnets were provided with addresses to select from. This
 	// is only valid for phantomHkdfMinVersion and newer.
 	ErrMissingAddrs = errors.New("no valid addresses specified to select")
+	// ErrGenerationNotActive indicates that the generation is scheduled to activate later.
+	ErrGenerationNotActive = errors.New("generation not yet active")
+	// ErrGenerationExpired indicates that the generation has been retired at its expiry time.
+	ErrGenerationExpired = errors.New("generation expired")
 )
 
 // getSubnetsVarint - return EITHER all subnet strings as one composite array if
@@ -208,14 +216,94 @@ func NewPhantomIPSelector() (*PhantomIPSelector, error) {
 	return GetPhantomSubnetSelector()
 }
 
-// Select - select an ip address from the list of subnets associated with the specified generation
+// Select - select an ip address from the list of subnets associated with the specified generation.
+// Generations outside of their scheduled window return ErrGenerationNotActive or
+// ErrGenerationExpired.
 func (p *PhantomIPSelector) Select(seed []byte, generation uint, clientLibVer uint, v6Support bool) (net.IP, error) {
 
+	genSubnets, err := p.selectSubnets(seed, generation, clientLibVer, v6Support)
+	if err != nil {
+		return nil, err
+	}
+
+	// handle legacy clientLibVersions for selecting phantoms.
+	if clientLibVer < phantomSelectionMinGeneration {
+		// Version 0
+		return selectPhantomImplV0(seed, genSubnets)
+	} else if clientLibVer < phantomHkdfMinVersion {
+		// Version 1
+		return selectPhantomImplVarint(seed, genSubnets)
+	}
+
+	// Version 2+
+	return selectPhantomImplHkdf(seed, genSubnets)
+}
+
+// SelectExcluding selects a phantom like Select, but while the address is excluded it is
+// re-derived from the same subnets with an incremented counter in the hkdf info, up to
+// phantomReselectLimit times. Only client library versions that select phantoms with hkdf are
+// re-derived. If every candidate is excluded the address chosen by Select is returned.
+func (p *PhantomIPSelector) SelectExcluding(seed []byte, generation uint, clientLibVer uint, v6Support bool, excluded func(net.IP) bool) (net.IP, error) {
+	if clientLibVer < phantomHkdfMinVersion || excluded == nil {
+		return p.Select(seed, generation, clientLibVer, v6Support)
+	}
+
+	genSubnets, err := p.selectSubnets(seed, generation, clientLibVer, v6Support)
+	if err != nil {
+		return nil, err
+	}
+
+	var first net.IP
+	for attempt := uint(0); attempt <= phantomReselectLimit; attempt++ {
+		addr, err := selectPhantomImplHkdfAttempt(seed, genSubnets, attempt)
+		if err != nil {
+			return nil, err
+		}
+		if !excluded(addr) {
+			return addr, nil
+		}
+		if first == nil {
+			first = addr
+		}
+	}
+	return first, nil
+}
+
+// IsReselection reports whether addr is a phantom that SelectExcluding may return for the seed in
+// place of the one chosen by Select.
+func (p *PhantomIPSelector) IsReselection(seed []byte, generation uint, clientLibVer uint, v6Support bool, addr net.IP) bool {
+	if clientLibVer < phantomHkdfMinVersion {
+		return false
+	}
+
+	genSubnets, err := p.selectSubnets(seed, generation, clientLibVer, v6Support)
+	if err != nil {
+		return false
+	}
+
+	for attempt := uint(1); attempt <= phantomReselectLimit; attempt++ {
+		candidate, err := selectPhantomImplHkdfAttempt(seed, genSubnets, attempt)
+		if err != nil {
+			return false
+		}
+		if candidate.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectSubnets returns the subnets of the generation that the phantom address is selected from.
+func (p *PhantomIPSelector) selectSubnets(seed []byte, generation uint, clientLibVer uint, v6Support bool) ([]*net.IPNet, error) {
 	genConfig := p.GetSubnetsByGeneration(generation)
 	if genConfig == nil {
 		return nil, fmt.Errorf("generation number not recognized")
 	}
 
+	if err := genConfig.ActiveAt(p.currentTime()); err != nil {
+		return nil, fmt.Errorf("generation %d: %w", generation, err)
+	}
+
 	var genSubnetStrings []string
 	if clientLibVer < phantomHkdfMinVersion {
 		// Version 0 or 1
@@ -237,17 +325,7 @@ func (p *PhantomIPSelector) Select(seed []byte, generation uint, clientLibVer ui
 		}
 	}
 
-	// handle legacy clientLibVersions for selecting phantoms.
-	if clientLibVer < phantomSelectionMinGeneration {
-		// Version 0
-		return selectPhantomImplV0(seed, genSubnets)
-	} else if clientLibVer < phantomHkdfMinVersion {
-		// Version 1
-		return selectPhantomImplVarint(seed, genSubnets)
-	}
-
-	// Version 2+
-	return selectPhantomImplHkdf(seed, genSubnets)
+	return genSubnets, nil
 }
 
 // selectPhantomImplVarint - select an ip address from the list of subnets
@@ -462,6 +540,12 @@ func SelectAddrFromSubnetOffset(net1 *net.IPNet, offset *big.Int) (net.IP, error
 // is then bound between the global min and max of that set. This ensures that
 // addresses are chosen based on the number of addresses in the subnet.
 func selectPhantomImplHkdf(seed []byte, subnets []*net.IPNet) (net.IP, error) {
+	return selectPhantomImplHkdfAttempt(seed, subnets, 0)
+}
+
+// selectPhantomImplHkdfAttempt selects an address like selectPhantomImplHkdf, with a different
+// address derived for every attempt after the first.
+func selectPhantomImplHkdfAttempt(seed []byte, subnets []*net.IPNet, attempt uint) (net.IP, error) {
 	type idNet struct {
 		min, max big.Int
 		net      net.IPNet
@@ -499,7 +583,11 @@ func selectPhantomImplHkdf(seed []byte, subnets []*net.IPNet) (net.IP, error) {
 
 	// Pick a value using the seed in the range of between 0 and the total
 	// number of addresses.
-	hkdfReader := hkdf.New(sha256.New, seed, nil, []byte("phantom-addr-id"))
+	info := []byte("phantom-addr-id")
+	if attempt > 0 {
+		info = []byte(fmt.Sprintf("phantom-addr-id-%d", attempt))
+	}
+	hkdfReader := hkdf.New(sha256.New, seed, nil, info)
 	id, err := rand.Int(hkdfReader, addressTotal)
 	if err != nil {
 		return nil, err
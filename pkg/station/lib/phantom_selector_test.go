@@ -9,6 +9,7 @@ import (
 	"net"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -358,3 +359,91 @@ func TestDuplicates(t *testing.T) {
 	}
 
 }
+
+func TestPhantomsGenerationSchedule(t *testing.T) {
+	path := writePhantomFile(t, `
+[Networks]
+    [Networks.1]
+        Generation = 1
+        Expires = 2024-03-01T00:00:00Z
+        [[Networks.1.WeightedSubnets]]
+            Weight = 1
+            Subnets = ["192.122.190.0/24", "2001:48a8:687f:1::/64"]
+    [Networks.2]
+        Generation = 2
+        Activates = 2024-02-01T00:00:00Z
+        [[Networks.2.WeightedSubnets]]
+            Weight = 1
+            Subnets = ["141.219.0.0/16", "2001:48a8:687f:2::/64"]
+`)
+	p, err := SubnetsFromTomlFile(path)
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), p.Networks[1].Expires.UTC())
+	require.True(t, p.Networks[1].Activates.IsZero())
+	require.Equal(t, time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), p.Networks[2].Activates.UTC())
+
+	seed, _ := hex.DecodeString("5a87133b68ea3468988a21659a12ed2ece07345c8c1a5b08459ffdea4218d12f")
+	selectAt := func(now string, gen uint) error {
+		ts, err := time.Parse(time.RFC3339, now)
+		require.Nil(t, err)
+		p.now = func() time.Time { return ts }
+		_, err = p.Select(seed, gen, phantomHkdfMinVersion, false)
+		return err
+	}
+
+	// Before generation 2 activates only generation 1 is accepted.
+	require.Nil(t, selectAt("2024-01-15T00:00:00Z", 1))
+	require.ErrorIs(t, selectAt("2024-01-15T00:00:00Z", 2), ErrGenerationNotActive)
+
+	// Both generations overlap while clients move to the new ClientConf.
+	require.Nil(t, selectAt("2024-02-01T00:00:00Z", 1))
+	require.Nil(t, selectAt("2024-02-01T00:00:00Z", 2))
+
+	// Generation 1 is retired at its expiry time.
+	require.ErrorIs(t, selectAt("2024-03-01T00:00:00Z", 1), ErrGenerationExpired)
+	require.Nil(t, selectAt("2024-03-01T00:00:00Z", 2))
+}
+
+func TestPhantomsSelectExcluding(t *testing.T) {
+	p := &PhantomIPSelector{Networks: map[uint]*SubnetConfig{
+		1: {WeightedSubnets: []ConjurePhantomSubnet{
+			{Weight: 1, Subnets: []string{"192.122.190.0/24", "2001:48a8:687f:1::/64"}},
+		}},
+	}}
+	seed, _ := hex.DecodeString("5a87133b68ea3468988a21659a12ed2ece07345c8c1a5b08459ffdea4218d12f")
+
+	selected, err := p.Select(seed, 1, phantomHkdfMinVersion, false)
+	require.Nil(t, err)
+
+	// Without exclusions the selection is unchanged.
+	addr, err := p.SelectExcluding(seed, 1, phantomHkdfMinVersion, false, func(net.IP) bool { return false })
+	require.Nil(t, err)
+	require.Equal(t, selected.String(), addr.String())
+	require.False(t, p.IsReselection(seed, 1, phantomHkdfMinVersion, false, selected))
+
+	// A live phantom is re-derived deterministically to an address the station accepts.
+	live := NewPhantomExclusions()
+	live.Add(selected)
+	reselected, err := p.SelectExcluding(seed, 1, phantomHkdfMinVersion, false, live.Contains)
+	require.Nil(t, err)
+	require.NotEqual(t, selected.String(), reselected.String())
+	require.True(t, net.ParseIP("192.122.190.0").Mask(net.CIDRMask(24, 32)).Equal(reselected.Mask(net.CIDRMask(24, 32))))
+	again, err := p.SelectExcluding(seed, 1, phantomHkdfMinVersion, false, live.Contains)
+	require.Nil(t, err)
+	require.Equal(t, reselected.String(), again.String())
+	require.True(t, p.IsReselection(seed, 1, phantomHkdfMinVersion, false, reselected))
+	require.False(t, p.IsReselection(seed, 1, phantomHkdfMinVersion, false, net.ParseIP("192.122.190.1")))
+
+	// If every candidate is live the original choice is kept.
+	addr, err = p.SelectExcluding(seed, 1, phantomHkdfMinVersion, false, func(net.IP) bool { return true })
+	require.Nil(t, err)
+	require.Equal(t, selected.String(), addr.String())
+
+	// Legacy selection is never re-derived.
+	legacy, err := p.Select(seed, 1, 1, false)
+	require.Nil(t, err)
+	live.Add(legacy)
+	addr, err = p.SelectExcluding(seed, 1, 1, false, live.Contains)
+	require.Nil(t, err)
+	require.Equal(t, legacy.String(), addr.String())
+}
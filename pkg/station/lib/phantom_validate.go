@@ -0,0 +1,293 @@
+package lib
+
+import (
+	"fmt"
+	"math/big"
+	"net"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	toml "github.com/pelletier/go-toml"
+
+	pb "github.com/refraction-networking/conjure/proto"
+)
+
+// PhantomReport is the result of validating a phantom subnet configuration.
+type PhantomReport struct {
+	// Errors are problems that make phantom selection fail, or make clients and the station
+	// select different phantoms.
+	Errors []string
+
+	// Warnings are problems that skew or limit phantom selection without breaking it.
+	Warnings []string
+
+	// Generations holds the selection probabilities of the subnets of every generation.
+	Generations map[uint][]SubnetProbability
+}
+
+// SubnetProbability is the probability that a client selects its phantom address from a subnet,
+// using the selection of client library versions 2 and newer.
+type SubnetProbability struct {
+	Subnet string
+	Weight uint32 // weight of the subnet group holding the subnet
+
+	// Probability for clients that support IPv6.
+	V6Client float64
+	// Probability for IPv4 only clients.
+	V4Client float64
+}
+
+// OK reports whether the configuration has no errors.
+func (r *PhantomReport) OK() bool {
+	return len(r.Errors) == 0
+}
+
+func (r *PhantomReport) errorf(gen uint, format string, a ...any) {
+	r.Errors = append(r.Errors, fmt.Sprintf("generation %d: ", gen)+fmt.Sprintf(format, a...))
+}
+
+func (r *PhantomReport) warnf(gen uint, format string, a ...any) {
+	r.Warnings = append(r.Warnings, fmt.Sprintf("generation %d: ", gen)+fmt.Sprintf(format, a...))
+}
+
+// ValidatePhantomFile validates the phantom subnets file at path. Subnets in the phantom
+// blocklist are reported, and if clientConf is not nil the subnets of its generation must match
+// the phantom subnets it gives clients. An error is returned if the file can not be loaded.
+func ValidatePhantomFile(path string, clientConf *pb.ClientConf, phantomBlocklist []string) (*PhantomReport, error) {
+	tree, err := toml.LoadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening configuration file: %v", err)
+	}
+
+	r := &PhantomReport{Generations: make(map[uint][]SubnetProbability)}
+	r.checkGenerationNumbers(tree)
+
+	p, err := SubnetsFromTomlFile(path)
+	if err != nil {
+		return nil, err
+	}
+	p.validate(r, clientConf, phantomBlocklist)
+	return r, nil
+}
+
+// Validate validates the phantom subnets of the selector, see ValidatePhantomFile.
+func (p *PhantomIPSelector) Validate(clientConf *pb.ClientConf, phantomBlocklist []string) *PhantomReport {
+	r := &PhantomReport{Generations: make(map[uint][]SubnetProbability)}
+	p.validate(r, clientConf, phantomBlocklist)
+	return r
+}
+
+func (p *PhantomIPSelector) validate(r *PhantomReport, clientConf *pb.ClientConf, phantomBlocklist []string) {
+	var blocklist []*net.IPNet
+	for _, s := range phantomBlocklist {
+		_, ipNet, err := net.ParseCIDR(s)
+		if err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("bad phantom blocklist subnet %q", s))
+			continue
+		}
+		blocklist = append(blocklist, ipNet)
+	}
+
+	gens := make([]uint, 0, len(p.Networks))
+	for gen := range p.Networks {
+		gens = append(gens, gen)
+	}
+	sort.Slice(gens, func(i, j int) bool { return gens[i] < gens[j] })
+	for _, gen := range gens {
+		r.checkSchedule(gen, p.Networks[gen])
+		r.checkGeneration(gen, p.Networks[gen], blocklist)
+	}
+
+	if clientConf != nil {
+		r.checkClientConf(p, clientConf)
+	}
+}
+
+// checkGenerationNumbers reports generation numbers that SubnetsFromTomlFile would silently
+// renumber or that disagree with the Generation field of their table.
+func (r *PhantomReport) checkGenerationNumbers(tree *toml.Tree) {
+	networks, ok := tree.Get("Networks").(*toml.Tree)
+	if !ok {
+		r.Errors = append(r.Errors, "no Networks table")
+		return
+	}
+
+	keys := networks.Keys()
+	sort.Strings(keys)
+	seen := make(map[int]string)
+	for _, key := range keys {
+		gen, err := strconv.Atoi(key)
+		if err != nil || gen < 0 {
+			r.Errors = append(r.Errors, fmt.Sprintf("generation %q: not a generation number", key))
+			continue
+		}
+		if other, ok := seen[gen]; ok {
+			r.errorf(uint(gen), "defined twice, as %q and %q", other, key)
+		}
+		seen[gen] = key
+
+		if table, ok := networks.GetPath([]string{key}).(*toml.Tree); ok {
+			if field, ok := table.Get("Generation").(int64); ok && field != int64(gen) {
+				r.errorf(uint(gen), "Generation field is %d", field)
+			}
+		}
+	}
+}
+
+func (r *PhantomReport) checkGeneration(gen uint, sc *SubnetConfig, blocklist []*net.IPNet) {
+	if sc == nil || len(sc.WeightedSubnets) == 0 {
+		r.errorf(gen, "no subnets")
+		return
+	}
+
+	type group struct {
+		weight uint32
+		nets   []*net.IPNet
+	}
+	groups := make([]group, len(sc.WeightedSubnets))
+	var all []*net.IPNet
+	var totWeight uint64
+	for i, ws := range sc.WeightedSubnets {
+		groups[i].weight = ws.Weight
+		// Groups without subnets are skipped by the selection of clients version 2 and newer,
+		// but break the selection of older clients.
+		if ws.Subnets != nil {
+			totWeight += uint64(ws.Weight)
+		}
+		if len(ws.Subnets) == 0 {
+			r.errorf(gen, "subnet group %d has no subnets", i)
+			continue
+		}
+		if ws.Weight == 0 {
+			r.warnf(gen, "subnet group %d has weight 0 and is never selected", i)
+		}
+
+		for _, s := range ws.Subnets {
+			_, ipNet, err := net.ParseCIDR(s)
+			if err != nil {
+				r.errorf(gen, "bad subnet %q", s)
+				continue
+			}
+			groups[i].nets = append(groups[i].nets, ipNet)
+			all = append(all, ipNet)
+		}
+	}
+	if totWeight == 0 {
+		r.errorf(gen, "total weight is 0")
+		return
+	}
+
+	v4, _ := V4Only(all)
+	if len(v4) == 0 {
+		r.errorf(gen, "no IPv4 subnets, IPv4 only clients can not select a phantom")
+	} else if len(v4) == len(all) {
+		r.warnf(gen, "no IPv6 subnets")
+	}
+
+	for i, a := range all {
+		for _, b := range all[i+1:] {
+			if subnetsOverlap(a, b) {
+				r.warnf(gen, "subnets %s and %s overlap", a, b)
+			}
+		}
+		for _, b := range blocklist {
+			if subnetContains(b, a) {
+				r.errorf(gen, "subnet %s is in blocklisted subnet %s", a, b)
+			} else if subnetsOverlap(a, b) {
+				r.warnf(gen, "subnet %s holds blocklisted subnet %s", a, b)
+			}
+		}
+	}
+
+	var probs []SubnetProbability
+	for i, g := range groups {
+		if len(g.nets) == 0 {
+			continue
+		}
+		groupProb := float64(g.weight) / float64(totWeight)
+
+		g4, _ := V4Only(g.nets)
+		if len(g4) == 0 && len(v4) > 0 && g.weight > 0 {
+			r.warnf(gen, "subnet group %d has no IPv4 subnets, IPv4 only clients fail to select a phantom with probability %.3f", i, groupProb)
+		}
+
+		total, total4 := new(big.Float), new(big.Float)
+		for _, n := range g.nets {
+			total.Add(total, subnetSize(n))
+			if n.IP.To4() != nil {
+				total4.Add(total4, subnetSize(n))
+			}
+		}
+		for _, n := range g.nets {
+			prob := SubnetProbability{Subnet: n.String(), Weight: g.weight}
+			share, _ := new(big.Float).Quo(subnetSize(n), total).Float64()
+			prob.V6Client = groupProb * share
+			if n.IP.To4() != nil {
+				share, _ = new(big.Float).Quo(subnetSize(n), total4).Float64()
+				prob.V4Client = groupProb * share
+			}
+			probs = append(probs, prob)
+		}
+	}
+	r.Generations[gen] = probs
+}
+
+// checkSchedule reports generations whose activation and expiry times leave no window in which
+// registrations are accepted.
+func (r *PhantomReport) checkSchedule(gen uint, sc *SubnetConfig) {
+	if sc == nil || sc.Activates.IsZero() || sc.Expires.IsZero() {
+		return
+	}
+	if !sc.Expires.After(sc.Activates) {
+		r.errorf(gen, "expires at %s, not after it activates at %s",
+			sc.Expires.Format(time.RFC3339), sc.Activates.Format(time.RFC3339))
+	}
+}
+
+// checkClientConf reports differences between the phantom subnets clients receive in clientConf
+// and the generation the station uses for them.
+func (r *PhantomReport) checkClientConf(p *PhantomIPSelector, clientConf *pb.ClientConf) {
+	gen := uint(clientConf.GetGeneration())
+	sc := p.GetSubnetsByGeneration(gen)
+	if sc == nil {
+		r.errorf(gen, "used by the ClientConf but not defined")
+		return
+	}
+	if err := sc.ActiveAt(p.currentTime()); err != nil {
+		r.errorf(gen, "used by the ClientConf but %v", err)
+	}
+
+	clientSubnets := clientConf.GetPhantomSubnetsList().GetWeightedSubnets()
+	if len(clientSubnets) != len(sc.WeightedSubnets) {
+		r.errorf(gen, "ClientConf has %d subnet groups, the station %d", len(clientSubnets), len(sc.WeightedSubnets))
+		return
+	}
+	for i, cs := range clientSubnets {
+		ws := sc.WeightedSubnets[i]
+		if cs.GetWeight() != ws.Weight || !slices.Equal(cs.GetSubnets(), ws.Subnets) {
+			r.errorf(gen, "subnet group %d is %d %v in the ClientConf but %d %v at the station",
+				i, cs.GetWeight(), cs.GetSubnets(), ws.Weight, ws.Subnets)
+		}
+	}
+}
+
+// subnetSize returns the number of addresses in n.
+func subnetSize(n *net.IPNet) *big.Float {
+	ones, bits := n.Mask.Size()
+	size := new(big.Int).Lsh(big.NewInt(1), uint(bits-ones))
+	return new(big.Float).SetInt(size)
+}
+
+// subnetContains reports whether subnet b is inside subnet a.
+func subnetContains(a, b *net.IPNet) bool {
+	aOnes, aBits := a.Mask.Size()
+	bOnes, bBits := b.Mask.Size()
+	return aBits == bBits && aOnes <= bOnes && a.Contains(b.IP)
+}
+
+// subnetsOverlap reports whether subnets a and b share addresses.
+func subnetsOverlap(a, b *net.IPNet) bool {
+	return subnetContains(a, b) || subnetContains(b, a)
+}
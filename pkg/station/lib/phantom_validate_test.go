@@ -0,0 +1,151 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	pb "github.com/refraction-networking/conjure/proto"
+)
+
+func writePhantomFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "phantom_subnets.toml")
+	require.Nil(t, os.WriteFile(path, []byte(contents), 0644))
+	return path
+}
+
+func requireIssue(t *testing.T, issues []string, substr string) {
+	for _, issue := range issues {
+		if strings.Contains(issue, substr) {
+			return
+		}
+	}
+	t.Fatalf("no issue containing %q in %q", substr, issues)
+}
+
+func TestValidatePhantomFile(t *testing.T) {
+	report, err := ValidatePhantomFile("./test/phantom_subnets.toml", nil, nil)
+	require.Nil(t, err)
+	require.True(t, report.OK(), report.Errors)
+
+	// Generation 957 picks the first group with probability 0.9, and IPv6 capable clients
+	// almost always pick the v6 subnet of the group.
+	probs := report.Generations[957]
+	require.Len(t, probs, 4)
+	require.Equal(t, "192.122.190.0/24", probs[0].Subnet)
+	require.InDelta(t, 0.9, probs[0].V4Client, 1e-9)
+	require.InDelta(t, 0.0, probs[0].V6Client, 1e-9)
+	require.InDelta(t, 0.9, probs[1].V6Client, 1e-9)
+	require.InDelta(t, 0.05, probs[2].V4Client, 1e-9)
+	require.InDelta(t, 0.05, probs[3].V6Client, 1e-9)
+
+	var total float64
+	for _, p := range probs {
+		total += p.V4Client
+	}
+	require.InDelta(t, 1.0, total, 1e-9)
+}
+
+func TestValidatePhantomFileProblems(t *testing.T) {
+	path := writePhantomFile(t, `
+[Networks]
+    [Networks.1]
+        Generation = 1
+        [[Networks.1.WeightedSubnets]]
+            Weight = 1
+            Subnets = ["2001:48a8:687f:1::/64"]
+    [Networks.01]
+        Generation = 1
+        [[Networks.01.WeightedSubnets]]
+            Weight = 0
+            Subnets = ["192.122.190.0/24"]
+    [Networks.3]
+        Generation = 4
+        [[Networks.3.WeightedSubnets]]
+            Weight = 3
+            Subnets = ["10.0.0.0/24", "10.0.0.0/25", "not-a-subnet"]
+        [[Networks.3.WeightedSubnets]]
+            Weight = 1
+            Subnets = ["2001:48a8:687f:1::/64"]
+`)
+	report, err := ValidatePhantomFile(path, nil, []string{"10.0.0.0/8"})
+	require.Nil(t, err)
+	require.False(t, report.OK())
+
+	requireIssue(t, report.Errors, `generation 1: defined twice, as "01" and "1"`)
+	requireIssue(t, report.Errors, "generation 3: Generation field is 4")
+	requireIssue(t, report.Errors, `generation 3: bad subnet "not-a-subnet"`)
+	requireIssue(t, report.Errors, "generation 3: subnet 10.0.0.0/24 is in blocklisted subnet 10.0.0.0/8")
+	requireIssue(t, report.Warnings, "generation 3: subnets 10.0.0.0/24 and 10.0.0.0/25 overlap")
+	requireIssue(t, report.Warnings, "generation 3: subnet group 1 has no IPv4 subnets, IPv4 only clients fail to select a phantom with probability 0.250")
+
+	// One of the duplicate generations is renumbered on load, but both are still checked.
+	requireIssue(t, report.Errors, "no IPv4 subnets, IPv4 only clients can not select a phantom")
+	requireIssue(t, report.Errors, "total weight is 0")
+
+	_, err = ValidatePhantomFile(filepath.Join(t.TempDir(), "missing.toml"), nil, nil)
+	require.NotNil(t, err)
+}
+
+func TestValidatePhantomClientConf(t *testing.T) {
+	clientConf := &pb.ClientConf{
+		Generation: proto.Uint32(957),
+		PhantomSubnetsList: &pb.PhantomSubnetsList{
+			WeightedSubnets: []*pb.PhantomSubnets{
+				{Weight: proto.Uint32(9), Subnets: []string{"192.122.190.0/24", "2001:48a8:687f:1::/64"}},
+				{Weight: proto.Uint32(1), Subnets: []string{"141.219.0.0/16", "35.8.0.0/16"}},
+			},
+		},
+	}
+
+	report, err := ValidatePhantomFile("./test/phantom_subnets.toml", clientConf, nil)
+	require.Nil(t, err)
+	require.True(t, report.OK(), report.Errors)
+
+	clientConf.PhantomSubnetsList.WeightedSubnets[1].Weight = proto.Uint32(2)
+	report, err = ValidatePhantomFile("./test/phantom_subnets.toml", clientConf, []string{"141.219.0.0/24"})
+	require.Nil(t, err)
+	requireIssue(t, report.Errors, "generation 957: subnet group 1 is 2 [141.219.0.0/16 35.8.0.0/16] in the ClientConf but 1 [141.219.0.0/16 35.8.0.0/16] at the station")
+	requireIssue(t, report.Warnings, "generation 957: subnet 141.219.0.0/16 holds blocklisted subnet 141.219.0.0/24")
+
+	clientConf.Generation = proto.Uint32(5)
+	report, err = ValidatePhantomFile("./test/phantom_subnets.toml", clientConf, nil)
+	require.Nil(t, err)
+	requireIssue(t, report.Errors, "generation 5: used by the ClientConf but not defined")
+}
+
+func TestValidatePhantomSchedule(t *testing.T) {
+	path := writePhantomFile(t, `
+[Networks]
+    [Networks.1]
+        Generation = 1
+        Expires = 2024-03-01T00:00:00Z
+        [[Networks.1.WeightedSubnets]]
+            Weight = 1
+            Subnets = ["192.122.190.0/24", "2001:48a8:687f:1::/64"]
+    [Networks.2]
+        Generation = 2
+        Activates = 2024-03-01T00:00:00Z
+        Expires = 2024-02-01T00:00:00Z
+        [[Networks.2.WeightedSubnets]]
+            Weight = 1
+            Subnets = ["141.219.0.0/16", "2001:48a8:687f:2::/64"]
+`)
+	clientConf := &pb.ClientConf{
+		Generation: proto.Uint32(1),
+		PhantomSubnetsList: &pb.PhantomSubnetsList{
+			WeightedSubnets: []*pb.PhantomSubnets{
+				{Weight: proto.Uint32(1), Subnets: []string{"192.122.190.0/24", "2001:48a8:687f:1::/64"}},
+			},
+		},
+	}
+
+	report, err := ValidatePhantomFile(path, clientConf, nil)
+	require.Nil(t, err)
+	requireIssue(t, report.Errors, "generation 1: used by the ClientConf but generation expired")
+	requireIssue(t, report.Errors, "generation 2: expires at 2024-02-01T00:00:00Z, not after it activates at 2024-03-01T00:00:00Z")
+}
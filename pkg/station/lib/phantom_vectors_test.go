@@ -0,0 +1,155 @@
+package lib
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// phantomVectorsPath is the golden vector corpus shared with the client selection in pkg/phantoms.
+// Any implementation of phantom selection (including the detector) must reproduce every vector.
+const phantomVectorsPath = "../../phantoms/testdata/phantom_vectors.json"
+
+// phantomVectorsVersion is bumped whenever the corpus layout changes. Changing the expected phantom
+// of an existing vector is a breaking change for deployed clients and must not happen silently.
+const phantomVectorsVersion = 1
+
+var updatePhantomVectors = flag.Bool("update-phantom-vectors", false, "regenerate the phantom selection golden vectors from the station implementation")
+
+type phantomVectorCorpus struct {
+	Version     int                               `json:"version"`
+	Generations map[string][]ConjurePhantomSubnet `json:"generations"`
+	Vectors     []phantomVector                   `json:"vectors"`
+}
+
+type phantomVector struct {
+	Seed       string `json:"seed"`
+	Generation uint   `json:"generation"`
+	LibVer     uint   `json:"libver"`
+	V6         bool   `json:"v6"`
+	Phantom    string `json:"phantom,omitempty"`
+	Err        string `json:"error,omitempty"`
+}
+
+// phantomVectorGenerations cover the shapes of subnet config that selection has to agree on:
+// uneven weights, tiny subnets, groups without IPv4 and groups of equal weight.
+var phantomVectorGenerations = map[uint][]ConjurePhantomSubnet{
+	1: {
+		{Weight: 9, Subnets: []string{"192.122.190.0/24", "2001:48a8:687f:1::/64"}},
+		{Weight: 1, Subnets: []string{"141.219.0.0/16", "35.8.0.0/16"}},
+	},
+	2: {
+		{Weight: 1, Subnets: []string{"192.122.190.0/28", "2001:48a8:687f:1::/96"}},
+	},
+	3: {
+		{Weight: 5, Subnets: []string{"192.0.2.1/32", "2001:db8::/128"}},
+		{Weight: 3, Subnets: []string{"198.51.100.0/31", "2001:db8::/127"}},
+		{Weight: 2, Subnets: []string{"203.0.113.0/30"}},
+	},
+	4: {
+		{Weight: 1, Subnets: []string{"2001:db8:1::/64"}},
+		{Weight: 4, Subnets: []string{"10.0.0.0/8", "2001:db8:2::/48"}},
+	},
+	5: {
+		{Weight: 2, Subnets: []string{"172.16.0.0/12"}},
+		{Weight: 2, Subnets: []string{"100.64.0.0/10", "2001:db8:3::/56"}},
+		{Weight: 1, Subnets: []string{"2001:db8:4::/32"}},
+	},
+}
+
+const phantomVectorSeedsPerCase = 6
+
+func phantomVectorSelector(generations map[string][]ConjurePhantomSubnet) (*PhantomIPSelector, error) {
+	p := &PhantomIPSelector{Networks: make(map[uint]*SubnetConfig)}
+	for gen, subnets := range generations {
+		g, err := strconv.ParseUint(gen, 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		p.Networks[uint(g)] = &SubnetConfig{WeightedSubnets: subnets}
+	}
+	return p, nil
+}
+
+func generatePhantomVectors() (*phantomVectorCorpus, error) {
+	corpus := &phantomVectorCorpus{
+		Version:     phantomVectorsVersion,
+		Generations: make(map[string][]ConjurePhantomSubnet),
+	}
+
+	gens := make([]uint, 0, len(phantomVectorGenerations))
+	for gen, subnets := range phantomVectorGenerations {
+		gens = append(gens, gen)
+		corpus.Generations[strconv.FormatUint(uint64(gen), 10)] = subnets
+	}
+	sort.Slice(gens, func(i, j int) bool { return gens[i] < gens[j] })
+
+	p, err := phantomVectorSelector(corpus.Generations)
+	if err != nil {
+		return nil, err
+	}
+	for _, gen := range gens {
+		for libVer := uint(0); libVer <= phantomHkdfMinVersion+1; libVer++ {
+			for _, v6 := range []bool{false, true} {
+				for i := 0; i < phantomVectorSeedsPerCase; i++ {
+					seed := sha256.Sum256([]byte(fmt.Sprintf("phantom-vector-%d-%d-%d", gen, libVer, i)))
+					v := phantomVector{Seed: hex.EncodeToString(seed[:]), Generation: gen, LibVer: libVer, V6: v6}
+
+					addr, err := p.Select(seed[:], gen, libVer, v6)
+					if err != nil {
+						v.Err = err.Error()
+					} else {
+						v.Phantom = addr.String()
+					}
+					corpus.Vectors = append(corpus.Vectors, v)
+				}
+			}
+		}
+	}
+	return corpus, nil
+}
+
+func TestPhantomGoldenVectors(t *testing.T) {
+	generated, err := generatePhantomVectors()
+	require.Nil(t, err)
+
+	if *updatePhantomVectors {
+		data, err := json.MarshalIndent(generated, "", "  ")
+		require.Nil(t, err)
+		require.Nil(t, os.WriteFile(phantomVectorsPath, append(data, '\n'), 0644))
+	}
+
+	data, err := os.ReadFile(phantomVectorsPath)
+	require.Nil(t, err)
+	var corpus phantomVectorCorpus
+	require.Nil(t, json.Unmarshal(data, &corpus))
+	require.Equal(t, phantomVectorsVersion, corpus.Version)
+
+	// The corpus generations must be the ones the vectors were generated from.
+	require.Equal(t, generated.Generations, corpus.Generations)
+
+	p, err := phantomVectorSelector(corpus.Generations)
+	require.Nil(t, err)
+
+	require.NotEmpty(t, corpus.Vectors)
+	for _, v := range corpus.Vectors {
+		seed, err := hex.DecodeString(v.Seed)
+		require.Nil(t, err)
+
+		addr, err := p.Select(seed, v.Generation, v.LibVer, v.V6)
+		if v.Err != "" {
+			require.EqualError(t, err, v.Err, "vector %+v", v)
+			continue
+		}
+		require.Nil(t, err, "vector %+v", v)
+		require.Equal(t, v.Phantom, addr.String(), "vector %+v", v)
+	}
+}
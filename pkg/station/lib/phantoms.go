@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	toml "github.com/pelletier/go-toml"
 )
@@ -17,11 +18,38 @@ type ConjurePhantomSubnet struct {
 // SubnetConfig - Configuration of subnets for Conjure to choose a Phantom out of.
 type SubnetConfig struct {
 	WeightedSubnets []ConjurePhantomSubnet
+
+	// Activates and Expires bound the time in which registrations using the generation are
+	// accepted. A zero time leaves that side of the window open.
+	Activates time.Time
+	Expires   time.Time
+}
+
+// ActiveAt returns ErrGenerationNotActive or ErrGenerationExpired if registrations using the
+// generation are not accepted at time t.
+func (sc *SubnetConfig) ActiveAt(t time.Time) error {
+	if !sc.Activates.IsZero() && t.Before(sc.Activates) {
+		return ErrGenerationNotActive
+	}
+	if !sc.Expires.IsZero() && !t.Before(sc.Expires) {
+		return ErrGenerationExpired
+	}
+	return nil
 }
 
 // PhantomIPSelector - Object for tracking current generation to SubnetConfig Mapping.
 type PhantomIPSelector struct {
 	Networks map[uint]*SubnetConfig
+
+	// now returns the current time for the generation schedule, time.Now if nil.
+	now func() time.Time
+}
+
+func (p *PhantomIPSelector) currentTime() time.Time {
+	if p.now != nil {
+		return p.now()
+	}
+	return time.Now()
 }
 
 // type shim because github.com/pelletier/go-toml doesn't allow for integer value keys to maps so
@@ -36,6 +64,25 @@ func GetPhantomSubnetSelector() (*PhantomIPSelector, error) {
 	return SubnetsFromTomlFile(os.Getenv("PHANTOM_SUBNET_LOCATION"))
 }
 
+// PhantomSubnetsLocation returns the path of the phantom subnets file, from the station config or
+// else the PHANTOM_SUBNET_LOCATION environment variable.
+func (c *RegConfig) PhantomSubnetsLocation() string {
+	if c == nil {
+		return PhantomSubnetsFile("")
+	}
+	return PhantomSubnetsFile(c.PhantomSubnetsPath)
+}
+
+// PhantomSubnetsFile returns path, or the PHANTOM_SUBNET_LOCATION environment variable if path is
+// empty. The station and the registrar both resolve their phantom_subnets_path with it so that
+// they select phantoms from the same file.
+func PhantomSubnetsFile(path string) string {
+	if path != "" {
+		return path
+	}
+	return os.Getenv("PHANTOM_SUBNET_LOCATION")
+}
+
 // SubnetsFromTomlFile takes a path and parses the toml config file
 func SubnetsFromTomlFile(path string) (*PhantomIPSelector, error) {
 
@@ -21,3 +21,14 @@ func TestPhantomsParse(t *testing.T) {
 	require.Equal(t, len(sc.WeightedSubnets[0].Subnets), 2)
 	require.Contains(t, sc.WeightedSubnets[0].Subnets, "192.122.190.0/24")
 }
+
+func TestPhantomSubnetsFile(t *testing.T) {
+	t.Setenv("PHANTOM_SUBNET_LOCATION", "./test/phantom_subnets.toml")
+	require.Equal(t, "./test/phantom_subnets.toml", PhantomSubnetsFile(""))
+	require.Equal(t, "./test/phantom_subnets_update.toml", PhantomSubnetsFile("./test/phantom_subnets_update.toml"))
+
+	// The station config and the registrar resolve the same path.
+	conf := &RegConfig{PhantomSubnetsPath: "./test/phantom_subnets_update.toml"}
+	require.Equal(t, PhantomSubnetsFile(conf.PhantomSubnetsPath), conf.PhantomSubnetsLocation())
+	require.Equal(t, "./test/phantom_subnets.toml", (*RegConfig)(nil).PhantomSubnetsLocation())
+}
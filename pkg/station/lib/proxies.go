@@ -3,7 +3,6 @@ package lib
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"math"
 	"net"
@@ -55,6 +54,8 @@ func generalizeErr(err error) error {
 		return errConnAborted
 	case errors.Is(err, syscall.EHOSTUNREACH):
 		return errUnreachable
+	case errors.Is(err, errUpstream):
+		return errUpstream
 	default:
 		if errN, ok := err.(net.Error); ok && errN.Timeout() {
 			return errConnTimeout
@@ -130,6 +131,18 @@ func halfPipe(src net.Conn, dst net.Conn,
 		return
 	}
 
+	// Raw TCP connections on both ends are spliced together in the kernel. The bandwidth limits
+	// need to see every read, so throttled tunnels always take the copy loop below.
+	if spliceEnabled && stats.throttle == nil {
+		srcTCP, prefix, srcOK := tcpConn(src)
+		// Data read ahead from dst belongs to the other direction.
+		dstTCP, _, dstOK := tcpConn(dst)
+		if srcOK && dstOK {
+			spliceHalfPipe(srcTCP, prefix, dstTCP, logger, tag, stats, isUpload)
+			return
+		}
+	}
+
 	// using io.CopyBuffer doesn't let us see
 	// bytes / second (until very end of connect, then only avg)
 	// But io.CopyBuffer is very performant:
@@ -155,6 +168,23 @@ func halfPipe(src net.Conn, dst net.Conn,
 	for {
 		nr, er := src.Read(buf)
 		if nr > 0 {
+			var eq error
+			if stats.throttle != nil {
+				nr, eq = stats.throttle.allow(nr)
+				if eq != nil {
+					stats.QuotaExceeded = true
+				}
+				if waited := stats.throttle.wait(nr); waited > 0 {
+					stats.throttled(int64(nr), waited, isUpload)
+					// The wait may have outlasted the stall timeout set on the last write.
+					err := dst.SetDeadline(time.Now().Add(proxyStallTimeout))
+					if err != nil {
+						logger.Errorln("error setting deadline for dst conn: ", tag)
+						return
+					}
+				}
+			}
+
 			nw, ew := dst.Write(buf[0:nr])
 
 			// Update stats:
@@ -180,6 +210,9 @@ func halfPipe(src net.Conn, dst net.Conn,
 				break
 			}
 
+			if eq != nil {
+				break
+			}
 		}
 		if er != nil {
 			if e := generalizeErr(er); e != nil {
@@ -214,6 +247,21 @@ func Proxy(reg *DecoyRegistration, clientConn net.Conn, logger *log.Logger) {
 	// New successful connection to station for this registration
 	atomic.AddInt64(&reg.tunnelCount, 1)
 
+	if reg.uploadSync != nil {
+		proxyResumable(reg, clientConn, logger)
+		return
+	}
+
+	if reg.Flags.GetMultiplex() {
+		proxyMultiplexed(reg, clientConn, logger)
+		return
+	}
+
+	proxyConn(reg, clientConn, logger, newTunnelStats(reg))
+}
+
+// newTunnelStats returns the stats for a new tunnel of reg.
+func newTunnelStats(reg *DecoyRegistration) *tunnelStats {
 	tunStats := &tunnelStats{
 		proxyStats: getProxyStats(),
 
@@ -234,8 +282,31 @@ func Proxy(reg *DecoyRegistration, clientConn net.Conn, logger *log.Logger) {
 	if paramStrs != nil {
 		tunStats.TransportOpts = paramStrs
 	}
+	return tunStats
+}
+
+// proxyConn dials the covert destination of reg and forwards traffic between it and clientConn.
+func proxyConn(reg *DecoyRegistration, clientConn net.Conn, logger *log.Logger, tunStats *tunnelStats) {
+	// A refused tunnel is reported and closed exactly as if the covert destination had refused
+	// the connection so that clients can not distinguish the station being at capacity.
+	if !acquireTunnel(reg, tunStats.proxyStats) {
+		tunStats.CovertDialErr = errConnRefused.Error()
+		tunStats.Print(logger)
+		return
+	}
+	defer releaseTunnel(reg, tunStats.proxyStats)
+	tunStats.RegTunnels = atomic.LoadInt64(&reg.activeTunnels)
 
-	covertConn, err := net.Dial("tcp", reg.Covert)
+	tunStats.throttle = newTunnelThrottle(reg)
+	if tunStats.throttle.exhausted() {
+		tunStats.QuotaExceeded = true
+		tunStats.CovertDialErr = errConnRefused.Error()
+		tunStats.Print(logger)
+		return
+	}
+
+	covertConn, upstream, err := dialCovert(reg)
+	tunStats.Upstream = upstream
 	if e := generalizeErr(err); e != nil {
 		tunStats.CovertDialErr = e.Error()
 	}
@@ -251,7 +322,8 @@ func Proxy(reg *DecoyRegistration, clientConn net.Conn, logger *log.Logger) {
 	defer covertConn.Close()
 
 	if reg.Flags.GetProxyHeader() {
-		err = writePROXYHeader(covertConn, clientConn.RemoteAddr().String())
+		version := getProxyConfig().proxyHeaderVersion(reg.Covert)
+		err = writePROXYHeader(covertConn, version, clientConn.RemoteAddr(), reg)
 		if err != nil {
 			logger.Errorf("failed to send PROXY header: %s", err)
 			return
@@ -271,26 +343,9 @@ func Proxy(reg *DecoyRegistration, clientConn net.Conn, logger *log.Logger) {
 	tunStats.Print(logger)
 }
 
-func writePROXYHeader(conn net.Conn, originalIPPort string) error {
-
-	if len(originalIPPort) == 0 {
-		return errors.New("can't write PROXY header: empty IP")
-	}
-	transportProtocol := "TCP4"
-	if !strings.Contains(originalIPPort, ".") {
-		transportProtocol = "TCP6"
-	}
-	host, port, err := net.SplitHostPort(originalIPPort)
-	if err != nil {
-		return err
-	}
-	proxyHeader := fmt.Sprintf("PROXY %s %s 127.0.0.1 %s 1234\r\n", transportProtocol, host, port)
-	_, err = conn.Write([]byte(proxyHeader))
-	return err
-}
-
 type tunnelStats struct {
 	proxyStats *ProxyStats
+	throttle   *tunnelThrottle
 
 	Duration  int64
 	BytesUp   int64
@@ -300,15 +355,27 @@ type tunnelStats struct {
 	CovertConnErr string
 	ClientConnErr string
 
+	ThrottledBytesUp   int64 `json:",omitempty"` // bytes delayed by the bandwidth limits
+	ThrottledBytesDown int64 `json:",omitempty"`
+	ThrottledMs        int64 `json:",omitempty"` // total time spent waiting on the bandwidth limits
+	QuotaExceeded      bool  `json:",omitempty"`
+
+	Resumed     bool   `json:",omitempty"` // the phantom conn reattached to an open resumable session
+	BytesResent int64  `json:",omitempty"` // downstream bytes resent to the client on attaching
+	ResumeErr   string `json:",omitempty"`
+
 	PhantomAddr    string
 	PhantomDstPort uint
 
 	TunnelCount   uint
+	RegTunnels    int64  `json:",omitempty"` // tunnels of the registration open when this one opened, itself included
+	Stream        uint32 `json:",omitempty"` // ID of the stream in a multiplexed session
 	V6            bool
 	ASN           uint   `json:",omitempty"`
 	CC            string `json:",omitempty"`
 	Transport     string `json:",omitempty"`
 	Registrar     string `json:",omitempty"`
+	Upstream      string `json:",omitempty"` // scheme of the upstream proxy the covert conn goes through
 	LibVer        uint
 	Gen           uint
 	TransportOpts []string `json:",omitempty"`
@@ -337,6 +404,15 @@ func (ts *tunnelStats) duration(duration int64, isUpload bool) {
 	}
 }
 
+func (ts *tunnelStats) throttled(n int64, waited time.Duration, isUpload bool) {
+	if isUpload {
+		atomic.AddInt64(&ts.ThrottledBytesUp, n)
+	} else {
+		atomic.AddInt64(&ts.ThrottledBytesDown, n)
+	}
+	atomic.AddInt64(&ts.ThrottledMs, waited.Milliseconds())
+}
+
 func (ts *tunnelStats) addBytes(n int64, isUpload bool) {
 	if isUpload {
 		atomic.AddInt64(&ts.BytesUp, n)
@@ -351,6 +427,11 @@ type ProxyStats struct {
 	time.Time // epoch start time
 
 	sessionsProxying int64 // Number of open Proxy connections (count - not reset)
+	activeTunnels    int64 // Number of tunnels holding a slot against the tunnel limits (count - not reset)
+	muxSessions      int64 // Number of open multiplexed sessions (count - not reset)
+
+	tunnelsRefused    int64 // Number of tunnels refused by the global tunnel limit during epoch
+	tunnelsRefusedReg int64 // Number of tunnels refused by the per registration tunnel limit during epoch
 
 	newBytesUp   int64 // Number of bytes transferred during epoch
 	newBytesDown int64 // Number of bytes transferred during epoch
@@ -374,7 +455,7 @@ func (s *ProxyStats) printStats(logger *log.Logger) {
 	var epochDur float64 = math.Max(float64(time.Since(s.Time).Milliseconds()), 1)
 
 	// fmtStr := "proxy-stats: %d (%f/s) up %d (%f/s) down %d completed %d 0up %d 0down  %f avg-non-0-up, %f avg-non-0-down"
-	fmtStr := "proxy-stats:%d %d %f %d %f %d %d %d %f %f"
+	fmtStr := "proxy-stats:%d %d %f %d %f %d %d %d %f %f %d %d %d %d"
 
 	completedSessions := atomic.LoadInt64(&s.completedSessions)
 	zbtu := atomic.LoadInt64(&s.zeroByteTunnelsUp)
@@ -391,6 +472,10 @@ func (s *ProxyStats) printStats(logger *log.Logger) {
 		zbtd,
 		float64(atomic.LoadInt64(&s.completeBytesUp))/math.Max(float64(completedSessions-zbtu), 1),
 		float64(atomic.LoadInt64(&s.completeBytesDown))/math.Max(float64(completedSessions-zbtd), 1),
+		atomic.LoadInt64(&s.activeTunnels),
+		atomic.LoadInt64(&s.tunnelsRefused),
+		atomic.LoadInt64(&s.tunnelsRefusedReg),
+		atomic.LoadInt64(&s.muxSessions),
 	)
 }
 
@@ -407,6 +492,8 @@ func (s *ProxyStats) reset() {
 	atomic.StoreInt64(&s.zeroByteTunnelsUp, 0)
 	atomic.StoreInt64(&s.zeroByteTunnelsDown, 0)
 	atomic.StoreInt64(&s.completedSessions, 0)
+	atomic.StoreInt64(&s.tunnelsRefused, 0)
+	atomic.StoreInt64(&s.tunnelsRefusedReg, 0)
 }
 
 func (s *ProxyStats) addSession() {
@@ -417,6 +504,14 @@ func (s *ProxyStats) removeSession() {
 	atomic.AddInt64(&s.sessionsProxying, -1)
 }
 
+func (s *ProxyStats) addMuxSession() {
+	atomic.AddInt64(&s.muxSessions, 1)
+}
+
+func (s *ProxyStats) removeMuxSession() {
+	atomic.AddInt64(&s.muxSessions, -1)
+}
+
 func (s *ProxyStats) addCompleted(nb int64, isUpload bool) {
 	if isUpload {
 		atomic.AddInt64(&s.completeBytesUp, nb)
@@ -0,0 +1,145 @@
+package lib
+
+import (
+	"fmt"
+	"regexp"
+	"sync/atomic"
+	"time"
+)
+
+// ProxyConfig bounds the tunnels that the station proxies to covert destinations.
+type ProxyConfig struct {
+	// Maximum number of tunnels proxied concurrently across all registrations. Zero means no
+	// limit.
+	MaxTunnels int64 `toml:"max_tunnels"`
+
+	// Maximum number of tunnels proxied concurrently for a single registration. Zero means no
+	// limit.
+	MaxTunnelsPerReg int64 `toml:"max_tunnels_per_registration"`
+
+	// Maximum number of streams open at once in a multiplexed session. Streams clients open
+	// beyond it are closed right away. Zero means no limit.
+	MaxStreamsPerSession int `toml:"max_streams_per_session"`
+
+	// Rate in bytes per second that traffic, counted in both directions, is limited to for all
+	// tunnels of a single registration. Zero means no limit.
+	BandwidthPerReg int64 `toml:"bandwidth_per_registration"`
+
+	// Rates in bytes per second that traffic is limited to for all tunnels of registrations
+	// from a single client ASN or country code. Zero means no limit. Registrations whose ASN or
+	// country code is unknown are not limited by these.
+	BandwidthPerASN int64 `toml:"bandwidth_per_asn"`
+	BandwidthPerCC  int64 `toml:"bandwidth_per_cc"`
+
+	// Number of bytes that may be sent at once above the bandwidth limits. Zero means one
+	// second worth of traffic at each rate.
+	BandwidthBurst int64 `toml:"bandwidth_burst"`
+
+	// Maximum number of bytes proxied, in both directions, over the lifetime of a single
+	// registration. Tunnels are closed once it is reached. Zero means no limit.
+	ByteQuotaPerReg int64 `toml:"byte_quota_per_registration"`
+
+	// Patterns matched against the covert address (host:port) of registrations that request a
+	// PROXY protocol header. Covert destinations matching one of them are sent a version 2
+	// (binary) header, all others a version 1 (text) header.
+	ProxyHeaderV2Destinations []string `toml:"proxy_header_v2_destinations"`
+	proxyHeaderV2Destinations []*regexp.Regexp
+
+	// Number of downstream bytes kept for each resumable session, to resend to clients that
+	// reconnect. Zero means 1 MiB.
+	ResumeBufferSize int64 `toml:"resume_buffer_size"`
+
+	// Time that a resumable session waits for its client to reconnect before closing the covert
+	// connection, as a Go duration string. Empty means two minutes.
+	ResumeTimeout string `toml:"resume_timeout"`
+	resumeTimeout time.Duration
+
+	// Upstream proxies that covert connections are routed through, selected by covert
+	// destination. Covert destinations matching none of them are dialed directly.
+	Upstreams []UpstreamConfig `toml:"upstream"`
+}
+
+// parse compiles the covert destination patterns, resume timeout and upstreams of the config.
+func (c *ProxyConfig) parse() error {
+	c.proxyHeaderV2Destinations = []*regexp.Regexp{}
+	for _, pattern := range c.ProxyHeaderV2Destinations {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("bad proxy header destination %q: %w", pattern, err)
+		}
+		c.proxyHeaderV2Destinations = append(c.proxyHeaderV2Destinations, r)
+	}
+
+	if c.ResumeTimeout != "" {
+		d, err := time.ParseDuration(c.ResumeTimeout)
+		if err != nil {
+			return fmt.Errorf("bad resume timeout %q: %w", c.ResumeTimeout, err)
+		}
+		c.resumeTimeout = d
+	}
+
+	for i := range c.Upstreams {
+		if err := c.Upstreams[i].parse(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// proxyHeaderVersion returns the PROXY protocol version used for the covert address covert.
+func (c *ProxyConfig) proxyHeaderVersion(covert string) int {
+	for _, r := range c.proxyHeaderV2Destinations {
+		if r.MatchString(covert) {
+			return 2
+		}
+	}
+	return 1
+}
+
+var proxyConfig atomic.Pointer[ProxyConfig]
+
+// SetProxyConfig sets the limits applied to tunnels opened from now on. Tunnels that are already
+// open are not affected. A nil config removes all limits.
+func SetProxyConfig(c *ProxyConfig) {
+	if c == nil {
+		c = &ProxyConfig{}
+	}
+	proxyConfig.Store(c)
+	resetGroupBuckets()
+}
+
+func getProxyConfig() *ProxyConfig {
+	if c := proxyConfig.Load(); c != nil {
+		return c
+	}
+	return &ProxyConfig{}
+}
+
+// acquireTunnel reserves a tunnel for reg against the global and per registration limits. If it
+// returns true the caller must call releaseTunnel once the tunnel is closed.
+func acquireTunnel(reg *DecoyRegistration, stats *ProxyStats) bool {
+	conf := getProxyConfig()
+
+	n := atomic.AddInt64(&stats.activeTunnels, 1)
+	if conf.MaxTunnels > 0 && n > conf.MaxTunnels {
+		atomic.AddInt64(&stats.activeTunnels, -1)
+		atomic.AddInt64(&stats.tunnelsRefused, 1)
+		return false
+	}
+
+	n = atomic.AddInt64(&reg.activeTunnels, 1)
+	if conf.MaxTunnelsPerReg > 0 && n > conf.MaxTunnelsPerReg {
+		atomic.AddInt64(&reg.activeTunnels, -1)
+		atomic.AddInt64(&stats.activeTunnels, -1)
+		atomic.AddInt64(&stats.tunnelsRefusedReg, 1)
+		return false
+	}
+
+	return true
+}
+
+// releaseTunnel returns a tunnel reserved by acquireTunnel.
+func releaseTunnel(reg *DecoyRegistration, stats *ProxyStats) {
+	atomic.AddInt64(&reg.activeTunnels, -1)
+	atomic.AddInt64(&stats.activeTunnels, -1)
+}
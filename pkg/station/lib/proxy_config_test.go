@@ -0,0 +1,116 @@
+package lib
+
+import (
+	"bytes"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/refraction-networking/conjure/pkg/station/log"
+	pb "github.com/refraction-networking/conjure/proto"
+)
+
+func TestAcquireTunnelLimits(t *testing.T) {
+	defer SetProxyConfig(nil)
+	SetProxyConfig(&ProxyConfig{MaxTunnels: 3, MaxTunnelsPerReg: 2})
+
+	stats := &ProxyStats{}
+	regA := &DecoyRegistration{}
+	regB := &DecoyRegistration{}
+
+	require.True(t, acquireTunnel(regA, stats))
+	require.True(t, acquireTunnel(regA, stats))
+	require.False(t, acquireTunnel(regA, stats))
+	require.Equal(t, int64(2), atomic.LoadInt64(&regA.activeTunnels))
+	require.Equal(t, int64(1), stats.tunnelsRefusedReg)
+
+	require.True(t, acquireTunnel(regB, stats))
+	require.False(t, acquireTunnel(regB, stats))
+	require.Equal(t, int64(3), atomic.LoadInt64(&stats.activeTunnels))
+	require.Equal(t, int64(1), stats.tunnelsRefused)
+	require.Equal(t, int64(1), atomic.LoadInt64(&regB.activeTunnels))
+
+	releaseTunnel(regA, stats)
+	require.True(t, acquireTunnel(regB, stats))
+	require.Equal(t, int64(1), atomic.LoadInt64(&regA.activeTunnels))
+	require.Equal(t, int64(2), atomic.LoadInt64(&regB.activeTunnels))
+
+	releaseTunnel(regA, stats)
+	releaseTunnel(regB, stats)
+	releaseTunnel(regB, stats)
+	require.Equal(t, int64(0), atomic.LoadInt64(&stats.activeTunnels))
+
+	// Removing the limits lets tunnels through again.
+	SetProxyConfig(nil)
+	for i := 0; i < 5; i++ {
+		require.True(t, acquireTunnel(regA, stats))
+	}
+}
+
+func testProxyReg(covert string) *DecoyRegistration {
+	var transport Transport = &mockTransport{}
+	return &DecoyRegistration{
+		PhantomIp:          net.ParseIP("192.0.2.1"),
+		Covert:             covert,
+		Flags:              &pb.RegistrationFlags{},
+		TransportPtr:       &transport,
+		RegistrationSource: pb.RegistrationSource_API.Enum(),
+	}
+}
+
+func TestProxyRefusedAtCapacity(t *testing.T) {
+	defer SetProxyConfig(nil)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer c.Close()
+		}
+	}()
+
+	// The log line of a tunnel whose covert destination refuses the connection.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	closedAddr := closed.Addr().String()
+	closed.Close()
+
+	var dialLog bytes.Buffer
+	client, _ := net.Pipe()
+	Proxy(testProxyReg(closedAddr), client, log.New(&dialLog, "", 0))
+	require.Contains(t, dialLog.String(), `"CovertDialErr":"refused"`)
+
+	SetProxyConfig(&ProxyConfig{MaxTunnels: 1})
+	stats := getProxyStats()
+	before := atomic.LoadInt64(&stats.activeTunnels)
+
+	client, station := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		Proxy(testProxyReg(ln.Addr().String()), station, log.New(&bytes.Buffer{}, "", 0))
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&stats.sessionsProxying) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, before+1, atomic.LoadInt64(&stats.activeTunnels))
+
+	var refusedLog bytes.Buffer
+	refusedClient, _ := net.Pipe()
+	reg := testProxyReg(ln.Addr().String())
+	Proxy(reg, refusedClient, log.New(&refusedLog, "", 0))
+	require.Contains(t, refusedLog.String(), `"CovertDialErr":"refused"`)
+	require.Equal(t, int64(0), atomic.LoadInt64(&reg.activeTunnels))
+
+	client.Close()
+	<-done
+	require.Equal(t, before, atomic.LoadInt64(&stats.activeTunnels))
+}
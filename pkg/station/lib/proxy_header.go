@@ -0,0 +1,120 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+)
+
+// proxyHeaderV2Sig is the signature that starts every PROXY protocol version 2 header.
+var proxyHeaderV2Sig = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// PROXY protocol version 2 header fields.
+//
+// https://www.haproxy.org/download/2.9/doc/proxy-protocol.txt
+const (
+	proxyV2VersionCmd = 0x21 // version 2, PROXY command
+	proxyV2TCP4       = 0x11 // TCP over IPv4
+	proxyV2TCP6       = 0x21 // TCP over IPv6
+
+	// proxyTLVUniqueID is the standard TLV carrying an opaque connection identifier, used for the
+	// registration ID.
+	proxyTLVUniqueID = 0x05
+
+	// Custom TLVs, from the range reserved for application-specific data.
+	proxyTLVTransport = 0xE0 // transport name
+	proxyTLVClientCC  = 0xE1 // client country code
+	proxyTLVClientASN = 0xE2 // client ASN, 4 bytes big endian
+)
+
+var errProxyHeaderAddr = errors.New("can't write PROXY header")
+
+// writePROXYHeader writes a PROXY protocol header of the given version to conn, carrying the
+// address of the client as source and the phantom address of reg as destination.
+func writePROXYHeader(conn io.Writer, version int, clientAddr net.Addr, reg *DecoyRegistration) error {
+	if clientAddr == nil {
+		return fmt.Errorf("%w: empty IP", errProxyHeaderAddr)
+	}
+	// Errors leave out the address to prevent client IP logging.
+	host, portStr, err := net.SplitHostPort(clientAddr.String())
+	if err != nil {
+		return fmt.Errorf("%w: bad client address", errProxyHeaderAddr)
+	}
+	srcIP := net.ParseIP(host)
+	srcPort, err := strconv.ParseUint(portStr, 10, 16)
+	if srcIP == nil || err != nil {
+		return fmt.Errorf("%w: bad client address", errProxyHeaderAddr)
+	}
+	dstIP := reg.PhantomIp
+	if dstIP == nil || (srcIP.To4() == nil) != (dstIP.To4() == nil) {
+		return fmt.Errorf("%w: bad phantom address", errProxyHeaderAddr)
+	}
+
+	var header []byte
+	if version == 2 {
+		header = proxyHeaderV2(srcIP, uint16(srcPort), dstIP, reg.PhantomPort, proxyHeaderTLVs(reg))
+	} else {
+		header = proxyHeaderV1(srcIP, uint16(srcPort), dstIP, reg.PhantomPort)
+	}
+	_, err = conn.Write(header)
+	return err
+}
+
+// proxyHeaderV1 builds a PROXY protocol version 1 (text) header.
+func proxyHeaderV1(srcIP net.IP, srcPort uint16, dstIP net.IP, dstPort uint16) []byte {
+	transportProtocol := "TCP4"
+	if srcIP.To4() == nil {
+		transportProtocol = "TCP6"
+	}
+	return []byte(fmt.Sprintf("PROXY %s %s %s %d %d\r\n", transportProtocol, srcIP, dstIP, srcPort, dstPort))
+}
+
+// proxyHeaderV2 builds a PROXY protocol version 2 (binary) header followed by the encoded tlvs.
+func proxyHeaderV2(srcIP net.IP, srcPort uint16, dstIP net.IP, dstPort uint16, tlvs []byte) []byte {
+	family := byte(proxyV2TCP6)
+	src, dst := srcIP.To16(), dstIP.To16()
+	if ip4 := srcIP.To4(); ip4 != nil {
+		family = proxyV2TCP4
+		src, dst = ip4, dstIP.To4()
+	}
+
+	var addrs []byte
+	addrs = append(addrs, src...)
+	addrs = append(addrs, dst...)
+	addrs = binary.BigEndian.AppendUint16(addrs, srcPort)
+	addrs = binary.BigEndian.AppendUint16(addrs, dstPort)
+
+	var b bytes.Buffer
+	b.Write(proxyHeaderV2Sig)
+	b.WriteByte(proxyV2VersionCmd)
+	b.WriteByte(family)
+	_ = binary.Write(&b, binary.BigEndian, uint16(len(addrs)+len(tlvs)))
+	b.Write(addrs)
+	b.Write(tlvs)
+	return b.Bytes()
+}
+
+// proxyHeaderTLVs encodes the registration ID, transport and client location of reg as PROXY
+// protocol version 2 TLVs. Unknown client locations are left out.
+func proxyHeaderTLVs(reg *DecoyRegistration) []byte {
+	var tlvs []byte
+	appendTLV := func(t byte, v []byte) {
+		tlvs = append(tlvs, t)
+		tlvs = binary.BigEndian.AppendUint16(tlvs, uint16(len(v)))
+		tlvs = append(tlvs, v...)
+	}
+
+	appendTLV(proxyTLVUniqueID, []byte(reg.IDString()))
+	appendTLV(proxyTLVTransport, []byte(reg.Transport.String()))
+	if reg.regCC != "" {
+		appendTLV(proxyTLVClientCC, []byte(reg.regCC))
+	}
+	if reg.regASN != 0 {
+		appendTLV(proxyTLVClientASN, binary.BigEndian.AppendUint32(nil, uint32(reg.regASN)))
+	}
+	return tlvs
+}
@@ -0,0 +1,95 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/refraction-networking/conjure/proto"
+)
+
+func headerTestReg(phantom string) *DecoyRegistration {
+	return &DecoyRegistration{
+		PhantomIp:   net.ParseIP(phantom),
+		PhantomPort: 443,
+		Keys:        &ConjureSharedKeys{SharedSecret: bytes.Repeat([]byte{0xab}, 32)},
+		Transport:   pb.TransportType_Min,
+		regCC:       "US",
+		regASN:      65000,
+	}
+}
+
+func TestPROXYHeaderV1(t *testing.T) {
+	var conn bytes.Buffer
+	client := &net.TCPAddr{IP: net.ParseIP("203.0.113.5"), Port: 51000}
+	err := writePROXYHeader(&conn, 1, client, headerTestReg("192.0.2.1"))
+	require.Nil(t, err)
+	require.Equal(t, "PROXY TCP4 203.0.113.5 192.0.2.1 51000 443\r\n", conn.String())
+
+	conn.Reset()
+	client = &net.TCPAddr{IP: net.ParseIP("2001:db8::5"), Port: 51000}
+	err = writePROXYHeader(&conn, 1, client, headerTestReg("2001:db8:1::1"))
+	require.Nil(t, err)
+	require.Equal(t, "PROXY TCP6 2001:db8::5 2001:db8:1::1 51000 443\r\n", conn.String())
+
+	// Mismatched address families can not be expressed.
+	err = writePROXYHeader(&conn, 1, client, headerTestReg("192.0.2.1"))
+	require.ErrorIs(t, err, errProxyHeaderAddr)
+	err = writePROXYHeader(&conn, 1, nil, headerTestReg("192.0.2.1"))
+	require.ErrorIs(t, err, errProxyHeaderAddr)
+}
+
+func TestPROXYHeaderV2(t *testing.T) {
+	var conn bytes.Buffer
+	reg := headerTestReg("192.0.2.1")
+	client := &net.TCPAddr{IP: net.ParseIP("203.0.113.5"), Port: 51000}
+	require.Nil(t, writePROXYHeader(&conn, 2, client, reg))
+
+	h := conn.Bytes()
+	require.Equal(t, proxyHeaderV2Sig, h[:12])
+	require.Equal(t, byte(0x21), h[12])
+	require.Equal(t, byte(0x11), h[13])
+	require.Equal(t, len(h)-16, int(binary.BigEndian.Uint16(h[14:])))
+
+	addrs := h[16:28]
+	require.Equal(t, net.ParseIP("203.0.113.5").To4(), net.IP(addrs[0:4]))
+	require.Equal(t, net.ParseIP("192.0.2.1").To4(), net.IP(addrs[4:8]))
+	require.Equal(t, uint16(51000), binary.BigEndian.Uint16(addrs[8:]))
+	require.Equal(t, uint16(443), binary.BigEndian.Uint16(addrs[10:]))
+
+	tlvs := map[byte][]byte{}
+	for p := h[28:]; len(p) > 0; {
+		require.GreaterOrEqual(t, len(p), 3)
+		n := int(binary.BigEndian.Uint16(p[1:]))
+		tlvs[p[0]] = p[3 : 3+n]
+		p = p[3+n:]
+	}
+	require.Equal(t, reg.IDString(), string(tlvs[proxyTLVUniqueID]))
+	require.Equal(t, "Min", string(tlvs[proxyTLVTransport]))
+	require.Equal(t, "US", string(tlvs[proxyTLVClientCC]))
+	require.Equal(t, uint32(65000), binary.BigEndian.Uint32(tlvs[proxyTLVClientASN]))
+
+	conn.Reset()
+	client = &net.TCPAddr{IP: net.ParseIP("2001:db8::5"), Port: 51000}
+	require.Nil(t, writePROXYHeader(&conn, 2, client, headerTestReg("2001:db8:1::1")))
+	h = conn.Bytes()
+	require.Equal(t, byte(0x21), h[13])
+	require.Equal(t, net.ParseIP("2001:db8::5"), net.IP(h[16:32]))
+	require.Equal(t, net.ParseIP("2001:db8:1::1"), net.IP(h[32:48]))
+}
+
+func TestProxyHeaderVersion(t *testing.T) {
+	conf := &ProxyConfig{ProxyHeaderV2Destinations: []string{`^10\.0\.0\.\d+:443$`, `\.example\.com:\d+$`}}
+	require.Nil(t, conf.parse())
+
+	require.Equal(t, 2, conf.proxyHeaderVersion("10.0.0.7:443"))
+	require.Equal(t, 2, conf.proxyHeaderVersion("covert.example.com:80"))
+	require.Equal(t, 1, conf.proxyHeaderVersion("10.0.0.7:80"))
+	require.Equal(t, 1, (&ProxyConfig{}).proxyHeaderVersion("10.0.0.7:443"))
+
+	conf = &ProxyConfig{ProxyHeaderV2Destinations: []string{`(`}}
+	require.NotNil(t, conf.parse())
+}
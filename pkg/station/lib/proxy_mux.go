@@ -0,0 +1,45 @@
+package lib
+
+import (
+	"errors"
+	"net"
+	"sync"
+
+	"github.com/refraction-networking/conjure/pkg/mux"
+	"github.com/refraction-networking/conjure/pkg/station/log"
+)
+
+// proxyMultiplexed runs a stream multiplexer over clientConn for a registration that asked for
+// multiplexing, and proxies each stream the client opens to its own connection to the covert
+// destination. Every stream counts against the tunnel limits and reports its own stats.
+func proxyMultiplexed(reg *DecoyRegistration, clientConn net.Conn, logger *log.Logger) {
+	sess := mux.Server(clientConn, mux.Config{MaxStreams: getProxyConfig().MaxStreamsPerSession})
+	defer sess.Close()
+
+	getProxyStats().addMuxSession()
+	defer getProxyStats().removeMuxSession()
+
+	wg := sync.WaitGroup{}
+	for {
+		stream, err := sess.Accept()
+		if err != nil {
+			if !errors.Is(err, mux.ErrSessionClosed) {
+				logger.Warnf("multiplexed session failed: %s", err)
+			}
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer stream.Close()
+
+			tunStats := newTunnelStats(reg)
+			tunStats.Stream = stream.ID()
+			proxyConn(reg, stream, logger, tunStats)
+		}()
+	}
+	wg.Wait()
+
+	logger.Debugf("multiplexed session closed after %d streams", sess.Accepted())
+}
@@ -0,0 +1,86 @@
+package lib
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/refraction-networking/conjure/pkg/mux"
+	"github.com/refraction-networking/conjure/pkg/station/log"
+)
+
+// syncBuffer is a bytes.Buffer that can be written from several goroutines.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.b.String()
+}
+
+func TestProxyMultiplexed(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+	serveUpstream(ln, func(c net.Conn) { _, _ = io.Copy(c, c) })
+
+	reg := testProxyReg(ln.Addr().String())
+	reg.Flags.Multiplex = proto.Bool(true)
+
+	clientConn, stationConn := net.Pipe()
+	var logBuf syncBuffer
+	done := make(chan struct{})
+	go func() {
+		Proxy(reg, stationConn, log.New(&logBuf, "", 0))
+		close(done)
+	}()
+
+	client := mux.Client(clientConn, mux.Config{})
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stream, err := client.Open()
+			require.Nil(t, err)
+			defer stream.Close()
+
+			msg := []byte(fmt.Sprintf("stream %d", i))
+			_, err = stream.Write(msg)
+			require.Nil(t, err)
+			got := make([]byte, len(msg))
+			_, err = io.ReadFull(stream, got)
+			require.Nil(t, err)
+			require.Equal(t, msg, got)
+		}(i)
+	}
+	wg.Wait()
+
+	client.Close()
+	<-done
+
+	// Each stream is its own tunnel with its own stats, over a single phantom connection.
+	require.Equal(t, int64(1), reg.tunnelCount)
+	require.Equal(t, 3, strings.Count(logBuf.String(), "proxy closed"))
+	for id := 1; id <= 5; id += 2 {
+		require.Contains(t, logBuf.String(), fmt.Sprintf(`"Stream":%d`, id))
+	}
+	require.Equal(t, int64(0), atomic.LoadInt64(&reg.activeTunnels))
+}
@@ -0,0 +1,106 @@
+package lib
+
+import (
+	"errors"
+	"io"
+	"net"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/station/log"
+)
+
+// spliceChunk is the most data moved by a single splice between deadline refreshes.
+const spliceChunk = 1024 * 1024
+
+// splitConn is implemented by connections that serve data read ahead of time before reading from
+// the underlying connection, like transports.PrefixConn.
+type splitConn interface {
+	Split() (io.Reader, net.Conn)
+}
+
+// tcpConn returns the TCP connection underlying c along with any data that has to be read before
+// it, if c is a raw TCP connection that reads and writes the underlying connection unchanged.
+func tcpConn(c net.Conn) (*net.TCPConn, io.Reader, bool) {
+	var prefixes []io.Reader
+	for {
+		switch conn := c.(type) {
+		case *net.TCPConn:
+			if len(prefixes) == 0 {
+				return conn, nil, true
+			}
+			return conn, io.MultiReader(prefixes...), true
+		case splitConn:
+			var prefix io.Reader
+			prefix, c = conn.Split()
+			prefixes = append(prefixes, prefix)
+		default:
+			return nil, nil, false
+		}
+	}
+}
+
+// spliceHalfPipe forwards traffic from src to dst like halfPipe, after writing the prefix data
+// of src to dst. The data is moved by splice(2) without copying it to user space, so deadlines
+// are only refreshed between chunks: a transfer stalls once no data is moved for between one and
+// two stall timeouts.
+//
+// A failed splice does not tell whether reading or writing failed, so errors are reported
+// against src unless they happen writing the prefix.
+func spliceHalfPipe(src *net.TCPConn, prefix io.Reader, dst *net.TCPConn, logger *log.Logger, tag string, stats *tunnelStats, isUpload bool) {
+	count := func(n int64) {
+		stats.addBytes(n, isUpload)
+		if isUpload {
+			Stat().AddBytesUp(n)
+		} else {
+			Stat().AddBytesDown(n)
+		}
+	}
+	setErr := func(err error, fromSrc bool) {
+		e := generalizeErr(err)
+		if e == nil {
+			return
+		}
+		// The client is the source of uploads and the destination of downloads.
+		if fromSrc == isUpload {
+			stats.ClientConnErr = e.Error()
+		} else {
+			stats.CovertConnErr = e.Error()
+		}
+	}
+
+	if prefix != nil {
+		n, err := io.Copy(dst, prefix)
+		count(n)
+		if err != nil {
+			setErr(err, false)
+			return
+		}
+	}
+
+	for {
+		n, err := dst.ReadFrom(&io.LimitedReader{R: src, N: spliceChunk})
+		count(n)
+
+		var netErr net.Error
+		if err != nil && !(n > 0 && errors.As(err, &netErr) && netErr.Timeout()) {
+			setErr(err, true)
+			return
+		}
+		if err == nil && n < spliceChunk {
+			// src reached EOF.
+			return
+		}
+
+		// Data moved since the deadlines were set, so the transfer has not stalled.
+		err = src.SetDeadline(time.Now().Add(proxyStallTimeout))
+		if err != nil {
+			logger.Errorln("error setting deadline for src conn: ", tag)
+			return
+		}
+		err = dst.SetDeadline(time.Now().Add(proxyStallTimeout))
+		if err != nil {
+			logger.Errorln("error setting deadline for dst conn: ", tag)
+			return
+		}
+	}
+}
@@ -0,0 +1,5 @@
+package lib
+
+// spliceEnabled reports whether halfPipe splices raw TCP connections together. On Linux,
+// (*net.TCPConn).ReadFrom moves data between TCP sockets with splice(2).
+const spliceEnabled = true
@@ -0,0 +1,141 @@
+//go:build !race
+// +build !race
+
+package lib
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"net"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/refraction-networking/conjure/pkg/station/log"
+	"github.com/refraction-networking/conjure/pkg/transports"
+)
+
+// tcpPair returns both ends of a loopback TCP connection.
+func tcpPair(t testing.TB) (*net.TCPConn, *net.TCPConn) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		c, _ := ln.Accept()
+		accepted <- c
+	}()
+	dialed, err := net.Dial("tcp", ln.Addr().String())
+	require.Nil(t, err)
+	return dialed.(*net.TCPConn), (<-accepted).(*net.TCPConn)
+}
+
+// wrappedConn hides the type of the connection it wraps, like the conns of obfuscated
+// transports, so that halfPipe can not splice it.
+type wrappedConn struct {
+	net.Conn
+}
+
+func TestTCPConn(t *testing.T) {
+	a, b := tcpPair(t)
+	defer a.Close()
+	defer b.Close()
+
+	c, prefix, ok := tcpConn(a)
+	require.True(t, ok)
+	require.Equal(t, a, c)
+	require.Nil(t, prefix)
+
+	pc := transports.PrependToConn(transports.PrependToConn(a, bytes.NewBufferString("in")), bytes.NewBufferString("out"))
+	c, prefix, ok = tcpConn(pc)
+	require.True(t, ok)
+	require.Equal(t, a, c)
+	data, err := io.ReadAll(prefix)
+	require.Nil(t, err)
+	require.Equal(t, "outin", string(data))
+
+	_, _, ok = tcpConn(wrappedConn{a})
+	require.False(t, ok)
+}
+
+func TestHalfPipeSplice(t *testing.T) {
+	client, srcConn := tcpPair(t)
+	dstConn, covert := tcpPair(t)
+	defer client.Close()
+	defer covert.Close()
+
+	data := make([]byte, 3*spliceChunk+123)
+	_, _ = rand.Read(data)
+	go func() {
+		_, _ = client.Write(data[5:])
+		client.Close()
+	}()
+
+	stats := &tunnelStats{proxyStats: getProxyStats()}
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	src := transports.PrependToConn(srcConn, bytes.NewReader(data[:5]))
+	go halfPipe(src, dstConn, &wg, log.New(io.Discard, "", 0), "Up test", stats)
+
+	got, err := io.ReadAll(covert)
+	require.Nil(t, err)
+	require.True(t, bytes.Equal(data, got))
+	wg.Wait()
+	require.Equal(t, int64(len(data)), stats.BytesUp)
+	require.Equal(t, "", stats.ClientConnErr)
+	require.Equal(t, "", stats.CovertConnErr)
+}
+
+// cpuTime returns the user and system CPU time used by the process so far.
+func cpuTime(b *testing.B) time.Duration {
+	var ru syscall.Rusage
+	require.Nil(b, syscall.Getrusage(syscall.RUSAGE_SELF, &ru))
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano())
+}
+
+// benchmarkHalfPipe measures the throughput and CPU time of halfPipe forwarding 1 MiB per op
+// between loopback TCP connections, as returned by wrap.
+func benchmarkHalfPipe(b *testing.B, wrap func(net.Conn) net.Conn) {
+	client, srcConn := tcpPair(b)
+	dstConn, covert := tcpPair(b)
+	defer client.Close()
+	defer covert.Close()
+
+	chunk := make([]byte, 1024*1024)
+	b.SetBytes(int64(len(chunk)))
+	go func() {
+		for i := 0; i < b.N; i++ {
+			if _, err := client.Write(chunk); err != nil {
+				break
+			}
+		}
+		client.Close()
+	}()
+
+	stats := &tunnelStats{proxyStats: getProxyStats()}
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+
+	b.ResetTimer()
+	start := cpuTime(b)
+	go halfPipe(wrap(srcConn), wrap(dstConn), &wg, log.New(io.Discard, "", 0), "Up bench", stats)
+	n, _ := io.Copy(io.Discard, covert)
+	wg.Wait()
+	b.ReportMetric(float64(cpuTime(b)-start)/float64(b.N), "cpu-ns/op")
+	b.StopTimer()
+
+	require.Equal(b, int64(b.N*len(chunk)), n)
+}
+
+func BenchmarkHalfPipeSplice(b *testing.B) {
+	benchmarkHalfPipe(b, func(c net.Conn) net.Conn { return c })
+}
+
+func BenchmarkHalfPipeCopy(b *testing.B) {
+	benchmarkHalfPipe(b, func(c net.Conn) net.Conn { return wrappedConn{c} })
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package lib
+
+// spliceEnabled reports whether halfPipe splices raw TCP connections together. Elsewhere
+// (*net.TCPConn).ReadFrom copies through user space, so the regular copy loop is used.
+const spliceEnabled = false
@@ -2,6 +2,7 @@ package lib
 
 import (
 	"context"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -12,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/refraction-networking/conjure/pkg/station/geoip"
@@ -44,6 +46,11 @@ type RegistrationManager struct {
 	// ingestChan is included here so that the capacity and use is available to
 	// stats
 	ingestChan <-chan interface{}
+
+	// livenessPipeline runs the liveness tests of ingested registrations, nil
+	// until HandleRegUpdates starts it. It is read by the stats printer while
+	// HandleRegUpdates sets it, so it is accessed atomically.
+	livenessPipeline atomic.Pointer[liveness.Pipeline]
 }
 
 // NewRegistrationManager returns a newly initialized registration Manager
@@ -56,7 +63,7 @@ func NewRegistrationManager(conf *RegConfig) *RegistrationManager {
 		logger.Fatal(err)
 	}
 
-	p, err := NewPhantomIPSelector()
+	p, err := SubnetsFromTomlFile(conf.PhantomSubnetsLocation())
 	if err != nil {
 		logger.Errorf("failed to create the PhantomIPSelector object: %v", err)
 		return nil
@@ -89,7 +96,7 @@ func (regManager *RegistrationManager) OnReload(conf *RegConfig) {
 
 	// try to re-initialize the phantom selector, if error occurs log err and
 	// do not update the existing PhantomSelector
-	p, err := NewPhantomIPSelector()
+	p, err := SubnetsFromTomlFile(conf.PhantomSubnetsLocation())
 	if err != nil {
 		regManager.Logger.Errorf("failed to reload phantom subnets: %v", err)
 	} else {
@@ -112,6 +119,7 @@ func (regManager *RegistrationManager) OnReload(conf *RegConfig) {
 
 	regManager.RegConfig.PhantomBlocklist = conf.PhantomBlocklist
 	regManager.RegConfig.phantomBlocklist = conf.phantomBlocklist
+	regManager.RegConfig.PhantomSubnetsPath = conf.PhantomSubnetsPath
 
 	geoipDB, err := geoip.New(conf.DBConfig)
 	if errors.Is(err, geoip.ErrMissingDB) {
@@ -220,6 +228,12 @@ func (regManager *RegistrationManager) GetRegistrations(phantomAddr net.IP) map[
 	return regManager.registeredDecoys.getRegistrations(phantomAddr)
 }
 
+// GetRegistrationsInBucket returns the registrations associated with a specific phantom address
+// that a BucketingTransport put in bucket.
+func (regManager *RegistrationManager) GetRegistrationsInBucket(phantomAddr net.IP, bucket string) []*DecoyRegistration {
+	return regManager.registeredDecoys.getRegistrationsInBucket(phantomAddr, bucket)
+}
+
 // CountRegistrations counts the number of registrations tracked that are using a
 // specific phantom address.
 func (regManager *RegistrationManager) CountRegistrations(phantomAddr net.IP) int {
@@ -245,6 +259,15 @@ func (regManager *RegistrationManager) PhantomIsLive(addr string, port uint16) (
 	return regManager.LivenessTester.PhantomIsLive(addr, port)
 }
 
+// SaveLivenessCache persists the liveness results of the station, if the
+// liveness tester is configured to, so that they survive a restart.
+func (regManager *RegistrationManager) SaveLivenessCache() error {
+	if p, ok := regManager.LivenessTester.(liveness.Persister); ok {
+		return p.Save()
+	}
+	return nil
+}
+
 // MarkActive indicates that an incoming connection has successfully been make
 // with the registration provided in the argument.
 func (regManager *RegistrationManager) MarkActive(reg *DecoyRegistration) {
@@ -280,7 +303,16 @@ type DecoyRegistration struct {
 	regCount           int32
 	clientLibVer       uint32
 
-	tunnelCount int64
+	tunnelCount   int64
+	activeTunnels int64 // tunnels currently open for this registration
+	bytesProxied  int64 // bytes charged against the registration byte quota
+
+	bandwidth   *tokenBucket // bandwidth limit shared by all tunnels of this registration
+	bandwidthMu sync.Mutex
+
+	uploadSync *uint64 // upload position of a client that opted into resumable sessions
+	resumeMu   sync.Mutex
+	resume     *resumableSession
 
 	// validity marks whether the registration has been validated through liveness and other checks.
 	// This also denotes whether the registration has been shared with the detector.
@@ -363,6 +395,8 @@ func (reg *DecoyRegistration) GenerateClientToStation() *pb.ClientToStation {
 		V6Support:           &v6,
 		V4Support:           &v4,
 		Transport:           &reg.Transport,
+		UploadSync:          reg.uploadSync,
+		ClientLibVersion:    proto.Uint32(reg.clientLibVer),
 	}
 
 	for (proto.Size(initProto)+AES_GCM_TAG_SIZE)%3 != 0 {
@@ -388,11 +422,21 @@ func (reg *DecoyRegistration) GenerateC2SWrapper() *pb.C2SWrapper {
 
 	source := pb.RegistrationSource_DetectorPrescan
 
+	// Peers derive the phantom from the seed, so the response carries the one this station uses
+	// in case the registrar re-derived it to avoid a known live host.
+	regResp := &pb.RegistrationResponse{}
+	if phantom4 := reg.PhantomIp.To4(); phantom4 != nil {
+		regResp.Ipv4Addr = proto.Uint32(binary.BigEndian.Uint32(phantom4))
+	} else {
+		regResp.Ipv6Addr = reg.PhantomIp.To16()
+	}
+
 	protoPayload := &pb.C2SWrapper{
-		SharedSecret:        reg.Keys.SharedSecret,
-		RegistrationPayload: c2s,
-		RegistrationSource:  &source,
-		RegistrationAddress: []byte(reg.registrationAddr),
+		SharedSecret:         reg.Keys.SharedSecret,
+		RegistrationPayload:  c2s,
+		RegistrationSource:   &source,
+		RegistrationAddress:  []byte(reg.registrationAddr),
+		RegistrationResponse: regResp,
 	}
 	return protoPayload
 }
@@ -427,6 +471,11 @@ func (reg *DecoyRegistration) GetSrcPort() uint16 {
 	return 0
 }
 
+// ClientLibVersion returns the library version reported by the client in the registration.
+func (reg *DecoyRegistration) ClientLibVersion() uint {
+	return uint(reg.clientLibVer)
+}
+
 type regStatus int
 
 const (
@@ -439,6 +488,7 @@ const (
 type DecoyTimeout struct {
 	decoy            string
 	identifier       string
+	bucket           *string // bucket of registrations of a BucketingTransport
 	registrationTime time.Time
 	regID            string
 	status           regStatus
@@ -456,6 +506,10 @@ type RegisteredDecoys struct {
 	// between transports.
 	decoys map[string]map[string]*DecoyRegistration
 
+	// buckets indexes the registrations of BucketingTransports by decoy_ip, then by bucket, then
+	// by registration identifier.
+	buckets map[string]map[string]map[string]*DecoyRegistration
+
 	transports map[pb.TransportType]Transport
 
 	decoysTimeouts map[string]*DecoyTimeout
@@ -474,6 +528,7 @@ func NewRegisteredDecoys() *RegisteredDecoys {
 		timeoutActive:  defaultActiveTimeout,
 		timeoutUnused:  defaultUnusedTimeout,
 		decoys:         make(map[string]map[string]*DecoyRegistration),
+		buckets:        make(map[string]map[string]map[string]*DecoyRegistration),
 		transports:     make(map[pb.TransportType]Transport),
 		decoysTimeouts: make(map[string]*DecoyTimeout),
 		registerForDetector: func(d *DecoyRegistration) {
@@ -531,6 +586,18 @@ func (r *RegisteredDecoys) track(d *DecoyRegistration) error {
 		regID:            d.IDString(),
 		status:           regStatusUnused,
 	}
+
+	if bt, ok := t.(BucketingTransport); ok {
+		bucket := bt.GetBucket(d)
+		if _, exists := r.buckets[phantomAddr]; !exists {
+			r.buckets[phantomAddr] = map[string]map[string]*DecoyRegistration{}
+		}
+		if _, exists := r.buckets[phantomAddr][bucket]; !exists {
+			r.buckets[phantomAddr][bucket] = map[string]*DecoyRegistration{}
+		}
+		r.buckets[phantomAddr][bucket][identifier] = d
+		newTimeout.bucket = &bucket
+	}
 	r.decoysTimeouts[d.IDString()+phantomAddr] = newTimeout
 
 	return nil
@@ -601,6 +668,20 @@ func (r *RegisteredDecoys) getRegistrations(darkDecoyAddr net.IP) map[string]*De
 	return regs
 }
 
+func (r *RegisteredDecoys) getRegistrationsInBucket(darkDecoyAddr net.IP, bucket string) []*DecoyRegistration {
+	r.m.RLock()
+	defer r.m.RUnlock()
+
+	var regs []*DecoyRegistration
+	for _, v := range r.buckets[darkDecoyAddr.String()][bucket] {
+		if v.Valid {
+			regs = append(regs, v)
+		}
+	}
+
+	return regs
+}
+
 // TotalRegistrations return the total number of current registrations
 func (r *RegisteredDecoys) TotalRegistrations() int {
 	r.m.RLock()
@@ -744,6 +825,18 @@ func (r *RegisteredDecoys) removeRegistration(index string) *regExpireLogMsg {
 		delete(r.decoys, expiredReg.decoy)
 	}
 
+	// remove from bucket tracking
+	if expiredReg.bucket != nil {
+		buckets := r.buckets[expiredReg.decoy]
+		delete(buckets[*expiredReg.bucket], expiredReg.identifier)
+		if len(buckets[*expiredReg.bucket]) == 0 {
+			delete(buckets, *expiredReg.bucket)
+		}
+		if len(buckets) == 0 {
+			delete(r.buckets, expiredReg.decoy)
+		}
+	}
+
 	return stats
 }
 
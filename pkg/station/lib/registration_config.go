@@ -45,6 +45,10 @@ type RegConfig struct {
 	// Local list of disallowed subnets patterns for phantom addresses.
 	PhantomBlocklist []string `toml:"phantom_blocklist"`
 	phantomBlocklist []*net.IPNet
+
+	// Path to the phantom subnets file. If empty the PHANTOM_SUBNET_LOCATION environment variable
+	// is used.
+	PhantomSubnetsPath string `toml:"phantom_subnets_path"`
 }
 
 // ParseBlocklists converts string arrays of blocklisted domains, addresses and
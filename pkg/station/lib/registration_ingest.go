@@ -3,6 +3,7 @@ package lib
 import (
 	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"net"
@@ -58,6 +59,16 @@ func (rm *RegistrationManager) HandleRegUpdates(ctx context.Context, regChan <-c
 	// Add to registration manager so that we cann access it for stats printing.
 	rm.ingestChan = shallowBuffer
 
+	// Liveness tests run in their own pool of workers so that ingest workers
+	// do not block on the network while a phantom is probed.
+	pipeline, err := liveness.NewPipeline(rm.LivenessTester, rm.LivenessConfig())
+	if err != nil {
+		logger.Errorf("failed to create liveness pipeline, testing liveness in ingest workers: %v", err)
+	} else {
+		pipeline.Start(ctx, wg)
+		rm.livenessPipeline.Store(pipeline)
+	}
+
 	// launch workers
 	for i := 0; i < workers; i++ {
 		wg.Add(1)
@@ -99,7 +110,7 @@ func (rm *RegistrationManager) startIngestThread(ctx context.Context, regChan <-
 			newRegs, err := rm.parseRegMessage(msg.([]byte))
 			if err != nil {
 
-				if !errors.Is(err, ErrLegacyAddrSelectBug) {
+				if !errors.Is(err, ErrLegacyAddrSelectBug) && !errors.Is(err, ErrGenerationExpired) {
 					logger.Errorf("Encountered err when creating Reg: %v\n", err)
 				}
 				continue
@@ -189,22 +200,56 @@ func (rm *RegistrationManager) ingestRegistration(reg *DecoyRegistration) {
 	reg.Covert = covert
 
 	// Perform liveness test IFF not done by other station or v6 (v6 should
-	// never be live)
-	if !reg.PreScanned() && reg.PhantomIp.To4() != nil {
+	// never be live) unless v6 liveness testing is enabled, as some v6
+	// phantom prefixes are shared with hosting providers.
+	if !reg.PreScanned() && (reg.PhantomIp.To4() != nil || rm.LivenessConfig().EnableV6) {
 		// New registration received over channel that requires liveness scan for the phantom
+		rm.testPhantomLiveness(reg)
+		return
+	}
+
+	rm.addIngestedRegistration(reg)
+}
+
+// testPhantomLiveness tests the phantom of reg for liveness and adds the
+// registration if the phantom is not live. When the ingest pipeline is running
+// the registration is parked in the liveness pipeline so that the ingest worker
+// can move on, otherwise the test is done in place.
+func (rm *RegistrationManager) testPhantomLiveness(reg *DecoyRegistration) {
+	pipeline := rm.livenessPipeline.Load()
+	if pipeline == nil {
 		live, response := rm.PhantomIsLive(reg.PhantomIp.String(), reg.PhantomPort)
+		rm.finishLivenessTest(reg, live, response)
+		return
+	}
 
-		// TODO JMWAMPLE REMOVE
-		if live {
-			logger.Warnf("Dropping registration %v -- live phantom: %v\n", reg.IDString(), response)
-			if errors.Is(response, liveness.ErrCachedPhantom) {
-				Stat().AddLivenessCached()
-			}
-			Stat().AddLivenessFail()
-			return
+	err := pipeline.Test(reg.PhantomIp.String(), reg.PhantomPort, func(live bool, response error) {
+		rm.finishLivenessTest(reg, live, response)
+	})
+	if err != nil {
+		rm.Logger.Tracef("dropping registration %v: %v", reg.IDString(), err)
+		rm.addDroppedMessage()
+	}
+}
+
+func (rm *RegistrationManager) finishLivenessTest(reg *DecoyRegistration, live bool, response error) {
+	if live {
+		rm.Logger.Warnf("Dropping registration %v -- live phantom: %v\n", reg.IDString(), response)
+		if errors.Is(response, liveness.ErrCachedPhantom) {
+			Stat().AddLivenessCached()
 		}
-		Stat().AddLivenessPass()
+		Stat().AddLivenessFail()
+		return
 	}
+	Stat().AddLivenessPass()
+
+	rm.addIngestedRegistration(reg)
+}
+
+// addIngestedRegistration adds a registration that passed all ingest checks
+// except for the phantom blocklist of decoy registrations.
+func (rm *RegistrationManager) addIngestedRegistration(reg *DecoyRegistration) {
+	logger := rm.Logger
 
 	if *reg.RegistrationSource == pb.RegistrationSource_Detector {
 		if rm.EnableShareOverAPI {
@@ -304,7 +349,9 @@ func (rm *RegistrationManager) parseRegMessage(msg []byte) ([]*DecoyRegistration
 		reg, err := rm.NewRegistrationC2SWrapper(parsed, false)
 		if err != nil {
 
-			if !errors.Is(err, ErrLegacyAddrSelectBug) {
+			if errors.Is(err, ErrGenerationExpired) {
+				rm.AddExpiredGenReg(parsed.GetRegistrationPayload().GetDecoyListGeneration())
+			} else if !errors.Is(err, ErrLegacyAddrSelectBug) {
 				logger.Errorf("Failed to create registration from v4 C2S: %v", err)
 			}
 			return nil, err
@@ -317,7 +364,11 @@ func (rm *RegistrationManager) parseRegMessage(msg []byte) ([]*DecoyRegistration
 	if parsed.GetRegistrationPayload().GetV6Support() && rm.EnableIPv6 {
 		reg, err := rm.NewRegistrationC2SWrapper(parsed, true)
 		if err != nil {
-			logger.Errorf("Failed to create registration from v6 C2S: %v", err)
+			if errors.Is(err, ErrGenerationExpired) {
+				rm.AddExpiredGenReg(parsed.GetRegistrationPayload().GetDecoyListGeneration())
+			} else {
+				logger.Errorf("Failed to create registration from v6 C2S: %v", err)
+			}
 			return nil, err
 		}
 		// add to list of new registrations to be processed.
@@ -341,7 +392,7 @@ func (rm *RegistrationManager) NewRegistration(c2s *pb.ClientToStation, conjureK
 		conjureKeys.ConjureSeed, gen, clientLibVer, includeV6)
 
 	if err != nil {
-		return nil, fmt.Errorf("failed phantom select: gen %d libv %d v6 %t err: %v",
+		return nil, fmt.Errorf("failed phantom select: gen %d libv %d v6 %t err: %w",
 			gen,
 			clientLibVer,
 			includeV6,
@@ -353,6 +404,10 @@ func (rm *RegistrationManager) NewRegistration(c2s *pb.ClientToStation, conjureK
 		return nil, fmt.Errorf("unknown transport")
 	}
 
+	if c2s.UploadSync != nil && c2s.GetFlags().GetMultiplex() {
+		return nil, ErrResumeMultiplex
+	}
+
 	transportParams, err := rm.getTransportParams(c2s.GetTransport(), c2s.GetTransportParams(), clientLibVer)
 	if err != nil {
 		return nil, fmt.Errorf("error handling transport params: %s", err)
@@ -376,6 +431,7 @@ func (rm *RegistrationManager) NewRegistration(c2s *pb.ClientToStation, conjureK
 		TransportPtr:     &transport,
 		TransportParams:  transportParams,
 		Flags:            c2s.Flags,
+		uploadSync:       c2s.UploadSync,
 
 		PhantomIp:    phantomAddr,
 		PhantomPort:  phantomPort,
@@ -387,6 +443,7 @@ func (rm *RegistrationManager) NewRegistration(c2s *pb.ClientToStation, conjureK
 		RegistrationTime:   time.Now(),
 		regCount:           0,
 		tunnelCount:        0,
+		clientLibVer:       uint32(clientLibVer),
 	}
 
 	return &reg, nil
@@ -419,12 +476,17 @@ func (rm *RegistrationManager) NewRegistrationC2SWrapper(c2sw *pb.C2SWrapper, in
 			c2s.TransportParams = rr.GetTransportParams()
 		}
 
-		// TODO: future, apply the ip addresses from the Registration response (rr.IPv4Addr, rr.IPv6Addr)
+		// The phantom addresses of the response are applied once the registration is built, see
+		// applyReselectedPhantom.
 	}
 
 	reg, err := rm.NewRegistration(c2s, &conjureKeys, includeV6, &regSrc)
 	if err != nil || reg == nil {
-		return nil, fmt.Errorf("failed to build registration: %s", err)
+		return nil, fmt.Errorf("failed to build registration: %w", err)
+	}
+
+	if rr := c2sw.GetRegistrationResponse(); rr != nil {
+		rm.applyReselectedPhantom(reg, rr, includeV6)
 	}
 
 	clientAddr := net.IP(c2sw.GetRegistrationAddress())
@@ -452,6 +514,26 @@ func (rm *RegistrationManager) NewRegistrationC2SWrapper(c2sw *pb.C2SWrapper, in
 	return reg, nil
 }
 
+// applyReselectedPhantom uses the phantom from the registration response if the registrar
+// re-derived it to avoid a known live host. Any other phantom in the response is ignored, so a
+// registrar can only pick among the re-derivations of the seed.
+func (rm *RegistrationManager) applyReselectedPhantom(reg *DecoyRegistration, rr *pb.RegistrationResponse, includeV6 bool) {
+	var addr net.IP
+	if includeV6 {
+		addr = net.IP(rr.GetIpv6Addr())
+	} else if rr.Ipv4Addr != nil {
+		addr = make(net.IP, net.IPv4len)
+		binary.BigEndian.PutUint32(addr, rr.GetIpv4Addr())
+	}
+	if len(addr) == 0 || addr.Equal(reg.PhantomIp) {
+		return
+	}
+
+	if rm.PhantomSelector.IsReselection(reg.Keys.ConjureSeed, uint(reg.DecoyListVersion), uint(reg.clientLibVer), includeV6, addr) {
+		reg.PhantomIp = addr
+	}
+}
+
 func (rm *RegistrationManager) getTransportParams(t pb.TransportType, data *anypb.Any, libVer uint) (any, error) {
 	var transport, ok = rm.registeredDecoys.transports[t]
 	if !ok {
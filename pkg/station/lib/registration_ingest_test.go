@@ -1,15 +1,22 @@
 package lib
 
 import (
+	"context"
+	"encoding/binary"
 	"encoding/hex"
+	"io"
 	"net"
 	"os"
+	"sync"
 	"testing"
+	"time"
 
+	"github.com/refraction-networking/conjure/pkg/station/liveness"
 	"github.com/refraction-networking/conjure/pkg/station/log"
 
 	pb "github.com/refraction-networking/conjure/proto"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 )
 
 func TestIngestPortHandling(t *testing.T) {
@@ -120,3 +127,174 @@ func TestIngestPortHandlingCorners(t *testing.T) {
 		require.Equal(t, testCase.err, err.Error(), "case: %v", testCase)
 	}
 }
+
+func TestIngestExpiredGeneration(t *testing.T) {
+	os.Setenv("PHANTOM_SUBNET_LOCATION", "./test/phantom_subnets.toml")
+	rm := NewRegistrationManager(&RegConfig{EnableIPv4: true, EnableIPv6: true})
+	require.NotNil(t, rm)
+
+	var transportType pb.TransportType = 0
+	err := rm.AddTransport(transportType, &mockTransport{})
+	require.Nil(t, err)
+
+	c2s, _ := mockReceiveFromDetector()
+	c2s.Transport = &transportType
+	c2s.V4Support = proto.Bool(true)
+	regSource := pb.RegistrationSource_Detector
+	msg, err := proto.Marshal(&pb.C2SWrapper{
+		SharedSecret:        make([]byte, 32),
+		RegistrationPayload: &c2s,
+		RegistrationSource:  &regSource,
+		RegistrationAddress: net.ParseIP("1.1.1.1"),
+	})
+	require.Nil(t, err)
+
+	gen := c2s.GetDecoyListGeneration()
+	rm.PhantomSelector.Networks[uint(gen)].Expires = time.Now().Add(-time.Minute)
+
+	_, err = rm.parseRegMessage(msg)
+	require.ErrorIs(t, err, ErrGenerationExpired)
+	require.Equal(t, int64(1), rm.generations[gen].expiredRegistrations)
+	require.Equal(t, int64(0), rm.generations[gen].newRegistrations)
+}
+
+func TestIngestReselectedPhantom(t *testing.T) {
+	os.Setenv("PHANTOM_SUBNET_LOCATION", "./test/phantom_subnets.toml")
+	rm := NewRegistrationManager(&RegConfig{})
+	require.NotNil(t, rm)
+
+	var transportType pb.TransportType = 0
+	err := rm.AddTransport(transportType, &mockTransport{})
+	require.Nil(t, err)
+
+	c2s, _ := mockReceiveFromDetector()
+	c2s.Transport = &transportType
+	c2s.ClientLibVersion = proto.Uint32(uint32(phantomHkdfMinVersion))
+	regSource := pb.RegistrationSource_BidirectionalAPI
+	sharedSecret := make([]byte, 32)
+
+	newReg := func(rr *pb.RegistrationResponse) *DecoyRegistration {
+		reg, err := rm.NewRegistrationC2SWrapper(&pb.C2SWrapper{
+			SharedSecret:         sharedSecret,
+			RegistrationPayload:  &c2s,
+			RegistrationSource:   &regSource,
+			RegistrationAddress:  net.ParseIP("1.1.1.1"),
+			RegistrationResponse: rr,
+		}, false)
+		require.Nil(t, err)
+		return reg
+	}
+
+	selected := newReg(nil).PhantomIp
+	keys, err := GenSharedKeys(phantomHkdfMinVersion, sharedSecret, transportType)
+	require.Nil(t, err)
+	live := NewPhantomExclusions()
+	live.Add(selected)
+	reselected, err := rm.PhantomSelector.SelectExcluding(keys.ConjureSeed, uint(c2s.GetDecoyListGeneration()), phantomHkdfMinVersion, false, live.Contains)
+	require.Nil(t, err)
+	require.False(t, reselected.Equal(selected))
+
+	// The station accepts the phantom the registrar re-derived ...
+	reg := newReg(&pb.RegistrationResponse{Ipv4Addr: proto.Uint32(binary.BigEndian.Uint32(reselected.To4()))})
+	require.Equal(t, reselected.String(), reg.PhantomIp.String())
+
+	// ... but not an arbitrary one.
+	reg = newReg(&pb.RegistrationResponse{Ipv4Addr: proto.Uint32(binary.BigEndian.Uint32(net.ParseIP("192.122.190.1").To4()))})
+	require.Equal(t, selected.String(), reg.PhantomIp.String())
+
+	// Stations the registration is shared with use the re-derived phantom too.
+	reg = newReg(&pb.RegistrationResponse{Ipv4Addr: proto.Uint32(binary.BigEndian.Uint32(reselected.To4()))})
+	rm.EnableIPv4 = true
+	shared, err := proto.Marshal(reg.GenerateC2SWrapper())
+	require.Nil(t, err)
+	peerRegs, err := rm.parseRegMessage(shared)
+	require.Nil(t, err)
+	require.Len(t, peerRegs, 1)
+	require.Equal(t, reselected.String(), peerRegs[0].PhantomIp.String())
+}
+
+// parkedLivenessTester answers liveness tests once release is closed.
+type parkedLivenessTester struct {
+	liveness.Tester
+	release chan struct{}
+	live    bool
+}
+
+func (pt *parkedLivenessTester) PhantomIsLive(addr string, port uint16) (bool, error) {
+	<-pt.release
+	return pt.live, nil
+}
+
+func TestIngestParkedLiveness(t *testing.T) {
+	os.Setenv("PHANTOM_SUBNET_LOCATION", "./test/phantom_subnets.toml")
+
+	testCases := []struct {
+		v6       bool
+		enableV6 bool
+		live     bool
+		added    bool
+	}{
+		{false, false, false, true},
+		{false, false, true, false},
+		// v6 phantoms are only tested for liveness when enabled.
+		{true, false, true, true},
+		{true, true, true, false},
+		{true, true, false, true},
+	}
+
+	for _, tc := range testCases {
+		rm := NewRegistrationManager(&RegConfig{
+			Config:     &liveness.Config{EnableV6: tc.enableV6},
+			EnableIPv4: true,
+			EnableIPv6: true,
+		})
+		require.NotNil(t, rm)
+		rm.Logger = log.New(io.Discard, "", 0)
+
+		var transportType pb.TransportType = 0
+		err := rm.AddTransport(transportType, &mockTransport{})
+		require.Nil(t, err)
+
+		tester := &parkedLivenessTester{release: make(chan struct{}), live: tc.live}
+		rm.LivenessTester = tester
+		pipeline, err := liveness.NewPipeline(tester, &liveness.Config{PipelineWorkers: 1})
+		require.Nil(t, err)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		wg := new(sync.WaitGroup)
+		pipeline.Start(ctx, wg)
+		rm.livenessPipeline.Store(pipeline)
+
+		c2s, _ := mockReceiveFromDetector()
+		c2s.Transport = &transportType
+		c2s.V6Support = proto.Bool(tc.v6)
+		c2s.V4Support = proto.Bool(!tc.v6)
+		regSource := pb.RegistrationSource_API
+		reg, err := rm.NewRegistrationC2SWrapper(&pb.C2SWrapper{
+			SharedSecret:        make([]byte, 32),
+			RegistrationPayload: &c2s,
+			RegistrationSource:  &regSource,
+			RegistrationAddress: net.ParseIP("1.1.1.1"),
+		}, tc.v6)
+		require.Nil(t, err)
+		require.Equal(t, tc.v6, reg.PhantomIp.To4() == nil)
+
+		// The ingest worker returns while the registration waits for its liveness result.
+		rm.ingestRegistration(reg)
+		tested := !tc.v6 || tc.enableV6
+		if tested {
+			require.Empty(t, rm.GetRegistrations(reg.PhantomIp), "%+v", tc)
+		}
+
+		close(tester.release)
+		if tc.added {
+			require.Eventually(t, func() bool { return len(rm.GetRegistrations(reg.PhantomIp)) == 1 }, time.Second, 10*time.Millisecond, "%+v", tc)
+		} else {
+			time.Sleep(100 * time.Millisecond)
+			require.Empty(t, rm.GetRegistrations(reg.PhantomIp), "%+v", tc)
+		}
+
+		cancel()
+		wg.Wait()
+	}
+}
@@ -47,7 +47,8 @@ type RegistrationStats struct {
 }
 
 type generationStats struct {
-	newRegistrations int64
+	newRegistrations     int64
+	expiredRegistrations int64 // registrations received after the generation expired
 }
 
 type libverStats struct {
@@ -153,6 +154,13 @@ func (s *RegistrationStats) PrintAndReset(logger *log.Logger) {
 				stats.newRegistrations,
 				float64(stats.newRegistrations)/epochDur*1000,
 			)
+			if stats.expiredRegistrations > 0 {
+				logger.Infof("expired-gen-stats: %d %d %.3f",
+					gen,
+					stats.expiredRegistrations,
+					float64(stats.expiredRegistrations)/epochDur*1000,
+				)
+			}
 		}
 	}()
 
@@ -239,6 +247,10 @@ func (s *RegistrationManager) PrintAndReset(logger *log.Logger) {
 		c,
 		float64(l)/float64(c)*100)
 
+	if pipeline := s.livenessPipeline.Load(); pipeline != nil {
+		pipeline.PrintAndReset(logger)
+	}
+
 	// this is done in func for lock / defer unlock without waiting for reset.
 	func() {
 		s.genMutex.RLock()
@@ -249,6 +261,13 @@ func (s *RegistrationManager) PrintAndReset(logger *log.Logger) {
 				stats.newRegistrations,
 				float64(stats.newRegistrations)/epochDur*1000,
 			)
+			if stats.expiredRegistrations > 0 {
+				logger.Infof("expired-gen-stats: %d %d %.3f",
+					gen,
+					stats.expiredRegistrations,
+					float64(stats.expiredRegistrations)/epochDur*1000,
+				)
+			}
 		}
 	}()
 
@@ -305,6 +324,17 @@ func (s *RegistrationStats) AddErrReg() {
 	atomic.AddInt64(&s.newErrRegistrations, 1)
 }
 
+// AddExpiredGenReg adds one to the count of registrations received this epoch for a generation
+// that has expired
+func (s *RegistrationStats) AddExpiredGenReg(gen uint32) {
+	s.genMutex.Lock()
+	defer s.genMutex.Unlock()
+	if stats, ok := s.generations[gen]; !ok || stats == nil {
+		s.generations[gen] = &generationStats{}
+	}
+	atomic.AddInt64(&s.generations[gen].expiredRegistrations, 1)
+}
+
 // AddBlocklistedPhantomReg adds one to the count of registrations that errored this epoch
 func (s *RegistrationStats) AddBlocklistedPhantomReg() {
 	atomic.AddInt64(&s.newBlocklistedPhantomReg, 1)
@@ -64,6 +64,41 @@ func TestRegistrationLookup(t *testing.T) {
 	}
 }
 
+// bucketTransport is a mock BucketingTransport that puts registrations in the bucket named by
+// their client library version.
+type bucketTransport struct {
+	mockTransport
+}
+
+func (*bucketTransport) GetBucket(d *DecoyRegistration) string {
+	return fmt.Sprint(d.ClientLibVersion())
+}
+
+func TestRegistrationBuckets(t *testing.T) {
+	os.Setenv("PHANTOM_SUBNET_LOCATION", "./test/phantom_subnets.toml")
+	rm := NewRegistrationManager(&RegConfig{})
+	err := rm.AddTransport(0, &bucketTransport{})
+	require.Nil(t, err)
+
+	c2s, keys := mockReceiveFromDetector()
+	regSource := pb.RegistrationSource_Detector
+	newReg, err := rm.NewRegistration(&c2s, &keys, c2s.GetV6Support(), &regSource)
+	require.Nil(t, err)
+	require.Equal(t, uint(1), newReg.ClientLibVersion())
+
+	// Registrations are only returned once they are valid.
+	err = rm.TrackRegistration(newReg)
+	require.Nil(t, err)
+	require.Len(t, rm.GetRegistrationsInBucket(newReg.PhantomIp, "1"), 0)
+	rm.AddRegistration(newReg)
+	require.Equal(t, []*DecoyRegistration{newReg}, rm.GetRegistrationsInBucket(newReg.PhantomIp, "1"))
+	require.Len(t, rm.GetRegistrationsInBucket(newReg.PhantomIp, "2"), 0)
+
+	rm.registeredDecoys.removeRegistration(newReg.IDString() + newReg.PhantomIp.String())
+	require.Len(t, rm.GetRegistrationsInBucket(newReg.PhantomIp, "1"), 0)
+	require.Len(t, rm.registeredDecoys.buckets, 0)
+}
+
 func TestRegisterForDetectorOnce(t *testing.T) {
 	if os.Getenv("TEST_REDIS") != "1" {
 		t.Skip("Skipping redis related test w/out mock")
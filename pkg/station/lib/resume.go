@@ -0,0 +1,450 @@
+package lib
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/resume"
+	"github.com/refraction-networking/conjure/pkg/station/log"
+)
+
+// Resumable sessions
+//
+// A registration whose ClientToStation sets upload_sync opts into resumable sessions: the
+// connection to the covert destination outlives the phantom connection it was opened for, and
+// a client that reconnects to the phantom with the same registration picks the covert stream up
+// where it left off. The handshake starting every phantom connection is described in package
+// resume. The session ends when the covert destination closes the connection, or when no phantom
+// connection is attached for ResumeTimeout. The station keeps the last ResumeBufferSize bytes of
+// downstream data; a client missing more than that can not resume and the session is closed.
+
+const defaultResumeTimeout = 2 * time.Minute
+
+var (
+	// ErrResumeMultiplex is returned for registrations asking for both resumable sessions and
+	// multiplexing, which can not be combined as a resumable session carries a single stream.
+	ErrResumeMultiplex = errors.New("upload_sync can not be combined with multiplexing")
+
+	// errResumeOffset is reported when a client asks to resume from downstream data the station
+	// no longer holds, or never sent.
+	errResumeOffset = errors.New("resume offset")
+
+	// errResumeClosed is reported when a client reconnects to a session that already closed.
+	errResumeClosed = errors.New("resume closed")
+
+	// errResumeReplaced is reported when a phantom connection is replaced by another before its
+	// handshake completes.
+	errResumeReplaced = errors.New("resume replaced")
+)
+
+// resumableSession holds the covert connection of a resumable registration and the state needed
+// to reattach it to a new phantom connection.
+type resumableSession struct {
+	reg      *DecoyRegistration
+	covert   net.Conn
+	throttle *tunnelThrottle
+	timeout  time.Duration
+
+	// upMu serializes writes to the covert connection, so that an upload from a replaced phantom
+	// connection can not interleave with the upload from its replacement.
+	upMu   sync.Mutex
+	upRecv uint64 // upstream bytes written to the covert connection
+
+	mu          sync.Mutex
+	attached    *sync.Cond
+	client      net.Conn       // phantom connection the session is attached to, nil if detached
+	clientStats *tunnelStats   // stats of the attached phantom connection
+	gen         uint64         // incremented on every attach
+	downBuf     *resume.Buffer // last downstream bytes read from the covert connection
+	closed      bool
+	detachTimer *time.Timer
+
+	// Errors of the covert connection, reported in the stats of the phantom connection attached
+	// when they happen.
+	covertErr     string
+	quotaExceeded bool
+}
+
+// resumeSession returns the open resumable session of reg, or opens a new one by dialing the
+// covert destination. The boolean result reports whether the session already existed.
+func (reg *DecoyRegistration) resumeSession(clientConn net.Conn, tunStats *tunnelStats, logger *log.Logger) (*resumableSession, bool, error) {
+	reg.resumeMu.Lock()
+	defer reg.resumeMu.Unlock()
+
+	if reg.resume != nil && !reg.resume.isClosed() {
+		return reg.resume, true, nil
+	}
+
+	if !acquireTunnel(reg, tunStats.proxyStats) {
+		return nil, false, errConnRefused
+	}
+	tunStats.RegTunnels = atomic.LoadInt64(&reg.activeTunnels)
+
+	throttle := newTunnelThrottle(reg)
+	if throttle.exhausted() {
+		releaseTunnel(reg, tunStats.proxyStats)
+		tunStats.QuotaExceeded = true
+		return nil, false, errConnRefused
+	}
+
+	covertConn, upstream, err := dialCovert(reg)
+	tunStats.Upstream = upstream
+	if err != nil {
+		releaseTunnel(reg, tunStats.proxyStats)
+		return nil, false, err
+	}
+
+	if reg.Flags.GetProxyHeader() {
+		version := getProxyConfig().proxyHeaderVersion(reg.Covert)
+		err = writePROXYHeader(covertConn, version, clientConn.RemoteAddr(), reg)
+		if err != nil {
+			logger.Errorf("failed to send PROXY header: %s", err)
+			covertConn.Close()
+			releaseTunnel(reg, tunStats.proxyStats)
+			return nil, false, err
+		}
+	}
+
+	conf := getProxyConfig()
+	bufSize := int(conf.ResumeBufferSize)
+	if bufSize <= 0 {
+		bufSize = resume.DefaultBufferSize
+	}
+	s := &resumableSession{
+		reg:      reg,
+		covert:   covertConn,
+		throttle: throttle,
+		timeout:  conf.resumeTimeout,
+		upRecv:   *reg.uploadSync,
+		downBuf:  resume.NewBuffer(bufSize, 0),
+	}
+	if s.timeout <= 0 {
+		s.timeout = defaultResumeTimeout
+	}
+	s.attached = sync.NewCond(&s.mu)
+	reg.resume = s
+
+	go s.download()
+	return s, false, nil
+}
+
+func (s *resumableSession) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// attach makes conn the phantom connection of the session, replacing any previous one. It
+// answers the handshake of the client, which received clientRecv downstream bytes, and resends
+// the downstream data the client is missing. It returns the generation of the attachment.
+//
+// Writes to conn are made without holding the session locks, so that a slow client does not hold
+// up the session, and are bounded by the handshake timeout.
+func (s *resumableSession) attach(conn net.Conn, clientRecv uint64, tunStats *tunnelStats) (uint64, error) {
+	// Stop uploads from the replaced phantom connection before reading the upstream position. upMu
+	// is taken before s.mu, so that an upload blocked on the covert connection only delays this
+	// attach and not the rest of the session.
+	s.upMu.Lock()
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		s.upMu.Unlock()
+		return 0, errResumeClosed
+	}
+	missing, err := s.downBuf.Since(clientRecv)
+	if err != nil {
+		s.closeLocked()
+		s.mu.Unlock()
+		s.upMu.Unlock()
+		return 0, errResumeOffset
+	}
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+		s.clientStats = nil
+	}
+	if s.detachTimer != nil {
+		s.detachTimer.Stop()
+		s.detachTimer = nil
+	}
+	s.gen++
+	gen := s.gen
+	reply := binary.BigEndian.AppendUint64(nil, s.upRecv)
+	s.mu.Unlock()
+	s.upMu.Unlock()
+
+	// No downstream data is read while no phantom connection is attached, but a read already in
+	// progress may add to what the client is missing while it is sent.
+	pos := clientRecv
+	reply = append(reply, missing...)
+	for {
+		pos += uint64(len(missing))
+		err := conn.SetWriteDeadline(time.Now().Add(resume.HandshakeTimeout))
+		if err == nil {
+			_, err = conn.Write(reply)
+		}
+
+		s.mu.Lock()
+		if err == nil && s.gen != gen {
+			err = errResumeReplaced
+		}
+		if err == nil && s.closed {
+			err = errResumeClosed
+		}
+		if err == nil {
+			missing, err = s.downBuf.Since(pos)
+			if err != nil {
+				s.closeLocked()
+				s.mu.Unlock()
+				return 0, errResumeOffset
+			}
+		}
+		if err != nil {
+			if s.gen == gen {
+				s.detachLocked()
+			}
+			s.mu.Unlock()
+			return 0, err
+		}
+		if len(missing) == 0 {
+			break
+		}
+		s.mu.Unlock()
+		reply = missing
+	}
+	defer s.mu.Unlock()
+
+	if err := conn.SetWriteDeadline(time.Time{}); err != nil {
+		s.detachLocked()
+		return 0, err
+	}
+	tunStats.BytesResent = int64(pos - clientRecv)
+	s.client = conn
+	s.clientStats = tunStats
+	s.attached.Broadcast()
+	return gen, nil
+}
+
+// detach detaches the phantom connection of generation gen and reports the covert connection
+// errors to its stats. If end is set the session is closed, otherwise it waits for the client to
+// reconnect.
+func (s *resumableSession) detach(gen uint64, end bool, tunStats *tunnelStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if tunStats != nil {
+		if tunStats.CovertConnErr == "" {
+			tunStats.CovertConnErr = s.covertErr
+		}
+		tunStats.QuotaExceeded = tunStats.QuotaExceeded || s.quotaExceeded
+	}
+	if s.gen != gen || s.closed {
+		return
+	}
+	if end {
+		s.closeLocked()
+		return
+	}
+	s.client = nil
+	s.clientStats = nil
+	s.detachLocked()
+}
+
+// detachLocked closes the session if no phantom connection attaches within the resume timeout.
+// The caller must hold s.mu.
+func (s *resumableSession) detachLocked() {
+	if s.detachTimer != nil {
+		return
+	}
+	gen := s.gen
+	s.detachTimer = time.AfterFunc(s.timeout, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.client == nil && s.gen == gen {
+			s.closeLocked()
+		}
+	})
+}
+
+// closeLocked closes the session and its connections. The caller must hold s.mu.
+func (s *resumableSession) closeLocked() {
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.covert.Close()
+	if s.client != nil {
+		s.client.Close()
+		s.client = nil
+	}
+	if s.detachTimer != nil {
+		s.detachTimer.Stop()
+	}
+	s.attached.Broadcast()
+	releaseTunnel(s.reg, getProxyStats())
+}
+
+// upload forwards upstream data from the phantom connection of generation gen to the covert
+// connection until reading from it fails. It reports whether the session must end because the
+// covert connection failed or the byte quota ran out.
+func (s *resumableSession) upload(conn net.Conn, gen uint64, tunStats *tunnelStats) bool {
+	buf := make([]byte, 32*1024)
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(proxyStallTimeout)); err != nil {
+			return false
+		}
+		nr, er := conn.Read(buf)
+		if nr > 0 {
+			var eq error
+			if s.throttle != nil {
+				nr, eq = s.throttle.allow(nr)
+				if waited := s.throttle.wait(nr); waited > 0 {
+					tunStats.throttled(int64(nr), waited, true)
+				}
+			}
+
+			s.upMu.Lock()
+			if s.gen != gen {
+				s.upMu.Unlock()
+				return false
+			}
+			nw, ew := s.covert.Write(buf[:nr])
+			s.upRecv += uint64(nw)
+			s.upMu.Unlock()
+
+			tunStats.addBytes(int64(nw), true)
+			Stat().AddBytesUp(int64(nw))
+			if ew != nil {
+				if e := generalizeErr(ew); e != nil {
+					tunStats.CovertConnErr = e.Error()
+				}
+				return true
+			}
+			if eq != nil {
+				tunStats.QuotaExceeded = true
+				return true
+			}
+		}
+		if er != nil {
+			if e := generalizeErr(er); e != nil {
+				tunStats.ClientConnErr = e.Error()
+			}
+			return false
+		}
+	}
+}
+
+// download forwards downstream data from the covert connection to the attached phantom
+// connection, keeping the last bytes for clients that reconnect, until the covert connection
+// closes. It only reads from the covert connection while a phantom connection is attached.
+func (s *resumableSession) download() {
+	buf := make([]byte, 32*1024)
+	for {
+		s.mu.Lock()
+		for s.client == nil && !s.closed {
+			s.attached.Wait()
+		}
+		closed := s.closed
+		s.mu.Unlock()
+		if closed {
+			return
+		}
+
+		nr, er := s.covert.Read(buf)
+		if nr > 0 {
+			quotaExceeded := false
+			if s.throttle != nil {
+				var eq error
+				nr, eq = s.throttle.allow(nr)
+				if eq != nil {
+					quotaExceeded = true
+					er = eq
+				}
+				s.throttle.wait(nr)
+			}
+
+			s.mu.Lock()
+			s.quotaExceeded = s.quotaExceeded || quotaExceeded
+			_, _ = s.downBuf.Write(buf[:nr])
+			client, stats, gen := s.client, s.clientStats, s.gen
+			s.mu.Unlock()
+
+			Stat().AddBytesDown(int64(nr))
+			if client != nil {
+				ew := client.SetDeadline(time.Now().Add(proxyStallTimeout))
+				if ew == nil {
+					_, ew = client.Write(buf[:nr])
+				}
+				if ew != nil {
+					// The data is kept for the client to get once it reconnects.
+					client.Close()
+					s.detach(gen, false, nil)
+				} else {
+					// The stats of a detached phantom connection may already be printed.
+					s.mu.Lock()
+					if s.gen == gen && s.client == client {
+						stats.addBytes(int64(nr), false)
+					}
+					s.mu.Unlock()
+				}
+			}
+		}
+		if er != nil {
+			s.mu.Lock()
+			if e := generalizeErr(er); e != nil && !errors.Is(er, errQuotaExceeded) {
+				s.covertErr = e.Error()
+			}
+			s.closeLocked()
+			s.mu.Unlock()
+			return
+		}
+	}
+}
+
+// proxyResumable proxies a phantom connection of a resumable registration, attaching it to the
+// covert connection of the registration's session.
+func proxyResumable(reg *DecoyRegistration, clientConn net.Conn, logger *log.Logger) {
+	tunStats := newTunnelStats(reg)
+
+	var hdr [resume.HandshakeLength]byte
+	err := clientConn.SetReadDeadline(time.Now().Add(resume.HandshakeTimeout))
+	if err == nil {
+		_, err = io.ReadFull(clientConn, hdr[:])
+	}
+	if err != nil {
+		if e := generalizeErr(err); e != nil {
+			tunStats.ClientConnErr = e.Error()
+		}
+		tunStats.Print(logger)
+		return
+	}
+
+	sess, resumed, err := reg.resumeSession(clientConn, tunStats, logger)
+	if err != nil {
+		if e := generalizeErr(err); e != nil {
+			tunStats.CovertDialErr = e.Error()
+		}
+		tunStats.Print(logger)
+		return
+	}
+	tunStats.Resumed = resumed
+
+	gen, err := sess.attach(clientConn, binary.BigEndian.Uint64(hdr[:]), tunStats)
+	if err != nil {
+		tunStats.ResumeErr = err.Error()
+		tunStats.Print(logger)
+		return
+	}
+
+	getProxyStats().addSession()
+	end := sess.upload(clientConn, gen, tunStats)
+	sess.detach(gen, end, tunStats)
+	getProxyStats().removeSession()
+
+	tunStats.Print(logger)
+}
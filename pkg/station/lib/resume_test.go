@@ -0,0 +1,220 @@
+package lib
+
+import (
+	"encoding/binary"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/refraction-networking/conjure/pkg/resume"
+	"github.com/refraction-networking/conjure/pkg/station/log"
+	pb "github.com/refraction-networking/conjure/proto"
+)
+
+// resumeConn opens a phantom connection for reg, having received downRecv downstream bytes, and
+// returns it with the number of upstream bytes the station has received.
+func resumeConn(t *testing.T, reg *DecoyRegistration, logger *log.Logger, downRecv uint64) (net.Conn, uint64, chan struct{}) {
+	clientConn, stationConn := net.Pipe()
+	done := make(chan struct{})
+	go func() {
+		Proxy(reg, stationConn, logger)
+		close(done)
+	}()
+
+	_, err := clientConn.Write(binary.BigEndian.AppendUint64(nil, downRecv))
+	require.Nil(t, err)
+	var reply [8]byte
+	_, err = io.ReadFull(clientConn, reply[:])
+	require.Nil(t, err)
+	return clientConn, binary.BigEndian.Uint64(reply[:]), done
+}
+
+func TestProxyResumable(t *testing.T) {
+	defer SetProxyConfig(nil)
+	conf := &ProxyConfig{ResumeTimeout: "100ms"}
+	require.Nil(t, conf.parse())
+	SetProxyConfig(conf)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+	var covertConns int32
+	serveUpstream(ln, func(c net.Conn) {
+		atomic.AddInt32(&covertConns, 1)
+		_, _ = io.Copy(c, c)
+	})
+
+	reg := testProxyReg(ln.Addr().String())
+	reg.uploadSync = proto.Uint64(0)
+	var logBuf syncBuffer
+	logger := log.New(&logBuf, "", 0)
+
+	conn, upRecv, done := resumeConn(t, reg, logger, 0)
+	require.Equal(t, uint64(0), upRecv)
+	_, err = conn.Write([]byte("hello"))
+	require.Nil(t, err)
+	got := make([]byte, 5)
+	_, err = io.ReadFull(conn, got)
+	require.Nil(t, err)
+	require.Equal(t, "hello", string(got))
+
+	// The phantom connection drops before the echo of the second write is read.
+	sent := []byte("helloworld")
+	_, err = conn.Write(sent[5:])
+	require.Nil(t, err)
+	conn.Close()
+	<-done
+
+	// The client reconnects, resends what the station missed and gets what it missed.
+	conn, upRecv, done = resumeConn(t, reg, logger, 5)
+	require.LessOrEqual(t, upRecv, uint64(len(sent)))
+	_, err = conn.Write(sent[upRecv:])
+	require.Nil(t, err)
+	_, err = io.ReadFull(conn, got)
+	require.Nil(t, err)
+	require.Equal(t, "world", string(got))
+	conn.Close()
+	<-done
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&covertConns))
+	require.Contains(t, logBuf.String(), `"Resumed":true`)
+
+	// Resuming from data the station never sent closes the session.
+	clientConn, stationConn := net.Pipe()
+	go func() {
+		// The caller of Proxy closes the phantom connection.
+		Proxy(reg, stationConn, logger)
+		stationConn.Close()
+	}()
+	_, err = clientConn.Write(binary.BigEndian.AppendUint64(nil, 1000))
+	require.Nil(t, err)
+	_, err = clientConn.Read(make([]byte, 1))
+	require.NotNil(t, err)
+	require.Eventually(t, func() bool { return strings.Contains(logBuf.String(), `"ResumeErr":"resume offset"`) },
+		time.Second, 10*time.Millisecond)
+}
+
+func TestProxyResumableTimeout(t *testing.T) {
+	defer SetProxyConfig(nil)
+	conf := &ProxyConfig{ResumeTimeout: "50ms"}
+	require.Nil(t, conf.parse())
+	SetProxyConfig(conf)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+	covertClosed := make(chan struct{})
+	serveUpstream(ln, func(c net.Conn) {
+		_, _ = io.Copy(io.Discard, c)
+		close(covertClosed)
+	})
+
+	reg := testProxyReg(ln.Addr().String())
+	reg.uploadSync = proto.Uint64(0)
+	conn, _, done := resumeConn(t, reg, log.New(io.Discard, "", 0), 0)
+	require.Equal(t, int64(1), atomic.LoadInt64(&reg.activeTunnels))
+	conn.Close()
+	<-done
+
+	// No phantom connection reattaches, so the covert connection is closed.
+	select {
+	case <-covertClosed:
+	case <-time.After(time.Second):
+		t.Fatal("covert connection not closed")
+	}
+	require.Eventually(t, func() bool { return atomic.LoadInt64(&reg.activeTunnels) == 0 }, time.Second, 10*time.Millisecond)
+
+	require.NotNil(t, (&ProxyConfig{ResumeTimeout: "soon"}).parse())
+}
+
+func TestProxyResumableClient(t *testing.T) {
+	defer SetProxyConfig(nil)
+	conf := &ProxyConfig{ResumeTimeout: "1s"}
+	require.Nil(t, conf.parse())
+	SetProxyConfig(conf)
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer ln.Close()
+	var covertConns int32
+	serveUpstream(ln, func(c net.Conn) {
+		atomic.AddInt32(&covertConns, 1)
+		_, _ = io.Copy(c, c)
+	})
+
+	reg := testProxyReg(ln.Addr().String())
+	reg.uploadSync = proto.Uint64(0)
+	logger := log.New(io.Discard, "", 0)
+
+	phantoms := make(chan net.Conn, 8)
+	dial := func() (net.Conn, error) {
+		clientConn, stationConn := net.Pipe()
+		go func() {
+			Proxy(reg, stationConn, logger)
+			stationConn.Close()
+		}()
+		phantoms <- clientConn
+		return clientConn, nil
+	}
+	conn, err := resume.Dial(dial, *reg.uploadSync, 0)
+	require.Nil(t, err)
+	defer conn.Close()
+
+	got := make([]byte, 5)
+	_, err = conn.Write([]byte("hello"))
+	require.Nil(t, err)
+	_, err = io.ReadFull(conn, got)
+	require.Nil(t, err)
+	require.Equal(t, "hello", string(got))
+
+	// The phantom connection drops, and the session is resumed over a new one by the next write.
+	(<-phantoms).Close()
+	_, err = conn.Write([]byte("world"))
+	require.Nil(t, err)
+	_, err = io.ReadFull(conn, got)
+	require.Nil(t, err)
+	require.Equal(t, "world", string(got))
+
+	// It drops again while reading, which resumes the session as well.
+	(<-phantoms).Close()
+	type result struct {
+		n   int
+		err error
+	}
+	read := make(chan result, 1)
+	go func() {
+		n, err := io.ReadFull(conn, got)
+		read <- result{n, err}
+	}()
+	_, err = conn.Write([]byte("again"))
+	require.Nil(t, err)
+	select {
+	case res := <-read:
+		require.Nil(t, res.err)
+		require.Equal(t, "again", string(got))
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for resumed read")
+	}
+	require.Len(t, phantoms, 1)
+	require.Equal(t, int32(1), atomic.LoadInt32(&covertConns))
+}
+
+func TestResumeMultiplexRejected(t *testing.T) {
+	os.Setenv("PHANTOM_SUBNET_LOCATION", "./test/phantom_subnets.toml")
+	rm := NewRegistrationManager(&RegConfig{})
+	require.Nil(t, rm.AddTransport(0, &mockTransport{}))
+
+	c2s, keys := mockReceiveFromDetector()
+	c2s.UploadSync = proto.Uint64(0)
+	c2s.Flags.Multiplex = proto.Bool(true)
+	regSource := pb.RegistrationSource_Detector
+	_, err := rm.NewRegistration(&c2s, &keys, false, &regSource)
+	require.ErrorIs(t, err, ErrResumeMultiplex)
+}
@@ -71,6 +71,18 @@ type WrappingTransport interface {
 	WrapConnection(data *bytes.Buffer, conn net.Conn, phantom net.IP, rm *RegistrationManager) (reg *DecoyRegistration, wrapped net.Conn, err error)
 }
 
+// BucketingTransport describes wrapping transports that can not look the registration of a
+// connection up by identifier, and instead check the connection against candidate registrations.
+// Registrations are also indexed by the bucket the transport puts them in, so that a connection
+// only has to be checked against the registrations of the bucket it names.
+type BucketingTransport interface {
+	WrappingTransport
+
+	// GetBucket returns the bucket of a registration. Registrations on the same phantom that share
+	// a bucket are returned together by RegistrationManager.GetRegistrationsInBucket.
+	GetBucket(*DecoyRegistration) string
+}
+
 // ConnectingTransport describes transports that actively form an outgoing connection to clients to
 // initiate the conversation.
 type ConnectingTransport interface {
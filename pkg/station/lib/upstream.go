@@ -0,0 +1,225 @@
+package lib
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// upstreamHandshakeTimeout bounds connecting to an upstream and its handshake, so that an
+// upstream that stops answering does not hold the tunnel open.
+var upstreamHandshakeTimeout = 10 * time.Second
+
+// errUpstream replaces errors from dialing through an upstream proxy, which may include the
+// covert address, when they are not one of the well known dial errors.
+var errUpstream = errors.New("upstream")
+
+// UpstreamConfig routes covert connections through an upstream proxy instead of dialing the
+// covert destination directly from the station.
+type UpstreamConfig struct {
+	// Patterns matched against the covert address (host:port) of registrations. Connections to
+	// matching covert destinations are routed through this upstream.
+	Destinations []string `toml:"destinations"`
+
+	// URL of the upstream. One of:
+	//   socks5://[user:pass@]host:port - SOCKS5 proxy
+	//   http://[user:pass@]host:port   - HTTP proxy supporting CONNECT
+	//   unix:///path/to/socket         - Unix socket bridge, which receives the tunnel itself in
+	//                                    place of the covert destination
+	URL string `toml:"url"`
+
+	destinations []*regexp.Regexp
+	dialer       proxy.ContextDialer
+	scheme       string
+}
+
+func (u *UpstreamConfig) parse() error {
+	u.destinations = []*regexp.Regexp{}
+	for _, pattern := range u.Destinations {
+		r, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("bad upstream destination %q: %w", pattern, err)
+		}
+		u.destinations = append(u.destinations, r)
+	}
+
+	target, err := url.Parse(u.URL)
+	if err != nil {
+		return fmt.Errorf("bad upstream url: %w", err)
+	}
+	u.scheme = target.Scheme
+
+	switch target.Scheme {
+	case "socks5":
+		var auth *proxy.Auth
+		if target.User != nil {
+			password, _ := target.User.Password()
+			auth = &proxy.Auth{User: target.User.Username(), Password: password}
+		}
+		dialer, err := proxy.SOCKS5("tcp", target.Host, auth, proxy.Direct)
+		if err != nil {
+			return fmt.Errorf("bad upstream url: %w", err)
+		}
+		u.dialer = dialer.(proxy.ContextDialer)
+	case "http":
+		u.dialer = &httpConnectDialer{addr: target.Host, user: target.User}
+	case "unix":
+		if target.Path == "" {
+			return fmt.Errorf("bad upstream url: missing socket path")
+		}
+		u.dialer = &unixBridgeDialer{path: target.Path}
+	default:
+		return fmt.Errorf("bad upstream url: unsupported scheme %q", target.Scheme)
+	}
+	return nil
+}
+
+func (u *UpstreamConfig) matches(covert string) bool {
+	for _, r := range u.destinations {
+		if r.MatchString(covert) {
+			return true
+		}
+	}
+	return false
+}
+
+// covertDialer returns the dialer for the covert address covert and the scheme of the upstream
+// it goes through. The first upstream with a matching pattern is used. If there is none the
+// covert destination is dialed directly and the scheme is empty.
+func (c *ProxyConfig) covertDialer(covert string) (proxy.ContextDialer, string) {
+	for _, u := range c.Upstreams {
+		if u.dialer != nil && u.matches(covert) {
+			return u.dialer, u.scheme
+		}
+	}
+	return proxy.Direct, ""
+}
+
+// dialCovert opens the connection to the covert destination of reg, through an upstream proxy
+// if one is configured for it.
+func dialCovert(reg *DecoyRegistration) (net.Conn, string, error) {
+	dialer, upstream := getProxyConfig().covertDialer(reg.Covert)
+	if upstream == "" {
+		conn, err := dialer.DialContext(context.Background(), "tcp", reg.Covert)
+		return conn, upstream, err
+	}
+
+	// Every upstream dialer sets the deadline of the context on the connection during its
+	// handshake and clears it afterwards.
+	ctx, cancel := context.WithTimeout(context.Background(), upstreamHandshakeTimeout)
+	defer cancel()
+	conn, err := dialer.DialContext(ctx, "tcp", reg.Covert)
+	if err != nil {
+		if generalizeErr(err) == nil {
+			// The upstream closed the connection during its handshake.
+			err = errUpstream
+		} else {
+			err = fmt.Errorf("%w: %w", errUpstream, err)
+		}
+	}
+	return conn, upstream, err
+}
+
+// httpConnectDialer dials through an HTTP proxy using the CONNECT method.
+type httpConnectDialer struct {
+	addr string
+	user *url.Userinfo
+}
+
+func (d *httpConnectDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+// DialContext implements proxy.ContextDialer. The deadline of ctx applies to the connection until
+// the proxy answered the CONNECT request.
+func (d *httpConnectDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := (&net.Dialer{}).DialContext(ctx, network, d.addr)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if d.user != nil {
+		password, _ := d.user.Password()
+		creds := base64.StdEncoding.EncodeToString([]byte(d.user.Username() + ":" + password))
+		req.Header.Set("Proxy-Authorization", "Basic "+creds)
+	}
+	if err = req.Write(conn); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, req)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		conn.Close()
+		return nil, fmt.Errorf("connect: %s", resp.Status)
+	}
+	if err = conn.SetDeadline(time.Time{}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	if br.Buffered() > 0 {
+		return &bufferedConn{Conn: conn, r: br}, nil
+	}
+	return conn, nil
+}
+
+// bufferedConn is a net.Conn whose reads are served from r, which may hold data already read
+// from the connection.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// Split returns the data buffered but not yet read and the underlying connection.
+func (c *bufferedConn) Split() (io.Reader, net.Conn) {
+	return io.LimitReader(c.r, int64(c.r.Buffered())), c.Conn
+}
+
+// unixBridgeDialer connects to a bridge listening on a Unix socket. The bridge handles the tunnel
+// itself, so the covert address is only used to select it.
+type unixBridgeDialer struct {
+	path string
+}
+
+func (d *unixBridgeDialer) Dial(network, addr string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, addr)
+}
+
+// DialContext implements proxy.ContextDialer. The bridge has no handshake, ctx only bounds
+// connecting to it.
+func (d *unixBridgeDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	return (&net.Dialer{}).DialContext(ctx, "unix", d.path)
+}
@@ -0,0 +1,218 @@
+package lib
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/refraction-networking/conjure/pkg/station/log"
+)
+
+// serveUpstream accepts connections on ln and hands each one to handle.
+func serveUpstream(ln net.Listener, handle func(net.Conn)) {
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer c.Close()
+				handle(c)
+			}()
+		}
+	}()
+}
+
+// echoUpstream writes the target it was asked for followed by everything it reads.
+func echoUpstream(c net.Conn, target string) {
+	_, _ = c.Write([]byte(target + "\n"))
+	_, _ = io.Copy(c, c)
+}
+
+func fakeHTTPConnect(t *testing.T) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	serveUpstream(ln, func(c net.Conn) {
+		req, err := http.ReadRequest(bufio.NewReader(c))
+		if err != nil || req.Method != http.MethodConnect {
+			return
+		}
+		if req.Header.Get("Proxy-Authorization") != "Basic dXNlcjpwYXNz" {
+			_, _ = c.Write([]byte("HTTP/1.1 407 Proxy Authentication Required\r\n\r\n"))
+			return
+		}
+		_, _ = c.Write([]byte("HTTP/1.1 200 OK\r\n\r\n"))
+		echoUpstream(c, req.Host)
+	})
+	return ln
+}
+
+func fakeSOCKS5(t *testing.T) net.Listener {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	serveUpstream(ln, func(c net.Conn) {
+		// Greeting: version, methods. Accept without authentication.
+		hdr := make([]byte, 2)
+		if _, err := io.ReadFull(c, hdr); err != nil {
+			return
+		}
+		if _, err := io.ReadFull(c, make([]byte, hdr[1])); err != nil {
+			return
+		}
+		_, _ = c.Write([]byte{0x05, 0x00})
+
+		// Request: version, command, reserved, address type, address, port.
+		req := make([]byte, 4)
+		if _, err := io.ReadFull(c, req); err != nil || req[3] != 0x01 {
+			return
+		}
+		addr := make([]byte, 6)
+		if _, err := io.ReadFull(c, addr); err != nil {
+			return
+		}
+		_, _ = c.Write([]byte{0x05, 0x00, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+
+		port := strconv.Itoa(int(binary.BigEndian.Uint16(addr[4:])))
+		echoUpstream(c, net.JoinHostPort(net.IP(addr[:4]).String(), port))
+	})
+	return ln
+}
+
+func dialAndEcho(t *testing.T, covert string) (string, string) {
+	conn, upstream, err := dialCovert(&DecoyRegistration{Covert: covert})
+	require.Nil(t, err)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("ping"))
+	require.Nil(t, err)
+	r := bufio.NewReader(conn)
+	target, err := r.ReadString('\n')
+	require.Nil(t, err)
+	echo := make([]byte, 4)
+	_, err = io.ReadFull(r, echo)
+	require.Nil(t, err)
+	require.Equal(t, "ping", string(echo))
+	return target[:len(target)-1], upstream
+}
+
+func TestUpstreamDial(t *testing.T) {
+	defer SetProxyConfig(nil)
+
+	httpLn := fakeHTTPConnect(t)
+	defer httpLn.Close()
+	socksLn := fakeSOCKS5(t)
+	defer socksLn.Close()
+
+	socketPath := filepath.Join(t.TempDir(), "bridge.sock")
+	unixLn, err := net.Listen("unix", socketPath)
+	require.Nil(t, err)
+	defer unixLn.Close()
+	serveUpstream(unixLn, func(c net.Conn) { echoUpstream(c, "bridge") })
+
+	conf := &ProxyConfig{Upstreams: []UpstreamConfig{
+		{Destinations: []string{`^192\.0\.2\.1:`}, URL: "http://user:pass@" + httpLn.Addr().String()},
+		{Destinations: []string{`^192\.0\.2\.2:`}, URL: "socks5://" + socksLn.Addr().String()},
+		{Destinations: []string{`^192\.0\.2\.3:`}, URL: "unix://" + socketPath},
+	}}
+	require.Nil(t, conf.parse())
+	SetProxyConfig(conf)
+
+	target, upstream := dialAndEcho(t, "192.0.2.1:443")
+	require.Equal(t, "192.0.2.1:443", target)
+	require.Equal(t, "http", upstream)
+
+	target, upstream = dialAndEcho(t, "192.0.2.2:80")
+	require.Equal(t, "192.0.2.2:80", target)
+	require.Equal(t, "socks5", upstream)
+
+	target, upstream = dialAndEcho(t, "192.0.2.3:22")
+	require.Equal(t, "bridge", target)
+	require.Equal(t, "unix", upstream)
+
+	_, upstream = conf.covertDialer("192.0.2.4:443")
+	require.Equal(t, "", upstream)
+}
+
+func TestUpstreamHandshakeTimeout(t *testing.T) {
+	defer SetProxyConfig(nil)
+	defer func(timeout time.Duration) { upstreamHandshakeTimeout = timeout }(upstreamHandshakeTimeout)
+	upstreamHandshakeTimeout = 100 * time.Millisecond
+
+	httpLn := fakeHTTPConnect(t)
+	defer httpLn.Close()
+	socksLn := fakeSOCKS5(t)
+	defer socksLn.Close()
+	silentLn, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer silentLn.Close()
+	serveUpstream(silentLn, func(c net.Conn) { _, _ = io.Copy(io.Discard, c) })
+
+	conf := &ProxyConfig{Upstreams: []UpstreamConfig{
+		{Destinations: []string{`^192\.0\.2\.1:`}, URL: "http://user:pass@" + httpLn.Addr().String()},
+		{Destinations: []string{`^192\.0\.2\.2:`}, URL: "socks5://" + socksLn.Addr().String()},
+		{Destinations: []string{`^192\.0\.2\.3:`}, URL: "http://" + silentLn.Addr().String()},
+		{Destinations: []string{`^192\.0\.2\.4:`}, URL: "socks5://" + silentLn.Addr().String()},
+	}}
+	require.Nil(t, conf.parse())
+	SetProxyConfig(conf)
+
+	// An upstream that never answers its handshake times out.
+	for _, covert := range []string{"192.0.2.3:443", "192.0.2.4:443"} {
+		start := time.Now()
+		_, _, err := dialCovert(&DecoyRegistration{Covert: covert})
+		require.ErrorIs(t, err, errUpstream)
+		require.Less(t, time.Since(start), 2*time.Second)
+	}
+
+	// The deadline is cleared once the handshake is done.
+	for _, covert := range []string{"192.0.2.1:443", "192.0.2.2:443"} {
+		conn, _, err := dialCovert(&DecoyRegistration{Covert: covert})
+		require.Nil(t, err)
+		time.Sleep(2 * upstreamHandshakeTimeout)
+		_, err = conn.Write([]byte("ping"))
+		require.Nil(t, err)
+		target, err := bufio.NewReader(conn).ReadString('\n')
+		require.Nil(t, err)
+		require.Equal(t, covert+"\n", target)
+		conn.Close()
+	}
+}
+
+func TestUpstreamDialErrors(t *testing.T) {
+	defer SetProxyConfig(nil)
+
+	httpLn := fakeHTTPConnect(t)
+	defer httpLn.Close()
+
+	conf := &ProxyConfig{Upstreams: []UpstreamConfig{
+		{Destinations: []string{`.*`}, URL: "http://" + httpLn.Addr().String()},
+	}}
+	require.Nil(t, conf.parse())
+	SetProxyConfig(conf)
+
+	// Failures through an upstream are logged without the covert address.
+	var logBuf bytes.Buffer
+	client, _ := net.Pipe()
+	Proxy(testProxyReg("192.0.2.1:443"), client, log.New(&logBuf, "", 0))
+	require.Contains(t, logBuf.String(), `"CovertDialErr":"upstream"`)
+	require.Contains(t, logBuf.String(), `"Upstream":"http"`)
+	require.NotContains(t, logBuf.String(), "192.0.2.1:443")
+
+	for _, bad := range []UpstreamConfig{
+		{URL: "ftp://127.0.0.1:21"},
+		{URL: "unix://"},
+		{URL: "http://127.0.0.1:8080", Destinations: []string{"("}},
+	} {
+		require.NotNil(t, bad.parse(), bad.URL)
+	}
+}
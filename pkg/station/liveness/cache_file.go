@@ -0,0 +1,176 @@
+package liveness
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/station/log"
+)
+
+// The cache file has one phantom per line, `<address> <live|nonlive> <time tested>` with the
+// time in RFC 3339 format. Blank lines and lines starting with # are ignored.
+const (
+	cacheFileHeader  = "# conjure liveness cache: <address> <live|nonlive> <time tested>"
+	cacheFileLive    = "live"
+	cacheFileNonLive = "nonlive"
+)
+
+// Persister is implemented by testers whose results can be saved across restarts.
+type Persister interface {
+	// Save writes the results of the tester to the configured cache file, if any.
+	Save() error
+}
+
+// Export writes the unexpired entries of the caches to w in the cache file format.
+func (blt *CachedLivenessTester) Export(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if _, err := fmt.Fprintln(bw, cacheFileHeader); err != nil {
+		return err
+	}
+
+	for _, c := range []struct {
+		cache  cache
+		status string
+	}{
+		{blt.ipCacheLive, cacheFileLive},
+		{blt.ipCacheLiveV6, cacheFileLive},
+		{blt.ipCacheNonLive, cacheFileNonLive},
+		{blt.ipCacheNonLiveV6, cacheFileNonLive},
+	} {
+		if c.cache == nil {
+			continue
+		}
+		entries := c.cache.Entries()
+		addrs := make([]string, 0, len(entries))
+		for addr := range entries {
+			addrs = append(addrs, addr)
+		}
+		sort.Strings(addrs)
+
+		for _, addr := range addrs {
+			if _, err := fmt.Fprintf(bw, "%s %s %s\n", addr, c.status, entries[addr].cachedTime.UTC().Format(time.RFC3339)); err != nil {
+				return err
+			}
+		}
+	}
+	return bw.Flush()
+}
+
+// Import adds the entries of a cache file read from r to the caches, keeping the time they were
+// tested so that they expire as they would have on the station that tested them. With liveOnly
+// non-live entries are skipped. Entries for which no cache is configured, or that have already
+// expired, are dropped. Malformed lines are logged and skipped. Import returns the number of
+// entries added and the number of malformed lines.
+func (blt *CachedLivenessTester) Import(r io.Reader, liveOnly bool) (int, int, error) {
+	added, skipped := 0, 0
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		c, addr, tested, err := blt.parseCacheLine(line, liveOnly)
+		if err != nil {
+			log.Warnf("liveness cache line %d: %v, skipping", n, err)
+			skipped++
+			continue
+		} else if c == nil {
+			continue
+		}
+
+		c.Add(addr, &cacheElement{cachedTime: tested})
+		added++
+	}
+	if err := scanner.Err(); err != nil {
+		return added, skipped, err
+	}
+
+	// drop the entries that expired before they were imported.
+	blt.ClearExpiredCache()
+	return added, skipped, nil
+}
+
+// parseCacheLine parses one line of a cache file, returning the cache the entry belongs in, or nil
+// if it is not imported.
+func (blt *CachedLivenessTester) parseCacheLine(line string, liveOnly bool) (cache, string, time.Time, error) {
+	fields := strings.Fields(line)
+	if len(fields) != 3 {
+		return nil, "", time.Time{}, fmt.Errorf("expected 3 fields, got %d", len(fields))
+	}
+	addr, status := fields[0], fields[1]
+	if net.ParseIP(addr) == nil {
+		return nil, "", time.Time{}, fmt.Errorf("bad address %q", addr)
+	}
+	tested, err := time.Parse(time.RFC3339, fields[2])
+	if err != nil {
+		return nil, "", time.Time{}, fmt.Errorf("bad time %q", fields[2])
+	}
+
+	ipCacheLive, ipCacheNonLive, _ := blt.cachesFor(addr)
+	switch status {
+	case cacheFileLive:
+		return ipCacheLive, addr, tested, nil
+	case cacheFileNonLive:
+		if liveOnly {
+			return nil, addr, tested, nil
+		}
+		return ipCacheNonLive, addr, tested, nil
+	default:
+		return nil, "", time.Time{}, fmt.Errorf("bad status %q", status)
+	}
+}
+
+// Save implements Persister, writing the caches to the cache path of the config. The file is
+// replaced atomically so that a station stopped while saving keeps the previous file.
+func (blt *CachedLivenessTester) Save() error {
+	if blt.cachePath == "" {
+		return nil
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(blt.cachePath), filepath.Base(blt.cachePath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := blt.Export(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), blt.cachePath)
+}
+
+// loadCacheFile imports the cache file at path. A missing file is not an error when it is the
+// cache path of the station itself, as it does not exist before the first shutdown.
+func (blt *CachedLivenessTester) loadCacheFile(path string, liveOnly bool, allowMissing bool) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) && allowMissing {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, skipped, err := blt.Import(f, liveOnly)
+	if skipped > 0 {
+		log.Warnf("liveness cache %s: skipped %d malformed lines", path, skipped)
+		atomic.AddInt64(&blt.cacheLinesSkipped, int64(skipped))
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
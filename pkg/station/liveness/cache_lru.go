@@ -95,6 +95,19 @@ func (lc *lruCache) Add(key string, elem *cacheElement) {
 	lc.lru.Add(key, struct{}{})
 }
 
+func (lc *lruCache) Entries() map[string]*cacheElement {
+	lc.m.RLock()
+	defer lc.m.RUnlock()
+
+	entries := make(map[string]*cacheElement, len(lc.ipCache))
+	for key, elem := range lc.ipCache {
+		if time.Since(elem.cachedTime) < lc.expiration {
+			entries[key] = elem
+		}
+	}
+	return entries
+}
+
 func (lc *lruCache) Len() int {
 	lc.m.RLock()
 	defer lc.m.RUnlock()
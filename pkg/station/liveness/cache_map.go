@@ -53,6 +53,19 @@ func (m *mapCache) Add(key string, elem *cacheElement) {
 	}
 }
 
+func (m *mapCache) Entries() map[string]*cacheElement {
+	m.m.RLock()
+	defer m.m.RUnlock()
+
+	entries := make(map[string]*cacheElement, len(m.ipCache))
+	for key, elem := range m.ipCache {
+		if time.Since(elem.cachedTime) < m.expiration {
+			entries[key] = elem
+		}
+	}
+	return entries
+}
+
 func (m *mapCache) Len() int {
 	m.m.RLock()
 	defer m.m.RUnlock()
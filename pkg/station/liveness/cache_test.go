@@ -1,6 +1,9 @@
 package liveness
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -144,3 +147,79 @@ func TestLivenessCacheLookupTimedOut(t *testing.T) {
 	ok = lru.Lookup("key1")
 	require.False(t, ok)
 }
+
+func TestLivenessCacheFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "liveness_cache")
+	conf := &Config{CacheDuration: "1h", CacheDurationNonLive: "5m", CachePath: path}
+
+	clt := &CachedLivenessTester{stats: &stats{}}
+	require.Nil(t, clt.Init(conf))
+	clt.ipCacheLive.Add("192.0.2.1", &cacheElement{cachedTime: time.Now()})
+	clt.ipCacheLive.Add("192.0.2.2", &cacheElement{cachedTime: time.Now().Add(-2 * time.Hour)})
+	clt.ipCacheNonLive.Add("192.0.2.3", &cacheElement{cachedTime: time.Now()})
+	require.Nil(t, clt.Save())
+
+	// Expired entries are not saved.
+	data, err := os.ReadFile(path)
+	require.Nil(t, err)
+	require.Contains(t, string(data), "192.0.2.1 live ")
+	require.Contains(t, string(data), "192.0.2.3 nonlive ")
+	require.NotContains(t, string(data), "192.0.2.2")
+
+	// A restarted station reloads both live and non-live results.
+	restarted := &CachedLivenessTester{stats: &stats{}}
+	require.Nil(t, restarted.Init(conf))
+	require.True(t, restarted.ipCacheLive.Lookup("192.0.2.1"))
+	require.True(t, restarted.ipCacheNonLive.Lookup("192.0.2.3"))
+
+	// Other stations only import the live results, which keep their test time.
+	seeded := &CachedLivenessTester{stats: &stats{}}
+	require.Nil(t, seeded.Init(&Config{CacheDuration: "1h", CacheDurationNonLive: "5m", CacheSeedPaths: []string{path}}))
+	require.True(t, seeded.ipCacheLive.Lookup("192.0.2.1"))
+	require.Equal(t, 0, seeded.ipCacheNonLive.Len())
+
+	short := &CachedLivenessTester{stats: &stats{}}
+	require.Nil(t, short.Init(&Config{CacheDuration: "1ns", CacheSeedPaths: []string{path}}))
+	require.Equal(t, 0, short.ipCacheLive.Len())
+
+	// A missing cache path is expected before the first shutdown, and seeds are optional.
+	fresh := &CachedLivenessTester{stats: &stats{}}
+	require.Nil(t, fresh.Init(&Config{CacheDuration: "1h", CachePath: path + ".missing"}))
+	require.Nil(t, fresh.Init(&Config{CacheDuration: "1h", CacheSeedPaths: []string{path + ".missing", path}}))
+	require.True(t, fresh.ipCacheLive.Lookup("192.0.2.1"))
+
+	// Malformed lines and unreadable files are skipped and counted.
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad")
+	require.Nil(t, os.WriteFile(bad, append([]byte("not a cache\n"), data...), 0o600))
+	corrupt := &CachedLivenessTester{stats: &stats{}}
+	require.Nil(t, corrupt.Init(&Config{CacheDuration: "1h", CachePath: bad, CacheSeedPaths: []string{dir, bad}}))
+	require.True(t, corrupt.ipCacheLive.Lookup("192.0.2.1"))
+	require.Equal(t, int64(2), corrupt.cacheLinesSkipped)
+	require.Equal(t, int64(1), corrupt.cacheFilesSkipped)
+}
+
+func TestLivenessCacheImport(t *testing.T) {
+	clt := &CachedLivenessTester{stats: &stats{}}
+	require.Nil(t, clt.Init(&Config{CacheDuration: "1h", CacheDurationNonLive: "5m", EnableV6: true}))
+
+	tested := time.Now().UTC().Format(time.RFC3339)
+	n, skipped, err := clt.Import(strings.NewReader("# comment\n\n192.0.2.1 live "+tested+"\n2001:db8::1 nonlive "+tested+"\n"), false)
+	require.Nil(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, 0, skipped)
+	require.True(t, clt.ipCacheLive.Lookup("192.0.2.1"))
+	require.True(t, clt.ipCacheNonLiveV6.Lookup("2001:db8::1"))
+
+	for _, bad := range []string{
+		"192.0.2.1 live",
+		"192.0.2 live " + tested,
+		"192.0.2.1 maybe " + tested,
+		"192.0.2.1 live yesterday",
+	} {
+		n, skipped, err := clt.Import(strings.NewReader(bad+"\n192.0.2.4 live "+tested), false)
+		require.Nil(t, err, bad)
+		require.Equal(t, 1, n, bad)
+		require.Equal(t, 1, skipped, bad)
+	}
+}
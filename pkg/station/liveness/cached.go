@@ -3,8 +3,6 @@ package liveness
 import (
 	"fmt"
 	"math"
-	"net"
-	"strconv"
 	"sync/atomic"
 	"time"
 
@@ -18,7 +16,22 @@ type CachedLivenessTester struct {
 	ipCacheLive    cache
 	ipCacheNonLive cache
 	signal         chan bool
+	probes         *ProbeSet
 	*stats
+
+	// IPv6 phantoms are cached and counted separately when IPv6 liveness
+	// testing is enabled, statsV6 is nil otherwise.
+	ipCacheLiveV6    cache
+	ipCacheNonLiveV6 cache
+	statsV6          *stats
+
+	// cachePath is the file the caches are loaded from and saved to, if set.
+	cachePath string
+
+	// cacheLinesSkipped and cacheFilesSkipped count the malformed lines and the unreadable files
+	// skipped while loading the cache and seed files.
+	cacheLinesSkipped int64
+	cacheFilesSkipped int64
 }
 
 // Init parses cache expiry duration and initializes the Cache.
@@ -33,11 +46,9 @@ func (blt *CachedLivenessTester) Init(conf *Config) error {
 			return fmt.Errorf("unable to parse cacheExpirationLive: %s", err)
 		}
 
-		if conf.CacheCapacity != 0 {
-			blt.ipCacheLive = newLRUCache(convertedTime, conf.CacheCapacity)
-
-		} else {
-			blt.ipCacheLive = newMapCache(convertedTime)
+		blt.ipCacheLive = newCache(convertedTime, conf.CacheCapacity)
+		if conf.EnableV6 {
+			blt.ipCacheLiveV6 = newCache(convertedTime, conf.CacheCapacityV6)
 		}
 	}
 
@@ -47,19 +58,51 @@ func (blt *CachedLivenessTester) Init(conf *Config) error {
 			return fmt.Errorf("unable to parse cacheExpirationNonLive: %s", err)
 		}
 
-		if conf.CacheCapacity != 0 {
-			blt.ipCacheNonLive = newLRUCache(convertedTime, conf.CacheCapacityNonLive)
-
-		} else {
-			blt.ipCacheNonLive = newMapCache(convertedTime)
+		blt.ipCacheNonLive = newCache(convertedTime, conf.CacheCapacityNonLive)
+		if conf.EnableV6 {
+			blt.ipCacheNonLiveV6 = newCache(convertedTime, conf.CacheCapacityNonLiveV6)
 		}
 	}
 
+	probes, err := probeSetFromConfig(conf)
+	if err != nil {
+		return err
+	}
+	blt.probes = probes
+
+	if conf.EnableV6 && blt.statsV6 == nil {
+		blt.statsV6 = &stats{}
+	}
+
 	blt.signal = make(chan bool)
 
+	// The cache files only save probes, a station that can not read them starts without them.
+	blt.cachePath = conf.CachePath
+	if blt.cachePath != "" {
+		if err := blt.loadCacheFile(blt.cachePath, false, true); err != nil {
+			log.Warnf("unable to load liveness cache, skipping: %v", err)
+			atomic.AddInt64(&blt.cacheFilesSkipped, 1)
+		}
+	}
+	for _, path := range conf.CacheSeedPaths {
+		if err := blt.loadCacheFile(path, true, true); err != nil {
+			log.Warnf("unable to load liveness cache seed, skipping: %v", err)
+			atomic.AddInt64(&blt.cacheFilesSkipped, 1)
+		}
+	}
+
 	return nil
 }
 
+// newCache returns an LRU cache of capacity entries, or a map of unlimited
+// capacity if capacity is 0.
+func newCache(expiration time.Duration, capacity int) cache {
+	if capacity != 0 {
+		return newLRUCache(expiration, capacity)
+	}
+	return newMapCache(expiration)
+}
+
 // Stop end periodic scanning using running in separate goroutine. If periodic
 // scanning is not running this will do nothing.
 func (blt *CachedLivenessTester) Stop() {
@@ -75,6 +118,23 @@ func (blt *CachedLivenessTester) ClearExpiredCache() {
 	if blt.ipCacheNonLive != nil {
 		blt.ipCacheNonLive.ClearExpired()
 	}
+
+	if blt.ipCacheLiveV6 != nil {
+		blt.ipCacheLiveV6.ClearExpired()
+	}
+
+	if blt.ipCacheNonLiveV6 != nil {
+		blt.ipCacheNonLiveV6.ClearExpired()
+	}
+}
+
+// cachesFor returns the caches and stats used for addr, which are separate for
+// IPv6 phantoms when IPv6 liveness testing is enabled.
+func (blt *CachedLivenessTester) cachesFor(addr string) (cache, cache, *stats) {
+	if blt.statsV6 != nil && isV6(addr) {
+		return blt.ipCacheLiveV6, blt.ipCacheNonLiveV6, blt.statsV6
+	}
+	return blt.ipCacheLive, blt.ipCacheNonLive, blt.stats
 }
 
 // PhantomIsLive first checks the cached set of addresses for a fresh entry. If
@@ -86,15 +146,12 @@ func (blt *CachedLivenessTester) ClearExpiredCache() {
 // Lock on mutex is taken for lookup, then for cache update. Do NOT hold mutex
 // while scanning for liveness as this will make cache extremely slow.
 func (blt *CachedLivenessTester) PhantomIsLive(addr string, port uint16) (bool, error) {
-	// cache lookup internal function to use RLock
-	if live, err := blt.phantomLookup(addr, port); live || err != nil {
-		// add to stats
-		blt.stats.incCached(live)
+	if live, err := blt.cachedResult(addr); err != nil {
 		return live, err
 	}
+	ipCacheLive, ipCacheNonLive, s := blt.cachesFor(addr)
 
-	// existing phantomIsLive() implementation
-	isLive, err := phantomIsLive(net.JoinHostPort(addr, strconv.Itoa(int(port))))
+	isLive, err := phantomIsLive(blt.probes, addr, port)
 
 	var val = &cacheElement{
 		cachedTime: time.Now(),
@@ -102,19 +159,19 @@ func (blt *CachedLivenessTester) PhantomIsLive(addr string, port uint16) (bool,
 
 	if isLive {
 		// add to stats
-		blt.stats.incFail()
+		s.incFail()
 
 		// Add to cache if enabled
-		if blt.ipCacheLive != nil {
-			blt.ipCacheLive.Add(addr, val)
+		if ipCacheLive != nil {
+			ipCacheLive.Add(addr, val)
 		}
 	} else {
 		// add to stats
-		blt.stats.incPass()
+		s.incPass()
 
 		// Add to cache if enabled
-		if blt.ipCacheNonLive != nil {
-			blt.ipCacheNonLive.Add(addr, val)
+		if ipCacheNonLive != nil {
+			ipCacheNonLive.Add(addr, val)
 
 		}
 	}
@@ -122,15 +179,28 @@ func (blt *CachedLivenessTester) PhantomIsLive(addr string, port uint16) (bool,
 	return isLive, err
 }
 
-func (blt *CachedLivenessTester) phantomLookup(addr string, port uint16) (bool, error) {
-	if blt.ipCacheLive != nil {
-		if ok := blt.ipCacheLive.Lookup(addr); ok {
+// cachedResult implements the cachedTester interface.
+func (blt *CachedLivenessTester) cachedResult(addr string) (bool, error) {
+	ipCacheLive, ipCacheNonLive, s := blt.cachesFor(addr)
+
+	// cache lookup internal function to use RLock
+	live, err := phantomLookup(ipCacheLive, ipCacheNonLive, addr)
+	if err != nil {
+		// add to stats
+		s.incCached(live)
+	}
+	return live, err
+}
+
+func phantomLookup(ipCacheLive, ipCacheNonLive cache, addr string) (bool, error) {
+	if ipCacheLive != nil {
+		if ok := ipCacheLive.Lookup(addr); ok {
 			return true, ErrCachedPhantom
 		}
 	}
 
-	if blt.ipCacheNonLive != nil {
-		if ok := blt.ipCacheNonLive.Lookup(addr); ok {
+	if ipCacheNonLive != nil {
+		if ok := ipCacheNonLive.Lookup(addr); ok {
 			return false, ErrCachedPhantom
 		}
 	}
@@ -147,12 +217,40 @@ func (blt *CachedLivenessTester) PrintStats(logger *log.Logger) {
 // to add logging for the cache capacity
 func (blt *CachedLivenessTester) PrintAndReset(logger *log.Logger) {
 	blt.printStats(logger)
+	blt.Reset()
+}
+
+// Reset implements the Stats interface
+func (blt *CachedLivenessTester) Reset() {
 	blt.stats.Reset()
+	if blt.statsV6 != nil {
+		blt.statsV6.Reset()
+	}
+	if blt.probes != nil {
+		blt.probes.resetStats()
+	}
 }
 
 func (blt *CachedLivenessTester) printStats(logger *log.Logger) {
-	s := blt.stats
+	// prevent div by 0 if thread starvation happens
+	var epochDur float64 = math.Max(float64(time.Since(blt.stats.epochStart).Milliseconds()), 1)
+
+	printCacheStats(logger, "liveness-stats", blt.stats, blt.ipCacheLive, blt.ipCacheNonLive)
+	if blt.statsV6 != nil {
+		printCacheStats(logger, "liveness-stats-v6", blt.statsV6, blt.ipCacheLiveV6, blt.ipCacheNonLiveV6)
+	}
+	if blt.probes != nil {
+		blt.probes.printStats(logger, epochDur)
+	}
 
+	linesSkipped := atomic.LoadInt64(&blt.cacheLinesSkipped)
+	filesSkipped := atomic.LoadInt64(&blt.cacheFilesSkipped)
+	if linesSkipped > 0 || filesSkipped > 0 {
+		logger.Infof("liveness-cache-file-stats: %d %d", linesSkipped, filesSkipped)
+	}
+}
+
+func printCacheStats(logger *log.Logger, label string, s *stats, ipCacheLive, ipCacheNonLive cache) {
 	// prevent div by 0 if thread starvation happens
 	var epochDur float64 = math.Max(float64(time.Since(s.epochStart).Milliseconds()), 1)
 	nlp := atomic.LoadInt64(&s.newLivenessPass)
@@ -163,19 +261,20 @@ func (blt *CachedLivenessTester) printStats(logger *log.Logger) {
 
 	liveCacheLen := 0
 	var liveCacheCapPct float64 = 0
-	if blt.ipCacheLive != nil {
-		liveCacheLen = blt.ipCacheLive.Len()
-		liveCacheCapPct = float64(liveCacheLen) / float64(blt.ipCacheLive.Cap()) * 100
+	if ipCacheLive != nil {
+		liveCacheLen = ipCacheLive.Len()
+		liveCacheCapPct = float64(liveCacheLen) / float64(ipCacheLive.Cap()) * 100
 	}
 
 	nonLiveCacheLen := 0
 	var nonLiveCacheCapPct float64 = 0
-	if blt.ipCacheLive != nil {
-		nonLiveCacheLen = blt.ipCacheNonLive.Len()
-		nonLiveCacheCapPct = float64(nonLiveCacheLen) / float64(blt.ipCacheNonLive.Cap()) * 100
+	if ipCacheNonLive != nil {
+		nonLiveCacheLen = ipCacheNonLive.Len()
+		nonLiveCacheCapPct = float64(nonLiveCacheLen) / float64(ipCacheNonLive.Cap()) * 100
 	}
 
-	logger.Infof("liveness-stats: %d %d %.3f%% %.3f/s %d %.3f%% %.3f/s %d %.3f%% %.3f/s %d %.3f%% %.3f/s %d %.3f%% %d %.3f%%",
+	logger.Infof("%s: %d %d %.3f%% %.3f/s %d %.3f%% %.3f/s %d %.3f%% %.3f/s %d %.3f%% %.3f/s %d %.3f%% %d %.3f%%",
+		label,
 		nlp+nlf+nlcl+nlcn,
 		nlp,
 		float64(nlp)/float64(total)*100,
@@ -1,8 +1,8 @@
 package liveness
 
 import (
+	"context"
 	"errors"
-	"fmt"
 	"math"
 	"net"
 	"sync/atomic"
@@ -27,6 +27,14 @@ type Tester interface {
 	PhantomIsLive(addr string, port uint16) (bool, error)
 }
 
+// cachedTester is implemented by testers that can answer some tests from earlier results. The
+// pipeline uses it to answer those without waiting on the rate of network probes.
+type cachedTester interface {
+	// cachedResult returns the cached liveness of addr along with ErrCachedPhantom, or a nil
+	// error if no result is cached.
+	cachedResult(addr string) (bool, error)
+}
+
 // Stats provides an interface to write out the collected metrics about liveness tester usage
 type Stats interface {
 	PrintAndReset(logger *log.Logger)
@@ -39,6 +47,9 @@ type cache interface {
 	ClearExpired()
 	Add(string, *cacheElement)
 
+	// Entries returns a copy of the unexpired entries of the cache
+	Entries() map[string]*cacheElement
+
 	// Len returns the number of elements in the cache
 	Len() int
 
@@ -79,6 +90,59 @@ type Config struct {
 	// If unset or 0 no capacity is set and a map is used for the cache
 	// otherwise cache will have finite capacity and implement LRU eviction.
 	CacheCapacityNonLive int `toml:"cache_capacity_nonlive"`
+
+	// ProbeTCPPorts lists ports probed with TCP connects in addition to the destination port of
+	// the registration, to detect hosts that only listen on other ports.
+	ProbeTCPPorts []uint16 `toml:"liveness_probe_tcp_ports"`
+
+	// ProbeUDPPorts lists ports probed with a UDP datagram.
+	ProbeUDPPorts []uint16 `toml:"liveness_probe_udp_ports"`
+
+	// ProbeWidth is the number of parallel connects of each TCP probe, 4 if unset.
+	ProbeWidth int `toml:"liveness_probe_width"`
+
+	// ProbeTimeout bounds the time each probe waits for an answer, 750ms if unset.
+	ProbeTimeout string `toml:"liveness_probe_timeout"`
+
+	// ProbeRule combines the probe results: "any" (default) considers a phantom live if any
+	// probe is answered, "all" if all are and "majority" if more than half are.
+	ProbeRule string `toml:"liveness_probe_rule"`
+
+	// CachePath is a file the cache of live and non-live phantoms is loaded from at startup and
+	// saved to on shutdown, so that results survive a restart. Empty disables persistence. The
+	// file can be copied to other stations and listed in their CacheSeedPaths.
+	CachePath string `toml:"cache_path"`
+
+	// CacheSeedPaths lists cache files exported by other stations of the deployment. The phantoms
+	// they found live are added to the live cache at startup, non-live results are ignored as
+	// they depend on the vantage point of the station. Missing or unreadable files and malformed
+	// lines are skipped.
+	CacheSeedPaths []string `toml:"cache_seed_paths"`
+
+	// EnableV6 tests IPv6 phantoms for liveness during registration ingest. IPv6 phantoms are
+	// otherwise assumed never to be live, which does not hold for prefixes shared with hosting
+	// providers. IPv6 results are cached and counted separately from IPv4 results.
+	EnableV6 bool `toml:"liveness_enable_v6"`
+
+	// CacheCapacityV6 is the CacheCapacity of IPv6 phantoms identified as "LIVE" when EnableV6
+	// is set. The IPv6 cache uses CacheDuration.
+	CacheCapacityV6 int `toml:"cache_capacity_v6"`
+
+	// CacheCapacityNonLiveV6 is the CacheCapacityNonLive of IPv6 phantoms identified as
+	// "NOT LIVE" when EnableV6 is set. The IPv6 cache uses CacheDurationNonLive.
+	CacheCapacityNonLiveV6 int `toml:"cache_capacity_nonlive_v6"`
+
+	// PipelineWorkers is the number of workers running liveness tests for registration ingest,
+	// 64 if unset.
+	PipelineWorkers int `toml:"liveness_workers"`
+
+	// PipelineQueueSize is the number of liveness tests that can wait for a worker before
+	// registrations are dropped, 4096 if unset.
+	PipelineQueueSize int `toml:"liveness_queue_size"`
+
+	// PipelineRate limits the liveness tests started per second across all workers, unlimited
+	// if unset. Tests answered from the cache do not count against it.
+	PipelineRate float64 `toml:"liveness_tests_per_second"`
 }
 
 var defaultConfig = &Config{
@@ -104,9 +168,18 @@ func New(c *Config) (Tester, error) {
 	}
 
 	if c.CacheDuration == "" && c.CacheDurationNonLive == "" {
-		return &UncachedLivenessTester{
-			stats: &stats{},
-		}, nil
+		probes, err := probeSetFromConfig(c)
+		if err != nil {
+			return nil, err
+		}
+		ult := &UncachedLivenessTester{
+			stats:  &stats{},
+			probes: probes,
+		}
+		if c.EnableV6 {
+			ult.statsV6 = &stats{}
+		}
+		return ult, nil
 	}
 
 	clt := &CachedLivenessTester{
@@ -116,41 +189,12 @@ func New(c *Config) (Tester, error) {
 	return clt, clt.Init(c)
 }
 
-func phantomIsLive(address string) (bool, error) {
-
-	width := 4
-	dialError := make(chan error, width)
-	timeout := 750 * time.Millisecond
-
-	testConnect := func() {
-		conn, err := net.DialTimeout("tcp", address, timeout)
-		if err != nil {
-			dialError <- err
-			return
-		}
-		conn.Close()
-		dialError <- nil
-	}
-
-	for i := 0; i < width; i++ {
-		go testConnect()
-	}
-
-	time.Sleep(timeout)
-
-	// If any return errors or connect then return nil before deadline it is live
-	select {
-	case err := <-dialError:
-		if e, ok := err.(net.Error); ok && e.Timeout() {
-			return false, fmt.Errorf("reached connection timeout")
-		}
-		if err != nil {
-			return true, err
-		}
-		return true, fmt.Errorf("phantom picked up the connection")
-	default:
-		return false, fmt.Errorf("reached statistical timeout %v", timeout)
+// phantomIsLive probes the phantom with probes, or the default probe set if nil.
+func phantomIsLive(probes *ProbeSet, addr string, port uint16) (bool, error) {
+	if probes == nil {
+		probes = defaultProbeSet()
 	}
+	return probes.IsLive(context.Background(), addr, port)
 }
 
 type stats struct {
@@ -182,6 +226,16 @@ func (s *stats) PrintStats(logger *log.Logger) {
 }
 
 func (s *stats) printStats(logger *log.Logger) {
+	s.printStatsAs(logger, "liveness-stats")
+}
+
+// isV6 reports whether addr is an IPv6 phantom address.
+func isV6(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.To4() == nil
+}
+
+func (s *stats) printStatsAs(logger *log.Logger, label string) {
 	// prevent div by 0 if thread starvation happens
 	var epochDur float64 = math.Max(float64(time.Since(s.epochStart).Milliseconds()), 1)
 
@@ -191,7 +245,8 @@ func (s *stats) printStats(logger *log.Logger) {
 	nlcn := atomic.LoadInt64(&s.newLivenessCachedNonLive)
 	total := math.Max(float64(nlp+nlf + +nlcl + nlcn), 1)
 
-	logger.Infof("liveness-stats: %d %.3f%% %.3f/s %d %.3f%% %.3f/s %d %.3f%% %.3f/s %d %.3f%% %.3f/s",
+	logger.Infof("%s: %d %.3f%% %.3f/s %d %.3f%% %.3f/s %d %.3f%% %.3f/s %d %.3f%% %.3f/s",
+		label,
 		nlp,
 		float64(nlp)/float64(total)*100,
 		float64(nlp)/float64(epochDur)*1000,
@@ -34,7 +34,7 @@ func TestCachedLiveness(t *testing.T) {
 	clt := CachedLivenessTester{
 		stats: &stats{},
 	}
-	err := clt.Init(&Config{"1h", 0, "5m", 0})
+	err := clt.Init(&Config{CacheDuration: "1h", CacheDurationNonLive: "5m"})
 	require.Nil(t, err)
 
 	liveness, response := clt.PhantomIsLive("1.1.1.1.", 80)
@@ -81,7 +81,7 @@ func TestCachedLiveness(t *testing.T) {
 
 func TestCachedLivenessLiveOnly(t *testing.T) {
 
-	clt, err := New(&Config{"1h", 0, "", 0})
+	clt, err := New(&Config{CacheDuration: "1h"})
 	require.Nil(t, err)
 
 	liveness, response := clt.PhantomIsLive("1.1.1.1.", 80)
@@ -148,6 +148,54 @@ func TestCachedLivenessThreaded(t *testing.T) {
 	}
 }
 
+func TestCachedLivenessV6(t *testing.T) {
+	clt := CachedLivenessTester{
+		stats: &stats{},
+	}
+	err := clt.Init(&Config{
+		CacheDuration:          "1h",
+		CacheDurationNonLive:   "5m",
+		EnableV6:               true,
+		CacheCapacityV6:        2,
+		CacheCapacityNonLiveV6: 2,
+	})
+	require.Nil(t, err)
+	require.NotNil(t, clt.statsV6)
+	clt.probes = NewProbeSet(RuleAny, &fakeProber{name: "fake", live: true})
+
+	live, _ := clt.PhantomIsLive("2001:db8::1", 443)
+	require.True(t, live)
+	live, _ = clt.PhantomIsLive("192.0.2.1", 443)
+	require.True(t, live)
+
+	// IPv6 phantoms are cached and counted apart from IPv4 phantoms.
+	require.True(t, clt.ipCacheLiveV6.Lookup("2001:db8::1"))
+	require.False(t, clt.ipCacheLive.Lookup("2001:db8::1"))
+	require.True(t, clt.ipCacheLive.Lookup("192.0.2.1"))
+	require.IsType(t, &lruCache{}, clt.ipCacheLiveV6)
+	require.IsType(t, &mapCache{}, clt.ipCacheLive)
+	require.Equal(t, int64(1), clt.statsV6.newLivenessFail)
+	require.Equal(t, int64(1), clt.stats.newLivenessFail)
+
+	live, err = clt.PhantomIsLive("2001:db8::1", 443)
+	require.True(t, live)
+	require.ErrorIs(t, err, ErrCachedPhantom)
+	require.Equal(t, int64(1), clt.statsV6.newLivenessCachedLive)
+	require.Equal(t, int64(0), clt.stats.newLivenessCachedLive)
+
+	// Without IPv6 liveness testing IPv6 phantoms share the IPv4 caches.
+	clt = CachedLivenessTester{
+		stats: &stats{},
+	}
+	err = clt.Init(&Config{CacheDuration: "1h"})
+	require.Nil(t, err)
+	require.Nil(t, clt.statsV6)
+	clt.probes = NewProbeSet(RuleAny, &fakeProber{name: "fake", live: true})
+	live, _ = clt.PhantomIsLive("2001:db8::1", 443)
+	require.True(t, live)
+	require.True(t, clt.ipCacheLive.Lookup("2001:db8::1"))
+}
+
 // // To run the measurements commands set the environment variable when running go test
 // //
 // //	$ MEASUREMENTS=1 go test -v
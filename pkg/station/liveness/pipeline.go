@@ -0,0 +1,308 @@
+package liveness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/station/log"
+)
+
+const (
+	defaultPipelineWorkers   = 64
+	defaultPipelineQueueSize = 4096
+)
+
+// ErrBadPipelineConfig indicates that the pipeline can not be built from the liveness config.
+var ErrBadPipelineConfig = errors.New("bad liveness pipeline config")
+
+// ErrPipelineFull is returned by Pipeline.Test when the queue of pending tests is full. The
+// caller should drop whatever was waiting on the result, as a registration would if there were
+// no ingest worker available.
+var ErrPipelineFull = errors.New("liveness test queue full")
+
+// Pipeline runs the liveness tests of a Tester in a bounded pool of workers so that callers do
+// not block on the network. Tests that probe the network are started at no more than a
+// configured rate, and concurrent tests of the same phantom address and port are coalesced into
+// one whose result is delivered to every caller.
+type Pipeline struct {
+	tester  Tester
+	workers int
+	jobs    chan *pipelineJob
+	limiter *rateLimiter
+
+	mu      sync.Mutex
+	pending map[string]*pipelineJob
+
+	*pipelineStats
+}
+
+type pipelineJob struct {
+	addr   string
+	port   uint16
+	queued time.Time
+
+	// callbacks are appended under Pipeline.mu while the job is pending.
+	callbacks []func(bool, error)
+}
+
+// NewPipeline returns a pipeline running the tests of tester with the pool size, queue size and
+// rate of the liveness config. The workers are launched by Start.
+func NewPipeline(tester Tester, c *Config) (*Pipeline, error) {
+	if c == nil {
+		c = defaultConfig
+	}
+
+	workers := c.PipelineWorkers
+	if workers == 0 {
+		workers = defaultPipelineWorkers
+	} else if workers < 0 {
+		return nil, fmt.Errorf("%w: pipeline workers %d", ErrBadPipelineConfig, workers)
+	}
+
+	queueSize := c.PipelineQueueSize
+	if queueSize == 0 {
+		queueSize = defaultPipelineQueueSize
+	} else if queueSize < 0 {
+		return nil, fmt.Errorf("%w: pipeline queue size %d", ErrBadPipelineConfig, queueSize)
+	}
+
+	if c.PipelineRate < 0 {
+		return nil, fmt.Errorf("%w: pipeline rate %v", ErrBadPipelineConfig, c.PipelineRate)
+	}
+
+	return &Pipeline{
+		tester:        tester,
+		workers:       workers,
+		jobs:          make(chan *pipelineJob, queueSize),
+		limiter:       newRateLimiter(c.PipelineRate),
+		pending:       make(map[string]*pipelineJob),
+		pipelineStats: &pipelineStats{epochStart: time.Now()},
+	}, nil
+}
+
+// Start launches the workers of the pipeline, which run until ctx is done. Tests still queued at
+// that point are abandoned and their callbacks never run.
+func (p *Pipeline) Start(ctx context.Context, wg *sync.WaitGroup) {
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go p.worker(ctx, wg)
+	}
+}
+
+// Test queues a liveness test of the phantom addr:port and calls done with the result from a
+// worker of the pipeline. If a test of the same phantom is already pending, done is called with
+// its result instead of testing again. ErrPipelineFull is returned, and done is never called, if
+// the test can not be queued.
+func (p *Pipeline) Test(addr string, port uint16, done func(bool, error)) error {
+	key := net.JoinHostPort(addr, strconv.Itoa(int(port)))
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if job, ok := p.pending[key]; ok {
+		job.callbacks = append(job.callbacks, done)
+		p.incCoalesced()
+		return nil
+	}
+
+	job := &pipelineJob{addr: addr, port: port, queued: time.Now(), callbacks: []func(bool, error){done}}
+	select {
+	case p.jobs <- job:
+	default:
+		p.incDropped()
+		return ErrPipelineFull
+	}
+	p.pending[key] = job
+	p.incQueued()
+	return nil
+}
+
+func (p *Pipeline) worker(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case job := <-p.jobs:
+			// Results cached by the tester are delivered right away, only tests that probe the
+			// network wait on the rate limit.
+			live, err := p.cachedResult(job.addr)
+			if !errors.Is(err, ErrCachedPhantom) {
+				if err := p.limiter.wait(ctx); err != nil {
+					return
+				}
+				p.addWait(time.Since(job.queued))
+				live, err = p.tester.PhantomIsLive(job.addr, job.port)
+			} else {
+				p.addWait(time.Since(job.queued))
+			}
+
+			// remove the job before running the callbacks so that a test requested from a
+			// callback is not coalesced into this one.
+			p.mu.Lock()
+			delete(p.pending, net.JoinHostPort(job.addr, strconv.Itoa(int(job.port))))
+			callbacks := job.callbacks
+			p.mu.Unlock()
+
+			for _, done := range callbacks {
+				done(live, err)
+			}
+		}
+	}
+}
+
+// cachedResult returns the result of the tester for addr along with ErrCachedPhantom if it is
+// cached, or a nil error if it is not.
+func (p *Pipeline) cachedResult(addr string) (bool, error) {
+	if c, ok := p.tester.(cachedTester); ok {
+		return c.cachedResult(addr)
+	}
+	return false, nil
+}
+
+// PrintStats implements the Stats interface.
+func (p *Pipeline) PrintStats(logger *log.Logger) {
+	p.printStats(logger, len(p.jobs), cap(p.jobs))
+}
+
+// PrintAndReset implements the Stats interface.
+func (p *Pipeline) PrintAndReset(logger *log.Logger) {
+	p.PrintStats(logger)
+	p.Reset()
+}
+
+// rateLimiter spaces out events to at most rate per second, unlimited if rate is 0.
+type rateLimiter struct {
+	interval time.Duration
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func newRateLimiter(rate float64) *rateLimiter {
+	if rate <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / rate)}
+}
+
+// wait blocks until the next event is allowed, or returns the error of ctx if it is done first.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	if r.interval == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	now := time.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	at := r.next
+	r.next = r.next.Add(r.interval)
+	r.mu.Unlock()
+
+	delay := time.Until(at)
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+type pipelineStats struct {
+	// newQueued count of tests queued since reset()
+	newQueued int64
+
+	// newCoalesced count of tests answered by a pending test of the same phantom since reset()
+	newCoalesced int64
+
+	// newDropped count of tests dropped because the queue was full since reset()
+	newDropped int64
+
+	// newStarted count of tests started by a worker since reset()
+	newStarted int64
+
+	// newWaitNanos total time tests started since reset() spent waiting in the queue
+	newWaitNanos int64
+
+	// maxWaitNanos longest time a test started since reset() spent waiting in the queue
+	maxWaitNanos int64
+
+	// start time of epoch to calculate per-second rates
+	epochStart time.Time
+}
+
+func (s *pipelineStats) printStats(logger *log.Logger, depth, capacity int) {
+	// prevent div by 0 if thread starvation happens
+	var epochDur float64 = math.Max(float64(time.Since(s.epochStart).Milliseconds()), 1)
+
+	nq := atomic.LoadInt64(&s.newQueued)
+	nc := atomic.LoadInt64(&s.newCoalesced)
+	nd := atomic.LoadInt64(&s.newDropped)
+	ns := atomic.LoadInt64(&s.newStarted)
+	avgWait := time.Duration(atomic.LoadInt64(&s.newWaitNanos) / int64(math.Max(float64(ns), 1)))
+	maxWait := time.Duration(atomic.LoadInt64(&s.maxWaitNanos))
+
+	logger.Infof("liveness-queue-stats: %d/%d %.3f%% %d %.3f/s %d %.3f/s %d %.3f/s %d %.3fms %.3fms",
+		depth,
+		capacity,
+		float64(depth)/math.Max(float64(capacity), 1)*100,
+		nq,
+		float64(nq)/epochDur*1000,
+		nc,
+		float64(nc)/epochDur*1000,
+		nd,
+		float64(nd)/epochDur*1000,
+		ns,
+		float64(avgWait.Microseconds())/1000,
+		float64(maxWait.Microseconds())/1000,
+	)
+}
+
+func (s *pipelineStats) Reset() {
+	atomic.StoreInt64(&s.newQueued, 0)
+	atomic.StoreInt64(&s.newCoalesced, 0)
+	atomic.StoreInt64(&s.newDropped, 0)
+	atomic.StoreInt64(&s.newStarted, 0)
+	atomic.StoreInt64(&s.newWaitNanos, 0)
+	atomic.StoreInt64(&s.maxWaitNanos, 0)
+
+	s.epochStart = time.Now()
+}
+
+func (s *pipelineStats) incQueued() {
+	atomic.AddInt64(&s.newQueued, 1)
+}
+
+func (s *pipelineStats) incCoalesced() {
+	atomic.AddInt64(&s.newCoalesced, 1)
+}
+
+func (s *pipelineStats) incDropped() {
+	atomic.AddInt64(&s.newDropped, 1)
+}
+
+func (s *pipelineStats) addWait(wait time.Duration) {
+	atomic.AddInt64(&s.newStarted, 1)
+	atomic.AddInt64(&s.newWaitNanos, int64(wait))
+	for {
+		max := atomic.LoadInt64(&s.maxWaitNanos)
+		if int64(wait) <= max || atomic.CompareAndSwapInt64(&s.maxWaitNanos, max, int64(wait)) {
+			return
+		}
+	}
+}
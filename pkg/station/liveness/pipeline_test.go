@@ -0,0 +1,138 @@
+package liveness
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// blockingTester answers every test once release is closed, counting the tests run.
+type blockingTester struct {
+	*stats
+	release chan struct{}
+	tests   int64
+}
+
+func (bt *blockingTester) PhantomIsLive(addr string, port uint16) (bool, error) {
+	atomic.AddInt64(&bt.tests, 1)
+	<-bt.release
+	return addr == "192.0.2.1", nil
+}
+
+func TestPipelineCoalesce(t *testing.T) {
+	tester := &blockingTester{stats: &stats{}, release: make(chan struct{})}
+	p, err := NewPipeline(tester, &Config{PipelineWorkers: 2, PipelineQueueSize: 4})
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := new(sync.WaitGroup)
+	defer wg.Wait()
+	defer cancel()
+	p.Start(ctx, wg)
+
+	results := make(chan bool, 4)
+	done := func(live bool, err error) { results <- live }
+	require.Nil(t, p.Test("192.0.2.1", 443, done))
+	require.Nil(t, p.Test("192.0.2.1", 443, done))
+	require.Nil(t, p.Test("192.0.2.1", 80, done))
+	require.Nil(t, p.Test("192.0.2.2", 443, done))
+	close(tester.release)
+
+	var live int
+	for i := 0; i < 4; i++ {
+		if <-results {
+			live++
+		}
+	}
+	require.Equal(t, 3, live)
+	require.Equal(t, int64(3), atomic.LoadInt64(&tester.tests))
+	require.Equal(t, int64(1), atomic.LoadInt64(&p.newCoalesced))
+	require.Equal(t, int64(3), atomic.LoadInt64(&p.newQueued))
+
+	// Once answered a phantom is tested again.
+	require.Nil(t, p.Test("192.0.2.1", 443, done))
+	require.True(t, <-results)
+	require.Equal(t, int64(4), atomic.LoadInt64(&tester.tests))
+}
+
+func TestPipelineFull(t *testing.T) {
+	tester := &blockingTester{stats: &stats{}, release: make(chan struct{})}
+	p, err := NewPipeline(tester, &Config{PipelineWorkers: 1, PipelineQueueSize: 1})
+	require.Nil(t, err)
+
+	// Without workers the queue holds a single test, coalesced tests do not take a slot.
+	done := func(bool, error) {}
+	require.Nil(t, p.Test("192.0.2.1", 443, done))
+	require.Nil(t, p.Test("192.0.2.1", 443, done))
+	require.ErrorIs(t, p.Test("192.0.2.2", 443, done), ErrPipelineFull)
+	require.Equal(t, int64(1), atomic.LoadInt64(&p.newDropped))
+}
+
+func TestPipelineRate(t *testing.T) {
+	tester := &blockingTester{stats: &stats{}, release: make(chan struct{})}
+	close(tester.release)
+	p, err := NewPipeline(tester, &Config{PipelineWorkers: 4, PipelineRate: 20})
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := new(sync.WaitGroup)
+	defer wg.Wait()
+	defer cancel()
+	p.Start(ctx, wg)
+
+	results := make(chan bool, 5)
+	start := time.Now()
+	for _, addr := range []string{"192.0.2.1", "192.0.2.2", "192.0.2.3", "192.0.2.4", "192.0.2.5"} {
+		require.Nil(t, p.Test(addr, 443, func(live bool, err error) { results <- live }))
+	}
+	for i := 0; i < 5; i++ {
+		<-results
+	}
+
+	// 5 tests at 20/s are spaced over at least 200ms.
+	require.GreaterOrEqual(t, time.Since(start), 190*time.Millisecond)
+	require.Greater(t, atomic.LoadInt64(&p.maxWaitNanos), int64(150*time.Millisecond))
+}
+
+func TestPipelineBadConfig(t *testing.T) {
+	for _, c := range []*Config{
+		{PipelineWorkers: -1},
+		{PipelineQueueSize: -1},
+		{PipelineRate: -1},
+	} {
+		_, err := NewPipeline(&UncachedLivenessTester{stats: &stats{}}, c)
+		require.ErrorIs(t, err, ErrBadPipelineConfig)
+	}
+}
+
+func TestPipelineRateCached(t *testing.T) {
+	tester := &CachedLivenessTester{stats: &stats{}, ipCacheLive: newCache(time.Hour, 0)}
+	addrs := []string{"192.0.2.1", "192.0.2.2", "192.0.2.3", "192.0.2.4", "192.0.2.5"}
+	for _, addr := range addrs {
+		tester.ipCacheLive.Add(addr, &cacheElement{cachedTime: time.Now()})
+	}
+	p, err := NewPipeline(tester, &Config{PipelineWorkers: 1, PipelineRate: 1})
+	require.Nil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := new(sync.WaitGroup)
+	defer wg.Wait()
+	defer cancel()
+	p.Start(ctx, wg)
+
+	// Cached results do not use the budget of network probes.
+	results := make(chan error, len(addrs))
+	start := time.Now()
+	for _, addr := range addrs {
+		require.Nil(t, p.Test(addr, 443, func(live bool, err error) { results <- err }))
+	}
+	for range addrs {
+		require.ErrorIs(t, <-results, ErrCachedPhantom)
+	}
+	require.Less(t, time.Since(start), 500*time.Millisecond)
+	require.Equal(t, int64(len(addrs)), atomic.LoadInt64(&tester.newLivenessCachedLive))
+}
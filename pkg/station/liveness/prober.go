@@ -0,0 +1,380 @@
+package liveness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/station/log"
+)
+
+const (
+	defaultProbeWidth   = 4
+	defaultProbeTimeout = 750 * time.Millisecond
+)
+
+// ErrBadProbeConfig indicates that the probe set can not be built from the liveness config.
+var ErrBadProbeConfig = errors.New("bad liveness probe config")
+
+// Prober sends one kind of probe to a phantom address to find out if a host is there.
+type Prober interface {
+	// Name identifies the probe in stats, e.g. "tcp/443".
+	Name() string
+
+	// Probe reports whether a host at addr answered the probe. port is the destination port of
+	// the registration, which probes of a fixed port ignore. Answers that show a host is there,
+	// such as a refused connection, count as live and are returned along with the error.
+	Probe(ctx context.Context, addr string, port uint16) (bool, error)
+}
+
+// Rule combines the results of the probes of a ProbeSet.
+type Rule int
+
+const (
+	// RuleAny considers an address live if any probe is answered.
+	RuleAny Rule = iota
+	// RuleAll considers an address live only if every probe is answered.
+	RuleAll
+	// RuleMajority considers an address live if more than half of the probes are answered.
+	RuleMajority
+)
+
+// ParseRule parses the name of a rule, empty selects RuleAny.
+func ParseRule(s string) (Rule, error) {
+	switch strings.ToLower(s) {
+	case "", "any":
+		return RuleAny, nil
+	case "all":
+		return RuleAll, nil
+	case "majority":
+		return RuleMajority, nil
+	default:
+		return RuleAny, fmt.Errorf("%w: unknown rule %q", ErrBadProbeConfig, s)
+	}
+}
+
+func (r Rule) String() string {
+	switch r {
+	case RuleAll:
+		return "all"
+	case RuleMajority:
+		return "majority"
+	default:
+		return "any"
+	}
+}
+
+// decided returns the outcome once enough of total probes have answered (live) or not (nonLive)
+// for the rule to be settled.
+func (r Rule) decided(live, nonLive, total int) (isLive bool, ok bool) {
+	switch r {
+	case RuleAll:
+		if nonLive > 0 {
+			return false, true
+		}
+		return true, live == total
+	case RuleMajority:
+		if live*2 > total {
+			return true, true
+		}
+		return false, nonLive*2 >= total
+	default:
+		if live > 0 {
+			return true, true
+		}
+		return false, nonLive == total
+	}
+}
+
+// TCPProber probes with parallel TCP connects to a port.
+type TCPProber struct {
+	// Port is the port to connect to, 0 for the destination port of the registration.
+	Port uint16
+	// Width is the number of parallel connects.
+	Width int
+	// Timeout bounds the time to wait for a connect to complete.
+	Timeout time.Duration
+
+	dial func(ctx context.Context, network, address string) (net.Conn, error)
+}
+
+// Name implements Prober.
+func (p *TCPProber) Name() string {
+	if p.Port == 0 {
+		return "tcp/phantom"
+	}
+	return "tcp/" + strconv.Itoa(int(p.Port))
+}
+
+// Probe implements Prober. Any connect that completes, or fails other than by timing out, shows
+// that a host is there.
+func (p *TCPProber) Probe(ctx context.Context, addr string, port uint16) (bool, error) {
+	if p.Port != 0 {
+		port = p.Port
+	}
+	address := net.JoinHostPort(addr, strconv.Itoa(int(port)))
+
+	width := p.Width
+	if width <= 0 {
+		width = defaultProbeWidth
+	}
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+	dial := p.dial
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dialError := make(chan error, width)
+	for i := 0; i < width; i++ {
+		go func() {
+			conn, err := dial(ctx, "tcp", address)
+			if err == nil {
+				conn.Close()
+			}
+			dialError <- err
+		}()
+	}
+
+	for i := 0; i < width; i++ {
+		err := <-dialError
+		if err == nil {
+			return true, fmt.Errorf("phantom picked up the connection")
+		}
+		if !isTimeout(ctx, err) {
+			return true, err
+		}
+	}
+	return false, fmt.Errorf("reached connection timeout")
+}
+
+// UDPProber probes by sending a datagram to a port. A reply shows that a host is there, while no
+// answer or an ICMP unreachable does not.
+type UDPProber struct {
+	// Port is the port to send to, 0 for the destination port of the registration.
+	Port uint16
+	// Payload is the content of the datagram.
+	Payload []byte
+	// Timeout bounds the time to wait for an answer.
+	Timeout time.Duration
+}
+
+// Name implements Prober.
+func (p *UDPProber) Name() string {
+	if p.Port == 0 {
+		return "udp/phantom"
+	}
+	return "udp/" + strconv.Itoa(int(p.Port))
+}
+
+// Probe implements Prober.
+func (p *UDPProber) Probe(ctx context.Context, addr string, port uint16) (bool, error) {
+	if p.Port != 0 {
+		port = p.Port
+	}
+	timeout := p.Timeout
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := (&net.Dialer{}).DialContext(ctx, "udp", net.JoinHostPort(addr, strconv.Itoa(int(port))))
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err := conn.Write(p.Payload); err != nil {
+		return false, err
+	}
+
+	buf := make([]byte, 1)
+	if _, err := conn.Read(buf); err != nil {
+		if isTimeout(ctx, err) {
+			return false, fmt.Errorf("reached read timeout")
+		}
+		if isUnreachable(err) {
+			return false, err
+		}
+		return true, err
+	}
+	return true, fmt.Errorf("phantom answered the datagram")
+}
+
+// isUnreachable reports whether err is the result of an ICMP unreachable, for the port, host or
+// network, received for a datagram.
+func isUnreachable(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EHOSTUNREACH) || errors.Is(err, syscall.ENETUNREACH)
+}
+
+func isTimeout(ctx context.Context, err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || ctx.Err() != nil
+}
+
+// ProbeSet runs a set of probes against an address and combines their results with a rule.
+type ProbeSet struct {
+	probers []Prober
+	rule    Rule
+
+	stats []*probeStats
+}
+
+// NewProbeSet returns a probe set running probers and combining them with rule.
+func NewProbeSet(rule Rule, probers ...Prober) *ProbeSet {
+	ps := &ProbeSet{probers: probers, rule: rule}
+	for _, p := range probers {
+		ps.stats = append(ps.stats, &probeStats{name: p.Name()})
+	}
+	return ps
+}
+
+// defaultProbeSet matches the original liveness test, parallel TCP connects to the phantom port.
+func defaultProbeSet() *ProbeSet {
+	return NewProbeSet(RuleAny, &TCPProber{Width: defaultProbeWidth, Timeout: defaultProbeTimeout})
+}
+
+// probeSetFromConfig builds the probe set described by the liveness config.
+func probeSetFromConfig(c *Config) (*ProbeSet, error) {
+	rule, err := ParseRule(c.ProbeRule)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := defaultProbeTimeout
+	if c.ProbeTimeout != "" {
+		timeout, err = time.ParseDuration(c.ProbeTimeout)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("%w: probe timeout %q", ErrBadProbeConfig, c.ProbeTimeout)
+		}
+	}
+
+	width := c.ProbeWidth
+	if width == 0 {
+		width = defaultProbeWidth
+	} else if width < 0 {
+		return nil, fmt.Errorf("%w: probe width %d", ErrBadProbeConfig, width)
+	}
+
+	probers := []Prober{&TCPProber{Width: width, Timeout: timeout}}
+	for _, port := range c.ProbeTCPPorts {
+		probers = append(probers, &TCPProber{Port: port, Width: width, Timeout: timeout})
+	}
+	for _, port := range c.ProbeUDPPorts {
+		probers = append(probers, &UDPProber{Port: port, Timeout: timeout})
+	}
+	return NewProbeSet(rule, probers...), nil
+}
+
+// IsLive probes addr with every prober and combines the results with the rule of the set. The
+// error returned is the one of a probe that agrees with the outcome.
+func (ps *ProbeSet) IsLive(ctx context.Context, addr string, port uint16) (bool, error) {
+	if len(ps.probers) == 0 {
+		return false, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		i    int
+		live bool
+		err  error
+	}
+	results := make(chan result, len(ps.probers))
+	for i, p := range ps.probers {
+		go func(i int, p Prober) {
+			live, err := p.Probe(ctx, addr, port)
+			results <- result{i, live, err}
+		}(i, p)
+	}
+
+	var live, nonLive int
+	var liveErr, nonLiveErr error
+	for range ps.probers {
+		r := <-results
+		if r.live {
+			live++
+			if liveErr == nil {
+				liveErr = r.err
+			}
+		} else {
+			nonLive++
+			if nonLiveErr == nil {
+				nonLiveErr = r.err
+			}
+		}
+		ps.recordProbe(r.i, r.live)
+
+		if isLive, ok := ps.rule.decided(live, nonLive, len(ps.probers)); ok {
+			// the remaining probes are canceled and not counted in the stats.
+			if isLive {
+				return true, liveErr
+			}
+			return false, nonLiveErr
+		}
+	}
+	return false, nonLiveErr
+}
+
+func (ps *ProbeSet) recordProbe(i int, live bool) {
+	if live {
+		atomic.AddInt64(&ps.stats[i].newLive, 1)
+	} else {
+		atomic.AddInt64(&ps.stats[i].newNonLive, 1)
+	}
+}
+
+// probeStats counts the results of one prober of a ProbeSet.
+type probeStats struct {
+	name string
+
+	// newLive count of probes answered since reset()
+	newLive int64
+	// newNonLive count of probes not answered since reset()
+	newNonLive int64
+}
+
+func (ps *ProbeSet) printStats(logger *log.Logger, epochDur float64) {
+	for _, s := range ps.stats {
+		nl := atomic.LoadInt64(&s.newLive)
+		nn := atomic.LoadInt64(&s.newNonLive)
+		total := math.Max(float64(nl+nn), 1)
+		logger.Infof("liveness-probe-stats: %s %d %.3f%% %.3f/s %d %.3f%% %.3f/s",
+			s.name,
+			nl,
+			float64(nl)/total*100,
+			float64(nl)/epochDur*1000,
+			nn,
+			float64(nn)/total*100,
+			float64(nn)/epochDur*1000,
+		)
+	}
+}
+
+func (ps *ProbeSet) resetStats() {
+	for _, s := range ps.stats {
+		atomic.StoreInt64(&s.newLive, 0)
+		atomic.StoreInt64(&s.newNonLive, 0)
+	}
+}
@@ -0,0 +1,163 @@
+package liveness
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeProber struct {
+	name  string
+	live  bool
+	delay time.Duration
+}
+
+func (p *fakeProber) Name() string { return p.name }
+
+func (p *fakeProber) Probe(ctx context.Context, addr string, port uint16) (bool, error) {
+	select {
+	case <-time.After(p.delay):
+		return p.live, nil
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+}
+
+func TestProbeSetRules(t *testing.T) {
+	testCases := []struct {
+		rule     Rule
+		results  []bool
+		expected bool
+	}{
+		{RuleAny, []bool{false, false, true}, true},
+		{RuleAny, []bool{false, false, false}, false},
+		{RuleAll, []bool{true, true, true}, true},
+		{RuleAll, []bool{true, false, true}, false},
+		{RuleMajority, []bool{true, false, true}, true},
+		{RuleMajority, []bool{true, false, false}, false},
+		{RuleMajority, []bool{true, false}, false},
+	}
+
+	for _, tc := range testCases {
+		var probers []Prober
+		for i, live := range tc.results {
+			probers = append(probers, &fakeProber{name: strconv.Itoa(i), live: live, delay: time.Duration(i) * time.Millisecond})
+		}
+		live, _ := NewProbeSet(tc.rule, probers...).IsLive(context.Background(), "192.0.2.1", 443)
+		require.Equal(t, tc.expected, live, "%s %v", tc.rule, tc.results)
+	}
+
+	// A decided outcome does not wait for the remaining probes.
+	ps := NewProbeSet(RuleAny, &fakeProber{name: "fast", live: true}, &fakeProber{name: "slow", delay: time.Hour})
+	start := time.Now()
+	live, _ := ps.IsLive(context.Background(), "192.0.2.1", 443)
+	require.True(t, live)
+	require.Less(t, time.Since(start), time.Second)
+	require.Equal(t, int64(1), ps.stats[0].newLive)
+	require.Equal(t, int64(0), ps.stats[1].newLive+ps.stats[1].newNonLive)
+
+	ps.resetStats()
+	require.Equal(t, int64(0), ps.stats[0].newLive)
+}
+
+func TestTCPProber(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer l.Close()
+	port := uint16(l.Addr().(*net.TCPAddr).Port)
+
+	// A listening port and the probe of a fixed port both reach the host.
+	live, err := (&TCPProber{Timeout: time.Second}).Probe(context.Background(), "127.0.0.1", port)
+	require.True(t, live, err)
+	live, err = (&TCPProber{Port: port, Timeout: time.Second}).Probe(context.Background(), "127.0.0.1", 1)
+	require.True(t, live, err)
+	require.Equal(t, "tcp/"+strconv.Itoa(int(port)), (&TCPProber{Port: port}).Name())
+
+	// A host that drops the connects is not live.
+	blackhole := func(ctx context.Context, network, address string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	live, err = (&TCPProber{Timeout: 10 * time.Millisecond, dial: blackhole}).Probe(context.Background(), "192.0.2.1", 443)
+	require.False(t, live)
+	require.NotNil(t, err)
+
+	// A refused connect shows a host is there.
+	refused := func(ctx context.Context, network, address string) (net.Conn, error) {
+		return nil, errors.New("connection refused")
+	}
+	live, _ = (&TCPProber{Timeout: time.Second, dial: refused}).Probe(context.Background(), "192.0.2.1", 443)
+	require.True(t, live)
+}
+
+func TestUDPProber(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer conn.Close()
+	port := uint16(conn.LocalAddr().(*net.UDPAddr).Port)
+
+	// A host that does not answer the datagram is not live.
+	live, err := (&UDPProber{Port: port, Timeout: 50 * time.Millisecond}).Probe(context.Background(), "127.0.0.1", 443)
+	require.False(t, live, err)
+
+	// Echo every datagram, including the unanswered one above.
+	go func() {
+		buf := make([]byte, 16)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			_, _ = conn.WriteTo(buf[:n], addr)
+		}
+	}()
+	live, err = (&UDPProber{Port: port, Payload: []byte("ping"), Timeout: time.Second}).Probe(context.Background(), "127.0.0.1", 443)
+	require.True(t, live, err)
+
+	// An ICMP port unreachable does not make the host live.
+	closed, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.Nil(t, err)
+	closedPort := uint16(closed.LocalAddr().(*net.UDPAddr).Port)
+	require.Nil(t, closed.Close())
+	live, err = (&UDPProber{Port: closedPort, Payload: []byte("ping"), Timeout: time.Second}).Probe(context.Background(), "127.0.0.1", 443)
+	require.False(t, live)
+	require.ErrorIs(t, err, syscall.ECONNREFUSED)
+}
+
+func TestProbeSetFromConfig(t *testing.T) {
+	ps, err := probeSetFromConfig(&Config{})
+	require.Nil(t, err)
+	require.Len(t, ps.probers, 1)
+	require.Equal(t, "tcp/phantom", ps.probers[0].Name())
+	require.Equal(t, RuleAny, ps.rule)
+
+	ps, err = probeSetFromConfig(&Config{
+		ProbeTCPPorts: []uint16{80, 22},
+		ProbeUDPPorts: []uint16{53},
+		ProbeWidth:    2,
+		ProbeTimeout:  "250ms",
+		ProbeRule:     "majority",
+	})
+	require.Nil(t, err)
+	var names []string
+	for _, p := range ps.probers {
+		names = append(names, p.Name())
+	}
+	require.Equal(t, []string{"tcp/phantom", "tcp/80", "tcp/22", "udp/53"}, names)
+	require.Equal(t, RuleMajority, ps.rule)
+	require.Equal(t, 2, ps.probers[1].(*TCPProber).Width)
+	require.Equal(t, 250*time.Millisecond, ps.probers[3].(*UDPProber).Timeout)
+
+	_, err = probeSetFromConfig(&Config{ProbeRule: "some"})
+	require.ErrorIs(t, err, ErrBadProbeConfig)
+	_, err = probeSetFromConfig(&Config{ProbeTimeout: "soon"})
+	require.ErrorIs(t, err, ErrBadProbeConfig)
+	_, err = New(&Config{ProbeWidth: -1})
+	require.ErrorIs(t, err, ErrBadProbeConfig)
+}
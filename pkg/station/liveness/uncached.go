@@ -1,26 +1,68 @@
 package liveness
 
 import (
-	"net"
-	"strconv"
+	"math"
+	"time"
+
+	"github.com/refraction-networking/conjure/pkg/station/log"
 )
 
 // UncachedLivenessTester implements LivenessTester interface without caching,
 // PhantomIsLive will always use the network to determine phantom liveness.
 type UncachedLivenessTester struct {
 	*stats
+	probes *ProbeSet
+
+	// statsV6 counts the tests of IPv6 phantoms separately, nil unless IPv6
+	// liveness testing is enabled.
+	statsV6 *stats
 }
 
-// PhantomIsLive sends 4 TCP syn packets to determine if the host will respond
-// to traffic and potentially interfere with a connection if used as a phantom
-// address. Measurement results are uncached, meaning endpoints are re-scanned
-// every time.
+// PhantomIsLive sends the configured probes (by default 4 TCP syn packets to
+// the phantom port) to determine if the host will respond to traffic and
+// potentially interfere with a connection if used as a phantom address.
+// Measurement results are uncached, meaning endpoints are re-scanned every
+// time.
 func (blt *UncachedLivenessTester) PhantomIsLive(addr string, port uint16) (bool, error) {
-	live, err := phantomIsLive(net.JoinHostPort(addr, strconv.Itoa(int(port))))
+	live, err := phantomIsLive(blt.probes, addr, port)
+	s := blt.stats
+	if blt.statsV6 != nil && isV6(addr) {
+		s = blt.statsV6
+	}
 	if live {
-		blt.stats.incPass()
+		s.incFail()
 	} else {
-		blt.stats.incFail()
+		s.incPass()
 	}
 	return live, err
 }
+
+// PrintStats implements the Stats interface extending from the stats struct
+// to add logging for the individual probes
+func (blt *UncachedLivenessTester) PrintStats(logger *log.Logger) {
+	blt.stats.printStats(logger)
+	if blt.statsV6 != nil {
+		blt.statsV6.printStatsAs(logger, "liveness-stats-v6")
+	}
+	if blt.probes != nil {
+		blt.probes.printStats(logger, math.Max(float64(time.Since(blt.epochStart).Milliseconds()), 1))
+	}
+}
+
+// PrintAndReset implements the Stats interface extending from the stats struct
+// to add logging for the individual probes
+func (blt *UncachedLivenessTester) PrintAndReset(logger *log.Logger) {
+	blt.PrintStats(logger)
+	blt.Reset()
+}
+
+// Reset implements the Stats interface
+func (blt *UncachedLivenessTester) Reset() {
+	blt.stats.Reset()
+	if blt.statsV6 != nil {
+		blt.statsV6.Reset()
+	}
+	if blt.probes != nil {
+		blt.probes.resetStats()
+	}
+}
@@ -36,17 +36,25 @@ var (
 // consume data from the socket while later making it available again (for things like handshakes).
 type PrefixConn struct {
 	net.Conn
-	r io.Reader
+	prefix io.Reader
+	r      io.Reader
 }
 
 func (pc PrefixConn) Read(p []byte) (int, error) {
 	return pc.r.Read(p)
 }
 
+// Split returns the prefix data not yet read and the underlying connection, for callers that
+// want to read from the connection directly once the prefix is consumed. The PrefixConn must not
+// be read from afterwards.
+func (pc PrefixConn) Split() (io.Reader, net.Conn) {
+	return pc.prefix, pc.Conn
+}
+
 // PrependToConn creates a PrefixConn which allows arbitrary readers to serve as
 // the data source of a net.Conn.
 func PrependToConn(c net.Conn, r io.Reader) PrefixConn {
-	return PrefixConn{Conn: c, r: io.MultiReader(r, c)}
+	return PrefixConn{Conn: c, prefix: r, r: io.MultiReader(r, c)}
 }
 
 // PortSelectorRange provides a generic and basic way to return a seeded port
@@ -0,0 +1,127 @@
+package obfs4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"gitlab.com/yawning/obfs4.git/common/ntor"
+)
+
+// Bucketed lookup
+//
+// The obfs4 handshake only reveals its registration through the mark, an HMAC keyed with the
+// registration keys, so finding the registration of a connection means computing the mark of
+// every registration on the phantom. To avoid this clients tag their handshake with the bucket of
+// their registration, derived from the registration keys, and the station only computes the marks
+// of the registrations in the bucket named by the tag.
+//
+// The tag is written over the start of the random padding that follows the representative, masked
+// with a hash of the representative, and the MAC covering the padding is computed again. The
+// representative is uniformly random, so the tag is too for observers that do not know the
+// registration keys. Handshakes of the same registration can be linked through their unmasked tag,
+// as they could already be through the client address and phantom they share.
+
+const (
+	// bucketLength is the length in bytes of a bucket, so that registrations sharing a phantom
+	// practically never share a bucket. It must not exceed ClientMinPadLength.
+	bucketLength = 4
+
+	// legacyBucket holds the registrations of clients that do not tag their handshake, which
+	// are checked against connections whose tag names no registration.
+	legacyBucket = ""
+)
+
+var errBadHandshake = errors.New("unexpected obfs4 client handshake")
+
+// keysBucket returns the bucket of the registration with the given keys.
+func keysBucket(nodeID *ntor.NodeID, pubkey *ntor.PublicKey) string {
+	h := sha256.New()
+	h.Write([]byte("conjure-obfs4-bucket-keys"))
+	h.Write(pubkey.Bytes()[:])
+	h.Write(nodeID.Bytes()[:])
+	return string(h.Sum(nil)[:bucketLength])
+}
+
+// representativeMask returns the mask of the bucket tag of a handshake with the given
+// representative.
+func representativeMask(representative *ntor.Representative) []byte {
+	h := sha256.New()
+	h.Write([]byte("conjure-obfs4-bucket-representative"))
+	h.Write(representative.Bytes()[:])
+	return h.Sum(nil)[:bucketLength]
+}
+
+// handshakeBucket returns the bucket named by the tag of the client handshake in data, which
+// must hold at least the representative and the tag.
+func handshakeBucket(data []byte, representative *ntor.Representative) string {
+	bucket := make([]byte, bucketLength)
+	subtle.XORBytes(bucket, data[ntor.RepresentativeLength:ntor.RepresentativeLength+bucketLength], representativeMask(representative))
+	return string(bucket)
+}
+
+// tagHandshake returns the client handshake hs tagged with the bucket of the registration keys.
+// The MAC of the handshake is computed again with the epoch hour it was first computed with, as
+// the client checks the response of the server with that hour.
+func tagHandshake(hs []byte, nodeID *ntor.NodeID, pubkey *ntor.PublicKey) ([]byte, error) {
+	if len(hs) < ClientMinHandshakeLength+ClientMinPadLength {
+		return nil, errBadHandshake
+	}
+	macPos := len(hs) - MacLength
+	mac := hmac.New(sha256.New, append(pubkey.Bytes()[:], nodeID.Bytes()[:]...))
+	handshakeMAC := func(hs []byte, epochHour int64) []byte {
+		mac.Reset()
+		mac.Write(hs[:macPos])
+		mac.Write([]byte(strconv.FormatInt(epochHour, 10)))
+		return mac.Sum(nil)[:MacLength]
+	}
+
+	// The handshake was generated at most an hour boundary ago.
+	now := time.Now().Unix() / 3600
+	epochHour := now
+	for ; epochHour >= now-1; epochHour-- {
+		if hmac.Equal(handshakeMAC(hs, epochHour), hs[macPos:]) {
+			break
+		}
+	}
+	if epochHour < now-1 {
+		return nil, errBadHandshake
+	}
+
+	var representative ntor.Representative
+	copy(representative[:], hs)
+	tagged := append([]byte{}, hs...)
+	subtle.XORBytes(tagged[ntor.RepresentativeLength:], []byte(keysBucket(nodeID, pubkey)), representativeMask(&representative))
+	copy(tagged[macPos:], handshakeMAC(tagged, epochHour))
+	return tagged, nil
+}
+
+// bucketConn tags the client handshake, the first write to the connection, with the bucket of the
+// registration keys.
+type bucketConn struct {
+	net.Conn
+	nodeID *ntor.NodeID
+	pubkey *ntor.PublicKey
+
+	tagged bool
+}
+
+func (c *bucketConn) Write(b []byte) (int, error) {
+	if c.tagged {
+		return c.Conn.Write(b)
+	}
+
+	tagged, err := tagHandshake(b, c.nodeID, c.pubkey)
+	if err != nil {
+		return 0, err
+	}
+	c.tagged = true
+	if _, err := c.Conn.Write(tagged); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"strconv"
 
 	pt "git.torproject.org/pluggable-transports/goptlib.git"
 	"github.com/refraction-networking/conjure/pkg/transports"
 	pb "github.com/refraction-networking/conjure/proto"
+	"gitlab.com/yawning/obfs4.git/common/drbg"
 	"gitlab.com/yawning/obfs4.git/transports/obfs4"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
@@ -19,6 +21,10 @@ import (
 type ClientTransport struct {
 	Parameters *pb.GenericTransportParams
 	keys       Obfs4Keys
+
+	// obfs4 specific parameters (IAT mode, padding seed). These are only sent to the station when
+	// set so that stations which only understand GenericTransportParams keep accepting clients.
+	obfs4Params *pb.Obfs4TransportParams
 }
 
 // Name returns a string identifier for the Transport for logging
@@ -40,17 +46,39 @@ func (*ClientTransport) ID() pb.TransportType {
 // GetParams returns a generic protobuf with any parameters from both the registration and the
 // transport.
 func (t *ClientTransport) GetParams() (proto.Message, error) {
-	return t.Parameters, nil
+	if t.obfs4Params == nil {
+		return t.Parameters, nil
+	}
+
+	return &pb.Obfs4TransportParams{
+		RandomizeDstPort: t.Parameters.RandomizeDstPort,
+		IatMode:          t.obfs4Params.IatMode,
+		PaddingSeed:      t.obfs4Params.PaddingSeed,
+	}, nil
 }
 
 // SetParams allows the caller to set parameters associated with the transport, returning an
 // error if the provided generic message is not compatible.
 func (t *ClientTransport) SetParams(p any, unchecked ...bool) error {
-	params, ok := p.(*pb.GenericTransportParams)
-	if !ok {
+	switch params := p.(type) {
+	case *pb.Obfs4TransportParams:
+		if params.IatMode != nil && params.GetIatMode() > maxIATMode {
+			return fmt.Errorf("%w: iat mode %d", ErrBadParams, params.GetIatMode())
+		}
+		if params.PaddingSeed != nil && len(params.GetPaddingSeed()) != drbg.SeedLength {
+			return fmt.Errorf("%w: padding seed length %d", ErrBadParams, len(params.GetPaddingSeed()))
+		}
+		t.Parameters = &pb.GenericTransportParams{RandomizeDstPort: params.RandomizeDstPort}
+		t.obfs4Params = nil
+		if params.IatMode != nil || params.PaddingSeed != nil {
+			t.obfs4Params = params
+		}
+	case *pb.GenericTransportParams:
+		t.Parameters = params
+		t.obfs4Params = nil
+	default:
 		return fmt.Errorf("unable to parse params")
 	}
-	t.Parameters = params
 
 	return nil
 }
@@ -62,6 +90,13 @@ func (t ClientTransport) ParseParams(data *anypb.Any) (any, error) {
 		return nil, nil
 	}
 
+	// Stations that predate the obfs4 specific params respond with GenericTransportParams.
+	if data.MessageIs(&pb.Obfs4TransportParams{}) {
+		var m = &pb.Obfs4TransportParams{}
+		err := transports.UnmarshalAnypbTo(data, m)
+		return m, err
+	}
+
 	var m = &pb.GenericTransportParams{}
 	err := transports.UnmarshalAnypbTo(data, m)
 	return m, err
@@ -84,7 +119,11 @@ func (t ClientTransport) WrapConn(conn net.Conn) (net.Conn, error) {
 
 	args.Add("node-id", t.keys.NodeID.Hex())
 	args.Add("public-key", t.keys.PublicKey.Hex())
-	args.Add("iat-mode", "1")
+	iatMode := uint32(defaultClientIATMode)
+	if t.obfs4Params != nil && t.obfs4Params.IatMode != nil {
+		iatMode = t.obfs4Params.GetIatMode()
+	}
+	args.Add("iat-mode", strconv.Itoa(int(iatMode)))
 
 	c, err := obfsTransport.ClientFactory("")
 	if err != nil {
@@ -97,7 +136,7 @@ func (t ClientTransport) WrapConn(conn net.Conn) (net.Conn, error) {
 	}
 
 	d := func(network, address string) (net.Conn, error) {
-		return conn, nil
+		return &bucketConn{Conn: conn, nodeID: t.keys.NodeID, pubkey: t.keys.PublicKey}, nil
 	}
 
 	return c.Dial("tcp", "", d, parsedArgs)
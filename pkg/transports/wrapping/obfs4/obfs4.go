@@ -2,8 +2,10 @@ package obfs4
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net"
+	"strconv"
 
 	pt "git.torproject.org/pluggable-transports/goptlib.git"
 	cj "github.com/refraction-networking/conjure/pkg/station/lib"
@@ -19,11 +21,23 @@ const (
 	// Earliest client library version ID that supports destination port randomization
 	randomizeDstPortMinVersion uint = 3
 
+	// Earliest client library version ID that tags its handshakes with the bucket of its registration
+	bucketedLookupMinVersion uint = 4
+
 	// port range boundaries for min when randomizing
 	portRangeMin = 22
 	portRangeMax = 65535
+
+	// Largest IAT mode supported by obfs4 (paranoid)
+	maxIATMode = 2
+
+	// IAT mode used by clients that do not set one in their params
+	defaultClientIATMode = 1
 )
 
+// ErrBadParams is returned when the obfs4 transport params of a registration are invalid.
+var ErrBadParams = errors.New("bad obfs4 transport params")
+
 // Transport implements the station Transport interface for the obfs4 transport
 type Transport struct{}
 
@@ -38,6 +52,14 @@ func (Transport) GetIdentifier(r *cj.DecoyRegistration) string {
 	return string(r.Keys.Obfs4Keys.PublicKey.Bytes()[:]) + string(r.Keys.Obfs4Keys.NodeID.Bytes()[:])
 }
 
+// GetBucket implements the station BucketingTransport interface
+func (Transport) GetBucket(r *cj.DecoyRegistration) string {
+	if r.ClientLibVersion() < bucketedLookupMinVersion {
+		return legacyBucket
+	}
+	return keysBucket(r.Keys.Obfs4Keys.NodeID, r.Keys.Obfs4Keys.PublicKey)
+}
+
 // GetProto returns the next layer protocol that the transport uses. Implements
 // the Transport interface.
 func (Transport) GetProto() pb.IPProto {
@@ -51,24 +73,84 @@ func (Transport) ParseParams(libVersion uint, data *anypb.Any) (any, error) {
 		return nil, nil
 	}
 
-	// For backwards compatibility we create a generic transport params object
-	// for transports that existed before the transportParams fields existed.
+	// For backwards compatibility we create a transport params object for
+	// transports that existed before the transportParams fields existed.
 	if libVersion < randomizeDstPortMinVersion {
 		f := false
-		return &pb.GenericTransportParams{
+		return &pb.Obfs4TransportParams{
 			RandomizeDstPort: &f,
 		}, nil
 	}
 
-	var m = &pb.GenericTransportParams{}
+	// Clients from before the obfs4 specific params send generic params. Both share the
+	// randomize_dst_port field, so params sent without a type url parse as either.
+	if data.MessageIs(&pb.GenericTransportParams{}) {
+		var g = &pb.GenericTransportParams{}
+		err := transports.UnmarshalAnypbTo(data, g)
+		return &pb.Obfs4TransportParams{RandomizeDstPort: g.RandomizeDstPort}, err
+	}
+
+	var m = &pb.Obfs4TransportParams{}
 	err := transports.UnmarshalAnypbTo(data, m)
-	return m, err
+	if err != nil {
+		return nil, err
+	}
+	if m.IatMode != nil && m.GetIatMode() > maxIATMode {
+		return nil, fmt.Errorf("%w: iat mode %d", ErrBadParams, m.GetIatMode())
+	}
+	if m.PaddingSeed != nil && len(m.GetPaddingSeed()) != drbg.SeedLength {
+		return nil, fmt.Errorf("%w: padding seed length %d", ErrBadParams, len(m.GetPaddingSeed()))
+	}
+	return m, nil
 }
 
 // ParamStrings returns an array of tag string that will be added to tunStats when a proxy
-// session is closed. For now, no params of interest.
+// session is closed.
 func (t Transport) ParamStrings(p any) []string {
-	return nil
+	params, ok := p.(*pb.Obfs4TransportParams)
+	if !ok {
+		return nil
+	}
+
+	var out []string
+	if params.IatMode != nil {
+		out = append(out, "iat-mode="+strconv.Itoa(int(params.GetIatMode())))
+	}
+	if params.PaddingSeed != nil {
+		out = append(out, "padding-seed")
+	}
+	return out
+}
+
+// serverArgs returns the obfs4 server args for registration r, applying the IAT mode and padding
+// seed of its transport params.
+func serverArgs(r *cj.DecoyRegistration) (*pt.Args, error) {
+	args := pt.Args{}
+	args.Add("node-id", r.Keys.Obfs4Keys.NodeID.Hex())
+	args.Add("private-key", r.Keys.Obfs4Keys.PrivateKey.Hex())
+
+	var iatMode *uint32
+	var paddingSeed []byte
+	if params, ok := r.TransportParams.(*pb.Obfs4TransportParams); ok && params != nil {
+		iatMode, paddingSeed = params.IatMode, params.PaddingSeed
+	}
+	if iatMode != nil {
+		args.Add("iat-mode", strconv.Itoa(int(*iatMode)))
+	}
+
+	var seed *drbg.Seed
+	var err error
+	if paddingSeed != nil {
+		seed, err = drbg.SeedFromBytes(paddingSeed)
+	} else {
+		seed, err = drbg.NewSeed()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DRBG seed: %w", err)
+	}
+	args.Add("drbg-seed", seed.Hex())
+
+	return &args, nil
 }
 
 // WrapConnection implements the station Transport interface
@@ -80,26 +162,17 @@ func (Transport) WrapConnection(data *bytes.Buffer, c net.Conn, phantom net.IP,
 	var representative ntor.Representative
 	copy(representative[:ntor.RepresentativeLength], data.Bytes()[:ntor.RepresentativeLength])
 
-	for _, r := range getObfs4Registrations(regManager, phantom) {
-		mark := generateMark(r.Keys.Obfs4Keys.NodeID, r.Keys.Obfs4Keys.PublicKey, &representative)
-		pos := findMarkMac(mark, data.Bytes(), ntor.RepresentativeLength+ClientMinPadLength, MaxHandshakeLength, true)
-		if pos == -1 {
-			continue
-		}
-
+	r := findRegistration(data.Bytes(), &representative, phantom, regManager)
+	if r != nil {
 		// We found the mark in the client handshake! We found our registration!
-		args := pt.Args{}
-		args.Add("node-id", r.Keys.Obfs4Keys.NodeID.Hex())
-		args.Add("private-key", r.Keys.Obfs4Keys.PrivateKey.Hex())
-		seed, err := drbg.NewSeed()
+		args, err := serverArgs(r)
 		if err != nil {
-			return nil, nil, fmt.Errorf("failed to create DRBG seed: %w", err)
+			return nil, nil, err
 		}
-		args.Add("drbg-seed", seed.Hex())
 
 		t := &obfs4.Transport{}
 
-		factory, err := t.ServerFactory("", &args)
+		factory, err := t.ServerFactory("", args)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to create server factory: %w", err)
 		}
@@ -122,19 +195,22 @@ func (Transport) WrapConnection(data *bytes.Buffer, c net.Conn, phantom net.IP,
 	return nil, nil, transports.ErrNotTransport
 }
 
-// This function makes the assumption that any identifier with length 52 is an obfs4 registration.
-// This may not be strictly true, but any other identifier will simply fail to form a connection and
-// should be harmless.
-func getObfs4Registrations(regManager *cj.RegistrationManager, darkDecoyAddr net.IP) []*cj.DecoyRegistration {
-	var regs []*cj.DecoyRegistration
-
-	for identifier, r := range regManager.GetRegistrations(darkDecoyAddr) {
-		if len(identifier) == ntor.PublicKeyLength+ntor.NodeIDLength {
-			regs = append(regs, r)
+// findRegistration returns the registration whose mark is in the client handshake in data, or nil
+// if there is none. Only the registrations in the bucket named by the handshake and those of legacy
+// clients are checked.
+func findRegistration(data []byte, representative *ntor.Representative, phantom net.IP, regManager *cj.RegistrationManager) *cj.DecoyRegistration {
+	regs := regManager.GetRegistrationsInBucket(phantom, handshakeBucket(data, representative))
+	regs = append(regs, regManager.GetRegistrationsInBucket(phantom, legacyBucket)...)
+
+	for _, r := range regs {
+		mark := generateMark(r.Keys.Obfs4Keys.NodeID, r.Keys.Obfs4Keys.PublicKey, representative)
+		pos := findMarkMac(mark, data, ntor.RepresentativeLength+ClientMinPadLength, MaxHandshakeLength, true)
+		if pos != -1 {
+			return r
 		}
 	}
 
-	return regs
+	return nil
 }
 
 // GetDstPort Given the library version, a seed, and a generic object
@@ -150,7 +226,7 @@ func (Transport) GetDstPort(libVersion uint, seed []byte, params any) (uint16, e
 		return 443, nil
 	}
 
-	parameters, ok := params.(*pb.GenericTransportParams)
+	parameters, ok := params.(*pb.Obfs4TransportParams)
 	if !ok {
 		return 0, fmt.Errorf("bad parameters provided")
 	}
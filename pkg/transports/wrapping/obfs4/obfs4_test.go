@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"io"
 	"log"
 	"math/rand"
@@ -18,6 +19,7 @@ import (
 	"github.com/refraction-networking/conjure/pkg/transports"
 	"github.com/refraction-networking/conjure/pkg/transports/wrapping/internal/tests"
 	pb "github.com/refraction-networking/conjure/proto"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/anypb"
 
 	pt "git.torproject.org/pluggable-transports/goptlib.git"
@@ -303,3 +305,366 @@ func TestTryParamsToDstPort(t *testing.T) {
 		require.Equal(t, testCase.p, port)
 	}
 }
+
+func TestParseParams(t *testing.T) {
+	var transport Transport
+	seed := make([]byte, drbg.SeedLength)
+
+	generic, err := anypb.New(&pb.GenericTransportParams{RandomizeDstPort: proto.Bool(true)})
+	require.Nil(t, err)
+	params, err := transport.ParseParams(randomizeDstPortMinVersion, generic)
+	require.Nil(t, err)
+	require.True(t, params.(*pb.Obfs4TransportParams).GetRandomizeDstPort())
+	require.Nil(t, transport.ParamStrings(params))
+
+	params, err = transport.ParseParams(randomizeDstPortMinVersion-1, generic)
+	require.Nil(t, err)
+	require.False(t, params.(*pb.Obfs4TransportParams).GetRandomizeDstPort())
+
+	cases := []struct {
+		p    *pb.Obfs4TransportParams
+		err  error
+		tags []string
+	}{
+		{&pb.Obfs4TransportParams{IatMode: proto.Uint32(0)}, nil, []string{"iat-mode=0"}},
+		{&pb.Obfs4TransportParams{IatMode: proto.Uint32(2), PaddingSeed: seed}, nil, []string{"iat-mode=2", "padding-seed"}},
+		{&pb.Obfs4TransportParams{IatMode: proto.Uint32(3)}, ErrBadParams, nil},
+		{&pb.Obfs4TransportParams{PaddingSeed: seed[1:]}, ErrBadParams, nil},
+	}
+	for _, c := range cases {
+		data, err := anypb.New(c.p)
+		require.Nil(t, err)
+		// Clients may leave out the type url to save space.
+		data.TypeUrl = ""
+
+		params, err := transport.ParseParams(randomizeDstPortMinVersion, data)
+		require.ErrorIs(t, err, c.err)
+		if c.err == nil {
+			require.Equal(t, c.tags, transport.ParamStrings(params))
+		}
+
+		var client ClientTransport
+		require.ErrorIs(t, client.SetParams(c.p), c.err)
+	}
+}
+
+// TestClientParamsCompat ensures that clients only send Obfs4TransportParams when obfs4 specific
+// options are set, so stations that only understand GenericTransportParams keep accepting them.
+func TestClientParamsCompat(t *testing.T) {
+	var transport Transport
+	var client ClientTransport
+	require.Nil(t, client.SetParams(&pb.GenericTransportParams{RandomizeDstPort: proto.Bool(true)}))
+
+	params, err := client.GetParams()
+	require.Nil(t, err)
+	data, err := anypb.New(params)
+	require.Nil(t, err)
+
+	// A station without obfs4 specific params unmarshals into GenericTransportParams.
+	var old = &pb.GenericTransportParams{}
+	require.Nil(t, transports.UnmarshalAnypbTo(data, old))
+	require.True(t, old.GetRandomizeDstPort())
+
+	newParams, err := transport.ParseParams(randomizeDstPortMinVersion, data)
+	require.Nil(t, err)
+	require.True(t, newParams.(*pb.Obfs4TransportParams).GetRandomizeDstPort())
+
+	// Obfs4TransportParams without obfs4 specific options are still sent as generic params.
+	require.Nil(t, client.SetParams(&pb.Obfs4TransportParams{RandomizeDstPort: proto.Bool(true)}))
+	params, err = client.GetParams()
+	require.Nil(t, err)
+	require.IsType(t, &pb.GenericTransportParams{}, params)
+
+	require.Nil(t, client.SetParams(&pb.Obfs4TransportParams{RandomizeDstPort: proto.Bool(true), IatMode: proto.Uint32(0)}))
+	params, err = client.GetParams()
+	require.Nil(t, err)
+	data, err = anypb.New(params)
+	require.Nil(t, err)
+	require.NotNil(t, transports.UnmarshalAnypbTo(data, &pb.GenericTransportParams{}))
+
+	newParams, err = transport.ParseParams(randomizeDstPortMinVersion, data)
+	require.Nil(t, err)
+	require.True(t, newParams.(*pb.Obfs4TransportParams).GetRandomizeDstPort())
+	require.Equal(t, []string{"iat-mode=0"}, transport.ParamStrings(newParams))
+
+	// Responses from stations that only know GenericTransportParams parse on the client.
+	generic, err := anypb.New(&pb.GenericTransportParams{RandomizeDstPort: proto.Bool(false)})
+	require.Nil(t, err)
+	resp, err := client.ParseParams(generic)
+	require.Nil(t, err)
+	require.IsType(t, &pb.GenericTransportParams{}, resp)
+}
+
+// TestSuccessfulWrapParams ensures that the station honors the IAT mode and padding seed of the
+// registration transport params.
+func TestSuccessfulWrapParams(t *testing.T) {
+	cwd, err := os.Getwd()
+	require.Nil(t, err)
+	testSubnetPath := cwd + "/../internal/tests/phantom_subnets_min.toml"
+
+	seed := bytes.Repeat([]byte{0x42}, drbg.SeedLength)
+	params := &pb.Obfs4TransportParams{IatMode: proto.Uint32(2), PaddingSeed: seed}
+
+	var transport Transport
+	manager := tests.SetupRegistrationManager(tests.Transport{Index: pb.TransportType_Obfs4, Transport: transport})
+	c2p, sfp, reg := tests.SetupPhantomConnectionsSecret(manager, pb.TransportType_Obfs4, params, tests.SharedSecret, bucketedLookupMinVersion, testSubnetPath)
+	defer c2p.Close()
+	defer sfp.Close()
+
+	args, err := serverArgs(reg)
+	require.Nil(t, err)
+	iatMode, _ := args.Get("iat-mode")
+	require.Equal(t, "2", iatMode)
+	drbgSeed, _ := args.Get("drbg-seed")
+	require.Equal(t, hex.EncodeToString(seed), drbgSeed)
+
+	client := ClientTransport{keys: Obfs4Keys(reg.Keys.Obfs4Keys)}
+	require.Nil(t, client.SetParams(params))
+	wrappedc2p := make(chan net.Conn)
+	go func() {
+		w, err := client.WrapConn(c2p)
+		if err != nil {
+			log.Fatalln("failed to wrap connection:", err)
+		}
+		wrappedc2p <- w
+	}()
+
+	var buf [4096]byte
+	var buffer bytes.Buffer
+	var wrappedsfp net.Conn
+	for {
+		n, _ := sfp.Read(buf[:])
+		buffer.Write(buf[:n])
+
+		_, wrappedsfp, err = transport.WrapConnection(&buffer, sfp, reg.PhantomIp, manager)
+		if errors.Is(err, transports.ErrTryAgain) {
+			continue
+		} else if err != nil {
+			t.Fatalf("expected nil or ErrTryAgain, got %v", err)
+		}
+
+		break
+	}
+
+	select {
+	case c2p = <-wrappedc2p:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timed out waiting for wrapped client connection")
+	}
+
+	message := []byte(`test message!`)
+	go func() {
+		_, _ = wrappedsfp.Write(message)
+	}()
+	received := make([]byte, len(message))
+	_, err = io.ReadFull(c2p, received)
+	require.Nil(t, err)
+	require.Equal(t, message, received)
+}
+
+// TestSuccessfulWrapBucketed ensures that clients recent enough to tag their handshake with the
+// bucket of their registration are found among registrations sharing the same phantom.
+func TestSuccessfulWrapBucketed(t *testing.T) {
+	var err error
+
+	cwd, err := os.Getwd()
+	require.Nil(t, err)
+	testSubnetPath := cwd + "/../internal/tests/phantom_subnets_min.toml"
+
+	sharedSecrets := [][]byte{
+		[]byte(`b07c17169ac6c4ec77de4b795e939e3994dc708be1afb6bcd5e646941cf97f35`),
+		[]byte(`3143952b9355b6187ddc6104eb9ea85fca52ba5f8d88a93f73910f860b133217`),
+		tests.SharedSecret,
+	}
+
+	var transport Transport
+	manager := tests.SetupRegistrationManager(tests.Transport{Index: pb.TransportType_Obfs4, Transport: transport})
+	var c2p, sfp net.Conn
+	var reg *dd.DecoyRegistration
+	for _, secret := range sharedSecrets {
+		c2p, sfp, reg = tests.SetupPhantomConnectionsSecret(manager, pb.TransportType_Obfs4, nil, secret, bucketedLookupMinVersion, testSubnetPath)
+	}
+	defer c2p.Close()
+	defer sfp.Close()
+
+	bucket := keysBucket(reg.Keys.Obfs4Keys.NodeID, reg.Keys.Obfs4Keys.PublicKey)
+	require.Contains(t, manager.GetRegistrationsInBucket(reg.PhantomIp, bucket), reg)
+	require.Len(t, manager.GetRegistrationsInBucket(reg.PhantomIp, legacyBucket), 0)
+
+	client := ClientTransport{keys: Obfs4Keys(reg.Keys.Obfs4Keys)}
+	wrappedc2p := make(chan net.Conn)
+	go func() {
+		w, err := client.WrapConn(c2p)
+		if err != nil {
+			log.Fatalln("failed to wrap connection:", err)
+		}
+		wrappedc2p <- w
+	}()
+
+	var buf [4096]byte
+	var buffer bytes.Buffer
+	var wrappedsfp net.Conn
+	for {
+		n, _ := sfp.Read(buf[:])
+		buffer.Write(buf[:n])
+
+		if buffer.Len() >= ntor.RepresentativeLength+bucketLength {
+			var representative ntor.Representative
+			copy(representative[:], buffer.Bytes())
+			require.Equal(t, bucket, handshakeBucket(buffer.Bytes(), &representative))
+		}
+
+		_, wrappedsfp, err = transport.WrapConnection(&buffer, sfp, reg.PhantomIp, manager)
+		if errors.Is(err, transports.ErrTryAgain) {
+			continue
+		} else if err != nil {
+			t.Fatalf("expected nil or ErrTryAgain, got %v", err)
+		}
+
+		break
+	}
+
+	select {
+	case c2p = <-wrappedc2p:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timed out waiting for wrapped client connection")
+	}
+
+	message := []byte(`test message!`)
+	_, err = c2p.Write(message)
+	require.Nil(t, err)
+
+	received := make([]byte, len(message))
+	_, err = io.ReadFull(wrappedsfp, received)
+	require.Nil(t, err)
+	require.Equal(t, message, received)
+}
+
+// captureConn is a net.Conn that keeps what is written to it and fails all reads.
+type captureConn struct {
+	buf bytes.Buffer
+}
+
+func (c *captureConn) Read([]byte) (int, error)         { return 0, net.ErrClosed }
+func (c *captureConn) Write(b []byte) (int, error)      { return c.buf.Write(b) }
+func (c *captureConn) Close() error                     { return nil }
+func (c *captureConn) LocalAddr() net.Addr              { return nil }
+func (c *captureConn) RemoteAddr() net.Addr             { return nil }
+func (c *captureConn) SetDeadline(time.Time) error      { return nil }
+func (c *captureConn) SetReadDeadline(time.Time) error  { return nil }
+func (c *captureConn) SetWriteDeadline(time.Time) error { return nil }
+
+// captureHandshake returns the client handshake of a client with keys.
+func captureHandshake(t testing.TB, keys Obfs4Keys) []byte {
+	conn := &captureConn{}
+	client := ClientTransport{keys: keys}
+	// The handshake fails once it waits for the response of the server.
+	_, err := client.WrapConn(conn)
+	require.NotNil(t, err)
+	return conn.buf.Bytes()
+}
+
+func TestTagHandshake(t *testing.T) {
+	keys, err := generateObfs4Keys(rand.New(rand.NewSource(0)))
+	require.Nil(t, err)
+
+	data := captureHandshake(t, keys)
+	var representative ntor.Representative
+	copy(representative[:], data)
+	require.Equal(t, keysBucket(keys.NodeID, keys.PublicKey), handshakeBucket(data, &representative))
+
+	// Tagging is only done to the handshake, so one that is not is refused.
+	_, err = tagHandshake(data[:ClientMinHandshakeLength], keys.NodeID, keys.PublicKey)
+	require.ErrorIs(t, err, errBadHandshake)
+	otherKeys, err := generateObfs4Keys(rand.New(rand.NewSource(1)))
+	require.Nil(t, err)
+	_, err = tagHandshake(data, otherKeys.NodeID, otherKeys.PublicKey)
+	require.ErrorIs(t, err, errBadHandshake)
+}
+
+// TestFindRegistrationLegacy ensures that a legacy client is found on its first attempt however
+// many legacy registrations share its phantom.
+func TestFindRegistrationLegacy(t *testing.T) {
+	manager, regs := setupRegistrations(t, bucketedLookupMinVersion-1, 100)
+	reg := regs[len(regs)-1]
+	require.Len(t, manager.GetRegistrationsInBucket(reg.PhantomIp, legacyBucket), len(regs))
+
+	data := captureHandshake(t, Obfs4Keys(reg.Keys.Obfs4Keys))
+	var representative ntor.Representative
+	copy(representative[:], data)
+
+	for i := 0; i < 10; i++ {
+		require.Equal(t, reg, findRegistration(data, &representative, reg.PhantomIp, manager))
+	}
+}
+
+// setupRegistrations returns a registration manager tracking n valid obfs4 registrations on the
+// same phantom, all made by clients with library version libver.
+func setupRegistrations(t testing.TB, libver uint, n int) (*dd.RegistrationManager, []*dd.DecoyRegistration) {
+	cwd, err := os.Getwd()
+	require.Nil(t, err)
+	os.Setenv("PHANTOM_SUBNET_LOCATION", cwd+"/../internal/tests/phantom_subnets_min.toml")
+
+	var transport Transport
+	manager := tests.SetupRegistrationManager(tests.Transport{Index: pb.TransportType_Obfs4, Transport: transport})
+
+	v := uint32(libver)
+	tt := pb.TransportType_Obfs4
+	covert := "1.2.3.4:56789"
+	gen := uint32(1)
+	regType := pb.RegistrationSource_API
+	c2s := &pb.ClientToStation{
+		ClientLibVersion:    &v,
+		Transport:           &tt,
+		CovertAddress:       &covert,
+		DecoyListGeneration: &gen,
+	}
+
+	var regs []*dd.DecoyRegistration
+	for i := 0; i < n; i++ {
+		keys, err := dd.GenSharedKeys(libver, []byte(fmt.Sprintf("%064x", i)), tt)
+		require.Nil(t, err)
+		reg, err := manager.NewRegistration(c2s, &keys, false, &regType)
+		require.Nil(t, err)
+		// Mark the registration valid directly rather than through AddRegistration, which
+		// publishes every registration to the detector.
+		require.Nil(t, manager.TrackRegistration(reg))
+		reg.Valid = true
+		regs = append(regs, reg)
+	}
+	require.Equal(t, n, manager.CountRegistrations(regs[0].PhantomIp))
+	return manager, regs
+}
+
+// BenchmarkFindRegistrationBucketed measures finding the registration of a client handshake among
+// 10k registrations on the same phantom.
+func BenchmarkFindRegistrationBucketed(b *testing.B) {
+	manager, regs := setupRegistrations(b, bucketedLookupMinVersion, 10000)
+	reg := regs[len(regs)-1]
+	data := captureHandshake(b, Obfs4Keys(reg.Keys.Obfs4Keys))
+	var representative ntor.Representative
+	copy(representative[:], data)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		found := findRegistration(data, &representative, reg.PhantomIp, manager)
+		require.Equal(b, reg, found)
+	}
+}
+
+// BenchmarkFindRegistrationUnknown measures checking a handshake that names no registration
+// against 10k legacy registrations on the same phantom.
+func BenchmarkFindRegistrationUnknown(b *testing.B) {
+	manager, regs := setupRegistrations(b, bucketedLookupMinVersion-1, 10000)
+	keys, err := generateObfs4Keys(rand.New(rand.NewSource(0)))
+	require.Nil(b, err)
+	data := captureHandshake(b, keys)
+	var representative ntor.Representative
+	copy(representative[:], data)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		found := findRegistration(data, &representative, regs[0].PhantomIp, manager)
+		require.Nil(b, found)
+	}
+}
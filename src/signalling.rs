@@ -2677,6 +2677,8 @@ pub struct RegistrationFlags {
     pub use_TIL: ::std::option::Option<bool>,
     // @@protoc_insertion_point(field:tapdance.RegistrationFlags.prescanned)
     pub prescanned: ::std::option::Option<bool>,
+    // @@protoc_insertion_point(field:tapdance.RegistrationFlags.multiplex)
+    pub multiplex: ::std::option::Option<bool>,
     // special fields
     // @@protoc_insertion_point(special_field:tapdance.RegistrationFlags.special_fields)
     pub special_fields: ::protobuf::SpecialFields,
@@ -2788,8 +2790,27 @@ impl RegistrationFlags {
         self.prescanned = ::std::option::Option::Some(v);
     }
 
+    // optional bool multiplex = 6;
+
+    pub fn multiplex(&self) -> bool {
+        self.multiplex.unwrap_or(false)
+    }
+
+    pub fn clear_multiplex(&mut self) {
+        self.multiplex = ::std::option::Option::None;
+    }
+
+    pub fn has_multiplex(&self) -> bool {
+        self.multiplex.is_some()
+    }
+
+    // Param is passed by value, moved
+    pub fn set_multiplex(&mut self, v: bool) {
+        self.multiplex = ::std::option::Option::Some(v);
+    }
+
     fn generated_message_descriptor_data() -> ::protobuf::reflect::GeneratedMessageDescriptorData {
-        let mut fields = ::std::vec::Vec::with_capacity(5);
+        let mut fields = ::std::vec::Vec::with_capacity(6);
         let mut oneofs = ::std::vec::Vec::with_capacity(0);
         fields.push(::protobuf::reflect::rt::v2::make_option_accessor::<_, _>(
             "upload_only",
@@ -2816,6 +2837,11 @@ impl RegistrationFlags {
             |m: &RegistrationFlags| { &m.prescanned },
             |m: &mut RegistrationFlags| { &mut m.prescanned },
         ));
+        fields.push(::protobuf::reflect::rt::v2::make_option_accessor::<_, _>(
+            "multiplex",
+            |m: &RegistrationFlags| { &m.multiplex },
+            |m: &mut RegistrationFlags| { &mut m.multiplex },
+        ));
         ::protobuf::reflect::GeneratedMessageDescriptorData::new_2::<RegistrationFlags>(
             "RegistrationFlags",
             fields,
@@ -2849,6 +2875,9 @@ impl ::protobuf::Message for RegistrationFlags {
                 40 => {
                     self.prescanned = ::std::option::Option::Some(is.read_bool()?);
                 },
+                48 => {
+                    self.multiplex = ::std::option::Option::Some(is.read_bool()?);
+                },
                 tag => {
                     ::protobuf::rt::read_unknown_or_skip_group(tag, is, self.special_fields.mut_unknown_fields())?;
                 },
@@ -2876,6 +2905,9 @@ impl ::protobuf::Message for RegistrationFlags {
         if let Some(v) = self.prescanned {
             my_size += 1 + 1;
         }
+        if let Some(v) = self.multiplex {
+            my_size += 1 + 1;
+        }
         my_size += ::protobuf::rt::unknown_fields_size(self.special_fields.unknown_fields());
         self.special_fields.cached_size().set(my_size as u32);
         my_size
@@ -2897,6 +2929,9 @@ impl ::protobuf::Message for RegistrationFlags {
         if let Some(v) = self.prescanned {
             os.write_bool(5, v)?;
         }
+        if let Some(v) = self.multiplex {
+            os.write_bool(6, v)?;
+        }
         os.write_unknown_fields(self.special_fields.unknown_fields())?;
         ::std::result::Result::Ok(())
     }
@@ -2919,6 +2954,7 @@ impl ::protobuf::Message for RegistrationFlags {
         self.proxy_header = ::std::option::Option::None;
         self.use_TIL = ::std::option::Option::None;
         self.prescanned = ::std::option::Option::None;
+        self.multiplex = ::std::option::Option::None;
         self.special_fields.clear();
     }
 
@@ -2929,6 +2965,7 @@ impl ::protobuf::Message for RegistrationFlags {
             proxy_header: ::std::option::Option::None,
             use_TIL: ::std::option::Option::None,
             prescanned: ::std::option::Option::None,
+            multiplex: ::std::option::Option::None,
             special_fields: ::protobuf::SpecialFields::new(),
         };
         &instance
@@ -4115,6 +4152,245 @@ impl ::protobuf::reflect::ProtobufValue for GenericTransportParams {
     type RuntimeType = ::protobuf::reflect::rt::RuntimeTypeMessage<Self>;
 }
 
+#[derive(PartialEq,Clone,Default,Debug)]
+// @@protoc_insertion_point(message:tapdance.Obfs4TransportParams)
+pub struct Obfs4TransportParams {
+    // message fields
+    ///  Inter-arrival time obfuscation mode used by both sides of the connection: 0 (off), 1 (on) or
+    ///  2 (paranoid). When unset the client uses 1 and the station 0.
+    // @@protoc_insertion_point(field:tapdance.Obfs4TransportParams.iat_mode)
+    pub iat_mode: ::std::option::Option<u32>,
+    ///  Seed (24 bytes) of the packet length and inter-arrival time distributions of the station side
+    ///  of the connection. When unset the station uses a random seed for every connection.
+    // @@protoc_insertion_point(field:tapdance.Obfs4TransportParams.padding_seed)
+    pub padding_seed: ::std::option::Option<::std::vec::Vec<u8>>,
+    ///  Indicates whether the client has elected to use destination port randomization. Should be
+    ///  checked against selected transport to ensure that destination port randomization is
+    ///  supported.
+    // @@protoc_insertion_point(field:tapdance.Obfs4TransportParams.randomize_dst_port)
+    pub randomize_dst_port: ::std::option::Option<bool>,
+    // special fields
+    // @@protoc_insertion_point(special_field:tapdance.Obfs4TransportParams.special_fields)
+    pub special_fields: ::protobuf::SpecialFields,
+}
+
+impl<'a> ::std::default::Default for &'a Obfs4TransportParams {
+    fn default() -> &'a Obfs4TransportParams {
+        <Obfs4TransportParams as ::protobuf::Message>::default_instance()
+    }
+}
+
+impl Obfs4TransportParams {
+    pub fn new() -> Obfs4TransportParams {
+        ::std::default::Default::default()
+    }
+
+    // optional uint32 iat_mode = 1;
+
+    pub fn iat_mode(&self) -> u32 {
+        self.iat_mode.unwrap_or(0)
+    }
+
+    pub fn clear_iat_mode(&mut self) {
+        self.iat_mode = ::std::option::Option::None;
+    }
+
+    pub fn has_iat_mode(&self) -> bool {
+        self.iat_mode.is_some()
+    }
+
+    // Param is passed by value, moved
+    pub fn set_iat_mode(&mut self, v: u32) {
+        self.iat_mode = ::std::option::Option::Some(v);
+    }
+
+    // optional bytes padding_seed = 2;
+
+    pub fn padding_seed(&self) -> &[u8] {
+        match self.padding_seed.as_ref() {
+            Some(v) => v,
+            None => &[],
+        }
+    }
+
+    pub fn clear_padding_seed(&mut self) {
+        self.padding_seed = ::std::option::Option::None;
+    }
+
+    pub fn has_padding_seed(&self) -> bool {
+        self.padding_seed.is_some()
+    }
+
+    // Param is passed by value, moved
+    pub fn set_padding_seed(&mut self, v: ::std::vec::Vec<u8>) {
+        self.padding_seed = ::std::option::Option::Some(v);
+    }
+
+    // Mutable pointer to the field.
+    // If field is not initialized, it is initialized with default value first.
+    pub fn mut_padding_seed(&mut self) -> &mut ::std::vec::Vec<u8> {
+        if self.padding_seed.is_none() {
+            self.padding_seed = ::std::option::Option::Some(::std::vec::Vec::new());
+        }
+        self.padding_seed.as_mut().unwrap()
+    }
+
+    // Take field
+    pub fn take_padding_seed(&mut self) -> ::std::vec::Vec<u8> {
+        self.padding_seed.take().unwrap_or_else(|| ::std::vec::Vec::new())
+    }
+
+    // optional bool randomize_dst_port = 13;
+
+    pub fn randomize_dst_port(&self) -> bool {
+        self.randomize_dst_port.unwrap_or(false)
+    }
+
+    pub fn clear_randomize_dst_port(&mut self) {
+        self.randomize_dst_port = ::std::option::Option::None;
+    }
+
+    pub fn has_randomize_dst_port(&self) -> bool {
+        self.randomize_dst_port.is_some()
+    }
+
+    // Param is passed by value, moved
+    pub fn set_randomize_dst_port(&mut self, v: bool) {
+        self.randomize_dst_port = ::std::option::Option::Some(v);
+    }
+
+    fn generated_message_descriptor_data() -> ::protobuf::reflect::GeneratedMessageDescriptorData {
+        let mut fields = ::std::vec::Vec::with_capacity(3);
+        let mut oneofs = ::std::vec::Vec::with_capacity(0);
+        fields.push(::protobuf::reflect::rt::v2::make_option_accessor::<_, _>(
+            "iat_mode",
+            |m: &Obfs4TransportParams| { &m.iat_mode },
+            |m: &mut Obfs4TransportParams| { &mut m.iat_mode },
+        ));
+        fields.push(::protobuf::reflect::rt::v2::make_option_accessor::<_, _>(
+            "padding_seed",
+            |m: &Obfs4TransportParams| { &m.padding_seed },
+            |m: &mut Obfs4TransportParams| { &mut m.padding_seed },
+        ));
+        fields.push(::protobuf::reflect::rt::v2::make_option_accessor::<_, _>(
+            "randomize_dst_port",
+            |m: &Obfs4TransportParams| { &m.randomize_dst_port },
+            |m: &mut Obfs4TransportParams| { &mut m.randomize_dst_port },
+        ));
+        ::protobuf::reflect::GeneratedMessageDescriptorData::new_2::<Obfs4TransportParams>(
+            "Obfs4TransportParams",
+            fields,
+            oneofs,
+        )
+    }
+}
+
+impl ::protobuf::Message for Obfs4TransportParams {
+    const NAME: &'static str = "Obfs4TransportParams";
+
+    fn is_initialized(&self) -> bool {
+        true
+    }
+
+    fn merge_from(&mut self, is: &mut ::protobuf::CodedInputStream<'_>) -> ::protobuf::Result<()> {
+        while let Some(tag) = is.read_raw_tag_or_eof()? {
+            match tag {
+                8 => {
+                    self.iat_mode = ::std::option::Option::Some(is.read_uint32()?);
+                },
+                18 => {
+                    self.padding_seed = ::std::option::Option::Some(is.read_bytes()?);
+                },
+                104 => {
+                    self.randomize_dst_port = ::std::option::Option::Some(is.read_bool()?);
+                },
+                tag => {
+                    ::protobuf::rt::read_unknown_or_skip_group(tag, is, self.special_fields.mut_unknown_fields())?;
+                },
+            };
+        }
+        ::std::result::Result::Ok(())
+    }
+
+    // Compute sizes of nested messages
+    #[allow(unused_variables)]
+    fn compute_size(&self) -> u64 {
+        let mut my_size = 0;
+        if let Some(v) = self.iat_mode {
+            my_size += ::protobuf::rt::uint32_size(1, v);
+        }
+        if let Some(v) = self.padding_seed.as_ref() {
+            my_size += ::protobuf::rt::bytes_size(2, &v);
+        }
+        if let Some(v) = self.randomize_dst_port {
+            my_size += 1 + 1;
+        }
+        my_size += ::protobuf::rt::unknown_fields_size(self.special_fields.unknown_fields());
+        self.special_fields.cached_size().set(my_size as u32);
+        my_size
+    }
+
+    fn write_to_with_cached_sizes(&self, os: &mut ::protobuf::CodedOutputStream<'_>) -> ::protobuf::Result<()> {
+        if let Some(v) = self.iat_mode {
+            os.write_uint32(1, v)?;
+        }
+        if let Some(v) = self.padding_seed.as_ref() {
+            os.write_bytes(2, v)?;
+        }
+        if let Some(v) = self.randomize_dst_port {
+            os.write_bool(13, v)?;
+        }
+        os.write_unknown_fields(self.special_fields.unknown_fields())?;
+        ::std::result::Result::Ok(())
+    }
+
+    fn special_fields(&self) -> &::protobuf::SpecialFields {
+        &self.special_fields
+    }
+
+    fn mut_special_fields(&mut self) -> &mut ::protobuf::SpecialFields {
+        &mut self.special_fields
+    }
+
+    fn new() -> Obfs4TransportParams {
+        Obfs4TransportParams::new()
+    }
+
+    fn clear(&mut self) {
+        self.iat_mode = ::std::option::Option::None;
+        self.padding_seed = ::std::option::Option::None;
+        self.randomize_dst_port = ::std::option::Option::None;
+        self.special_fields.clear();
+    }
+
+    fn default_instance() -> &'static Obfs4TransportParams {
+        static instance: Obfs4TransportParams = Obfs4TransportParams {
+            iat_mode: ::std::option::Option::None,
+            padding_seed: ::std::option::Option::None,
+            randomize_dst_port: ::std::option::Option::None,
+            special_fields: ::protobuf::SpecialFields::new(),
+        };
+        &instance
+    }
+}
+
+impl ::protobuf::MessageFull for Obfs4TransportParams {
+    fn descriptor() -> ::protobuf::reflect::MessageDescriptor {
+        static descriptor: ::protobuf::rt::Lazy<::protobuf::reflect::MessageDescriptor> = ::protobuf::rt::Lazy::new();
+        descriptor.get(|| file_descriptor().message_by_package_relative_name("Obfs4TransportParams").unwrap()).clone()
+    }
+}
+
+impl ::std::fmt::Display for Obfs4TransportParams {
+    fn fmt(&self, f: &mut ::std::fmt::Formatter<'_>) -> ::std::fmt::Result {
+        ::protobuf::text_format::fmt(self, f)
+    }
+}
+
+impl ::protobuf::reflect::ProtobufValue for Obfs4TransportParams {
+    type RuntimeType = ::protobuf::reflect::rt::RuntimeTypeMessage<Self>;
+}
+
 #[derive(PartialEq,Clone,Default,Debug)]
 // @@protoc_insertion_point(message:tapdance.C2SWrapper)
 pub struct C2SWrapper {
@@ -6598,202 +6874,206 @@ static file_descriptor_proto_data: &'static [u8] = b"\
     reason\x18\x04\x20\x01(\x0e2\x18.tapdance.ErrorReasonS2CR\terrReason\x12\
     \x1f\n\x0btmp_backoff\x18\x05\x20\x01(\rR\ntmpBackoff\x12\x1d\n\nstation\
     _id\x18\x06\x20\x01(\tR\tstationId\x12\x18\n\x07padding\x18d\x20\x01(\
-    \x0cR\x07padding\"\xaf\x01\n\x11RegistrationFlags\x12\x1f\n\x0bupload_on\
+    \x0cR\x07padding\"\xcd\x01\n\x11RegistrationFlags\x12\x1f\n\x0bupload_on\
     ly\x18\x01\x20\x01(\x08R\nuploadOnly\x12\x1d\n\ndark_decoy\x18\x02\x20\
     \x01(\x08R\tdarkDecoy\x12!\n\x0cproxy_header\x18\x03\x20\x01(\x08R\x0bpr\
     oxyHeader\x12\x17\n\x07use_TIL\x18\x04\x20\x01(\x08R\x06useTIL\x12\x1e\n\
-    \nprescanned\x18\x05\x20\x01(\x08R\nprescanned\"\xb7\x06\n\x0fClientToSt\
-    ation\x12)\n\x10protocol_version\x18\x01\x20\x01(\rR\x0fprotocolVersion\
-    \x122\n\x15decoy_list_generation\x18\x02\x20\x01(\rR\x13decoyListGenerat\
-    ion\x12C\n\x10state_transition\x18\x03\x20\x01(\x0e2\x18.tapdance.C2S_Tr\
-    ansitionR\x0fstateTransition\x12\x1f\n\x0bupload_sync\x18\x04\x20\x01(\
-    \x04R\nuploadSync\x12,\n\x12client_lib_version\x18\x05\x20\x01(\rR\x10cl\
-    ientLibVersion\x12>\n\x1bdisable_registrar_overrides\x18\x06\x20\x01(\
-    \x08R\x19disableRegistrarOverrides\x12#\n\rfailed_decoys\x18\n\x20\x03(\
-    \tR\x0cfailedDecoys\x12,\n\x05stats\x18\x0b\x20\x01(\x0b2\x16.tapdance.S\
-    essionStatsR\x05stats\x125\n\ttransport\x18\x0c\x20\x01(\x0e2\x17.tapdan\
-    ce.TransportTypeR\ttransport\x12?\n\x10transport_params\x18\r\x20\x01(\
-    \x0b2\x14.google.protobuf.AnyR\x0ftransportParams\x12%\n\x0ecovert_addre\
-    ss\x18\x14\x20\x01(\tR\rcovertAddress\x127\n\x18masked_decoy_server_name\
-    \x18\x15\x20\x01(\tR\x15maskedDecoyServerName\x12\x1d\n\nv6_support\x18\
-    \x16\x20\x01(\x08R\tv6Support\x12\x1d\n\nv4_support\x18\x17\x20\x01(\x08\
-    R\tv4Support\x121\n\x05flags\x18\x18\x20\x01(\x0b2\x1b.tapdance.Registra\
-    tionFlagsR\x05flags\x12;\n\rwebrtc_signal\x18\x1f\x20\x01(\x0b2\x16.tapd\
-    ance.WebRTCSignalR\x0cwebrtcSignal\x12\x18\n\x07padding\x18d\x20\x01(\
-    \x0cR\x07padding\"\xa8\x01\n\x15PrefixTransportParams\x12\x1b\n\tprefix_\
-    id\x18\x01\x20\x01(\x05R\x08prefixId\x12\x16\n\x06prefix\x18\x02\x20\x01\
-    (\x0cR\x06prefix\x12,\n\x12flush_after_prefix\x18\x03\x20\x01(\x08R\x10f\
-    lushAfterPrefix\x12,\n\x12randomize_dst_port\x18\r\x20\x01(\x08R\x10rand\
-    omizeDstPort\"F\n\x16GenericTransportParams\x12,\n\x12randomize_dst_port\
-    \x18\r\x20\x01(\x08R\x10randomizeDstPort\"\xcb\x03\n\nC2SWrapper\x12#\n\
-    \rshared_secret\x18\x01\x20\x01(\x0cR\x0csharedSecret\x12L\n\x14registra\
-    tion_payload\x18\x03\x20\x01(\x0b2\x19.tapdance.ClientToStationR\x13regi\
-    strationPayload\x12M\n\x13registration_source\x18\x04\x20\x01(\x0e2\x1c.\
-    tapdance.RegistrationSourceR\x12registrationSource\x121\n\x14registratio\
-    n_address\x18\x06\x20\x01(\x0cR\x13registrationAddress\x12#\n\rdecoy_add\
-    ress\x18\x07\x20\x01(\x0cR\x0cdecoyAddress\x12S\n\x15registration_respon\
-    se\x18\x08\x20\x01(\x0b2\x1e.tapdance.RegistrationResponseR\x14registrat\
-    ionResponse\x12\"\n\x0cRegRespBytes\x18\t\x20\x01(\x0cR\x0cRegRespBytes\
-    \x12*\n\x10RegRespSignature\x18\n\x20\x01(\x0cR\x10RegRespSignature\"\
-    \xdd\x01\n\x0cSessionStats\x120\n\x14failed_decoys_amount\x18\x14\x20\
-    \x01(\rR\x12failedDecoysAmount\x121\n\x15total_time_to_connect\x18\x1f\
-    \x20\x01(\rR\x12totalTimeToConnect\x12$\n\x0ertt_to_station\x18!\x20\x01\
-    (\rR\x0crttToStation\x12\x20\n\x0ctls_to_decoy\x18&\x20\x01(\rR\ntlsToDe\
-    coy\x12\x20\n\x0ctcp_to_decoy\x18'\x20\x01(\rR\ntcpToDecoy\"\x88\x02\n\
-    \x11StationToDetector\x12\x1d\n\nphantom_ip\x18\x01\x20\x01(\tR\tphantom\
-    Ip\x12\x1b\n\tclient_ip\x18\x02\x20\x01(\tR\x08clientIp\x12\x1d\n\ntimeo\
-    ut_ns\x18\x03\x20\x01(\x04R\ttimeoutNs\x129\n\toperation\x18\x04\x20\x01\
-    (\x0e2\x1b.tapdance.StationOperationsR\toperation\x12\x19\n\x08dst_port\
-    \x18\n\x20\x01(\rR\x07dstPort\x12\x19\n\x08src_port\x18\x0b\x20\x01(\rR\
-    \x07srcPort\x12'\n\x05proto\x18\x0c\x20\x01(\x0e2\x11.tapdance.IPProtoR\
-    \x05proto\"\x9a\x02\n\x14RegistrationResponse\x12\x1a\n\x08ipv4addr\x18\
-    \x01\x20\x01(\x07R\x08ipv4addr\x12\x1a\n\x08ipv6addr\x18\x02\x20\x01(\
-    \x0cR\x08ipv6addr\x12\x19\n\x08dst_port\x18\x03\x20\x01(\rR\x07dstPort\
-    \x12\"\n\x0cserverRandom\x18\x04\x20\x01(\x0cR\x0cserverRandom\x12\x14\n\
-    \x05error\x18\x05\x20\x01(\tR\x05error\x124\n\nclientConf\x18\x06\x20\
-    \x01(\x0b2\x14.tapdance.ClientConfR\nclientConf\x12?\n\x10transport_para\
-    ms\x18\n\x20\x01(\x0b2\x14.google.protobuf.AnyR\x0ftransportParams\"\xaf\
-    \x01\n\x0bDnsResponse\x12\x18\n\x07success\x18\x01\x20\x01(\x08R\x07succ\
-    ess\x12/\n\x13clientconf_outdated\x18\x02\x20\x01(\x08R\x12clientconfOut\
-    dated\x12U\n\x16bidirectional_response\x18\x03\x20\x01(\x0b2\x1e.tapdanc\
-    e.RegistrationResponseR\x15bidirectionalResponse*+\n\x07KeyType\x12\x0f\
-    \n\x0bAES_GCM_128\x10Z\x12\x0f\n\x0bAES_GCM_256\x10[*)\n\x0cDnsRegMethod\
-    \x12\x07\n\x03UDP\x10\x01\x12\x07\n\x03DOT\x10\x02\x12\x07\n\x03DOH\x10\
-    \x03*\xe7\x01\n\x0eC2S_Transition\x12\x11\n\rC2S_NO_CHANGE\x10\0\x12\x14\
-    \n\x10C2S_SESSION_INIT\x10\x01\x12\x1b\n\x17C2S_SESSION_COVERT_INIT\x10\
-    \x0b\x12\x18\n\x14C2S_EXPECT_RECONNECT\x10\x02\x12\x15\n\x11C2S_SESSION_\
-    CLOSE\x10\x03\x12\x14\n\x10C2S_YIELD_UPLOAD\x10\x04\x12\x16\n\x12C2S_ACQ\
-    UIRE_UPLOAD\x10\x05\x12\x20\n\x1cC2S_EXPECT_UPLOADONLY_RECONN\x10\x06\
-    \x12\x0e\n\tC2S_ERROR\x10\xff\x01*\x98\x01\n\x0eS2C_Transition\x12\x11\n\
-    \rS2C_NO_CHANGE\x10\0\x12\x14\n\x10S2C_SESSION_INIT\x10\x01\x12\x1b\n\
-    \x17S2C_SESSION_COVERT_INIT\x10\x0b\x12\x19\n\x15S2C_CONFIRM_RECONNECT\
-    \x10\x02\x12\x15\n\x11S2C_SESSION_CLOSE\x10\x03\x12\x0e\n\tS2C_ERROR\x10\
-    \xff\x01*\xac\x01\n\x0eErrorReasonS2C\x12\x0c\n\x08NO_ERROR\x10\0\x12\
-    \x11\n\rCOVERT_STREAM\x10\x01\x12\x13\n\x0fCLIENT_REPORTED\x10\x02\x12\
-    \x13\n\x0fCLIENT_PROTOCOL\x10\x03\x12\x14\n\x10STATION_INTERNAL\x10\x04\
-    \x12\x12\n\x0eDECOY_OVERLOAD\x10\x05\x12\x11\n\rCLIENT_STREAM\x10d\x12\
-    \x12\n\x0eCLIENT_TIMEOUT\x10e*\x82\x01\n\rTransportType\x12\x08\n\x04Nul\
-    l\x10\0\x12\x07\n\x03Min\x10\x01\x12\t\n\x05Obfs4\x10\x02\x12\x08\n\x04D\
-    TLS\x10\x03\x12\n\n\x06Prefix\x10\x04\x12\x08\n\x04uTLS\x10\x05\x12\n\n\
-    \x06Format\x10\x06\x12\x08\n\x04WASM\x10\x07\x12\x07\n\x03FTE\x10\x08\
-    \x12\x08\n\x04Quic\x10\t\x12\n\n\x06Webrtc\x10c*\x86\x01\n\x12Registrati\
-    onSource\x12\x0f\n\x0bUnspecified\x10\0\x12\x0c\n\x08Detector\x10\x01\
-    \x12\x07\n\x03API\x10\x02\x12\x13\n\x0fDetectorPrescan\x10\x03\x12\x14\n\
-    \x10BidirectionalAPI\x10\x04\x12\x07\n\x03DNS\x10\x05\x12\x14\n\x10Bidir\
-    ectionalDNS\x10\x06*@\n\x11StationOperations\x12\x0b\n\x07Unknown\x10\0\
-    \x12\x07\n\x03New\x10\x01\x12\n\n\x06Update\x10\x02\x12\t\n\x05Clear\x10\
-    \x03*$\n\x07IPProto\x12\x07\n\x03Unk\x10\0\x12\x07\n\x03Tcp\x10\x01\x12\
-    \x07\n\x03Udp\x10\x02J\x8a\x8e\x01\n\x07\x12\x05\0\0\xa2\x03\x01\n\x08\n\
-    \x01\x0c\x12\x03\0\0\x12\n\xb0\x01\n\x01\x02\x12\x03\x06\0\x112\xa5\x01\
-    \x20TODO:\x20We're\x20using\x20proto2\x20because\x20it's\x20the\x20defau\
-    lt\x20on\x20Ubuntu\x2016.04.\n\x20At\x20some\x20point\x20we\x20will\x20w\
-    ant\x20to\x20migrate\x20to\x20proto3,\x20but\x20we\x20are\x20not\n\x20us\
-    ing\x20any\x20proto3\x20features\x20yet.\n\n\t\n\x02\x03\0\x12\x03\x08\0\
-    #\n\n\n\x02\x05\0\x12\x04\n\0\r\x01\n\n\n\x03\x05\0\x01\x12\x03\n\x05\
-    \x0c\n\x0b\n\x04\x05\0\x02\0\x12\x03\x0b\x04\x15\n\x0c\n\x05\x05\0\x02\0\
-    \x01\x12\x03\x0b\x04\x0f\n\x0c\n\x05\x05\0\x02\0\x02\x12\x03\x0b\x12\x14\
-    \n\x20\n\x04\x05\0\x02\x01\x12\x03\x0c\x04\x15\"\x13\x20not\x20supported\
-    \x20atm\n\n\x0c\n\x05\x05\0\x02\x01\x01\x12\x03\x0c\x04\x0f\n\x0c\n\x05\
-    \x05\0\x02\x01\x02\x12\x03\x0c\x12\x14\n\n\n\x02\x04\0\x12\x04\x0f\0\x14\
-    \x01\n\n\n\x03\x04\0\x01\x12\x03\x0f\x08\x0e\n4\n\x04\x04\0\x02\0\x12\
-    \x03\x11\x04\x1b\x1a'\x20A\x20public\x20key,\x20as\x20used\x20by\x20the\
-    \x20station.\n\n\x0c\n\x05\x04\0\x02\0\x04\x12\x03\x11\x04\x0c\n\x0c\n\
-    \x05\x04\0\x02\0\x05\x12\x03\x11\r\x12\n\x0c\n\x05\x04\0\x02\0\x01\x12\
-    \x03\x11\x13\x16\n\x0c\n\x05\x04\0\x02\0\x03\x12\x03\x11\x19\x1a\n\x0b\n\
-    \x04\x04\0\x02\x01\x12\x03\x13\x04\x1e\n\x0c\n\x05\x04\0\x02\x01\x04\x12\
-    \x03\x13\x04\x0c\n\x0c\n\x05\x04\0\x02\x01\x06\x12\x03\x13\r\x14\n\x0c\n\
-    \x05\x04\0\x02\x01\x01\x12\x03\x13\x15\x19\n\x0c\n\x05\x04\0\x02\x01\x03\
-    \x12\x03\x13\x1c\x1d\n\n\n\x02\x04\x01\x12\x04\x16\0<\x01\n\n\n\x03\x04\
-    \x01\x01\x12\x03\x16\x08\x14\n\xa1\x01\n\x04\x04\x01\x02\0\x12\x03\x1b\
-    \x04!\x1a\x93\x01\x20The\x20hostname/SNI\x20to\x20use\x20for\x20this\x20\
-    host\n\n\x20The\x20hostname\x20is\x20the\x20only\x20required\x20field,\
-    \x20although\x20other\n\x20fields\x20are\x20expected\x20to\x20be\x20pres\
-    ent\x20in\x20most\x20cases.\n\n\x0c\n\x05\x04\x01\x02\0\x04\x12\x03\x1b\
-    \x04\x0c\n\x0c\n\x05\x04\x01\x02\0\x05\x12\x03\x1b\r\x13\n\x0c\n\x05\x04\
-    \x01\x02\0\x01\x12\x03\x1b\x14\x1c\n\x0c\n\x05\x04\x01\x02\0\x03\x12\x03\
-    \x1b\x1f\x20\n\xf7\x01\n\x04\x04\x01\x02\x01\x12\x03\"\x04\"\x1a\xe9\x01\
-    \x20The\x2032-bit\x20ipv4\x20address,\x20in\x20network\x20byte\x20order\
-    \n\n\x20If\x20the\x20IPv4\x20address\x20is\x20absent,\x20then\x20it\x20m\
-    ay\x20be\x20resolved\x20via\n\x20DNS\x20by\x20the\x20client,\x20or\x20th\
-    e\x20client\x20may\x20discard\x20this\x20decoy\x20spec\n\x20if\x20local\
-    \x20DNS\x20is\x20untrusted,\x20or\x20the\x20service\x20may\x20be\x20mult\
-    ihomed.\n\n\x0c\n\x05\x04\x01\x02\x01\x04\x12\x03\"\x04\x0c\n\x0c\n\x05\
-    \x04\x01\x02\x01\x05\x12\x03\"\r\x14\n\x0c\n\x05\x04\x01\x02\x01\x01\x12\
-    \x03\"\x15\x1d\n\x0c\n\x05\x04\x01\x02\x01\x03\x12\x03\"\x20!\n>\n\x04\
-    \x04\x01\x02\x02\x12\x03%\x04\x20\x1a1\x20The\x20128-bit\x20ipv6\x20addr\
-    ess,\x20in\x20network\x20byte\x20order\n\n\x0c\n\x05\x04\x01\x02\x02\x04\
-    \x12\x03%\x04\x0c\n\x0c\n\x05\x04\x01\x02\x02\x05\x12\x03%\r\x12\n\x0c\n\
-    \x05\x04\x01\x02\x02\x01\x12\x03%\x13\x1b\n\x0c\n\x05\x04\x01\x02\x02\
-    \x03\x12\x03%\x1e\x1f\n\x91\x01\n\x04\x04\x01\x02\x03\x12\x03+\x04\x1f\
-    \x1a\x83\x01\x20The\x20Tapdance\x20station\x20public\x20key\x20to\x20use\
-    \x20when\x20contacting\x20this\n\x20decoy\n\n\x20If\x20omitted,\x20the\
-    \x20default\x20station\x20public\x20key\x20(if\x20any)\x20is\x20used.\n\
-    \n\x0c\n\x05\x04\x01\x02\x03\x04\x12\x03+\x04\x0c\n\x0c\n\x05\x04\x01\
-    \x02\x03\x06\x12\x03+\r\x13\n\x0c\n\x05\x04\x01\x02\x03\x01\x12\x03+\x14\
-    \x1a\n\x0c\n\x05\x04\x01\x02\x03\x03\x12\x03+\x1d\x1e\n\xee\x01\n\x04\
-    \x04\x01\x02\x04\x12\x032\x04\x20\x1a\xe0\x01\x20The\x20maximum\x20durat\
-    ion,\x20in\x20milliseconds,\x20to\x20maintain\x20an\x20open\n\x20connect\
-    ion\x20to\x20this\x20decoy\x20(because\x20the\x20decoy\x20may\x20close\
-    \x20the\n\x20connection\x20itself\x20after\x20this\x20length\x20of\x20ti\
-    me)\n\n\x20If\x20omitted,\x20a\x20default\x20of\x2030,000\x20millisecond\
-    s\x20is\x20assumed.\n\n\x0c\n\x05\x04\x01\x02\x04\x04\x12\x032\x04\x0c\n\
-    \x0c\n\x05\x04\x01\x02\x04\x05\x12\x032\r\x13\n\x0c\n\x05\x04\x01\x02\
-    \x04\x01\x12\x032\x14\x1b\n\x0c\n\x05\x04\x01\x02\x04\x03\x12\x032\x1e\
-    \x1f\n\xb0\x02\n\x04\x04\x01\x02\x05\x12\x03;\x04\x1f\x1a\xa2\x02\x20The\
-    \x20maximum\x20TCP\x20window\x20size\x20to\x20attempt\x20to\x20use\x20fo\
-    r\x20this\x20decoy.\n\n\x20If\x20omitted,\x20a\x20default\x20of\x2015360\
-    \x20is\x20assumed.\n\n\x20TODO:\x20the\x20default\x20is\x20based\x20on\
-    \x20the\x20current\x20heuristic\x20of\x20only\n\x20using\x20decoys\x20th\
-    at\x20permit\x20windows\x20of\x2015KB\x20or\x20larger.\x20\x20If\x20this\
-    \n\x20heuristic\x20changes,\x20then\x20this\x20default\x20doesn't\x20mak\
-    e\x20sense.\n\n\x0c\n\x05\x04\x01\x02\x05\x04\x12\x03;\x04\x0c\n\x0c\n\
-    \x05\x04\x01\x02\x05\x05\x12\x03;\r\x13\n\x0c\n\x05\x04\x01\x02\x05\x01\
-    \x12\x03;\x14\x1a\n\x0c\n\x05\x04\x01\x02\x05\x03\x12\x03;\x1d\x1e\n\x83\
-    \x08\n\x02\x04\x02\x12\x04S\0Z\x012\xf6\x07\x20In\x20version\x201,\x20th\
-    e\x20request\x20is\x20very\x20simple:\x20when\n\x20the\x20client\x20send\
-    s\x20a\x20MSG_PROTO\x20to\x20the\x20station,\x20if\x20the\n\x20generatio\
-    n\x20number\x20is\x20present,\x20then\x20this\x20request\x20includes\n\
-    \x20(in\x20addition\x20to\x20whatever\x20other\x20operations\x20are\x20p\
-    art\x20of\x20the\n\x20request)\x20a\x20request\x20for\x20the\x20station\
-    \x20to\x20send\x20a\x20copy\x20of\n\x20the\x20current\x20decoy\x20set\
-    \x20that\x20has\x20a\x20generation\x20number\x20greater\n\x20than\x20the\
-    \x20generation\x20number\x20in\x20its\x20request.\n\n\x20If\x20the\x20re\
-    sponse\x20contains\x20a\x20DecoyListUpdate\x20with\x20a\x20generation\
-    \x20number\x20equal\n\x20to\x20that\x20which\x20the\x20client\x20sent,\
-    \x20then\x20the\x20client\x20is\x20\"caught\x20up\"\x20with\n\x20the\x20\
-    station\x20and\x20the\x20response\x20contains\x20no\x20new\x20informatio\
-    n\n\x20(and\x20all\x20other\x20fields\x20may\x20be\x20omitted\x20or\x20e\
-    mpty).\x20\x20Otherwise,\n\x20the\x20station\x20will\x20send\x20the\x20l\
-    atest\x20configuration\x20information,\n\x20along\x20with\x20its\x20gene\
-    ration\x20number.\n\n\x20The\x20station\x20can\x20also\x20send\x20Client\
-    Conf\x20messages\n\x20(as\x20part\x20of\x20Station2Client\x20messages)\
-    \x20whenever\x20it\x20wants.\n\x20The\x20client\x20is\x20expected\x20to\
-    \x20react\x20as\x20if\x20it\x20had\x20requested\n\x20such\x20messages\
-    \x20--\x20possibly\x20by\x20ignoring\x20them,\x20if\x20the\x20client\n\
-    \x20is\x20already\x20up-to-date\x20according\x20to\x20the\x20generation\
-    \x20number.\n\n\n\n\x03\x04\x02\x01\x12\x03S\x08\x12\n\x0b\n\x04\x04\x02\
-    \x02\0\x12\x03T\x04&\n\x0c\n\x05\x04\x02\x02\0\x04\x12\x03T\x04\x0c\n\
-    \x0c\n\x05\x04\x02\x02\0\x06\x12\x03T\r\x16\n\x0c\n\x05\x04\x02\x02\0\
-    \x01\x12\x03T\x17!\n\x0c\n\x05\x04\x02\x02\0\x03\x12\x03T$%\n\x0b\n\x04\
-    \x04\x02\x02\x01\x12\x03U\x04#\n\x0c\n\x05\x04\x02\x02\x01\x04\x12\x03U\
-    \x04\x0c\n\x0c\n\x05\x04\x02\x02\x01\x05\x12\x03U\r\x13\n\x0c\n\x05\x04\
-    \x02\x02\x01\x01\x12\x03U\x14\x1e\n\x0c\n\x05\x04\x02\x02\x01\x03\x12\
-    \x03U!\"\n\x0b\n\x04\x04\x02\x02\x02\x12\x03V\x04'\n\x0c\n\x05\x04\x02\
-    \x02\x02\x04\x12\x03V\x04\x0c\n\x0c\n\x05\x04\x02\x02\x02\x06\x12\x03V\r\
-    \x13\n\x0c\n\x05\x04\x02\x02\x02\x01\x12\x03V\x14\"\n\x0c\n\x05\x04\x02\
-    \x02\x02\x03\x12\x03V%&\n\x0b\n\x04\x04\x02\x02\x03\x12\x03W\x049\n\x0c\
-    \n\x05\x04\x02\x02\x03\x04\x12\x03W\x04\x0c\n\x0c\n\x05\x04\x02\x02\x03\
-    \x06\x12\x03W\r\x1f\n\x0c\n\x05\x04\x02\x02\x03\x01\x12\x03W\x204\n\x0c\
-    \n\x05\x04\x02\x02\x03\x03\x12\x03W78\n\x0b\n\x04\x04\x02\x02\x04\x12\
-    \x03X\x04'\n\x0c\n\x05\x04\x02\x02\x04\x04\x12\x03X\x04\x0c\n\x0c\n\x05\
-    \x04\x02\x02\x04\x06\x12\x03X\r\x13\n\x0c\n\x05\x04\x02\x02\x04\x01\x12\
-    \x03X\x14\"\n\x0c\n\x05\x04\x02\x02\x04\x03\x12\x03X%&\n\x0b\n\x04\x04\
-    \x02\x02\x05\x12\x03Y\x04)\n\x0c\n\x05\x04\x02\x02\x05\x04\x12\x03Y\x04\
-    \x0c\n\x0c\n\x05\x04\x02\x02\x05\x06\x12\x03Y\r\x17\n\x0c\n\x05\x04\x02\
-    \x02\x05\x01\x12\x03Y\x18$\n\x0c\n\x05\x04\x02\x02\x05\x03\x12\x03Y'(\n-\
-    \n\x02\x04\x03\x12\x04]\0d\x01\x1a!\x20Configuration\x20for\x20DNS\x20re\
-    gistrar\n\n\n\n\x03\x04\x03\x01\x12\x03]\x08\x12\n\x0b\n\x04\x04\x03\x02\
-    \0\x12\x03^\x04-\n\x0c\n\x05\x04\x03\x02\0\x04\x12\x03^\x04\x0c\n\x0c\n\
+    \nprescanned\x18\x05\x20\x01(\x08R\nprescanned\x12\x1c\n\tmultiplex\x18\
+    \x06\x20\x01(\x08R\tmultiplex\"\xb7\x06\n\x0fClientToStation\x12)\n\x10p\
+    rotocol_version\x18\x01\x20\x01(\rR\x0fprotocolVersion\x122\n\x15decoy_l\
+    ist_generation\x18\x02\x20\x01(\rR\x13decoyListGeneration\x12C\n\x10stat\
+    e_transition\x18\x03\x20\x01(\x0e2\x18.tapdance.C2S_TransitionR\x0fstate\
+    Transition\x12\x1f\n\x0bupload_sync\x18\x04\x20\x01(\x04R\nuploadSync\
+    \x12,\n\x12client_lib_version\x18\x05\x20\x01(\rR\x10clientLibVersion\
+    \x12>\n\x1bdisable_registrar_overrides\x18\x06\x20\x01(\x08R\x19disableR\
+    egistrarOverrides\x12#\n\rfailed_decoys\x18\n\x20\x03(\tR\x0cfailedDecoy\
+    s\x12,\n\x05stats\x18\x0b\x20\x01(\x0b2\x16.tapdance.SessionStatsR\x05st\
+    ats\x125\n\ttransport\x18\x0c\x20\x01(\x0e2\x17.tapdance.TransportTypeR\
+    \ttransport\x12?\n\x10transport_params\x18\r\x20\x01(\x0b2\x14.google.pr\
+    otobuf.AnyR\x0ftransportParams\x12%\n\x0ecovert_address\x18\x14\x20\x01(\
+    \tR\rcovertAddress\x127\n\x18masked_decoy_server_name\x18\x15\x20\x01(\t\
+    R\x15maskedDecoyServerName\x12\x1d\n\nv6_support\x18\x16\x20\x01(\x08R\t\
+    v6Support\x12\x1d\n\nv4_support\x18\x17\x20\x01(\x08R\tv4Support\x121\n\
+    \x05flags\x18\x18\x20\x01(\x0b2\x1b.tapdance.RegistrationFlagsR\x05flags\
+    \x12;\n\rwebrtc_signal\x18\x1f\x20\x01(\x0b2\x16.tapdance.WebRTCSignalR\
+    \x0cwebrtcSignal\x12\x18\n\x07padding\x18d\x20\x01(\x0cR\x07padding\"\
+    \xa8\x01\n\x15PrefixTransportParams\x12\x1b\n\tprefix_id\x18\x01\x20\x01\
+    (\x05R\x08prefixId\x12\x16\n\x06prefix\x18\x02\x20\x01(\x0cR\x06prefix\
+    \x12,\n\x12flush_after_prefix\x18\x03\x20\x01(\x08R\x10flushAfterPrefix\
+    \x12,\n\x12randomize_dst_port\x18\r\x20\x01(\x08R\x10randomizeDstPort\"F\
+    \n\x16GenericTransportParams\x12,\n\x12randomize_dst_port\x18\r\x20\x01(\
+    \x08R\x10randomizeDstPort\"\x82\x01\n\x14Obfs4TransportParams\x12\x19\n\
+    \x08iat_mode\x18\x01\x20\x01(\rR\x07iatMode\x12!\n\x0cpadding_seed\x18\
+    \x02\x20\x01(\x0cR\x0bpaddingSeed\x12,\n\x12randomize_dst_port\x18\r\x20\
+    \x01(\x08R\x10randomizeDstPort\"\xcb\x03\n\nC2SWrapper\x12#\n\rshared_se\
+    cret\x18\x01\x20\x01(\x0cR\x0csharedSecret\x12L\n\x14registration_payloa\
+    d\x18\x03\x20\x01(\x0b2\x19.tapdance.ClientToStationR\x13registrationPay\
+    load\x12M\n\x13registration_source\x18\x04\x20\x01(\x0e2\x1c.tapdance.Re\
+    gistrationSourceR\x12registrationSource\x121\n\x14registration_address\
+    \x18\x06\x20\x01(\x0cR\x13registrationAddress\x12#\n\rdecoy_address\x18\
+    \x07\x20\x01(\x0cR\x0cdecoyAddress\x12S\n\x15registration_response\x18\
+    \x08\x20\x01(\x0b2\x1e.tapdance.RegistrationResponseR\x14registrationRes\
+    ponse\x12\"\n\x0cRegRespBytes\x18\t\x20\x01(\x0cR\x0cRegRespBytes\x12*\n\
+    \x10RegRespSignature\x18\n\x20\x01(\x0cR\x10RegRespSignature\"\xdd\x01\n\
+    \x0cSessionStats\x120\n\x14failed_decoys_amount\x18\x14\x20\x01(\rR\x12f\
+    ailedDecoysAmount\x121\n\x15total_time_to_connect\x18\x1f\x20\x01(\rR\
+    \x12totalTimeToConnect\x12$\n\x0ertt_to_station\x18!\x20\x01(\rR\x0crttT\
+    oStation\x12\x20\n\x0ctls_to_decoy\x18&\x20\x01(\rR\ntlsToDecoy\x12\x20\
+    \n\x0ctcp_to_decoy\x18'\x20\x01(\rR\ntcpToDecoy\"\x88\x02\n\x11StationTo\
+    Detector\x12\x1d\n\nphantom_ip\x18\x01\x20\x01(\tR\tphantomIp\x12\x1b\n\
+    \tclient_ip\x18\x02\x20\x01(\tR\x08clientIp\x12\x1d\n\ntimeout_ns\x18\
+    \x03\x20\x01(\x04R\ttimeoutNs\x129\n\toperation\x18\x04\x20\x01(\x0e2\
+    \x1b.tapdance.StationOperationsR\toperation\x12\x19\n\x08dst_port\x18\n\
+    \x20\x01(\rR\x07dstPort\x12\x19\n\x08src_port\x18\x0b\x20\x01(\rR\x07src\
+    Port\x12'\n\x05proto\x18\x0c\x20\x01(\x0e2\x11.tapdance.IPProtoR\x05prot\
+    o\"\x9a\x02\n\x14RegistrationResponse\x12\x1a\n\x08ipv4addr\x18\x01\x20\
+    \x01(\x07R\x08ipv4addr\x12\x1a\n\x08ipv6addr\x18\x02\x20\x01(\x0cR\x08ip\
+    v6addr\x12\x19\n\x08dst_port\x18\x03\x20\x01(\rR\x07dstPort\x12\"\n\x0cs\
+    erverRandom\x18\x04\x20\x01(\x0cR\x0cserverRandom\x12\x14\n\x05error\x18\
+    \x05\x20\x01(\tR\x05error\x124\n\nclientConf\x18\x06\x20\x01(\x0b2\x14.t\
+    apdance.ClientConfR\nclientConf\x12?\n\x10transport_params\x18\n\x20\x01\
+    (\x0b2\x14.google.protobuf.AnyR\x0ftransportParams\"\xaf\x01\n\x0bDnsRes\
+    ponse\x12\x18\n\x07success\x18\x01\x20\x01(\x08R\x07success\x12/\n\x13cl\
+    ientconf_outdated\x18\x02\x20\x01(\x08R\x12clientconfOutdated\x12U\n\x16\
+    bidirectional_response\x18\x03\x20\x01(\x0b2\x1e.tapdance.RegistrationRe\
+    sponseR\x15bidirectionalResponse*+\n\x07KeyType\x12\x0f\n\x0bAES_GCM_128\
+    \x10Z\x12\x0f\n\x0bAES_GCM_256\x10[*)\n\x0cDnsRegMethod\x12\x07\n\x03UDP\
+    \x10\x01\x12\x07\n\x03DOT\x10\x02\x12\x07\n\x03DOH\x10\x03*\xe7\x01\n\
+    \x0eC2S_Transition\x12\x11\n\rC2S_NO_CHANGE\x10\0\x12\x14\n\x10C2S_SESSI\
+    ON_INIT\x10\x01\x12\x1b\n\x17C2S_SESSION_COVERT_INIT\x10\x0b\x12\x18\n\
+    \x14C2S_EXPECT_RECONNECT\x10\x02\x12\x15\n\x11C2S_SESSION_CLOSE\x10\x03\
+    \x12\x14\n\x10C2S_YIELD_UPLOAD\x10\x04\x12\x16\n\x12C2S_ACQUIRE_UPLOAD\
+    \x10\x05\x12\x20\n\x1cC2S_EXPECT_UPLOADONLY_RECONN\x10\x06\x12\x0e\n\tC2\
+    S_ERROR\x10\xff\x01*\x98\x01\n\x0eS2C_Transition\x12\x11\n\rS2C_NO_CHANG\
+    E\x10\0\x12\x14\n\x10S2C_SESSION_INIT\x10\x01\x12\x1b\n\x17S2C_SESSION_C\
+    OVERT_INIT\x10\x0b\x12\x19\n\x15S2C_CONFIRM_RECONNECT\x10\x02\x12\x15\n\
+    \x11S2C_SESSION_CLOSE\x10\x03\x12\x0e\n\tS2C_ERROR\x10\xff\x01*\xac\x01\
+    \n\x0eErrorReasonS2C\x12\x0c\n\x08NO_ERROR\x10\0\x12\x11\n\rCOVERT_STREA\
+    M\x10\x01\x12\x13\n\x0fCLIENT_REPORTED\x10\x02\x12\x13\n\x0fCLIENT_PROTO\
+    COL\x10\x03\x12\x14\n\x10STATION_INTERNAL\x10\x04\x12\x12\n\x0eDECOY_OVE\
+    RLOAD\x10\x05\x12\x11\n\rCLIENT_STREAM\x10d\x12\x12\n\x0eCLIENT_TIMEOUT\
+    \x10e*\x82\x01\n\rTransportType\x12\x08\n\x04Null\x10\0\x12\x07\n\x03Min\
+    \x10\x01\x12\t\n\x05Obfs4\x10\x02\x12\x08\n\x04DTLS\x10\x03\x12\n\n\x06P\
+    refix\x10\x04\x12\x08\n\x04uTLS\x10\x05\x12\n\n\x06Format\x10\x06\x12\
+    \x08\n\x04WASM\x10\x07\x12\x07\n\x03FTE\x10\x08\x12\x08\n\x04Quic\x10\t\
+    \x12\n\n\x06Webrtc\x10c*\x86\x01\n\x12RegistrationSource\x12\x0f\n\x0bUn\
+    specified\x10\0\x12\x0c\n\x08Detector\x10\x01\x12\x07\n\x03API\x10\x02\
+    \x12\x13\n\x0fDetectorPrescan\x10\x03\x12\x14\n\x10BidirectionalAPI\x10\
+    \x04\x12\x07\n\x03DNS\x10\x05\x12\x14\n\x10BidirectionalDNS\x10\x06*@\n\
+    \x11StationOperations\x12\x0b\n\x07Unknown\x10\0\x12\x07\n\x03New\x10\
+    \x01\x12\n\n\x06Update\x10\x02\x12\t\n\x05Clear\x10\x03*$\n\x07IPProto\
+    \x12\x07\n\x03Unk\x10\0\x12\x07\n\x03Tcp\x10\x01\x12\x07\n\x03Udp\x10\
+    \x02J\x8a\x96\x01\n\x07\x12\x05\0\0\xb4\x03\x01\n\x08\n\x01\x0c\x12\x03\
+    \0\0\x12\n\xb0\x01\n\x01\x02\x12\x03\x06\0\x112\xa5\x01\x20TODO:\x20We'r\
+    e\x20using\x20proto2\x20because\x20it's\x20the\x20default\x20on\x20Ubunt\
+    u\x2016.04.\n\x20At\x20some\x20point\x20we\x20will\x20want\x20to\x20migr\
+    ate\x20to\x20proto3,\x20but\x20we\x20are\x20not\n\x20using\x20any\x20pro\
+    to3\x20features\x20yet.\n\n\t\n\x02\x03\0\x12\x03\x08\0#\n\n\n\x02\x05\0\
+    \x12\x04\n\0\r\x01\n\n\n\x03\x05\0\x01\x12\x03\n\x05\x0c\n\x0b\n\x04\x05\
+    \0\x02\0\x12\x03\x0b\x04\x15\n\x0c\n\x05\x05\0\x02\0\x01\x12\x03\x0b\x04\
+    \x0f\n\x0c\n\x05\x05\0\x02\0\x02\x12\x03\x0b\x12\x14\n\x20\n\x04\x05\0\
+    \x02\x01\x12\x03\x0c\x04\x15\"\x13\x20not\x20supported\x20atm\n\n\x0c\n\
+    \x05\x05\0\x02\x01\x01\x12\x03\x0c\x04\x0f\n\x0c\n\x05\x05\0\x02\x01\x02\
+    \x12\x03\x0c\x12\x14\n\n\n\x02\x04\0\x12\x04\x0f\0\x14\x01\n\n\n\x03\x04\
+    \0\x01\x12\x03\x0f\x08\x0e\n4\n\x04\x04\0\x02\0\x12\x03\x11\x04\x1b\x1a'\
+    \x20A\x20public\x20key,\x20as\x20used\x20by\x20the\x20station.\n\n\x0c\n\
+    \x05\x04\0\x02\0\x04\x12\x03\x11\x04\x0c\n\x0c\n\x05\x04\0\x02\0\x05\x12\
+    \x03\x11\r\x12\n\x0c\n\x05\x04\0\x02\0\x01\x12\x03\x11\x13\x16\n\x0c\n\
+    \x05\x04\0\x02\0\x03\x12\x03\x11\x19\x1a\n\x0b\n\x04\x04\0\x02\x01\x12\
+    \x03\x13\x04\x1e\n\x0c\n\x05\x04\0\x02\x01\x04\x12\x03\x13\x04\x0c\n\x0c\
+    \n\x05\x04\0\x02\x01\x06\x12\x03\x13\r\x14\n\x0c\n\x05\x04\0\x02\x01\x01\
+    \x12\x03\x13\x15\x19\n\x0c\n\x05\x04\0\x02\x01\x03\x12\x03\x13\x1c\x1d\n\
+    \n\n\x02\x04\x01\x12\x04\x16\0<\x01\n\n\n\x03\x04\x01\x01\x12\x03\x16\
+    \x08\x14\n\xa1\x01\n\x04\x04\x01\x02\0\x12\x03\x1b\x04!\x1a\x93\x01\x20T\
+    he\x20hostname/SNI\x20to\x20use\x20for\x20this\x20host\n\n\x20The\x20hos\
+    tname\x20is\x20the\x20only\x20required\x20field,\x20although\x20other\n\
+    \x20fields\x20are\x20expected\x20to\x20be\x20present\x20in\x20most\x20ca\
+    ses.\n\n\x0c\n\x05\x04\x01\x02\0\x04\x12\x03\x1b\x04\x0c\n\x0c\n\x05\x04\
+    \x01\x02\0\x05\x12\x03\x1b\r\x13\n\x0c\n\x05\x04\x01\x02\0\x01\x12\x03\
+    \x1b\x14\x1c\n\x0c\n\x05\x04\x01\x02\0\x03\x12\x03\x1b\x1f\x20\n\xf7\x01\
+    \n\x04\x04\x01\x02\x01\x12\x03\"\x04\"\x1a\xe9\x01\x20The\x2032-bit\x20i\
+    pv4\x20address,\x20in\x20network\x20byte\x20order\n\n\x20If\x20the\x20IP\
+    v4\x20address\x20is\x20absent,\x20then\x20it\x20may\x20be\x20resolved\
+    \x20via\n\x20DNS\x20by\x20the\x20client,\x20or\x20the\x20client\x20may\
+    \x20discard\x20this\x20decoy\x20spec\n\x20if\x20local\x20DNS\x20is\x20un\
+    trusted,\x20or\x20the\x20service\x20may\x20be\x20multihomed.\n\n\x0c\n\
+    \x05\x04\x01\x02\x01\x04\x12\x03\"\x04\x0c\n\x0c\n\x05\x04\x01\x02\x01\
+    \x05\x12\x03\"\r\x14\n\x0c\n\x05\x04\x01\x02\x01\x01\x12\x03\"\x15\x1d\n\
+    \x0c\n\x05\x04\x01\x02\x01\x03\x12\x03\"\x20!\n>\n\x04\x04\x01\x02\x02\
+    \x12\x03%\x04\x20\x1a1\x20The\x20128-bit\x20ipv6\x20address,\x20in\x20ne\
+    twork\x20byte\x20order\n\n\x0c\n\x05\x04\x01\x02\x02\x04\x12\x03%\x04\
+    \x0c\n\x0c\n\x05\x04\x01\x02\x02\x05\x12\x03%\r\x12\n\x0c\n\x05\x04\x01\
+    \x02\x02\x01\x12\x03%\x13\x1b\n\x0c\n\x05\x04\x01\x02\x02\x03\x12\x03%\
+    \x1e\x1f\n\x91\x01\n\x04\x04\x01\x02\x03\x12\x03+\x04\x1f\x1a\x83\x01\
+    \x20The\x20Tapdance\x20station\x20public\x20key\x20to\x20use\x20when\x20\
+    contacting\x20this\n\x20decoy\n\n\x20If\x20omitted,\x20the\x20default\
+    \x20station\x20public\x20key\x20(if\x20any)\x20is\x20used.\n\n\x0c\n\x05\
+    \x04\x01\x02\x03\x04\x12\x03+\x04\x0c\n\x0c\n\x05\x04\x01\x02\x03\x06\
+    \x12\x03+\r\x13\n\x0c\n\x05\x04\x01\x02\x03\x01\x12\x03+\x14\x1a\n\x0c\n\
+    \x05\x04\x01\x02\x03\x03\x12\x03+\x1d\x1e\n\xee\x01\n\x04\x04\x01\x02\
+    \x04\x12\x032\x04\x20\x1a\xe0\x01\x20The\x20maximum\x20duration,\x20in\
+    \x20milliseconds,\x20to\x20maintain\x20an\x20open\n\x20connection\x20to\
+    \x20this\x20decoy\x20(because\x20the\x20decoy\x20may\x20close\x20the\n\
+    \x20connection\x20itself\x20after\x20this\x20length\x20of\x20time)\n\n\
+    \x20If\x20omitted,\x20a\x20default\x20of\x2030,000\x20milliseconds\x20is\
+    \x20assumed.\n\n\x0c\n\x05\x04\x01\x02\x04\x04\x12\x032\x04\x0c\n\x0c\n\
+    \x05\x04\x01\x02\x04\x05\x12\x032\r\x13\n\x0c\n\x05\x04\x01\x02\x04\x01\
+    \x12\x032\x14\x1b\n\x0c\n\x05\x04\x01\x02\x04\x03\x12\x032\x1e\x1f\n\xb0\
+    \x02\n\x04\x04\x01\x02\x05\x12\x03;\x04\x1f\x1a\xa2\x02\x20The\x20maximu\
+    m\x20TCP\x20window\x20size\x20to\x20attempt\x20to\x20use\x20for\x20this\
+    \x20decoy.\n\n\x20If\x20omitted,\x20a\x20default\x20of\x2015360\x20is\
+    \x20assumed.\n\n\x20TODO:\x20the\x20default\x20is\x20based\x20on\x20the\
+    \x20current\x20heuristic\x20of\x20only\n\x20using\x20decoys\x20that\x20p\
+    ermit\x20windows\x20of\x2015KB\x20or\x20larger.\x20\x20If\x20this\n\x20h\
+    euristic\x20changes,\x20then\x20this\x20default\x20doesn't\x20make\x20se\
+    nse.\n\n\x0c\n\x05\x04\x01\x02\x05\x04\x12\x03;\x04\x0c\n\x0c\n\x05\x04\
+    \x01\x02\x05\x05\x12\x03;\r\x13\n\x0c\n\x05\x04\x01\x02\x05\x01\x12\x03;\
+    \x14\x1a\n\x0c\n\x05\x04\x01\x02\x05\x03\x12\x03;\x1d\x1e\n\x83\x08\n\
+    \x02\x04\x02\x12\x04S\0Z\x012\xf6\x07\x20In\x20version\x201,\x20the\x20r\
+    equest\x20is\x20very\x20simple:\x20when\n\x20the\x20client\x20sends\x20a\
+    \x20MSG_PROTO\x20to\x20the\x20station,\x20if\x20the\n\x20generation\x20n\
+    umber\x20is\x20present,\x20then\x20this\x20request\x20includes\n\x20(in\
+    \x20addition\x20to\x20whatever\x20other\x20operations\x20are\x20part\x20\
+    of\x20the\n\x20request)\x20a\x20request\x20for\x20the\x20station\x20to\
+    \x20send\x20a\x20copy\x20of\n\x20the\x20current\x20decoy\x20set\x20that\
+    \x20has\x20a\x20generation\x20number\x20greater\n\x20than\x20the\x20gene\
+    ration\x20number\x20in\x20its\x20request.\n\n\x20If\x20the\x20response\
+    \x20contains\x20a\x20DecoyListUpdate\x20with\x20a\x20generation\x20numbe\
+    r\x20equal\n\x20to\x20that\x20which\x20the\x20client\x20sent,\x20then\
+    \x20the\x20client\x20is\x20\"caught\x20up\"\x20with\n\x20the\x20station\
+    \x20and\x20the\x20response\x20contains\x20no\x20new\x20information\n\x20\
+    (and\x20all\x20other\x20fields\x20may\x20be\x20omitted\x20or\x20empty).\
+    \x20\x20Otherwise,\n\x20the\x20station\x20will\x20send\x20the\x20latest\
+    \x20configuration\x20information,\n\x20along\x20with\x20its\x20generatio\
+    n\x20number.\n\n\x20The\x20station\x20can\x20also\x20send\x20ClientConf\
+    \x20messages\n\x20(as\x20part\x20of\x20Station2Client\x20messages)\x20wh\
+    enever\x20it\x20wants.\n\x20The\x20client\x20is\x20expected\x20to\x20rea\
+    ct\x20as\x20if\x20it\x20had\x20requested\n\x20such\x20messages\x20--\x20\
+    possibly\x20by\x20ignoring\x20them,\x20if\x20the\x20client\n\x20is\x20al\
+    ready\x20up-to-date\x20according\x20to\x20the\x20generation\x20number.\n\
+    \n\n\n\x03\x04\x02\x01\x12\x03S\x08\x12\n\x0b\n\x04\x04\x02\x02\0\x12\
+    \x03T\x04&\n\x0c\n\x05\x04\x02\x02\0\x04\x12\x03T\x04\x0c\n\x0c\n\x05\
+    \x04\x02\x02\0\x06\x12\x03T\r\x16\n\x0c\n\x05\x04\x02\x02\0\x01\x12\x03T\
+    \x17!\n\x0c\n\x05\x04\x02\x02\0\x03\x12\x03T$%\n\x0b\n\x04\x04\x02\x02\
+    \x01\x12\x03U\x04#\n\x0c\n\x05\x04\x02\x02\x01\x04\x12\x03U\x04\x0c\n\
+    \x0c\n\x05\x04\x02\x02\x01\x05\x12\x03U\r\x13\n\x0c\n\x05\x04\x02\x02\
+    \x01\x01\x12\x03U\x14\x1e\n\x0c\n\x05\x04\x02\x02\x01\x03\x12\x03U!\"\n\
+    \x0b\n\x04\x04\x02\x02\x02\x12\x03V\x04'\n\x0c\n\x05\x04\x02\x02\x02\x04\
+    \x12\x03V\x04\x0c\n\x0c\n\x05\x04\x02\x02\x02\x06\x12\x03V\r\x13\n\x0c\n\
+    \x05\x04\x02\x02\x02\x01\x12\x03V\x14\"\n\x0c\n\x05\x04\x02\x02\x02\x03\
+    \x12\x03V%&\n\x0b\n\x04\x04\x02\x02\x03\x12\x03W\x049\n\x0c\n\x05\x04\
+    \x02\x02\x03\x04\x12\x03W\x04\x0c\n\x0c\n\x05\x04\x02\x02\x03\x06\x12\
+    \x03W\r\x1f\n\x0c\n\x05\x04\x02\x02\x03\x01\x12\x03W\x204\n\x0c\n\x05\
+    \x04\x02\x02\x03\x03\x12\x03W78\n\x0b\n\x04\x04\x02\x02\x04\x12\x03X\x04\
+    '\n\x0c\n\x05\x04\x02\x02\x04\x04\x12\x03X\x04\x0c\n\x0c\n\x05\x04\x02\
+    \x02\x04\x06\x12\x03X\r\x13\n\x0c\n\x05\x04\x02\x02\x04\x01\x12\x03X\x14\
+    \"\n\x0c\n\x05\x04\x02\x02\x04\x03\x12\x03X%&\n\x0b\n\x04\x04\x02\x02\
+    \x05\x12\x03Y\x04)\n\x0c\n\x05\x04\x02\x02\x05\x04\x12\x03Y\x04\x0c\n\
+    \x0c\n\x05\x04\x02\x02\x05\x06\x12\x03Y\r\x17\n\x0c\n\x05\x04\x02\x02\
+    \x05\x01\x12\x03Y\x18$\n\x0c\n\x05\x04\x02\x02\x05\x03\x12\x03Y'(\n-\n\
+    \x02\x04\x03\x12\x04]\0d\x01\x1a!\x20Configuration\x20for\x20DNS\x20regi\
+    strar\n\n\n\n\x03\x04\x03\x01\x12\x03]\x08\x12\n\x0b\n\x04\x04\x03\x02\0\
+    \x12\x03^\x04-\n\x0c\n\x05\x04\x03\x02\0\x04\x12\x03^\x04\x0c\n\x0c\n\
     \x05\x04\x03\x02\0\x06\x12\x03^\r\x19\n\x0c\n\x05\x04\x03\x02\0\x01\x12\
     \x03^\x1a(\n\x0c\n\x05\x04\x03\x02\0\x03\x12\x03^+,\n\x0b\n\x04\x04\x03\
     \x02\x01\x12\x03_\x04\x1f\n\x0c\n\x05\x04\x03\x02\x01\x04\x12\x03_\x04\
@@ -7007,7 +7287,7 @@ static file_descriptor_proto_data: &'static [u8] = b"\
     .\n\n\r\n\x05\x04\n\x02\x06\x04\x12\x04\xd7\x01\x04\x0c\n\r\n\x05\x04\n\
     \x02\x06\x05\x12\x04\xd7\x01\r\x12\n\r\n\x05\x04\n\x02\x06\x01\x12\x04\
     \xd7\x01\x13\x1a\n\r\n\x05\x04\n\x02\x06\x03\x12\x04\xd7\x01\x1d\x20\n\
-    \x0c\n\x02\x04\x0b\x12\x06\xda\x01\0\xe0\x01\x01\n\x0b\n\x03\x04\x0b\x01\
+    \x0c\n\x02\x04\x0b\x12\x06\xda\x01\0\xe3\x01\x01\n\x0b\n\x03\x04\x0b\x01\
     \x12\x04\xda\x01\x08\x19\n\x0c\n\x04\x04\x0b\x02\0\x12\x04\xdb\x01\x08&\
     \n\r\n\x05\x04\x0b\x02\0\x04\x12\x04\xdb\x01\x08\x10\n\r\n\x05\x04\x0b\
     \x02\0\x05\x12\x04\xdb\x01\x11\x15\n\r\n\x05\x04\x0b\x02\0\x01\x12\x04\
@@ -7026,322 +7306,354 @@ static file_descriptor_proto_data: &'static [u8] = b"\
     \x04\x0b\x02\x04\x12\x04\xdf\x01\x04!\n\r\n\x05\x04\x0b\x02\x04\x04\x12\
     \x04\xdf\x01\x04\x0c\n\r\n\x05\x04\x0b\x02\x04\x05\x12\x04\xdf\x01\r\x11\
     \n\r\n\x05\x04\x0b\x02\x04\x01\x12\x04\xdf\x01\x12\x1c\n\r\n\x05\x04\x0b\
-    \x02\x04\x03\x12\x04\xdf\x01\x1f\x20\n\x0c\n\x02\x04\x0c\x12\x06\xe2\x01\
-    \0\x9c\x02\x01\n\x0b\n\x03\x04\x0c\x01\x12\x04\xe2\x01\x08\x17\n\x0c\n\
-    \x04\x04\x0c\x02\0\x12\x04\xe3\x01\x04)\n\r\n\x05\x04\x0c\x02\0\x04\x12\
-    \x04\xe3\x01\x04\x0c\n\r\n\x05\x04\x0c\x02\0\x05\x12\x04\xe3\x01\r\x13\n\
-    \r\n\x05\x04\x0c\x02\0\x01\x12\x04\xe3\x01\x14$\n\r\n\x05\x04\x0c\x02\0\
-    \x03\x12\x04\xe3\x01'(\n\xd0\x01\n\x04\x04\x0c\x02\x01\x12\x04\xe8\x01\
-    \x04.\x1a\xc1\x01\x20The\x20client\x20reports\x20its\x20decoy\x20list's\
-    \x20version\x20number\x20here,\x20which\x20the\n\x20station\x20can\x20us\
-    e\x20to\x20decide\x20whether\x20to\x20send\x20an\x20updated\x20one.\x20T\
-    he\x20station\n\x20should\x20always\x20send\x20a\x20list\x20if\x20this\
-    \x20field\x20is\x20set\x20to\x200.\n\n\r\n\x05\x04\x0c\x02\x01\x04\x12\
-    \x04\xe8\x01\x04\x0c\n\r\n\x05\x04\x0c\x02\x01\x05\x12\x04\xe8\x01\r\x13\
-    \n\r\n\x05\x04\x0c\x02\x01\x01\x12\x04\xe8\x01\x14)\n\r\n\x05\x04\x0c\
-    \x02\x01\x03\x12\x04\xe8\x01,-\n\x0c\n\x04\x04\x0c\x02\x02\x12\x04\xea\
-    \x01\x041\n\r\n\x05\x04\x0c\x02\x02\x04\x12\x04\xea\x01\x04\x0c\n\r\n\
-    \x05\x04\x0c\x02\x02\x06\x12\x04\xea\x01\r\x1b\n\r\n\x05\x04\x0c\x02\x02\
-    \x01\x12\x04\xea\x01\x1c,\n\r\n\x05\x04\x0c\x02\x02\x03\x12\x04\xea\x01/\
-    0\n\x80\x01\n\x04\x04\x0c\x02\x03\x12\x04\xee\x01\x04$\x1ar\x20The\x20po\
-    sition\x20in\x20the\x20overall\x20session's\x20upload\x20sequence\x20whe\
-    re\x20the\x20current\n\x20YIELD=>ACQUIRE\x20switchover\x20is\x20happenin\
-    g.\n\n\r\n\x05\x04\x0c\x02\x03\x04\x12\x04\xee\x01\x04\x0c\n\r\n\x05\x04\
-    \x0c\x02\x03\x05\x12\x04\xee\x01\r\x13\n\r\n\x05\x04\x0c\x02\x03\x01\x12\
-    \x04\xee\x01\x14\x1f\n\r\n\x05\x04\x0c\x02\x03\x03\x12\x04\xee\x01\"#\ng\
-    \n\x04\x04\x0c\x02\x04\x12\x04\xf2\x01\x04+\x1aY\x20High\x20level\x20cli\
-    ent\x20library\x20version\x20used\x20for\x20indicating\x20feature\x20sup\
-    port,\x20or\n\x20lack\x20therof.\n\n\r\n\x05\x04\x0c\x02\x04\x04\x12\x04\
-    \xf2\x01\x04\x0c\n\r\n\x05\x04\x0c\x02\x04\x05\x12\x04\xf2\x01\r\x13\n\r\
-    \n\x05\x04\x0c\x02\x04\x01\x12\x04\xf2\x01\x14&\n\r\n\x05\x04\x0c\x02\
-    \x04\x03\x12\x04\xf2\x01)*\n\xa5\x02\n\x04\x04\x0c\x02\x05\x12\x04\xf7\
-    \x01\x042\x1a\x96\x02\x20Indicates\x20whether\x20the\x20client\x20will\
-    \x20allow\x20the\x20registrar\x20to\x20provide\x20alternative\x20paramet\
-    ers\x20that\n\x20may\x20work\x20better\x20in\x20substitute\x20for\x20the\
-    \x20deterministically\x20selected\x20parameters.\x20This\x20only\x20work\
-    s\n\x20for\x20bidirectional\x20registration\x20methods\x20where\x20the\
-    \x20client\x20receives\x20a\x20RegistrationResponse.\n\n\r\n\x05\x04\x0c\
-    \x02\x05\x04\x12\x04\xf7\x01\x04\x0c\n\r\n\x05\x04\x0c\x02\x05\x05\x12\
-    \x04\xf7\x01\r\x11\n\r\n\x05\x04\x0c\x02\x05\x01\x12\x04\xf7\x01\x12-\n\
-    \r\n\x05\x04\x0c\x02\x05\x03\x12\x04\xf7\x0101\nq\n\x04\x04\x0c\x02\x06\
-    \x12\x04\xfb\x01\x04'\x1ac\x20List\x20of\x20decoys\x20that\x20client\x20\
-    have\x20unsuccessfully\x20tried\x20in\x20current\x20session.\n\x20Could\
-    \x20be\x20sent\x20in\x20chunks\n\n\r\n\x05\x04\x0c\x02\x06\x04\x12\x04\
-    \xfb\x01\x04\x0c\n\r\n\x05\x04\x0c\x02\x06\x05\x12\x04\xfb\x01\r\x13\n\r\
-    \n\x05\x04\x0c\x02\x06\x01\x12\x04\xfb\x01\x14!\n\r\n\x05\x04\x0c\x02\
-    \x06\x03\x12\x04\xfb\x01$&\n\x0c\n\x04\x04\x0c\x02\x07\x12\x04\xfd\x01\
-    \x04%\n\r\n\x05\x04\x0c\x02\x07\x04\x12\x04\xfd\x01\x04\x0c\n\r\n\x05\
-    \x04\x0c\x02\x07\x06\x12\x04\xfd\x01\r\x19\n\r\n\x05\x04\x0c\x02\x07\x01\
-    \x12\x04\xfd\x01\x1a\x1f\n\r\n\x05\x04\x0c\x02\x07\x03\x12\x04\xfd\x01\"\
-    $\nk\n\x04\x04\x0c\x02\x08\x12\x04\x80\x02\x04*\x1a]\x20NullTransport,\
-    \x20MinTransport,\x20Obfs4Transport,\x20etc.\x20Transport\x20type\x20we\
-    \x20want\x20from\x20phantom\x20proxy\n\n\r\n\x05\x04\x0c\x02\x08\x04\x12\
-    \x04\x80\x02\x04\x0c\n\r\n\x05\x04\x0c\x02\x08\x06\x12\x04\x80\x02\r\x1a\
-    \n\r\n\x05\x04\x0c\x02\x08\x01\x12\x04\x80\x02\x1b$\n\r\n\x05\x04\x0c\
-    \x02\x08\x03\x12\x04\x80\x02')\n\x0c\n\x04\x04\x0c\x02\t\x12\x04\x82\x02\
-    \x047\n\r\n\x05\x04\x0c\x02\t\x04\x12\x04\x82\x02\x04\x0c\n\r\n\x05\x04\
-    \x0c\x02\t\x06\x12\x04\x82\x02\r\x20\n\r\n\x05\x04\x0c\x02\t\x01\x12\x04\
-    \x82\x02!1\n\r\n\x05\x04\x0c\x02\t\x03\x12\x04\x82\x0246\n\xc8\x03\n\x04\
-    \x04\x0c\x02\n\x12\x04\x8a\x02\x04(\x1a\xb9\x03\x20Station\x20is\x20only\
-    \x20required\x20to\x20check\x20this\x20variable\x20during\x20session\x20\
-    initialization.\n\x20If\x20set,\x20station\x20must\x20facilitate\x20conn\
-    ection\x20to\x20said\x20target\x20by\x20itself,\x20i.e.\x20write\x20into\
-    \x20squid\n\x20socket\x20an\x20HTTP/SOCKS/any\x20other\x20connection\x20\
-    request.\n\x20covert_address\x20must\x20have\x20exactly\x20one\x20':'\
-    \x20colon,\x20that\x20separates\x20host\x20(literal\x20IP\x20address\x20\
-    or\n\x20resolvable\x20hostname)\x20and\x20port\n\x20TODO:\x20make\x20it\
-    \x20required\x20for\x20initialization,\x20and\x20stop\x20connecting\x20a\
-    ny\x20client\x20straight\x20to\x20squid?\n\n\r\n\x05\x04\x0c\x02\n\x04\
-    \x12\x04\x8a\x02\x04\x0c\n\r\n\x05\x04\x0c\x02\n\x05\x12\x04\x8a\x02\r\
-    \x13\n\r\n\x05\x04\x0c\x02\n\x01\x12\x04\x8a\x02\x14\"\n\r\n\x05\x04\x0c\
-    \x02\n\x03\x12\x04\x8a\x02%'\nR\n\x04\x04\x0c\x02\x0b\x12\x04\x8d\x02\
-    \x042\x1aD\x20Used\x20in\x20dark\x20decoys\x20to\x20signal\x20which\x20d\
-    ark\x20decoy\x20it\x20will\x20connect\x20to.\n\n\r\n\x05\x04\x0c\x02\x0b\
-    \x04\x12\x04\x8d\x02\x04\x0c\n\r\n\x05\x04\x0c\x02\x0b\x05\x12\x04\x8d\
-    \x02\r\x13\n\r\n\x05\x04\x0c\x02\x0b\x01\x12\x04\x8d\x02\x14,\n\r\n\x05\
-    \x04\x0c\x02\x0b\x03\x12\x04\x8d\x02/1\nR\n\x04\x04\x0c\x02\x0c\x12\x04\
-    \x90\x02\x04\"\x1aD\x20Used\x20to\x20indicate\x20to\x20server\x20if\x20c\
-    lient\x20is\x20registering\x20v4,\x20v6\x20or\x20both\n\n\r\n\x05\x04\
-    \x0c\x02\x0c\x04\x12\x04\x90\x02\x04\x0c\n\r\n\x05\x04\x0c\x02\x0c\x05\
-    \x12\x04\x90\x02\r\x11\n\r\n\x05\x04\x0c\x02\x0c\x01\x12\x04\x90\x02\x12\
-    \x1c\n\r\n\x05\x04\x0c\x02\x0c\x03\x12\x04\x90\x02\x1f!\n\x0c\n\x04\x04\
-    \x0c\x02\r\x12\x04\x91\x02\x04\"\n\r\n\x05\x04\x0c\x02\r\x04\x12\x04\x91\
-    \x02\x04\x0c\n\r\n\x05\x04\x0c\x02\r\x05\x12\x04\x91\x02\r\x11\n\r\n\x05\
-    \x04\x0c\x02\r\x01\x12\x04\x91\x02\x12\x1c\n\r\n\x05\x04\x0c\x02\r\x03\
-    \x12\x04\x91\x02\x1f!\nD\n\x04\x04\x0c\x02\x0e\x12\x04\x94\x02\x04*\x1a6\
-    \x20A\x20collection\x20of\x20optional\x20flags\x20for\x20the\x20registra\
-    tion.\n\n\r\n\x05\x04\x0c\x02\x0e\x04\x12\x04\x94\x02\x04\x0c\n\r\n\x05\
-    \x04\x0c\x02\x0e\x06\x12\x04\x94\x02\r\x1e\n\r\n\x05\x04\x0c\x02\x0e\x01\
-    \x12\x04\x94\x02\x1f$\n\r\n\x05\x04\x0c\x02\x0e\x03\x12\x04\x94\x02')\nq\
-    \n\x04\x04\x0c\x02\x0f\x12\x04\x98\x02\x04-\x1ac\x20Transport\x20Extensi\
-    ons\n\x20TODO(jmwample)\x20-\x20move\x20to\x20WebRTC\x20specific\x20tran\
-    sport\x20params\x20protobuf\x20message.\n\n\r\n\x05\x04\x0c\x02\x0f\x04\
-    \x12\x04\x98\x02\x04\x0c\n\r\n\x05\x04\x0c\x02\x0f\x06\x12\x04\x98\x02\r\
-    \x19\n\r\n\x05\x04\x0c\x02\x0f\x01\x12\x04\x98\x02\x1a'\n\r\n\x05\x04\
-    \x0c\x02\x0f\x03\x12\x04\x98\x02*,\nG\n\x04\x04\x0c\x02\x10\x12\x04\x9b\
-    \x02\x04!\x1a9\x20Random-sized\x20junk\x20to\x20defeat\x20packet\x20size\
-    \x20fingerprinting.\n\n\r\n\x05\x04\x0c\x02\x10\x04\x12\x04\x9b\x02\x04\
-    \x0c\n\r\n\x05\x04\x0c\x02\x10\x05\x12\x04\x9b\x02\r\x12\n\r\n\x05\x04\
-    \x0c\x02\x10\x01\x12\x04\x9b\x02\x13\x1a\n\r\n\x05\x04\x0c\x02\x10\x03\
-    \x12\x04\x9b\x02\x1d\x20\n\x0c\n\x02\x04\r\x12\x06\x9f\x02\0\xb1\x02\x01\
-    \n\x0b\n\x03\x04\r\x01\x12\x04\x9f\x02\x08\x1d\n!\n\x04\x04\r\x02\0\x12\
-    \x04\xa1\x02\x04!\x1a\x13\x20Prefix\x20Identifier\n\n\r\n\x05\x04\r\x02\
-    \0\x04\x12\x04\xa1\x02\x04\x0c\n\r\n\x05\x04\r\x02\0\x05\x12\x04\xa1\x02\
-    \r\x12\n\r\n\x05\x04\r\x02\0\x01\x12\x04\xa1\x02\x13\x1c\n\r\n\x05\x04\r\
-    \x02\0\x03\x12\x04\xa1\x02\x1f\x20\n\xc4\x01\n\x04\x04\r\x02\x01\x12\x04\
-    \xa5\x02\x04\x1e\x1a\xb5\x01\x20Prefix\x20bytes\x20(optional\x20-\x20usu\
-    ally\x20sent\x20from\x20station\x20to\x20client\x20as\x20override\x20if\
-    \x20allowed\x20by\x20C2S)\n\x20as\x20the\x20station\x20cannot\x20take\
-    \x20this\x20into\x20account\x20when\x20attempting\x20to\x20identify\x20a\
-    \x20connection.\n\n\r\n\x05\x04\r\x02\x01\x04\x12\x04\xa5\x02\x04\x0c\n\
-    \r\n\x05\x04\r\x02\x01\x05\x12\x04\xa5\x02\r\x12\n\r\n\x05\x04\r\x02\x01\
-    \x01\x12\x04\xa5\x02\x13\x19\n\r\n\x05\x04\r\x02\x01\x03\x12\x04\xa5\x02\
-    \x1c\x1d\n\x0c\n\x04\x04\r\x02\x02\x12\x04\xa6\x02\x04)\n\r\n\x05\x04\r\
-    \x02\x02\x04\x12\x04\xa6\x02\x04\x0c\n\r\n\x05\x04\r\x02\x02\x05\x12\x04\
-    \xa6\x02\r\x11\n\r\n\x05\x04\r\x02\x02\x01\x12\x04\xa6\x02\x12$\n\r\n\
-    \x05\x04\r\x02\x02\x03\x12\x04\xa6\x02'(\n\xed\x02\n\x04\x04\r\x02\x03\
-    \x12\x04\xb0\x02\x04*\x1a\xbc\x01\x20Indicates\x20whether\x20the\x20clie\
-    nt\x20has\x20elected\x20to\x20use\x20destination\x20port\x20randomizatio\
-    n.\x20Should\x20be\n\x20checked\x20against\x20selected\x20transport\x20t\
-    o\x20ensure\x20that\x20destination\x20port\x20randomization\x20is\n\x20s\
-    upported.\n2\x9f\x01\x20//\x20potential\x20future\x20fields\n\x20obfusca\
-    tor\x20ID\n\x20tagEncoder\x20ID\x20(&params?,\x20e.g.\x20format-base64\
-    \x20/\x20padding)\n\x20streamEncoder\x20ID\x20(&params?,\x20e.g.\x20fora\
-    mat-base64\x20/\x20padding)\n\n\r\n\x05\x04\r\x02\x03\x04\x12\x04\xb0\
-    \x02\x04\x0c\n\r\n\x05\x04\r\x02\x03\x05\x12\x04\xb0\x02\r\x11\n\r\n\x05\
-    \x04\r\x02\x03\x01\x12\x04\xb0\x02\x12$\n\r\n\x05\x04\r\x02\x03\x03\x12\
-    \x04\xb0\x02')\n\x0c\n\x02\x04\x0e\x12\x06\xb3\x02\0\xb8\x02\x01\n\x0b\n\
-    \x03\x04\x0e\x01\x12\x04\xb3\x02\x08\x1e\n\xcb\x01\n\x04\x04\x0e\x02\0\
-    \x12\x04\xb7\x02\x04*\x1a\xbc\x01\x20Indicates\x20whether\x20the\x20clie\
-    nt\x20has\x20elected\x20to\x20use\x20destination\x20port\x20randomizatio\
-    n.\x20Should\x20be\n\x20checked\x20against\x20selected\x20transport\x20t\
-    o\x20ensure\x20that\x20destination\x20port\x20randomization\x20is\n\x20s\
-    upported.\n\n\r\n\x05\x04\x0e\x02\0\x04\x12\x04\xb7\x02\x04\x0c\n\r\n\
-    \x05\x04\x0e\x02\0\x05\x12\x04\xb7\x02\r\x11\n\r\n\x05\x04\x0e\x02\0\x01\
-    \x12\x04\xb7\x02\x12$\n\r\n\x05\x04\x0e\x02\0\x03\x12\x04\xb7\x02')\n\
-    \x0c\n\x02\x05\x06\x12\x06\xba\x02\0\xc2\x02\x01\n\x0b\n\x03\x05\x06\x01\
-    \x12\x04\xba\x02\x05\x17\n\x0c\n\x04\x05\x06\x02\0\x12\x04\xbb\x02\x02\
-    \x12\n\r\n\x05\x05\x06\x02\0\x01\x12\x04\xbb\x02\x02\r\n\r\n\x05\x05\x06\
-    \x02\0\x02\x12\x04\xbb\x02\x10\x11\n\x0c\n\x04\x05\x06\x02\x01\x12\x04\
-    \xbc\x02\x08\x15\n\r\n\x05\x05\x06\x02\x01\x01\x12\x04\xbc\x02\x08\x10\n\
-    \r\n\x05\x05\x06\x02\x01\x02\x12\x04\xbc\x02\x13\x14\n\x0c\n\x04\x05\x06\
-    \x02\x02\x12\x04\xbd\x02\x08\x10\n\r\n\x05\x05\x06\x02\x02\x01\x12\x04\
-    \xbd\x02\x08\x0b\n\r\n\x05\x05\x06\x02\x02\x02\x12\x04\xbd\x02\x0e\x0f\n\
-    \x0c\n\x04\x05\x06\x02\x03\x12\x04\xbe\x02\x02\x16\n\r\n\x05\x05\x06\x02\
-    \x03\x01\x12\x04\xbe\x02\x02\x11\n\r\n\x05\x05\x06\x02\x03\x02\x12\x04\
-    \xbe\x02\x14\x15\n\x0c\n\x04\x05\x06\x02\x04\x12\x04\xbf\x02\x02\x17\n\r\
-    \n\x05\x05\x06\x02\x04\x01\x12\x04\xbf\x02\x02\x12\n\r\n\x05\x05\x06\x02\
-    \x04\x02\x12\x04\xbf\x02\x15\x16\n\x0c\n\x04\x05\x06\x02\x05\x12\x04\xc0\
-    \x02\x02\n\n\r\n\x05\x05\x06\x02\x05\x01\x12\x04\xc0\x02\x02\x05\n\r\n\
-    \x05\x05\x06\x02\x05\x02\x12\x04\xc0\x02\x08\t\n\x0c\n\x04\x05\x06\x02\
-    \x06\x12\x04\xc1\x02\x02\x17\n\r\n\x05\x05\x06\x02\x06\x01\x12\x04\xc1\
-    \x02\x02\x12\n\r\n\x05\x05\x06\x02\x06\x02\x12\x04\xc1\x02\x15\x16\n\x0c\
-    \n\x02\x04\x0f\x12\x06\xc4\x02\0\xdc\x02\x01\n\x0b\n\x03\x04\x0f\x01\x12\
-    \x04\xc4\x02\x08\x12\n\x0c\n\x04\x04\x0f\x02\0\x12\x04\xc5\x02\x02#\n\r\
-    \n\x05\x04\x0f\x02\0\x04\x12\x04\xc5\x02\x02\n\n\r\n\x05\x04\x0f\x02\0\
-    \x05\x12\x04\xc5\x02\x0b\x10\n\r\n\x05\x04\x0f\x02\0\x01\x12\x04\xc5\x02\
-    \x11\x1e\n\r\n\x05\x04\x0f\x02\0\x03\x12\x04\xc5\x02!\"\n\x0c\n\x04\x04\
-    \x0f\x02\x01\x12\x04\xc6\x02\x024\n\r\n\x05\x04\x0f\x02\x01\x04\x12\x04\
-    \xc6\x02\x02\n\n\r\n\x05\x04\x0f\x02\x01\x06\x12\x04\xc6\x02\x0b\x1a\n\r\
-    \n\x05\x04\x0f\x02\x01\x01\x12\x04\xc6\x02\x1b/\n\r\n\x05\x04\x0f\x02\
-    \x01\x03\x12\x04\xc6\x0223\n\x0c\n\x04\x04\x0f\x02\x02\x12\x04\xc7\x02\
-    \x026\n\r\n\x05\x04\x0f\x02\x02\x04\x12\x04\xc7\x02\x02\n\n\r\n\x05\x04\
-    \x0f\x02\x02\x06\x12\x04\xc7\x02\x0b\x1d\n\r\n\x05\x04\x0f\x02\x02\x01\
-    \x12\x04\xc7\x02\x1e1\n\r\n\x05\x04\x0f\x02\x02\x03\x12\x04\xc7\x0245\nC\
-    \n\x04\x04\x0f\x02\x03\x12\x04\xca\x02\x02*\x1a5\x20client\x20source\x20\
-    address\x20when\x20receiving\x20a\x20registration\n\n\r\n\x05\x04\x0f\
-    \x02\x03\x04\x12\x04\xca\x02\x02\n\n\r\n\x05\x04\x0f\x02\x03\x05\x12\x04\
-    \xca\x02\x0b\x10\n\r\n\x05\x04\x0f\x02\x03\x01\x12\x04\xca\x02\x11%\n\r\
-    \n\x05\x04\x0f\x02\x03\x03\x12\x04\xca\x02()\nH\n\x04\x04\x0f\x02\x04\
-    \x12\x04\xcd\x02\x02#\x1a:\x20Decoy\x20address\x20used\x20when\x20regist\
-    ering\x20over\x20Decoy\x20registrar\n\n\r\n\x05\x04\x0f\x02\x04\x04\x12\
-    \x04\xcd\x02\x02\n\n\r\n\x05\x04\x0f\x02\x04\x05\x12\x04\xcd\x02\x0b\x10\
-    \n\r\n\x05\x04\x0f\x02\x04\x01\x12\x04\xcd\x02\x11\x1e\n\r\n\x05\x04\x0f\
-    \x02\x04\x03\x12\x04\xcd\x02!\"\n\xeb\x05\n\x04\x04\x0f\x02\x05\x12\x04\
-    \xd9\x02\x02:\x1a\xdc\x05\x20The\x20next\x20three\x20fields\x20allow\x20\
-    an\x20independent\x20registrar\x20(trusted\x20by\x20a\x20station\x20w/\
-    \x20a\x20zmq\x20keypair)\x20to\n\x20share\x20the\x20registration\x20over\
-    rides\x20that\x20it\x20assigned\x20to\x20the\x20client\x20with\x20the\
-    \x20station(s).\n\x20Registration\x20Respose\x20is\x20here\x20to\x20allo\
-    w\x20a\x20parsed\x20object\x20with\x20direct\x20access\x20to\x20the\x20f\
-    ields\x20within.\n\x20RegRespBytes\x20provides\x20a\x20serialized\x20ver\
-    ion\x20of\x20the\x20Registration\x20response\x20so\x20that\x20the\x20sig\
-    nature\x20of\n\x20the\x20Bidirectional\x20registrar\x20can\x20be\x20vali\
-    dated\x20before\x20a\x20station\x20applies\x20any\x20overrides\x20presen\
-    t\x20in\n\x20the\x20Registration\x20Response.\n\n\x20If\x20you\x20are\
-    \x20reading\x20this\x20in\x20the\x20future\x20and\x20you\x20want\x20to\
-    \x20extend\x20the\x20functionality\x20here\x20it\x20might\n\x20make\x20s\
-    ense\x20to\x20make\x20the\x20RegistrationResponse\x20that\x20is\x20sent\
-    \x20to\x20the\x20client\x20a\x20distinct\x20message\x20from\n\x20the\x20\
-    one\x20that\x20gets\x20sent\x20to\x20the\x20stations.\n\n\r\n\x05\x04\
-    \x0f\x02\x05\x04\x12\x04\xd9\x02\x02\n\n\r\n\x05\x04\x0f\x02\x05\x06\x12\
-    \x04\xd9\x02\x0b\x1f\n\r\n\x05\x04\x0f\x02\x05\x01\x12\x04\xd9\x02\x205\
-    \n\r\n\x05\x04\x0f\x02\x05\x03\x12\x04\xd9\x0289\n\x0c\n\x04\x04\x0f\x02\
-    \x06\x12\x04\xda\x02\x02\"\n\r\n\x05\x04\x0f\x02\x06\x04\x12\x04\xda\x02\
-    \x02\n\n\r\n\x05\x04\x0f\x02\x06\x05\x12\x04\xda\x02\x0b\x10\n\r\n\x05\
-    \x04\x0f\x02\x06\x01\x12\x04\xda\x02\x11\x1d\n\r\n\x05\x04\x0f\x02\x06\
-    \x03\x12\x04\xda\x02\x20!\n\x0c\n\x04\x04\x0f\x02\x07\x12\x04\xdb\x02\
-    \x02'\n\r\n\x05\x04\x0f\x02\x07\x04\x12\x04\xdb\x02\x02\n\n\r\n\x05\x04\
-    \x0f\x02\x07\x05\x12\x04\xdb\x02\x0b\x10\n\r\n\x05\x04\x0f\x02\x07\x01\
-    \x12\x04\xdb\x02\x11!\n\r\n\x05\x04\x0f\x02\x07\x03\x12\x04\xdb\x02$&\n\
-    \x0c\n\x02\x04\x10\x12\x06\xde\x02\0\xea\x02\x01\n\x0b\n\x03\x04\x10\x01\
-    \x12\x04\xde\x02\x08\x14\n9\n\x04\x04\x10\x02\0\x12\x04\xdf\x02\x04.\"+\
-    \x20how\x20many\x20decoys\x20were\x20tried\x20before\x20success\n\n\r\n\
-    \x05\x04\x10\x02\0\x04\x12\x04\xdf\x02\x04\x0c\n\r\n\x05\x04\x10\x02\0\
-    \x05\x12\x04\xdf\x02\r\x13\n\r\n\x05\x04\x10\x02\0\x01\x12\x04\xdf\x02\
-    \x14(\n\r\n\x05\x04\x10\x02\0\x03\x12\x04\xdf\x02+-\nm\n\x04\x04\x10\x02\
-    \x01\x12\x04\xe4\x02\x04/\x1a\x1e\x20Applicable\x20to\x20whole\x20sessio\
-    n:\n\"\x1a\x20includes\x20failed\x20attempts\n2#\x20Timings\x20below\x20\
-    are\x20in\x20milliseconds\n\n\r\n\x05\x04\x10\x02\x01\x04\x12\x04\xe4\
-    \x02\x04\x0c\n\r\n\x05\x04\x10\x02\x01\x05\x12\x04\xe4\x02\r\x13\n\r\n\
-    \x05\x04\x10\x02\x01\x01\x12\x04\xe4\x02\x14)\n\r\n\x05\x04\x10\x02\x01\
-    \x03\x12\x04\xe4\x02,.\nR\n\x04\x04\x10\x02\x02\x12\x04\xe7\x02\x04(\x1a\
-    \x1f\x20Last\x20(i.e.\x20successful)\x20decoy:\n\"#\x20measured\x20durin\
-    g\x20initial\x20handshake\n\n\r\n\x05\x04\x10\x02\x02\x04\x12\x04\xe7\
-    \x02\x04\x0c\n\r\n\x05\x04\x10\x02\x02\x05\x12\x04\xe7\x02\r\x13\n\r\n\
-    \x05\x04\x10\x02\x02\x01\x12\x04\xe7\x02\x14\"\n\r\n\x05\x04\x10\x02\x02\
-    \x03\x12\x04\xe7\x02%'\n%\n\x04\x04\x10\x02\x03\x12\x04\xe8\x02\x04&\"\
-    \x17\x20includes\x20tcp\x20to\x20decoy\n\n\r\n\x05\x04\x10\x02\x03\x04\
-    \x12\x04\xe8\x02\x04\x0c\n\r\n\x05\x04\x10\x02\x03\x05\x12\x04\xe8\x02\r\
-    \x13\n\r\n\x05\x04\x10\x02\x03\x01\x12\x04\xe8\x02\x14\x20\n\r\n\x05\x04\
-    \x10\x02\x03\x03\x12\x04\xe8\x02#%\nB\n\x04\x04\x10\x02\x04\x12\x04\xe9\
-    \x02\x04&\"4\x20measured\x20when\x20establishing\x20tcp\x20connection\
-    \x20to\x20decot\n\n\r\n\x05\x04\x10\x02\x04\x04\x12\x04\xe9\x02\x04\x0c\
-    \n\r\n\x05\x04\x10\x02\x04\x05\x12\x04\xe9\x02\r\x13\n\r\n\x05\x04\x10\
-    \x02\x04\x01\x12\x04\xe9\x02\x14\x20\n\r\n\x05\x04\x10\x02\x04\x03\x12\
-    \x04\xe9\x02#%\n\x0c\n\x02\x05\x07\x12\x06\xec\x02\0\xf1\x02\x01\n\x0b\n\
-    \x03\x05\x07\x01\x12\x04\xec\x02\x05\x16\n\x0c\n\x04\x05\x07\x02\0\x12\
-    \x04\xed\x02\x04\x10\n\r\n\x05\x05\x07\x02\0\x01\x12\x04\xed\x02\x04\x0b\
-    \n\r\n\x05\x05\x07\x02\0\x02\x12\x04\xed\x02\x0e\x0f\n\x0c\n\x04\x05\x07\
-    \x02\x01\x12\x04\xee\x02\x04\x0c\n\r\n\x05\x05\x07\x02\x01\x01\x12\x04\
-    \xee\x02\x04\x07\n\r\n\x05\x05\x07\x02\x01\x02\x12\x04\xee\x02\n\x0b\n\
-    \x0c\n\x04\x05\x07\x02\x02\x12\x04\xef\x02\x04\x0f\n\r\n\x05\x05\x07\x02\
-    \x02\x01\x12\x04\xef\x02\x04\n\n\r\n\x05\x05\x07\x02\x02\x02\x12\x04\xef\
-    \x02\r\x0e\n\x0c\n\x04\x05\x07\x02\x03\x12\x04\xf0\x02\x04\x0e\n\r\n\x05\
-    \x05\x07\x02\x03\x01\x12\x04\xf0\x02\x04\t\n\r\n\x05\x05\x07\x02\x03\x02\
-    \x12\x04\xf0\x02\x0c\r\n\x0c\n\x02\x05\x08\x12\x06\xf3\x02\0\xf7\x02\x01\
-    \n\x0b\n\x03\x05\x08\x01\x12\x04\xf3\x02\x05\x0c\n\x0c\n\x04\x05\x08\x02\
-    \0\x12\x04\xf4\x02\x04\x0c\n\r\n\x05\x05\x08\x02\0\x01\x12\x04\xf4\x02\
-    \x04\x07\n\r\n\x05\x05\x08\x02\0\x02\x12\x04\xf4\x02\n\x0b\n\x0c\n\x04\
-    \x05\x08\x02\x01\x12\x04\xf5\x02\x04\x0c\n\r\n\x05\x05\x08\x02\x01\x01\
-    \x12\x04\xf5\x02\x04\x07\n\r\n\x05\x05\x08\x02\x01\x02\x12\x04\xf5\x02\n\
-    \x0b\n\x0c\n\x04\x05\x08\x02\x02\x12\x04\xf6\x02\x04\x0c\n\r\n\x05\x05\
-    \x08\x02\x02\x01\x12\x04\xf6\x02\x04\x07\n\r\n\x05\x05\x08\x02\x02\x02\
-    \x12\x04\xf6\x02\n\x0b\n\x0c\n\x02\x04\x11\x12\x06\xf9\x02\0\x84\x03\x01\
-    \n\x0b\n\x03\x04\x11\x01\x12\x04\xf9\x02\x08\x19\n\x0c\n\x04\x04\x11\x02\
-    \0\x12\x04\xfa\x02\x04#\n\r\n\x05\x04\x11\x02\0\x04\x12\x04\xfa\x02\x04\
-    \x0c\n\r\n\x05\x04\x11\x02\0\x05\x12\x04\xfa\x02\r\x13\n\r\n\x05\x04\x11\
-    \x02\0\x01\x12\x04\xfa\x02\x14\x1e\n\r\n\x05\x04\x11\x02\0\x03\x12\x04\
-    \xfa\x02!\"\n\x0c\n\x04\x04\x11\x02\x01\x12\x04\xfb\x02\x04\"\n\r\n\x05\
-    \x04\x11\x02\x01\x04\x12\x04\xfb\x02\x04\x0c\n\r\n\x05\x04\x11\x02\x01\
-    \x05\x12\x04\xfb\x02\r\x13\n\r\n\x05\x04\x11\x02\x01\x01\x12\x04\xfb\x02\
-    \x14\x1d\n\r\n\x05\x04\x11\x02\x01\x03\x12\x04\xfb\x02\x20!\n\x0c\n\x04\
-    \x04\x11\x02\x02\x12\x04\xfd\x02\x04#\n\r\n\x05\x04\x11\x02\x02\x04\x12\
-    \x04\xfd\x02\x04\x0c\n\r\n\x05\x04\x11\x02\x02\x05\x12\x04\xfd\x02\r\x13\
-    \n\r\n\x05\x04\x11\x02\x02\x01\x12\x04\xfd\x02\x14\x1e\n\r\n\x05\x04\x11\
-    \x02\x02\x03\x12\x04\xfd\x02!\"\n\x0c\n\x04\x04\x11\x02\x03\x12\x04\xff\
-    \x02\x04-\n\r\n\x05\x04\x11\x02\x03\x04\x12\x04\xff\x02\x04\x0c\n\r\n\
-    \x05\x04\x11\x02\x03\x06\x12\x04\xff\x02\r\x1e\n\r\n\x05\x04\x11\x02\x03\
-    \x01\x12\x04\xff\x02\x1f(\n\r\n\x05\x04\x11\x02\x03\x03\x12\x04\xff\x02+\
-    ,\n\x0c\n\x04\x04\x11\x02\x04\x12\x04\x81\x03\x04\"\n\r\n\x05\x04\x11\
-    \x02\x04\x04\x12\x04\x81\x03\x04\x0c\n\r\n\x05\x04\x11\x02\x04\x05\x12\
-    \x04\x81\x03\r\x13\n\r\n\x05\x04\x11\x02\x04\x01\x12\x04\x81\x03\x14\x1c\
-    \n\r\n\x05\x04\x11\x02\x04\x03\x12\x04\x81\x03\x1f!\n\x0c\n\x04\x04\x11\
-    \x02\x05\x12\x04\x82\x03\x04\"\n\r\n\x05\x04\x11\x02\x05\x04\x12\x04\x82\
-    \x03\x04\x0c\n\r\n\x05\x04\x11\x02\x05\x05\x12\x04\x82\x03\r\x13\n\r\n\
-    \x05\x04\x11\x02\x05\x01\x12\x04\x82\x03\x14\x1c\n\r\n\x05\x04\x11\x02\
-    \x05\x03\x12\x04\x82\x03\x1f!\n\x0c\n\x04\x04\x11\x02\x06\x12\x04\x83\
-    \x03\x04\x20\n\r\n\x05\x04\x11\x02\x06\x04\x12\x04\x83\x03\x04\x0c\n\r\n\
-    \x05\x04\x11\x02\x06\x06\x12\x04\x83\x03\r\x14\n\r\n\x05\x04\x11\x02\x06\
-    \x01\x12\x04\x83\x03\x15\x1a\n\r\n\x05\x04\x11\x02\x06\x03\x12\x04\x83\
-    \x03\x1d\x1f\nT\n\x02\x04\x12\x12\x06\x87\x03\0\x9b\x03\x01\x1aF\x20Addi\
-    ng\x20message\x20response\x20from\x20Station\x20to\x20Client\x20for\x20b\
-    idirectional\x20API\n\n\x0b\n\x03\x04\x12\x01\x12\x04\x87\x03\x08\x1c\n\
-    \x0c\n\x04\x04\x12\x02\0\x12\x04\x88\x03\x02\x20\n\r\n\x05\x04\x12\x02\0\
-    \x04\x12\x04\x88\x03\x02\n\n\r\n\x05\x04\x12\x02\0\x05\x12\x04\x88\x03\
-    \x0b\x12\n\r\n\x05\x04\x12\x02\0\x01\x12\x04\x88\x03\x13\x1b\n\r\n\x05\
-    \x04\x12\x02\0\x03\x12\x04\x88\x03\x1e\x1f\n?\n\x04\x04\x12\x02\x01\x12\
-    \x04\x8a\x03\x02\x1e\x1a1\x20The\x20128-bit\x20ipv6\x20address,\x20in\
-    \x20network\x20byte\x20order\n\n\r\n\x05\x04\x12\x02\x01\x04\x12\x04\x8a\
-    \x03\x02\n\n\r\n\x05\x04\x12\x02\x01\x05\x12\x04\x8a\x03\x0b\x10\n\r\n\
-    \x05\x04\x12\x02\x01\x01\x12\x04\x8a\x03\x11\x19\n\r\n\x05\x04\x12\x02\
-    \x01\x03\x12\x04\x8a\x03\x1c\x1d\n,\n\x04\x04\x12\x02\x02\x12\x04\x8d\
-    \x03\x02\x1f\x1a\x1e\x20Respond\x20with\x20randomized\x20port\n\n\r\n\
-    \x05\x04\x12\x02\x02\x04\x12\x04\x8d\x03\x02\n\n\r\n\x05\x04\x12\x02\x02\
-    \x05\x12\x04\x8d\x03\x0b\x11\n\r\n\x05\x04\x12\x02\x02\x01\x12\x04\x8d\
-    \x03\x12\x1a\n\r\n\x05\x04\x12\x02\x02\x03\x12\x04\x8d\x03\x1d\x1e\nd\n\
-    \x04\x04\x12\x02\x03\x12\x04\x91\x03\x02\"\x1aV\x20Future:\x20station\
-    \x20provides\x20client\x20with\x20secret,\x20want\x20chanel\x20present\n\
-    \x20Leave\x20null\x20for\x20now\n\n\r\n\x05\x04\x12\x02\x03\x04\x12\x04\
-    \x91\x03\x02\n\n\r\n\x05\x04\x12\x02\x03\x05\x12\x04\x91\x03\x0b\x10\n\r\
-    \n\x05\x04\x12\x02\x03\x01\x12\x04\x91\x03\x11\x1d\n\r\n\x05\x04\x12\x02\
-    \x03\x03\x12\x04\x91\x03\x20!\nA\n\x04\x04\x12\x02\x04\x12\x04\x94\x03\
-    \x02\x1c\x1a3\x20If\x20registration\x20wrong,\x20populate\x20this\x20err\
-    or\x20string\n\n\r\n\x05\x04\x12\x02\x04\x04\x12\x04\x94\x03\x02\n\n\r\n\
-    \x05\x04\x12\x02\x04\x05\x12\x04\x94\x03\x0b\x11\n\r\n\x05\x04\x12\x02\
-    \x04\x01\x12\x04\x94\x03\x12\x17\n\r\n\x05\x04\x12\x02\x04\x03\x12\x04\
-    \x94\x03\x1a\x1b\n+\n\x04\x04\x12\x02\x05\x12\x04\x97\x03\x02%\x1a\x1d\
-    \x20ClientConf\x20field\x20(optional)\n\n\r\n\x05\x04\x12\x02\x05\x04\
-    \x12\x04\x97\x03\x02\n\n\r\n\x05\x04\x12\x02\x05\x06\x12\x04\x97\x03\x0b\
-    \x15\n\r\n\x05\x04\x12\x02\x05\x01\x12\x04\x97\x03\x16\x20\n\r\n\x05\x04\
-    \x12\x02\x05\x03\x12\x04\x97\x03#$\nJ\n\x04\x04\x12\x02\x06\x12\x04\x9a\
-    \x03\x025\x1a<\x20Transport\x20Params\x20to\x20if\x20`allow_registrar_ov\
-    errides`\x20is\x20set.\n\n\r\n\x05\x04\x12\x02\x06\x04\x12\x04\x9a\x03\
-    \x02\n\n\r\n\x05\x04\x12\x02\x06\x06\x12\x04\x9a\x03\x0b\x1e\n\r\n\x05\
-    \x04\x12\x02\x06\x01\x12\x04\x9a\x03\x1f/\n\r\n\x05\x04\x12\x02\x06\x03\
-    \x12\x04\x9a\x0324\n!\n\x02\x04\x13\x12\x06\x9e\x03\0\xa2\x03\x01\x1a\
-    \x13\x20response\x20from\x20dns\n\n\x0b\n\x03\x04\x13\x01\x12\x04\x9e\
-    \x03\x08\x13\n\x0c\n\x04\x04\x13\x02\0\x12\x04\x9f\x03\x04\x1e\n\r\n\x05\
-    \x04\x13\x02\0\x04\x12\x04\x9f\x03\x04\x0c\n\r\n\x05\x04\x13\x02\0\x05\
-    \x12\x04\x9f\x03\r\x11\n\r\n\x05\x04\x13\x02\0\x01\x12\x04\x9f\x03\x12\
-    \x19\n\r\n\x05\x04\x13\x02\0\x03\x12\x04\x9f\x03\x1c\x1d\n\x0c\n\x04\x04\
-    \x13\x02\x01\x12\x04\xa0\x03\x04*\n\r\n\x05\x04\x13\x02\x01\x04\x12\x04\
-    \xa0\x03\x04\x0c\n\r\n\x05\x04\x13\x02\x01\x05\x12\x04\xa0\x03\r\x11\n\r\
-    \n\x05\x04\x13\x02\x01\x01\x12\x04\xa0\x03\x12%\n\r\n\x05\x04\x13\x02\
-    \x01\x03\x12\x04\xa0\x03()\n\x0c\n\x04\x04\x13\x02\x02\x12\x04\xa1\x03\
-    \x04=\n\r\n\x05\x04\x13\x02\x02\x04\x12\x04\xa1\x03\x04\x0c\n\r\n\x05\
-    \x04\x13\x02\x02\x06\x12\x04\xa1\x03\r!\n\r\n\x05\x04\x13\x02\x02\x01\
-    \x12\x04\xa1\x03\"8\n\r\n\x05\x04\x13\x02\x02\x03\x12\x04\xa1\x03;<\
+    \x02\x04\x03\x12\x04\xdf\x01\x1f\x20\n\xb0\x01\n\x04\x04\x0b\x02\x05\x12\
+    \x04\xe2\x01\x04\x20\x1a\xa1\x01\x20The\x20client\x20carries\x20many\x20\
+    streams\x20over\x20its\x20phantom\x20connection\x20using\x20the\x20strea\
+    m\n\x20multiplexer\x20in\x20pkg/mux,\x20and\x20the\x20station\x20dials\
+    \x20the\x20covert\x20address\x20once\x20per\x20stream.\n\n\r\n\x05\x04\
+    \x0b\x02\x05\x04\x12\x04\xe2\x01\x04\x0c\n\r\n\x05\x04\x0b\x02\x05\x05\
+    \x12\x04\xe2\x01\r\x11\n\r\n\x05\x04\x0b\x02\x05\x01\x12\x04\xe2\x01\x12\
+    \x1b\n\r\n\x05\x04\x0b\x02\x05\x03\x12\x04\xe2\x01\x1e\x1f\n\x0c\n\x02\
+    \x04\x0c\x12\x06\xe5\x01\0\x9f\x02\x01\n\x0b\n\x03\x04\x0c\x01\x12\x04\
+    \xe5\x01\x08\x17\n\x0c\n\x04\x04\x0c\x02\0\x12\x04\xe6\x01\x04)\n\r\n\
+    \x05\x04\x0c\x02\0\x04\x12\x04\xe6\x01\x04\x0c\n\r\n\x05\x04\x0c\x02\0\
+    \x05\x12\x04\xe6\x01\r\x13\n\r\n\x05\x04\x0c\x02\0\x01\x12\x04\xe6\x01\
+    \x14$\n\r\n\x05\x04\x0c\x02\0\x03\x12\x04\xe6\x01'(\n\xd0\x01\n\x04\x04\
+    \x0c\x02\x01\x12\x04\xeb\x01\x04.\x1a\xc1\x01\x20The\x20client\x20report\
+    s\x20its\x20decoy\x20list's\x20version\x20number\x20here,\x20which\x20th\
+    e\n\x20station\x20can\x20use\x20to\x20decide\x20whether\x20to\x20send\
+    \x20an\x20updated\x20one.\x20The\x20station\n\x20should\x20always\x20sen\
+    d\x20a\x20list\x20if\x20this\x20field\x20is\x20set\x20to\x200.\n\n\r\n\
+    \x05\x04\x0c\x02\x01\x04\x12\x04\xeb\x01\x04\x0c\n\r\n\x05\x04\x0c\x02\
+    \x01\x05\x12\x04\xeb\x01\r\x13\n\r\n\x05\x04\x0c\x02\x01\x01\x12\x04\xeb\
+    \x01\x14)\n\r\n\x05\x04\x0c\x02\x01\x03\x12\x04\xeb\x01,-\n\x0c\n\x04\
+    \x04\x0c\x02\x02\x12\x04\xed\x01\x041\n\r\n\x05\x04\x0c\x02\x02\x04\x12\
+    \x04\xed\x01\x04\x0c\n\r\n\x05\x04\x0c\x02\x02\x06\x12\x04\xed\x01\r\x1b\
+    \n\r\n\x05\x04\x0c\x02\x02\x01\x12\x04\xed\x01\x1c,\n\r\n\x05\x04\x0c\
+    \x02\x02\x03\x12\x04\xed\x01/0\n\x80\x01\n\x04\x04\x0c\x02\x03\x12\x04\
+    \xf1\x01\x04$\x1ar\x20The\x20position\x20in\x20the\x20overall\x20session\
+    's\x20upload\x20sequence\x20where\x20the\x20current\n\x20YIELD=>ACQUIRE\
+    \x20switchover\x20is\x20happening.\n\n\r\n\x05\x04\x0c\x02\x03\x04\x12\
+    \x04\xf1\x01\x04\x0c\n\r\n\x05\x04\x0c\x02\x03\x05\x12\x04\xf1\x01\r\x13\
+    \n\r\n\x05\x04\x0c\x02\x03\x01\x12\x04\xf1\x01\x14\x1f\n\r\n\x05\x04\x0c\
+    \x02\x03\x03\x12\x04\xf1\x01\"#\ng\n\x04\x04\x0c\x02\x04\x12\x04\xf5\x01\
+    \x04+\x1aY\x20High\x20level\x20client\x20library\x20version\x20used\x20f\
+    or\x20indicating\x20feature\x20support,\x20or\n\x20lack\x20therof.\n\n\r\
+    \n\x05\x04\x0c\x02\x04\x04\x12\x04\xf5\x01\x04\x0c\n\r\n\x05\x04\x0c\x02\
+    \x04\x05\x12\x04\xf5\x01\r\x13\n\r\n\x05\x04\x0c\x02\x04\x01\x12\x04\xf5\
+    \x01\x14&\n\r\n\x05\x04\x0c\x02\x04\x03\x12\x04\xf5\x01)*\n\xa5\x02\n\
+    \x04\x04\x0c\x02\x05\x12\x04\xfa\x01\x042\x1a\x96\x02\x20Indicates\x20wh\
+    ether\x20the\x20client\x20will\x20allow\x20the\x20registrar\x20to\x20pro\
+    vide\x20alternative\x20parameters\x20that\n\x20may\x20work\x20better\x20\
+    in\x20substitute\x20for\x20the\x20deterministically\x20selected\x20param\
+    eters.\x20This\x20only\x20works\n\x20for\x20bidirectional\x20registratio\
+    n\x20methods\x20where\x20the\x20client\x20receives\x20a\x20RegistrationR\
+    esponse.\n\n\r\n\x05\x04\x0c\x02\x05\x04\x12\x04\xfa\x01\x04\x0c\n\r\n\
+    \x05\x04\x0c\x02\x05\x05\x12\x04\xfa\x01\r\x11\n\r\n\x05\x04\x0c\x02\x05\
+    \x01\x12\x04\xfa\x01\x12-\n\r\n\x05\x04\x0c\x02\x05\x03\x12\x04\xfa\x010\
+    1\nq\n\x04\x04\x0c\x02\x06\x12\x04\xfe\x01\x04'\x1ac\x20List\x20of\x20de\
+    coys\x20that\x20client\x20have\x20unsuccessfully\x20tried\x20in\x20curre\
+    nt\x20session.\n\x20Could\x20be\x20sent\x20in\x20chunks\n\n\r\n\x05\x04\
+    \x0c\x02\x06\x04\x12\x04\xfe\x01\x04\x0c\n\r\n\x05\x04\x0c\x02\x06\x05\
+    \x12\x04\xfe\x01\r\x13\n\r\n\x05\x04\x0c\x02\x06\x01\x12\x04\xfe\x01\x14\
+    !\n\r\n\x05\x04\x0c\x02\x06\x03\x12\x04\xfe\x01$&\n\x0c\n\x04\x04\x0c\
+    \x02\x07\x12\x04\x80\x02\x04%\n\r\n\x05\x04\x0c\x02\x07\x04\x12\x04\x80\
+    \x02\x04\x0c\n\r\n\x05\x04\x0c\x02\x07\x06\x12\x04\x80\x02\r\x19\n\r\n\
+    \x05\x04\x0c\x02\x07\x01\x12\x04\x80\x02\x1a\x1f\n\r\n\x05\x04\x0c\x02\
+    \x07\x03\x12\x04\x80\x02\"$\nk\n\x04\x04\x0c\x02\x08\x12\x04\x83\x02\x04\
+    *\x1a]\x20NullTransport,\x20MinTransport,\x20Obfs4Transport,\x20etc.\x20\
+    Transport\x20type\x20we\x20want\x20from\x20phantom\x20proxy\n\n\r\n\x05\
+    \x04\x0c\x02\x08\x04\x12\x04\x83\x02\x04\x0c\n\r\n\x05\x04\x0c\x02\x08\
+    \x06\x12\x04\x83\x02\r\x1a\n\r\n\x05\x04\x0c\x02\x08\x01\x12\x04\x83\x02\
+    \x1b$\n\r\n\x05\x04\x0c\x02\x08\x03\x12\x04\x83\x02')\n\x0c\n\x04\x04\
+    \x0c\x02\t\x12\x04\x85\x02\x047\n\r\n\x05\x04\x0c\x02\t\x04\x12\x04\x85\
+    \x02\x04\x0c\n\r\n\x05\x04\x0c\x02\t\x06\x12\x04\x85\x02\r\x20\n\r\n\x05\
+    \x04\x0c\x02\t\x01\x12\x04\x85\x02!1\n\r\n\x05\x04\x0c\x02\t\x03\x12\x04\
+    \x85\x0246\n\xc8\x03\n\x04\x04\x0c\x02\n\x12\x04\x8d\x02\x04(\x1a\xb9\
+    \x03\x20Station\x20is\x20only\x20required\x20to\x20check\x20this\x20vari\
+    able\x20during\x20session\x20initialization.\n\x20If\x20set,\x20station\
+    \x20must\x20facilitate\x20connection\x20to\x20said\x20target\x20by\x20it\
+    self,\x20i.e.\x20write\x20into\x20squid\n\x20socket\x20an\x20HTTP/SOCKS/\
+    any\x20other\x20connection\x20request.\n\x20covert_address\x20must\x20ha\
+    ve\x20exactly\x20one\x20':'\x20colon,\x20that\x20separates\x20host\x20(l\
+    iteral\x20IP\x20address\x20or\n\x20resolvable\x20hostname)\x20and\x20por\
+    t\n\x20TODO:\x20make\x20it\x20required\x20for\x20initialization,\x20and\
+    \x20stop\x20connecting\x20any\x20client\x20straight\x20to\x20squid?\n\n\
+    \r\n\x05\x04\x0c\x02\n\x04\x12\x04\x8d\x02\x04\x0c\n\r\n\x05\x04\x0c\x02\
+    \n\x05\x12\x04\x8d\x02\r\x13\n\r\n\x05\x04\x0c\x02\n\x01\x12\x04\x8d\x02\
+    \x14\"\n\r\n\x05\x04\x0c\x02\n\x03\x12\x04\x8d\x02%'\nR\n\x04\x04\x0c\
+    \x02\x0b\x12\x04\x90\x02\x042\x1aD\x20Used\x20in\x20dark\x20decoys\x20to\
+    \x20signal\x20which\x20dark\x20decoy\x20it\x20will\x20connect\x20to.\n\n\
+    \r\n\x05\x04\x0c\x02\x0b\x04\x12\x04\x90\x02\x04\x0c\n\r\n\x05\x04\x0c\
+    \x02\x0b\x05\x12\x04\x90\x02\r\x13\n\r\n\x05\x04\x0c\x02\x0b\x01\x12\x04\
+    \x90\x02\x14,\n\r\n\x05\x04\x0c\x02\x0b\x03\x12\x04\x90\x02/1\nR\n\x04\
+    \x04\x0c\x02\x0c\x12\x04\x93\x02\x04\"\x1aD\x20Used\x20to\x20indicate\
+    \x20to\x20server\x20if\x20client\x20is\x20registering\x20v4,\x20v6\x20or\
+    \x20both\n\n\r\n\x05\x04\x0c\x02\x0c\x04\x12\x04\x93\x02\x04\x0c\n\r\n\
+    \x05\x04\x0c\x02\x0c\x05\x12\x04\x93\x02\r\x11\n\r\n\x05\x04\x0c\x02\x0c\
+    \x01\x12\x04\x93\x02\x12\x1c\n\r\n\x05\x04\x0c\x02\x0c\x03\x12\x04\x93\
+    \x02\x1f!\n\x0c\n\x04\x04\x0c\x02\r\x12\x04\x94\x02\x04\"\n\r\n\x05\x04\
+    \x0c\x02\r\x04\x12\x04\x94\x02\x04\x0c\n\r\n\x05\x04\x0c\x02\r\x05\x12\
+    \x04\x94\x02\r\x11\n\r\n\x05\x04\x0c\x02\r\x01\x12\x04\x94\x02\x12\x1c\n\
+    \r\n\x05\x04\x0c\x02\r\x03\x12\x04\x94\x02\x1f!\nD\n\x04\x04\x0c\x02\x0e\
+    \x12\x04\x97\x02\x04*\x1a6\x20A\x20collection\x20of\x20optional\x20flags\
+    \x20for\x20the\x20registration.\n\n\r\n\x05\x04\x0c\x02\x0e\x04\x12\x04\
+    \x97\x02\x04\x0c\n\r\n\x05\x04\x0c\x02\x0e\x06\x12\x04\x97\x02\r\x1e\n\r\
+    \n\x05\x04\x0c\x02\x0e\x01\x12\x04\x97\x02\x1f$\n\r\n\x05\x04\x0c\x02\
+    \x0e\x03\x12\x04\x97\x02')\nq\n\x04\x04\x0c\x02\x0f\x12\x04\x9b\x02\x04-\
+    \x1ac\x20Transport\x20Extensions\n\x20TODO(jmwample)\x20-\x20move\x20to\
+    \x20WebRTC\x20specific\x20transport\x20params\x20protobuf\x20message.\n\
+    \n\r\n\x05\x04\x0c\x02\x0f\x04\x12\x04\x9b\x02\x04\x0c\n\r\n\x05\x04\x0c\
+    \x02\x0f\x06\x12\x04\x9b\x02\r\x19\n\r\n\x05\x04\x0c\x02\x0f\x01\x12\x04\
+    \x9b\x02\x1a'\n\r\n\x05\x04\x0c\x02\x0f\x03\x12\x04\x9b\x02*,\nG\n\x04\
+    \x04\x0c\x02\x10\x12\x04\x9e\x02\x04!\x1a9\x20Random-sized\x20junk\x20to\
+    \x20defeat\x20packet\x20size\x20fingerprinting.\n\n\r\n\x05\x04\x0c\x02\
+    \x10\x04\x12\x04\x9e\x02\x04\x0c\n\r\n\x05\x04\x0c\x02\x10\x05\x12\x04\
+    \x9e\x02\r\x12\n\r\n\x05\x04\x0c\x02\x10\x01\x12\x04\x9e\x02\x13\x1a\n\r\
+    \n\x05\x04\x0c\x02\x10\x03\x12\x04\x9e\x02\x1d\x20\n\x0c\n\x02\x04\r\x12\
+    \x06\xa2\x02\0\xb4\x02\x01\n\x0b\n\x03\x04\r\x01\x12\x04\xa2\x02\x08\x1d\
+    \n!\n\x04\x04\r\x02\0\x12\x04\xa4\x02\x04!\x1a\x13\x20Prefix\x20Identifi\
+    er\n\n\r\n\x05\x04\r\x02\0\x04\x12\x04\xa4\x02\x04\x0c\n\r\n\x05\x04\r\
+    \x02\0\x05\x12\x04\xa4\x02\r\x12\n\r\n\x05\x04\r\x02\0\x01\x12\x04\xa4\
+    \x02\x13\x1c\n\r\n\x05\x04\r\x02\0\x03\x12\x04\xa4\x02\x1f\x20\n\xc4\x01\
+    \n\x04\x04\r\x02\x01\x12\x04\xa8\x02\x04\x1e\x1a\xb5\x01\x20Prefix\x20by\
+    tes\x20(optional\x20-\x20usually\x20sent\x20from\x20station\x20to\x20cli\
+    ent\x20as\x20override\x20if\x20allowed\x20by\x20C2S)\n\x20as\x20the\x20s\
+    tation\x20cannot\x20take\x20this\x20into\x20account\x20when\x20attemptin\
+    g\x20to\x20identify\x20a\x20connection.\n\n\r\n\x05\x04\r\x02\x01\x04\
+    \x12\x04\xa8\x02\x04\x0c\n\r\n\x05\x04\r\x02\x01\x05\x12\x04\xa8\x02\r\
+    \x12\n\r\n\x05\x04\r\x02\x01\x01\x12\x04\xa8\x02\x13\x19\n\r\n\x05\x04\r\
+    \x02\x01\x03\x12\x04\xa8\x02\x1c\x1d\n\x0c\n\x04\x04\r\x02\x02\x12\x04\
+    \xa9\x02\x04)\n\r\n\x05\x04\r\x02\x02\x04\x12\x04\xa9\x02\x04\x0c\n\r\n\
+    \x05\x04\r\x02\x02\x05\x12\x04\xa9\x02\r\x11\n\r\n\x05\x04\r\x02\x02\x01\
+    \x12\x04\xa9\x02\x12$\n\r\n\x05\x04\r\x02\x02\x03\x12\x04\xa9\x02'(\n\
+    \xed\x02\n\x04\x04\r\x02\x03\x12\x04\xb3\x02\x04*\x1a\xbc\x01\x20Indicat\
+    es\x20whether\x20the\x20client\x20has\x20elected\x20to\x20use\x20destina\
+    tion\x20port\x20randomization.\x20Should\x20be\n\x20checked\x20against\
+    \x20selected\x20transport\x20to\x20ensure\x20that\x20destination\x20port\
+    \x20randomization\x20is\n\x20supported.\n2\x9f\x01\x20//\x20potential\
+    \x20future\x20fields\n\x20obfuscator\x20ID\n\x20tagEncoder\x20ID\x20(&pa\
+    rams?,\x20e.g.\x20format-base64\x20/\x20padding)\n\x20streamEncoder\x20I\
+    D\x20(&params?,\x20e.g.\x20foramat-base64\x20/\x20padding)\n\n\r\n\x05\
+    \x04\r\x02\x03\x04\x12\x04\xb3\x02\x04\x0c\n\r\n\x05\x04\r\x02\x03\x05\
+    \x12\x04\xb3\x02\r\x11\n\r\n\x05\x04\r\x02\x03\x01\x12\x04\xb3\x02\x12$\
+    \n\r\n\x05\x04\r\x02\x03\x03\x12\x04\xb3\x02')\n\x0c\n\x02\x04\x0e\x12\
+    \x06\xb6\x02\0\xbb\x02\x01\n\x0b\n\x03\x04\x0e\x01\x12\x04\xb6\x02\x08\
+    \x1e\n\xcb\x01\n\x04\x04\x0e\x02\0\x12\x04\xba\x02\x04*\x1a\xbc\x01\x20I\
+    ndicates\x20whether\x20the\x20client\x20has\x20elected\x20to\x20use\x20d\
+    estination\x20port\x20randomization.\x20Should\x20be\n\x20checked\x20aga\
+    inst\x20selected\x20transport\x20to\x20ensure\x20that\x20destination\x20\
+    port\x20randomization\x20is\n\x20supported.\n\n\r\n\x05\x04\x0e\x02\0\
+    \x04\x12\x04\xba\x02\x04\x0c\n\r\n\x05\x04\x0e\x02\0\x05\x12\x04\xba\x02\
+    \r\x11\n\r\n\x05\x04\x0e\x02\0\x01\x12\x04\xba\x02\x12$\n\r\n\x05\x04\
+    \x0e\x02\0\x03\x12\x04\xba\x02')\n\x0c\n\x02\x04\x0f\x12\x06\xbd\x02\0\
+    \xca\x02\x01\n\x0b\n\x03\x04\x0f\x01\x12\x04\xbd\x02\x08\x1c\n\xac\x01\n\
+    \x04\x04\x0f\x02\0\x12\x04\xc0\x02\x04!\x1a\x9d\x01\x20Inter-arrival\x20\
+    time\x20obfuscation\x20mode\x20used\x20by\x20both\x20sides\x20of\x20the\
+    \x20connection:\x200\x20(off),\x201\x20(on)\x20or\n\x202\x20(paranoid).\
+    \x20When\x20unset\x20the\x20client\x20uses\x201\x20and\x20the\x20station\
+    \x200.\n\n\r\n\x05\x04\x0f\x02\0\x04\x12\x04\xc0\x02\x04\x0c\n\r\n\x05\
+    \x04\x0f\x02\0\x05\x12\x04\xc0\x02\r\x13\n\r\n\x05\x04\x0f\x02\0\x01\x12\
+    \x04\xc0\x02\x14\x1c\n\r\n\x05\x04\x0f\x02\0\x03\x12\x04\xc0\x02\x1f\x20\
+    \n\xc2\x01\n\x04\x04\x0f\x02\x01\x12\x04\xc4\x02\x04$\x1a\xb3\x01\x20See\
+    d\x20(24\x20bytes)\x20of\x20the\x20packet\x20length\x20and\x20inter-arri\
+    val\x20time\x20distributions\x20of\x20the\x20station\x20side\n\x20of\x20\
+    the\x20connection.\x20When\x20unset\x20the\x20station\x20uses\x20a\x20ra\
+    ndom\x20seed\x20for\x20every\x20connection.\n\n\r\n\x05\x04\x0f\x02\x01\
+    \x04\x12\x04\xc4\x02\x04\x0c\n\r\n\x05\x04\x0f\x02\x01\x05\x12\x04\xc4\
+    \x02\r\x12\n\r\n\x05\x04\x0f\x02\x01\x01\x12\x04\xc4\x02\x13\x1f\n\r\n\
+    \x05\x04\x0f\x02\x01\x03\x12\x04\xc4\x02\"#\n\xcb\x01\n\x04\x04\x0f\x02\
+    \x02\x12\x04\xc9\x02\x04*\x1a\xbc\x01\x20Indicates\x20whether\x20the\x20\
+    client\x20has\x20elected\x20to\x20use\x20destination\x20port\x20randomiz\
+    ation.\x20Should\x20be\n\x20checked\x20against\x20selected\x20transport\
+    \x20to\x20ensure\x20that\x20destination\x20port\x20randomization\x20is\n\
+    \x20supported.\n\n\r\n\x05\x04\x0f\x02\x02\x04\x12\x04\xc9\x02\x04\x0c\n\
+    \r\n\x05\x04\x0f\x02\x02\x05\x12\x04\xc9\x02\r\x11\n\r\n\x05\x04\x0f\x02\
+    \x02\x01\x12\x04\xc9\x02\x12$\n\r\n\x05\x04\x0f\x02\x02\x03\x12\x04\xc9\
+    \x02')\n\x0c\n\x02\x05\x06\x12\x06\xcc\x02\0\xd4\x02\x01\n\x0b\n\x03\x05\
+    \x06\x01\x12\x04\xcc\x02\x05\x17\n\x0c\n\x04\x05\x06\x02\0\x12\x04\xcd\
+    \x02\x02\x12\n\r\n\x05\x05\x06\x02\0\x01\x12\x04\xcd\x02\x02\r\n\r\n\x05\
+    \x05\x06\x02\0\x02\x12\x04\xcd\x02\x10\x11\n\x0c\n\x04\x05\x06\x02\x01\
+    \x12\x04\xce\x02\x08\x15\n\r\n\x05\x05\x06\x02\x01\x01\x12\x04\xce\x02\
+    \x08\x10\n\r\n\x05\x05\x06\x02\x01\x02\x12\x04\xce\x02\x13\x14\n\x0c\n\
+    \x04\x05\x06\x02\x02\x12\x04\xcf\x02\x08\x10\n\r\n\x05\x05\x06\x02\x02\
+    \x01\x12\x04\xcf\x02\x08\x0b\n\r\n\x05\x05\x06\x02\x02\x02\x12\x04\xcf\
+    \x02\x0e\x0f\n\x0c\n\x04\x05\x06\x02\x03\x12\x04\xd0\x02\x02\x16\n\r\n\
+    \x05\x05\x06\x02\x03\x01\x12\x04\xd0\x02\x02\x11\n\r\n\x05\x05\x06\x02\
+    \x03\x02\x12\x04\xd0\x02\x14\x15\n\x0c\n\x04\x05\x06\x02\x04\x12\x04\xd1\
+    \x02\x02\x17\n\r\n\x05\x05\x06\x02\x04\x01\x12\x04\xd1\x02\x02\x12\n\r\n\
+    \x05\x05\x06\x02\x04\x02\x12\x04\xd1\x02\x15\x16\n\x0c\n\x04\x05\x06\x02\
+    \x05\x12\x04\xd2\x02\x02\n\n\r\n\x05\x05\x06\x02\x05\x01\x12\x04\xd2\x02\
+    \x02\x05\n\r\n\x05\x05\x06\x02\x05\x02\x12\x04\xd2\x02\x08\t\n\x0c\n\x04\
+    \x05\x06\x02\x06\x12\x04\xd3\x02\x02\x17\n\r\n\x05\x05\x06\x02\x06\x01\
+    \x12\x04\xd3\x02\x02\x12\n\r\n\x05\x05\x06\x02\x06\x02\x12\x04\xd3\x02\
+    \x15\x16\n\x0c\n\x02\x04\x10\x12\x06\xd6\x02\0\xee\x02\x01\n\x0b\n\x03\
+    \x04\x10\x01\x12\x04\xd6\x02\x08\x12\n\x0c\n\x04\x04\x10\x02\0\x12\x04\
+    \xd7\x02\x02#\n\r\n\x05\x04\x10\x02\0\x04\x12\x04\xd7\x02\x02\n\n\r\n\
+    \x05\x04\x10\x02\0\x05\x12\x04\xd7\x02\x0b\x10\n\r\n\x05\x04\x10\x02\0\
+    \x01\x12\x04\xd7\x02\x11\x1e\n\r\n\x05\x04\x10\x02\0\x03\x12\x04\xd7\x02\
+    !\"\n\x0c\n\x04\x04\x10\x02\x01\x12\x04\xd8\x02\x024\n\r\n\x05\x04\x10\
+    \x02\x01\x04\x12\x04\xd8\x02\x02\n\n\r\n\x05\x04\x10\x02\x01\x06\x12\x04\
+    \xd8\x02\x0b\x1a\n\r\n\x05\x04\x10\x02\x01\x01\x12\x04\xd8\x02\x1b/\n\r\
+    \n\x05\x04\x10\x02\x01\x03\x12\x04\xd8\x0223\n\x0c\n\x04\x04\x10\x02\x02\
+    \x12\x04\xd9\x02\x026\n\r\n\x05\x04\x10\x02\x02\x04\x12\x04\xd9\x02\x02\
+    \n\n\r\n\x05\x04\x10\x02\x02\x06\x12\x04\xd9\x02\x0b\x1d\n\r\n\x05\x04\
+    \x10\x02\x02\x01\x12\x04\xd9\x02\x1e1\n\r\n\x05\x04\x10\x02\x02\x03\x12\
+    \x04\xd9\x0245\nC\n\x04\x04\x10\x02\x03\x12\x04\xdc\x02\x02*\x1a5\x20cli\
+    ent\x20source\x20address\x20when\x20receiving\x20a\x20registration\n\n\r\
+    \n\x05\x04\x10\x02\x03\x04\x12\x04\xdc\x02\x02\n\n\r\n\x05\x04\x10\x02\
+    \x03\x05\x12\x04\xdc\x02\x0b\x10\n\r\n\x05\x04\x10\x02\x03\x01\x12\x04\
+    \xdc\x02\x11%\n\r\n\x05\x04\x10\x02\x03\x03\x12\x04\xdc\x02()\nH\n\x04\
+    \x04\x10\x02\x04\x12\x04\xdf\x02\x02#\x1a:\x20Decoy\x20address\x20used\
+    \x20when\x20registering\x20over\x20Decoy\x20registrar\n\n\r\n\x05\x04\
+    \x10\x02\x04\x04\x12\x04\xdf\x02\x02\n\n\r\n\x05\x04\x10\x02\x04\x05\x12\
+    \x04\xdf\x02\x0b\x10\n\r\n\x05\x04\x10\x02\x04\x01\x12\x04\xdf\x02\x11\
+    \x1e\n\r\n\x05\x04\x10\x02\x04\x03\x12\x04\xdf\x02!\"\n\xeb\x05\n\x04\
+    \x04\x10\x02\x05\x12\x04\xeb\x02\x02:\x1a\xdc\x05\x20The\x20next\x20thre\
+    e\x20fields\x20allow\x20an\x20independent\x20registrar\x20(trusted\x20by\
+    \x20a\x20station\x20w/\x20a\x20zmq\x20keypair)\x20to\n\x20share\x20the\
+    \x20registration\x20overrides\x20that\x20it\x20assigned\x20to\x20the\x20\
+    client\x20with\x20the\x20station(s).\n\x20Registration\x20Respose\x20is\
+    \x20here\x20to\x20allow\x20a\x20parsed\x20object\x20with\x20direct\x20ac\
+    cess\x20to\x20the\x20fields\x20within.\n\x20RegRespBytes\x20provides\x20\
+    a\x20serialized\x20verion\x20of\x20the\x20Registration\x20response\x20so\
+    \x20that\x20the\x20signature\x20of\n\x20the\x20Bidirectional\x20registra\
+    r\x20can\x20be\x20validated\x20before\x20a\x20station\x20applies\x20any\
+    \x20overrides\x20present\x20in\n\x20the\x20Registration\x20Response.\n\n\
+    \x20If\x20you\x20are\x20reading\x20this\x20in\x20the\x20future\x20and\
+    \x20you\x20want\x20to\x20extend\x20the\x20functionality\x20here\x20it\
+    \x20might\n\x20make\x20sense\x20to\x20make\x20the\x20RegistrationRespons\
+    e\x20that\x20is\x20sent\x20to\x20the\x20client\x20a\x20distinct\x20messa\
+    ge\x20from\n\x20the\x20one\x20that\x20gets\x20sent\x20to\x20the\x20stati\
+    ons.\n\n\r\n\x05\x04\x10\x02\x05\x04\x12\x04\xeb\x02\x02\n\n\r\n\x05\x04\
+    \x10\x02\x05\x06\x12\x04\xeb\x02\x0b\x1f\n\r\n\x05\x04\x10\x02\x05\x01\
+    \x12\x04\xeb\x02\x205\n\r\n\x05\x04\x10\x02\x05\x03\x12\x04\xeb\x0289\n\
+    \x0c\n\x04\x04\x10\x02\x06\x12\x04\xec\x02\x02\"\n\r\n\x05\x04\x10\x02\
+    \x06\x04\x12\x04\xec\x02\x02\n\n\r\n\x05\x04\x10\x02\x06\x05\x12\x04\xec\
+    \x02\x0b\x10\n\r\n\x05\x04\x10\x02\x06\x01\x12\x04\xec\x02\x11\x1d\n\r\n\
+    \x05\x04\x10\x02\x06\x03\x12\x04\xec\x02\x20!\n\x0c\n\x04\x04\x10\x02\
+    \x07\x12\x04\xed\x02\x02'\n\r\n\x05\x04\x10\x02\x07\x04\x12\x04\xed\x02\
+    \x02\n\n\r\n\x05\x04\x10\x02\x07\x05\x12\x04\xed\x02\x0b\x10\n\r\n\x05\
+    \x04\x10\x02\x07\x01\x12\x04\xed\x02\x11!\n\r\n\x05\x04\x10\x02\x07\x03\
+    \x12\x04\xed\x02$&\n\x0c\n\x02\x04\x11\x12\x06\xf0\x02\0\xfc\x02\x01\n\
+    \x0b\n\x03\x04\x11\x01\x12\x04\xf0\x02\x08\x14\n9\n\x04\x04\x11\x02\0\
+    \x12\x04\xf1\x02\x04.\"+\x20how\x20many\x20decoys\x20were\x20tried\x20be\
+    fore\x20success\n\n\r\n\x05\x04\x11\x02\0\x04\x12\x04\xf1\x02\x04\x0c\n\
+    \r\n\x05\x04\x11\x02\0\x05\x12\x04\xf1\x02\r\x13\n\r\n\x05\x04\x11\x02\0\
+    \x01\x12\x04\xf1\x02\x14(\n\r\n\x05\x04\x11\x02\0\x03\x12\x04\xf1\x02+-\
+    \nm\n\x04\x04\x11\x02\x01\x12\x04\xf6\x02\x04/\x1a\x1e\x20Applicable\x20\
+    to\x20whole\x20session:\n\"\x1a\x20includes\x20failed\x20attempts\n2#\
+    \x20Timings\x20below\x20are\x20in\x20milliseconds\n\n\r\n\x05\x04\x11\
+    \x02\x01\x04\x12\x04\xf6\x02\x04\x0c\n\r\n\x05\x04\x11\x02\x01\x05\x12\
+    \x04\xf6\x02\r\x13\n\r\n\x05\x04\x11\x02\x01\x01\x12\x04\xf6\x02\x14)\n\
+    \r\n\x05\x04\x11\x02\x01\x03\x12\x04\xf6\x02,.\nR\n\x04\x04\x11\x02\x02\
+    \x12\x04\xf9\x02\x04(\x1a\x1f\x20Last\x20(i.e.\x20successful)\x20decoy:\
+    \n\"#\x20measured\x20during\x20initial\x20handshake\n\n\r\n\x05\x04\x11\
+    \x02\x02\x04\x12\x04\xf9\x02\x04\x0c\n\r\n\x05\x04\x11\x02\x02\x05\x12\
+    \x04\xf9\x02\r\x13\n\r\n\x05\x04\x11\x02\x02\x01\x12\x04\xf9\x02\x14\"\n\
+    \r\n\x05\x04\x11\x02\x02\x03\x12\x04\xf9\x02%'\n%\n\x04\x04\x11\x02\x03\
+    \x12\x04\xfa\x02\x04&\"\x17\x20includes\x20tcp\x20to\x20decoy\n\n\r\n\
+    \x05\x04\x11\x02\x03\x04\x12\x04\xfa\x02\x04\x0c\n\r\n\x05\x04\x11\x02\
+    \x03\x05\x12\x04\xfa\x02\r\x13\n\r\n\x05\x04\x11\x02\x03\x01\x12\x04\xfa\
+    \x02\x14\x20\n\r\n\x05\x04\x11\x02\x03\x03\x12\x04\xfa\x02#%\nB\n\x04\
+    \x04\x11\x02\x04\x12\x04\xfb\x02\x04&\"4\x20measured\x20when\x20establis\
+    hing\x20tcp\x20connection\x20to\x20decot\n\n\r\n\x05\x04\x11\x02\x04\x04\
+    \x12\x04\xfb\x02\x04\x0c\n\r\n\x05\x04\x11\x02\x04\x05\x12\x04\xfb\x02\r\
+    \x13\n\r\n\x05\x04\x11\x02\x04\x01\x12\x04\xfb\x02\x14\x20\n\r\n\x05\x04\
+    \x11\x02\x04\x03\x12\x04\xfb\x02#%\n\x0c\n\x02\x05\x07\x12\x06\xfe\x02\0\
+    \x83\x03\x01\n\x0b\n\x03\x05\x07\x01\x12\x04\xfe\x02\x05\x16\n\x0c\n\x04\
+    \x05\x07\x02\0\x12\x04\xff\x02\x04\x10\n\r\n\x05\x05\x07\x02\0\x01\x12\
+    \x04\xff\x02\x04\x0b\n\r\n\x05\x05\x07\x02\0\x02\x12\x04\xff\x02\x0e\x0f\
+    \n\x0c\n\x04\x05\x07\x02\x01\x12\x04\x80\x03\x04\x0c\n\r\n\x05\x05\x07\
+    \x02\x01\x01\x12\x04\x80\x03\x04\x07\n\r\n\x05\x05\x07\x02\x01\x02\x12\
+    \x04\x80\x03\n\x0b\n\x0c\n\x04\x05\x07\x02\x02\x12\x04\x81\x03\x04\x0f\n\
+    \r\n\x05\x05\x07\x02\x02\x01\x12\x04\x81\x03\x04\n\n\r\n\x05\x05\x07\x02\
+    \x02\x02\x12\x04\x81\x03\r\x0e\n\x0c\n\x04\x05\x07\x02\x03\x12\x04\x82\
+    \x03\x04\x0e\n\r\n\x05\x05\x07\x02\x03\x01\x12\x04\x82\x03\x04\t\n\r\n\
+    \x05\x05\x07\x02\x03\x02\x12\x04\x82\x03\x0c\r\n\x0c\n\x02\x05\x08\x12\
+    \x06\x85\x03\0\x89\x03\x01\n\x0b\n\x03\x05\x08\x01\x12\x04\x85\x03\x05\
+    \x0c\n\x0c\n\x04\x05\x08\x02\0\x12\x04\x86\x03\x04\x0c\n\r\n\x05\x05\x08\
+    \x02\0\x01\x12\x04\x86\x03\x04\x07\n\r\n\x05\x05\x08\x02\0\x02\x12\x04\
+    \x86\x03\n\x0b\n\x0c\n\x04\x05\x08\x02\x01\x12\x04\x87\x03\x04\x0c\n\r\n\
+    \x05\x05\x08\x02\x01\x01\x12\x04\x87\x03\x04\x07\n\r\n\x05\x05\x08\x02\
+    \x01\x02\x12\x04\x87\x03\n\x0b\n\x0c\n\x04\x05\x08\x02\x02\x12\x04\x88\
+    \x03\x04\x0c\n\r\n\x05\x05\x08\x02\x02\x01\x12\x04\x88\x03\x04\x07\n\r\n\
+    \x05\x05\x08\x02\x02\x02\x12\x04\x88\x03\n\x0b\n\x0c\n\x02\x04\x12\x12\
+    \x06\x8b\x03\0\x96\x03\x01\n\x0b\n\x03\x04\x12\x01\x12\x04\x8b\x03\x08\
+    \x19\n\x0c\n\x04\x04\x12\x02\0\x12\x04\x8c\x03\x04#\n\r\n\x05\x04\x12\
+    \x02\0\x04\x12\x04\x8c\x03\x04\x0c\n\r\n\x05\x04\x12\x02\0\x05\x12\x04\
+    \x8c\x03\r\x13\n\r\n\x05\x04\x12\x02\0\x01\x12\x04\x8c\x03\x14\x1e\n\r\n\
+    \x05\x04\x12\x02\0\x03\x12\x04\x8c\x03!\"\n\x0c\n\x04\x04\x12\x02\x01\
+    \x12\x04\x8d\x03\x04\"\n\r\n\x05\x04\x12\x02\x01\x04\x12\x04\x8d\x03\x04\
+    \x0c\n\r\n\x05\x04\x12\x02\x01\x05\x12\x04\x8d\x03\r\x13\n\r\n\x05\x04\
+    \x12\x02\x01\x01\x12\x04\x8d\x03\x14\x1d\n\r\n\x05\x04\x12\x02\x01\x03\
+    \x12\x04\x8d\x03\x20!\n\x0c\n\x04\x04\x12\x02\x02\x12\x04\x8f\x03\x04#\n\
+    \r\n\x05\x04\x12\x02\x02\x04\x12\x04\x8f\x03\x04\x0c\n\r\n\x05\x04\x12\
+    \x02\x02\x05\x12\x04\x8f\x03\r\x13\n\r\n\x05\x04\x12\x02\x02\x01\x12\x04\
+    \x8f\x03\x14\x1e\n\r\n\x05\x04\x12\x02\x02\x03\x12\x04\x8f\x03!\"\n\x0c\
+    \n\x04\x04\x12\x02\x03\x12\x04\x91\x03\x04-\n\r\n\x05\x04\x12\x02\x03\
+    \x04\x12\x04\x91\x03\x04\x0c\n\r\n\x05\x04\x12\x02\x03\x06\x12\x04\x91\
+    \x03\r\x1e\n\r\n\x05\x04\x12\x02\x03\x01\x12\x04\x91\x03\x1f(\n\r\n\x05\
+    \x04\x12\x02\x03\x03\x12\x04\x91\x03+,\n\x0c\n\x04\x04\x12\x02\x04\x12\
+    \x04\x93\x03\x04\"\n\r\n\x05\x04\x12\x02\x04\x04\x12\x04\x93\x03\x04\x0c\
+    \n\r\n\x05\x04\x12\x02\x04\x05\x12\x04\x93\x03\r\x13\n\r\n\x05\x04\x12\
+    \x02\x04\x01\x12\x04\x93\x03\x14\x1c\n\r\n\x05\x04\x12\x02\x04\x03\x12\
+    \x04\x93\x03\x1f!\n\x0c\n\x04\x04\x12\x02\x05\x12\x04\x94\x03\x04\"\n\r\
+    \n\x05\x04\x12\x02\x05\x04\x12\x04\x94\x03\x04\x0c\n\r\n\x05\x04\x12\x02\
+    \x05\x05\x12\x04\x94\x03\r\x13\n\r\n\x05\x04\x12\x02\x05\x01\x12\x04\x94\
+    \x03\x14\x1c\n\r\n\x05\x04\x12\x02\x05\x03\x12\x04\x94\x03\x1f!\n\x0c\n\
+    \x04\x04\x12\x02\x06\x12\x04\x95\x03\x04\x20\n\r\n\x05\x04\x12\x02\x06\
+    \x04\x12\x04\x95\x03\x04\x0c\n\r\n\x05\x04\x12\x02\x06\x06\x12\x04\x95\
+    \x03\r\x14\n\r\n\x05\x04\x12\x02\x06\x01\x12\x04\x95\x03\x15\x1a\n\r\n\
+    \x05\x04\x12\x02\x06\x03\x12\x04\x95\x03\x1d\x1f\nT\n\x02\x04\x13\x12\
+    \x06\x99\x03\0\xad\x03\x01\x1aF\x20Adding\x20message\x20response\x20from\
+    \x20Station\x20to\x20Client\x20for\x20bidirectional\x20API\n\n\x0b\n\x03\
+    \x04\x13\x01\x12\x04\x99\x03\x08\x1c\n\x0c\n\x04\x04\x13\x02\0\x12\x04\
+    \x9a\x03\x02\x20\n\r\n\x05\x04\x13\x02\0\x04\x12\x04\x9a\x03\x02\n\n\r\n\
+    \x05\x04\x13\x02\0\x05\x12\x04\x9a\x03\x0b\x12\n\r\n\x05\x04\x13\x02\0\
+    \x01\x12\x04\x9a\x03\x13\x1b\n\r\n\x05\x04\x13\x02\0\x03\x12\x04\x9a\x03\
+    \x1e\x1f\n?\n\x04\x04\x13\x02\x01\x12\x04\x9c\x03\x02\x1e\x1a1\x20The\
+    \x20128-bit\x20ipv6\x20address,\x20in\x20network\x20byte\x20order\n\n\r\
+    \n\x05\x04\x13\x02\x01\x04\x12\x04\x9c\x03\x02\n\n\r\n\x05\x04\x13\x02\
+    \x01\x05\x12\x04\x9c\x03\x0b\x10\n\r\n\x05\x04\x13\x02\x01\x01\x12\x04\
+    \x9c\x03\x11\x19\n\r\n\x05\x04\x13\x02\x01\x03\x12\x04\x9c\x03\x1c\x1d\n\
+    ,\n\x04\x04\x13\x02\x02\x12\x04\x9f\x03\x02\x1f\x1a\x1e\x20Respond\x20wi\
+    th\x20randomized\x20port\n\n\r\n\x05\x04\x13\x02\x02\x04\x12\x04\x9f\x03\
+    \x02\n\n\r\n\x05\x04\x13\x02\x02\x05\x12\x04\x9f\x03\x0b\x11\n\r\n\x05\
+    \x04\x13\x02\x02\x01\x12\x04\x9f\x03\x12\x1a\n\r\n\x05\x04\x13\x02\x02\
+    \x03\x12\x04\x9f\x03\x1d\x1e\nd\n\x04\x04\x13\x02\x03\x12\x04\xa3\x03\
+    \x02\"\x1aV\x20Future:\x20station\x20provides\x20client\x20with\x20secre\
+    t,\x20want\x20chanel\x20present\n\x20Leave\x20null\x20for\x20now\n\n\r\n\
+    \x05\x04\x13\x02\x03\x04\x12\x04\xa3\x03\x02\n\n\r\n\x05\x04\x13\x02\x03\
+    \x05\x12\x04\xa3\x03\x0b\x10\n\r\n\x05\x04\x13\x02\x03\x01\x12\x04\xa3\
+    \x03\x11\x1d\n\r\n\x05\x04\x13\x02\x03\x03\x12\x04\xa3\x03\x20!\nA\n\x04\
+    \x04\x13\x02\x04\x12\x04\xa6\x03\x02\x1c\x1a3\x20If\x20registration\x20w\
+    rong,\x20populate\x20this\x20error\x20string\n\n\r\n\x05\x04\x13\x02\x04\
+    \x04\x12\x04\xa6\x03\x02\n\n\r\n\x05\x04\x13\x02\x04\x05\x12\x04\xa6\x03\
+    \x0b\x11\n\r\n\x05\x04\x13\x02\x04\x01\x12\x04\xa6\x03\x12\x17\n\r\n\x05\
+    \x04\x13\x02\x04\x03\x12\x04\xa6\x03\x1a\x1b\n+\n\x04\x04\x13\x02\x05\
+    \x12\x04\xa9\x03\x02%\x1a\x1d\x20ClientConf\x20field\x20(optional)\n\n\r\
+    \n\x05\x04\x13\x02\x05\x04\x12\x04\xa9\x03\x02\n\n\r\n\x05\x04\x13\x02\
+    \x05\x06\x12\x04\xa9\x03\x0b\x15\n\r\n\x05\x04\x13\x02\x05\x01\x12\x04\
+    \xa9\x03\x16\x20\n\r\n\x05\x04\x13\x02\x05\x03\x12\x04\xa9\x03#$\nJ\n\
+    \x04\x04\x13\x02\x06\x12\x04\xac\x03\x025\x1a<\x20Transport\x20Params\
+    \x20to\x20if\x20`allow_registrar_overrides`\x20is\x20set.\n\n\r\n\x05\
+    \x04\x13\x02\x06\x04\x12\x04\xac\x03\x02\n\n\r\n\x05\x04\x13\x02\x06\x06\
+    \x12\x04\xac\x03\x0b\x1e\n\r\n\x05\x04\x13\x02\x06\x01\x12\x04\xac\x03\
+    \x1f/\n\r\n\x05\x04\x13\x02\x06\x03\x12\x04\xac\x0324\n!\n\x02\x04\x14\
+    \x12\x06\xb0\x03\0\xb4\x03\x01\x1a\x13\x20response\x20from\x20dns\n\n\
+    \x0b\n\x03\x04\x14\x01\x12\x04\xb0\x03\x08\x13\n\x0c\n\x04\x04\x14\x02\0\
+    \x12\x04\xb1\x03\x04\x1e\n\r\n\x05\x04\x14\x02\0\x04\x12\x04\xb1\x03\x04\
+    \x0c\n\r\n\x05\x04\x14\x02\0\x05\x12\x04\xb1\x03\r\x11\n\r\n\x05\x04\x14\
+    \x02\0\x01\x12\x04\xb1\x03\x12\x19\n\r\n\x05\x04\x14\x02\0\x03\x12\x04\
+    \xb1\x03\x1c\x1d\n\x0c\n\x04\x04\x14\x02\x01\x12\x04\xb2\x03\x04*\n\r\n\
+    \x05\x04\x14\x02\x01\x04\x12\x04\xb2\x03\x04\x0c\n\r\n\x05\x04\x14\x02\
+    \x01\x05\x12\x04\xb2\x03\r\x11\n\r\n\x05\x04\x14\x02\x01\x01\x12\x04\xb2\
+    \x03\x12%\n\r\n\x05\x04\x14\x02\x01\x03\x12\x04\xb2\x03()\n\x0c\n\x04\
+    \x04\x14\x02\x02\x12\x04\xb3\x03\x04=\n\r\n\x05\x04\x14\x02\x02\x04\x12\
+    \x04\xb3\x03\x04\x0c\n\r\n\x05\x04\x14\x02\x02\x06\x12\x04\xb3\x03\r!\n\
+    \r\n\x05\x04\x14\x02\x02\x01\x12\x04\xb3\x03\"8\n\r\n\x05\x04\x14\x02\
+    \x02\x03\x12\x04\xb3\x03;<\
 ";
 
 /// `FileDescriptorProto` object which was a source for this generated file
@@ -7360,7 +7672,7 @@ pub fn file_descriptor() -> &'static ::protobuf::reflect::FileDescriptor {
         let generated_file_descriptor = generated_file_descriptor_lazy.get(|| {
             let mut deps = ::std::vec::Vec::with_capacity(1);
             deps.push(::protobuf::well_known_types::any::file_descriptor().clone());
-            let mut messages = ::std::vec::Vec::with_capacity(20);
+            let mut messages = ::std::vec::Vec::with_capacity(21);
             messages.push(PubKey::generated_message_descriptor_data());
             messages.push(TLSDecoySpec::generated_message_descriptor_data());
             messages.push(ClientConf::generated_message_descriptor_data());
@@ -7376,6 +7688,7 @@ pub fn file_descriptor() -> &'static ::protobuf::reflect::FileDescriptor {
             messages.push(ClientToStation::generated_message_descriptor_data());
             messages.push(PrefixTransportParams::generated_message_descriptor_data());
             messages.push(GenericTransportParams::generated_message_descriptor_data());
+            messages.push(Obfs4TransportParams::generated_message_descriptor_data());
             messages.push(C2SWrapper::generated_message_descriptor_data());
             messages.push(SessionStats::generated_message_descriptor_data());
             messages.push(StationToDetector::generated_message_descriptor_data());